@@ -20,6 +20,7 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenterror"
 	"go.opentelemetry.io/collector/receiver/otlpreceiver"
+	"go.opentelemetry.io/collector/receiver/prometheusremotewritereceiver"
 	"go.opentelemetry.io/collector/service/defaultcomponents"
 )
 
@@ -41,6 +42,7 @@ func components() (component.Factories, error) {
 	// add all default receivers
 	receivers := []component.ReceiverFactory{
 		otlpreceiver.NewFactory(),
+		prometheusremotewritereceiver.NewFactory(),
 	}
 	factories.Receivers, err = component.MakeReceiverFactoryMap(receivers...)
 	if err != nil {
@@ -4,25 +4,42 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"net/http/httputil"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"go.opencensus.io/stats"
+	"go.uber.org/zap"
 )
 
+// credentialsExpiryWindow is how long before they expire the credentials of an assumed role are refreshed.
+const credentialsExpiryWindow = time.Minute
+
+// sensitiveHeaders are the headers whose values are redacted from debug logs, in lower case.
+var sensitiveHeaders = map[string]bool{
+	"authorization":        true,
+	"proxy-authorization":  true,
+	"x-amz-security-token": true,
+}
+
 // SigningRoundTripper is a Custom RoundTripper that performs AWS Sig V4
 type SigningRoundTripper struct {
 	transport http.RoundTripper
 	signer    *v4.Signer
 	cfg       *aws.Config
 	params    AuthSettings
+	logger    *zap.Logger
 }
 
-// RoundTrip signs each outgoing request
+// RoundTrip signs each outgoing request. Errors are returned to the caller, which retries the request if they are
+// transient.
 func (si *SigningRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	reqBody, err := req.GetBody()
 	if err != nil {
@@ -40,78 +57,151 @@ func (si *SigningRoundTripper) RoundTrip(req *http.Request) (*http.Response, err
 	// Sign the request
 	_, err = si.signer.Sign(req, body, si.params.Service, *si.cfg.Region, time.Now())
 	if err != nil {
+		// the context of requests of the exporter carries its name
+		stats.Record(req.Context(), mSigningFailures.M(1))
 		return nil, err
 	}
+	dumpBody := si.params.DebugVerbosity == DebugVerbosityBody
 	if si.params.Debug {
-		requestDump, err := httputil.DumpRequest(req, false)
-		if err != nil {
-			log.Println(err)
+		if requestDump, err := httputil.DumpRequest(req, dumpBody); err != nil {
+			si.logger.Warn("Failed to dump the signed request", zap.Error(err))
+		} else {
+			si.logger.Info("Sending signed request", zap.String("request", redactHeaders(string(requestDump))))
 		}
-		log.Println(string(requestDump))
 	}
 	// Send the request to Cortex
 	resp, err := si.transport.RoundTrip(req)
 	if err != nil {
-		log.Fatal(err)
 		return nil, err
 	}
 
 	if si.params.Debug {
-		responseDump, err := httputil.DumpResponse(resp, false)
-		if err != nil {
-			log.Println(err)
+		if responseDump, err := httputil.DumpResponse(resp, dumpBody); err != nil {
+			si.logger.Warn("Failed to dump the response", zap.Error(err))
+		} else {
+			si.logger.Info("Received response", zap.String("response", redactHeaders(string(responseDump))))
 		}
-		log.Println(string(responseDump))
 	}
 
-	return resp, err
+	return resp, nil
+}
+
+// redactHeaders replaces the values of sensitive headers in dump, a dump of a request or response or a canonical
+// request of the signer, with [REDACTED].
+func redactHeaders(dump string) string {
+	lines := strings.Split(dump, "\n")
+	for i, line := range lines {
+		colon := strings.IndexByte(line, ':')
+		if colon <= 0 || !sensitiveHeaders[strings.ToLower(strings.TrimSpace(line[:colon]))] {
+			continue
+		}
+		sep := ""
+		if strings.HasPrefix(line[colon+1:], " ") {
+			sep = " "
+		}
+		lines[i] = line[:colon+1] + sep + "[REDACTED]"
+		if strings.HasSuffix(line, "\r") {
+			lines[i] += "\r"
+		}
+	}
+	return strings.Join(lines, "\n")
 }
 
 // NewAuth takes a map of strings as parameters and return a http.RoundTripper that perform Sig V4 signing on each
-// request.
-func NewAuth(params AuthSettings, origClient *http.Client) (http.RoundTripper, error) {
+// request. Debug information is logged to logger if enabled.
+func NewAuth(params AuthSettings, origClient *http.Client, logger *zap.Logger) (http.RoundTripper, error) {
 	// check if region and service name are present
 	err := validateAuthSettings(params)
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
 
-	// Initialize session with default credential chain
+	// Initialize session with static credentials if configured, or with the default credential chain
 	// https://docs.aws.amazon.com/sdk-for-go/v1/developer-guide/configuring-sdk.html
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String(params.Region)},
-		aws.NewConfig().WithLogLevel(aws.LogDebugWithSigning),
-	)
+	cfg := &aws.Config{Region: aws.String(params.Region)}
+	if params.AccessKeyID != "" {
+		cfg.Credentials = credentials.NewStaticCredentials(params.AccessKeyID, params.SecretAccessKey,
+			params.SessionToken)
+	}
+	awsLogger := aws.LoggerFunc(func(args ...interface{}) {
+		logger.Info("AWS SDK", zap.String("message", redactHeaders(fmt.Sprint(args...))))
+	})
+	sessCfg := aws.NewConfig()
+	if params.Debug {
+		sessCfg = sessCfg.WithLogLevel(aws.LogDebugWithSigning).WithLogger(awsLogger)
+	}
+	sess, err := session.NewSession(cfg, sessCfg)
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
-	if _, err = sess.Config.Credentials.Get(); err != nil {
-		log.Println(err)
+	// Get Credentials, either from the session or by assuming a role
+	creds := newCredentials(sess, params)
+	if _, err = creds.Get(); err != nil {
 		return nil, err
 	}
-
-	// Get Credentials, either from ./aws or from environmental variables
-	creds := sess.Config.Credentials
 	signer := v4.NewSigner(creds)
 	if params.Debug {
 		signer.Debug = aws.LogDebugWithSigning
-		signer.Logger = aws.NewDefaultLogger()
+		signer.Logger = awsLogger
 	}
 	rtp := SigningRoundTripper{
 		transport: origClient.Transport,
 		signer:    signer,
 		cfg:       sess.Config,
 		params:    params,
+		logger:    logger,
 	}
 	// return a RoundTripper
 	return &rtp, nil
 }
+
+// newCredentials returns the credentials of sess, or the credentials of the role assumed with them or with a web
+// identity token if a role is configured. Credentials of an assumed role are cached, and refreshed
+// credentialsExpiryWindow before they expire.
+func newCredentials(sess *session.Session, params AuthSettings) *credentials.Credentials {
+	if params.RoleARN == "" {
+		return sess.Config.Credentials
+	}
+
+	stsCfg := aws.NewConfig()
+	if params.STSEndpoint != "" {
+		stsCfg = stsCfg.WithEndpoint(params.STSEndpoint)
+	}
+	svc := sts.New(sess, stsCfg)
+
+	if params.WebIdentityTokenFile != "" {
+		provider := stscreds.NewWebIdentityRoleProvider(svc, params.RoleARN, params.SessionName,
+			params.WebIdentityTokenFile)
+		provider.ExpiryWindow = credentialsExpiryWindow
+		return credentials.NewCredentials(provider)
+	}
+	return stscreds.NewCredentialsWithClient(svc, params.RoleARN, func(p *stscreds.AssumeRoleProvider) {
+		if params.ExternalID != "" {
+			p.ExternalID = aws.String(params.ExternalID)
+		}
+		p.RoleSessionName = params.SessionName
+		p.ExpiryWindow = credentialsExpiryWindow
+	})
+}
+
 func validateAuthSettings(params AuthSettings) error {
 	if params.Enabled && params.Region == "" || params.Service == "" {
 		return fmt.Errorf("invalid authentication configuration")
 	}
+	switch params.DebugVerbosity {
+	case "", DebugVerbosityHeaders, DebugVerbosityBody:
+	default:
+		return fmt.Errorf("invalid authentication configuration: unknown debug_verbosity %q", params.DebugVerbosity)
+	}
+	if (params.AccessKeyID == "") != (params.SecretAccessKey == "") {
+		return fmt.Errorf("invalid authentication configuration: access_key_id and secret_access_key must be set together")
+	}
+	if params.RoleARN == "" && (params.WebIdentityTokenFile != "" || params.ExternalID != "") {
+		return fmt.Errorf("invalid authentication configuration: role_arn must be set to assume a role")
+	}
 	return nil
 }
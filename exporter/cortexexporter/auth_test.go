@@ -0,0 +1,338 @@
+package cortexexporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// stsStub is a local stand-in of STS issuing credentials for AssumeRole and AssumeRoleWithWebIdentity.
+type stsStub struct {
+	mu sync.Mutex
+	// issued is the number of credentials issued
+	issued int
+	// requests are the form values of each request
+	requests []map[string]string
+	// lifetime is how long issued credentials are valid
+	lifetime time.Duration
+}
+
+func (s *stsStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.issued++
+	values := map[string]string{}
+	for k := range r.PostForm {
+		values[k] = r.PostForm.Get(k)
+	}
+	s.requests = append(s.requests, values)
+
+	action := r.PostForm.Get("Action")
+	w.Header().Set("Content-Type", "text/xml")
+	fmt.Fprintf(w, `<%[1]sResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <%[1]sResult>
+    <Credentials>
+      <AccessKeyId>ASSUMED%[2]d</AccessKeyId>
+      <SecretAccessKey>secret%[2]d</SecretAccessKey>
+      <SessionToken>token%[2]d</SessionToken>
+      <Expiration>%[3]s</Expiration>
+    </Credentials>
+  </%[1]sResult>
+  <ResponseMetadata><RequestId>%[2]d</RequestId></ResponseMetadata>
+</%[1]sResponse>`, action, s.issued, time.Now().Add(s.lifetime).UTC().Format(time.RFC3339))
+}
+
+// newSigV4HeaderServer returns a stand-in for Cortex recording the access key ID and the security token each request
+// is signed with.
+func newSigV4HeaderServer(keys *[]string) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		auth := r.Header.Get("Authorization")
+		key := ""
+		if i := strings.Index(auth, "Credential="); i >= 0 {
+			key = strings.SplitN(auth[i+len("Credential="):], "/", 2)[0]
+		}
+		*keys = append(*keys, key+":"+r.Header.Get("X-Amz-Security-Token"))
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func sendSignedRequest(t *testing.T, rt http.RoundTripper, url string) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader([]byte("body")))
+	require.NoError(t, err)
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+// Test_NewAuthCredentials checks that requests are signed with static credentials, or with the credentials of a role
+// assumed from a local STS stub, which are cached and refreshed before they expire.
+func Test_NewAuthCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cortexexporter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	tokenPath := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(tokenPath, []byte("web-identity-token"), 0600))
+
+	static := AuthSettings{
+		Enabled:         true,
+		Region:          "us-west-2",
+		Service:         "aps",
+		AccessKeyID:     "STATIC",
+		SecretAccessKey: "secret",
+	}
+
+	tests := []struct {
+		name     string
+		settings func(stsURL string) AuthSettings
+		lifetime time.Duration
+		// keys are the expected access key ID and security token of each of three requests
+		keys []string
+		// action is the expected STS action, if any
+		action string
+		form   map[string]string
+	}{
+		{
+			name:     "static_credentials",
+			settings: func(string) AuthSettings { return static },
+			keys:     []string{"STATIC:", "STATIC:", "STATIC:"},
+		},
+		{
+			name: "assume_role",
+			settings: func(stsURL string) AuthSettings {
+				s := static
+				s.RoleARN = "arn:aws:iam::123456789012:role/writer"
+				s.ExternalID = "external"
+				s.SessionName = "collector"
+				s.STSEndpoint = stsURL
+				return s
+			},
+			lifetime: time.Hour,
+			keys:     []string{"ASSUMED1:token1", "ASSUMED1:token1", "ASSUMED1:token1"},
+			action:   "AssumeRole",
+			form: map[string]string{
+				"RoleArn":         "arn:aws:iam::123456789012:role/writer",
+				"ExternalId":      "external",
+				"RoleSessionName": "collector",
+			},
+		},
+		{
+			name: "assume_role_refreshed_before_expiry",
+			settings: func(stsURL string) AuthSettings {
+				s := static
+				s.RoleARN = "arn:aws:iam::123456789012:role/writer"
+				s.STSEndpoint = stsURL
+				return s
+			},
+			// credentials expiring within the expiry window are refreshed before each request, after being retrieved
+			// once by NewAuth
+			lifetime: credentialsExpiryWindow / 2,
+			keys:     []string{"ASSUMED2:token2", "ASSUMED3:token3", "ASSUMED4:token4"},
+			action:   "AssumeRole",
+			form:     map[string]string{"RoleArn": "arn:aws:iam::123456789012:role/writer"},
+		},
+		{
+			name: "web_identity",
+			settings: func(stsURL string) AuthSettings {
+				return AuthSettings{
+					Enabled:              true,
+					Region:               "us-west-2",
+					Service:              "aps",
+					RoleARN:              "arn:aws:iam::123456789012:role/writer",
+					SessionName:          "collector",
+					WebIdentityTokenFile: tokenPath,
+					STSEndpoint:          stsURL,
+				}
+			},
+			lifetime: time.Hour,
+			keys:     []string{"ASSUMED1:token1", "ASSUMED1:token1", "ASSUMED1:token1"},
+			action:   "AssumeRoleWithWebIdentity",
+			form: map[string]string{
+				"RoleArn":          "arn:aws:iam::123456789012:role/writer",
+				"RoleSessionName":  "collector",
+				"WebIdentityToken": "web-identity-token",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stsStub{lifetime: tt.lifetime}
+			stsServer := httptest.NewServer(stub)
+			defer stsServer.Close()
+
+			var keys []string
+			server := newSigV4HeaderServer(&keys)
+			defer server.Close()
+
+			rt, err := NewAuth(tt.settings(stsServer.URL), &http.Client{Transport: http.DefaultTransport}, zap.NewNop())
+			require.NoError(t, err)
+			for range tt.keys {
+				sendSignedRequest(t, rt, server.URL)
+			}
+			assert.Equal(t, tt.keys, keys)
+
+			if tt.action == "" {
+				assert.Empty(t, stub.requests)
+				return
+			}
+			require.NotEmpty(t, stub.requests)
+			for _, req := range stub.requests {
+				assert.Equal(t, tt.action, req["Action"])
+				for k, v := range tt.form {
+					assert.Equal(t, v, req[k], k)
+				}
+			}
+		})
+	}
+}
+
+// Test_validateAuthSettings checks that incomplete credential configurations and unknown debug verbosities are
+// rejected.
+func Test_validateAuthSettings(t *testing.T) {
+	base := AuthSettings{Enabled: true, Region: "us-west-2", Service: "aps"}
+	tests := []struct {
+		name        string
+		update      func(*AuthSettings)
+		returnError bool
+	}{
+		{"default_chain", func(*AuthSettings) {}, false},
+		{"missing_service", func(s *AuthSettings) { s.Service = "" }, true},
+		{"static_credentials", func(s *AuthSettings) { s.AccessKeyID, s.SecretAccessKey = "key", "secret" }, false},
+		{"missing_secret_access_key", func(s *AuthSettings) { s.AccessKeyID = "key" }, true},
+		{"missing_access_key_id", func(s *AuthSettings) { s.SecretAccessKey = "secret" }, true},
+		{"web_identity_without_role", func(s *AuthSettings) { s.WebIdentityTokenFile = "token" }, true},
+		{"external_id_without_role", func(s *AuthSettings) { s.ExternalID = "external" }, true},
+		{"assume_role", func(s *AuthSettings) { s.RoleARN, s.ExternalID = "role", "external" }, false},
+		{"debug_body", func(s *AuthSettings) { s.Debug, s.DebugVerbosity = true, DebugVerbosityBody }, false},
+		{"unknown_debug_verbosity", func(s *AuthSettings) { s.DebugVerbosity = "all" }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := base
+			tt.update(&settings)
+			err := validateAuthSettings(settings)
+			if tt.returnError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// Test_SigningFailures checks that requests that fail to be signed are recorded in the metrics of the exporter.
+func Test_SigningFailures(t *testing.T) {
+	views := MetricViews()
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	rt := &SigningRoundTripper{
+		transport: http.DefaultTransport,
+		signer:    v4.NewSigner(credentials.NewCredentials(&credentials.StaticProvider{})),
+		cfg:       &aws.Config{Region: aws.String("us-west-2")},
+		params:    AuthSettings{Enabled: true, Region: "us-west-2", Service: "aps"},
+	}
+	ctx, err := tag.New(context.Background(), tag.Insert(tagExporterName, "cortex/signing"))
+	require.NoError(t, err)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://localhost", bytes.NewReader([]byte("body")))
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req)
+	require.Error(t, err)
+
+	rows, err := view.RetrieveData(mSigningFailures.Name())
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, []tag.Tag{{Key: tagExporterName, Value: "cortex/signing"}}, rows[0].Tags)
+	assert.Equal(t, &view.SumData{Value: 1}, rows[0].Data)
+}
+
+// Test_SigningRoundTripperDebug checks that signed requests and their responses are logged with their sensitive
+// headers redacted, along with their bodies at the body verbosity, and that transport errors are returned.
+func Test_SigningRoundTripperDebug(t *testing.T) {
+	var keys []string
+	server := newSigV4HeaderServer(&keys)
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		verbosity string
+		body      bool
+	}{
+		{"headers_by_default", "", false},
+		{"headers", DebugVerbosityHeaders, false},
+		{"body", DebugVerbosityBody, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+			rt, err := NewAuth(AuthSettings{
+				Enabled:         true,
+				Region:          "us-west-2",
+				Service:         "aps",
+				Debug:           true,
+				DebugVerbosity:  tt.verbosity,
+				AccessKeyID:     "STATIC",
+				SecretAccessKey: "secret",
+				SessionToken:    "session-token",
+			}, &http.Client{Transport: http.DefaultTransport}, zap.New(core))
+			require.NoError(t, err)
+			sendSignedRequest(t, rt, server.URL)
+
+			var messages []string
+			for _, entry := range logs.All() {
+				messages = append(messages, fmt.Sprint(entry.ContextMap()))
+			}
+			all := strings.Join(messages, "\n")
+			assert.Contains(t, all, "[REDACTED]")
+			assert.NotContains(t, all, "session-token")
+			assert.NotContains(t, all, "Signature=")
+			assert.Equal(t, tt.body, strings.Contains(all, "body"))
+		})
+	}
+
+	rt, err := NewAuth(AuthSettings{
+		Enabled:         true,
+		Region:          "us-west-2",
+		Service:         "aps",
+		AccessKeyID:     "STATIC",
+		SecretAccessKey: "secret",
+	}, &http.Client{Transport: http.DefaultTransport}, zap.NewNop())
+	require.NoError(t, err)
+	server.Close()
+	req, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader([]byte("body")))
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req)
+	assert.Error(t, err)
+}
+
+func Test_redactHeaders(t *testing.T) {
+	dump := "POST / HTTP/1.1\r\nAuthorization: AWS4-HMAC-SHA256 Credential=KEY\r\nX-Amz-Date: 20200101T000000Z\r\n" +
+		"X-Amz-Security-Token: token\r\n\r\nx-amz-security-token:token\nhost:localhost"
+	assert.Equal(t, "POST / HTTP/1.1\r\nAuthorization: [REDACTED]\r\nX-Amz-Date: 20200101T000000Z\r\n"+
+		"X-Amz-Security-Token: [REDACTED]\r\n\r\nx-amz-security-token:[REDACTED]\nhost:localhost", redactHeaders(dump))
+}
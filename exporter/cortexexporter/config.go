@@ -1,9 +1,12 @@
 package cortexexporter
 
 import (
+	"github.com/prometheus/prometheus/pkg/relabel"
+
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/config/configmodels"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	prw "go.opentelemetry.io/collector/exporter/prometheusremotewriteexporter"
 )
 
 // Config defines configuration for Remote Write exporter.
@@ -18,12 +21,59 @@ type Config struct {
 	// See: https://prometheus.io/docs/practices/naming/#metric-names
 	Namespace string `mapstructure:"namespace"`
 
+	// resource attributes attached to each exported TimeSeries as labels
+	ResourceAttributes prw.ResourceAttributesSettings `mapstructure:"resource_attributes"`
+
+	// on-disk write-ahead log buffering batches until they are accepted by the endpoint
+	WAL prw.WALSettings `mapstructure:"wal"`
+
+	// how exported TimeSeries are split into requests sent concurrently
+	Shards prw.ShardSettings `mapstructure:"shards"`
+
+	// whether and how metric metadata is sent with remote write
+	Metadata prw.MetadataSettings `mapstructure:"metadata"`
+
+	// whether and how delta sums and histograms are converted to cumulative ones
+	DeltaToCumulative prw.DeltaToCumulativeSettings `mapstructure:"delta_to_cumulative"`
+
+	// whether and how exported TimeSeries are routed to tenants with the X-Scope-OrgID header
+	Tenant prw.TenantSettings `mapstructure:"tenant"`
+
+	// how metric names are translated to Prometheus metric names
+	Naming prw.NamingSettings `mapstructure:"naming"`
+
+	// whether and when staleness markers are sent for series that are no longer exported
+	Staleness prw.StalenessSettings `mapstructure:"staleness"`
+	// whether and how many exemplars of histograms and sums are sent with their series
+	Exemplars prw.ExemplarSettings `mapstructure:"exemplars"`
+	// which of the samples of a series with the same timestamp is sent
+	Duplicates prw.DuplicateSettings `mapstructure:"duplicates"`
+	// the remote write protocol version and compression of requests
+	Protocol prw.ProtocolSettings `mapstructure:"protocol"`
+
+	// relabel configs applied to each series before it is sent, parsed from WriteRelabelConfigsPlaceholder by the
+	// custom unmarshaler of the factory
+	WriteRelabelConfigs []*relabel.Config `mapstructure:"-"`
+	// holds the write_relabel_configs setting until it is parsed, so that the key is known
+	WriteRelabelConfigsPlaceholder interface{} `mapstructure:"write_relabel_configs"`
+
 	// AWS Sig V4 configuration options
 	AuthSettings AuthSettings `mapstructure:"aws_auth"`
 
+	// basic auth, bearer token and OAuth2 configuration options, which are exclusive with AWS Sig V4
+	HTTPAuth HTTPAuthSettings `mapstructure:"auth"`
+
 	HTTPClientSettings confighttp.HTTPClientSettings `mapstructure:",squash"`
 }
 
+// Verbosity levels of the debug information of AWS Sig V4.
+const (
+	// DebugVerbosityHeaders logs the signature, and the headers of each request and response.
+	DebugVerbosityHeaders = "headers"
+	// DebugVerbosityBody also logs the body of each request and response.
+	DebugVerbosityBody = "body"
+)
+
 // AuthSettings defines AWS authentication configurations for SigningRoundTripper
 type AuthSettings struct {
 	Enabled bool `mapstructure:"enabled"`
@@ -31,6 +81,71 @@ type AuthSettings struct {
 	Region string `mapstructure:"region"`
 	// service string for AWS Sig V4
 	Service string `mapstructure:"service"`
-	// whether AWS Sig v4 debug information should be printed
+	// whether AWS Sig v4 debug information should be logged
 	Debug bool `mapstructure:"debug"`
+	// how much of each request and response is logged in debug information, DebugVerbosityHeaders if empty
+	DebugVerbosity string `mapstructure:"debug_verbosity"`
+	// static access key ID used instead of the default credential chain
+	AccessKeyID string `mapstructure:"access_key_id"`
+	// static secret access key, required along with AccessKeyID
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	// optional session token of temporary static credentials
+	SessionToken string `mapstructure:"session_token"`
+	// ARN of the role assumed with STS to sign requests, using the static or default credentials
+	RoleARN string `mapstructure:"role_arn"`
+	// external ID passed to STS when assuming RoleARN
+	ExternalID string `mapstructure:"external_id"`
+	// session name used when assuming RoleARN, generated if empty
+	SessionName string `mapstructure:"session_name"`
+	// path of the web identity token file used to assume RoleARN with AssumeRoleWithWebIdentity
+	WebIdentityTokenFile string `mapstructure:"web_identity_token_file"`
+	// endpoint of STS used to assume RoleARN instead of the default endpoint of the region
+	STSEndpoint string `mapstructure:"sts_endpoint"`
+}
+
+// HTTPAuthSettings defines how each request is authenticated by other means than AWS Sig V4. At most one of them can be
+// configured. Secrets read from files are read again whenever the file is modified, so they can be rotated without
+// restarting the Collector.
+type HTTPAuthSettings struct {
+	// Basic defines the credentials of HTTP basic authentication.
+	Basic BasicAuthSettings `mapstructure:"basic"`
+	// BearerToken defines the bearer token sent in the Authorization header.
+	BearerToken BearerTokenSettings `mapstructure:"bearer_token"`
+	// OAuth2 defines the OAuth2 client credentials flow used to obtain bearer tokens.
+	OAuth2 OAuth2Settings `mapstructure:"oauth2"`
+}
+
+// BasicAuthSettings defines the credentials of HTTP basic authentication, which is enabled if Username is set.
+type BasicAuthSettings struct {
+	// Username is the user name of basic authentication.
+	Username string `mapstructure:"username"`
+	// Password is the password of basic authentication. It cannot be set along with PasswordFile.
+	Password string `mapstructure:"password"`
+	// PasswordFile is the path of the file holding the password of basic authentication.
+	PasswordFile string `mapstructure:"password_file"`
+}
+
+// BearerTokenSettings defines the bearer token sent in the Authorization header, which is sent if either field is set.
+type BearerTokenSettings struct {
+	// Token is the bearer token. It cannot be set along with TokenFile.
+	Token string `mapstructure:"token"`
+	// TokenFile is the path of the file holding the bearer token.
+	TokenFile string `mapstructure:"token_file"`
+}
+
+// OAuth2Settings defines the OAuth2 client credentials flow used to obtain the bearer token sent in the Authorization
+// header, which is enabled if TokenURL is set. Tokens are obtained again when they expire.
+type OAuth2Settings struct {
+	// ClientID is the client ID of the exporter.
+	ClientID string `mapstructure:"client_id"`
+	// ClientSecret is the client secret of the exporter. It cannot be set along with ClientSecretFile.
+	ClientSecret string `mapstructure:"client_secret"`
+	// ClientSecretFile is the path of the file holding the client secret of the exporter.
+	ClientSecretFile string `mapstructure:"client_secret_file"`
+	// TokenURL is the URL of the token endpoint of the authorization server.
+	TokenURL string `mapstructure:"token_url"`
+	// Scopes is the list of scopes requested.
+	Scopes []string `mapstructure:"scopes"`
+	// EndpointParams are additional parameters sent to the token endpoint.
+	EndpointParams map[string]string `mapstructure:"endpoint_params"`
 }
@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/relabel"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -14,6 +16,7 @@ import (
 	"go.opentelemetry.io/collector/config/configtest"
 	"go.opentelemetry.io/collector/config/configtls"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	prw "go.opentelemetry.io/collector/exporter/prometheusremotewriteexporter"
 )
 
 // TestLoadConfig checks whether yaml configuration can be loaded correctly
@@ -53,6 +56,51 @@ func Test_loadConfig(t *testing.T) {
 				MaxElapsedTime:  10 * time.Minute,
 			},
 			Namespace: "test-space",
+			ResourceAttributes: prw.ResourceAttributesSettings{
+				Include:     []string{"host.name", "k8s.pod.name"},
+				Rename:      map[string]string{"k8s.pod.name": "pod"},
+				JobInstance: true,
+			},
+			WAL: prw.WALSettings{
+				Enabled:     true,
+				Directory:   "/var/lib/otelcol/wal",
+				SegmentSize: 1024 * 1024,
+				MaxSize:     10 * 1024 * 1024,
+				MaxAge:      30 * time.Minute,
+			},
+			Shards: prw.ShardSettings{
+				MinShards:         2,
+				MaxShards:         20,
+				MaxSamplesPerSend: 1000,
+				MaxBytesPerSend:   1024 * 1024,
+			},
+			Metadata: prw.MetadataSettings{
+				Enabled:      true,
+				SendInterval: time.Minute,
+				MaxStaleness: 5 * time.Minute,
+				MaxFamilies:  2000,
+			},
+			DeltaToCumulative: prw.DeltaToCumulativeSettings{
+				Enabled:      true,
+				MaxStaleness: 10 * time.Minute,
+				MaxSeries:    5000,
+			},
+			Tenant: prw.CreateDefaultTenantSettings(),
+			Naming: prw.NamingSettings{Mode: prw.NamingModeNormalize},
+			Staleness: prw.StalenessSettings{
+				Enabled:   true,
+				Interval:  90 * time.Second,
+				MaxSeries: 20000,
+			},
+			Exemplars: prw.ExemplarSettings{
+				Enabled:      true,
+				MaxPerSeries: 5,
+			},
+			Duplicates: prw.DuplicateSettings{Policy: prw.DuplicatePolicyFirstWins},
+			Protocol: prw.ProtocolSettings{
+				Version:     prw.ProtocolVersion1,
+				Compression: prw.CompressionZstd,
+			},
 			HTTPClientSettings: confighttp.HTTPClientSettings{
 				Endpoint: "http://localhost:9009",
 				TLSSetting: configtls.TLSClientSetting{
@@ -72,10 +120,40 @@ func Test_loadConfig(t *testing.T) {
 					"x-scope-orgid":                   "234"},
 			},
 			AuthSettings: AuthSettings{
-				Enabled: true,
-				Region:  "us-west-2",
-				Service: "aps",
-				Debug:   true,
+				Enabled:        true,
+				Region:         "us-west-2",
+				Service:        "aps",
+				Debug:          true,
+				DebugVerbosity: DebugVerbosityBody,
+				RoleARN:        "arn:aws:iam::123456789012:role/prometheus-writer",
+				ExternalID:     "collector",
+				SessionName:    "otelcol",
+				STSEndpoint:    "https://sts.us-west-2.amazonaws.com",
 			},
 		})
+
+	// checks if authentication other than AWS Sig V4 can be configured
+	e2 := cfg.Exporters["cortex/3"].(*Config)
+	assert.Equal(t, HTTPAuthSettings{
+		OAuth2: OAuth2Settings{
+			ClientID:         "collector",
+			ClientSecretFile: "/var/run/secrets/cortex/client_secret",
+			TokenURL:         "https://auth.example.com/oauth2/token",
+			Scopes:           []string{"metrics.write"},
+			EndpointParams:   map[string]string{"audience": "cortex"},
+		},
+	}, e2.HTTPAuth)
+	assert.Equal(t, prw.TenantSettings{
+		Enabled: true,
+		Label:   "k8s.namespace.name",
+		Default: "anonymous",
+		Header:  "X-Scope-OrgID",
+	}, e2.Tenant)
+
+	// checks that relabel configs are parsed with the defaults of Prometheus
+	drop := relabel.DefaultRelabelConfig
+	drop.SourceLabels = model.LabelNames{"__name__"}
+	drop.Regex = relabel.MustNewRegexp("go_.*")
+	drop.Action = relabel.Drop
+	assert.Equal(t, []*relabel.Config{&drop}, e2.WriteRelabelConfigs)
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 
+	"github.com/spf13/viper"
+
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/config/configmodels"
@@ -26,44 +28,93 @@ func NewFactory() component.ExporterFactory {
 	return exporterhelper.NewFactory(
 		typeStr,
 		createDefaultConfig,
-		exporterhelper.WithMetrics(createMetricsExporter))
+		exporterhelper.WithMetrics(createMetricsExporter),
+		exporterhelper.WithCustomUnmarshaler(customUnmarshaler))
+}
+
+// customUnmarshaler parses write_relabel_configs with the YAML unmarshaling of Prometheus, after the other settings.
+func customUnmarshaler(componentViperSection *viper.Viper, intoCfg interface{}) error {
+	if componentViperSection == nil {
+		return nil
+	}
+	if err := componentViperSection.UnmarshalExact(intoCfg); err != nil {
+		return err
+	}
+	cfg := intoCfg.(*Config)
+	relabelConfigs, err := prw.ParseWriteRelabelConfigs(cfg.WriteRelabelConfigsPlaceholder)
+	if err != nil {
+		return err
+	}
+	cfg.WriteRelabelConfigs = relabelConfigs
+	cfg.WriteRelabelConfigsPlaceholder = nil
+	return nil
 }
 
-func createMetricsExporter(_ context.Context, _ component.ExporterCreateParams,
+func createMetricsExporter(_ context.Context, params component.ExporterCreateParams,
 	cfg configmodels.Exporter) (component.MetricsExporter, error) {
 	// check if the configuration is valid
 	prwCfg, ok := cfg.(*Config)
 	if !ok {
 		return nil, errors.New("invalid configuration")
 	}
+	// the tenant header is set on each request, and would be replaced by a static header of the same name
+	if err := prwCfg.Tenant.Validate(prwCfg.HTTPClientSettings.Headers); err != nil {
+		return nil, err
+	}
+	if err := prwCfg.Naming.Validate(); err != nil {
+		return nil, err
+	}
+	if err := prwCfg.Staleness.Validate(); err != nil {
+		return nil, err
+	}
+	if err := prwCfg.Duplicates.Validate(); err != nil {
+		return nil, err
+	}
+	if err := prwCfg.Protocol.Validate(prwCfg.HTTPClientSettings.Headers); err != nil {
+		return nil, err
+	}
 	client, cerr := prwCfg.HTTPClientSettings.ToClient()
 	if cerr != nil {
 		return nil, cerr
 	}
 
-	// load AWS auth configurations and create interceptor based on configuration
-	if prwCfg.AuthSettings.Enabled {
-		roundTripper, err := NewAuth(prwCfg.AuthSettings, client)
-		if err != nil {
-			return nil, err
-		}
-		client.Transport = roundTripper
+	// load auth configurations and create interceptor based on configuration
+	roundTripper, err := newAuthRoundTripper(prwCfg, client, params.Logger)
+	if err != nil {
+		return nil, err
 	}
+	client.Transport = roundTripper
 
 	// initialize an upstream exporter and pass it an http.Client with interceptor
-	prwe, err := prw.NewPrwExporter(prwCfg.Namespace, prwCfg.HTTPClientSettings.Endpoint, client)
+	prwe, err := prw.NewPrwExporter(prwCfg.Namespace, prwCfg.HTTPClientSettings.Endpoint, client,
+		prw.WithResourceAttributes(prwCfg.ResourceAttributes),
+		prw.WithName(prwCfg.Name()),
+		prw.WithType(string(prwCfg.Type())),
+		prw.WithLogger(params.Logger),
+		prw.WithWAL(prwCfg.WAL),
+		prw.WithShards(prwCfg.Shards),
+		prw.WithMetadata(prwCfg.Metadata),
+		prw.WithDeltaToCumulative(prwCfg.DeltaToCumulative),
+		prw.WithTenant(prwCfg.Tenant),
+		prw.WithNaming(prwCfg.Naming),
+		prw.WithStaleness(prwCfg.Staleness),
+		prw.WithExemplars(prwCfg.Exemplars),
+		prw.WithDuplicates(prwCfg.Duplicates),
+		prw.WithProtocol(prwCfg.Protocol),
+		prw.WithWriteRelabelConfigs(prwCfg.WriteRelabelConfigs))
 	if err != nil {
 		return nil, err
 	}
 
 	// use upstream helper package to return an exporter that implements the required interface, and has timeout,
-	// queueing and retry feature enabled
+	// queueing and retry feature enabled, and opens the write-ahead log on start
 	prwexp, err := exporterhelper.NewMetricsExporter(
 		cfg,
 		prwe.PushMetrics,
 		exporterhelper.WithTimeout(prwCfg.TimeoutSettings),
 		exporterhelper.WithQueue(prwCfg.QueueSettings),
 		exporterhelper.WithRetry(prwCfg.RetrySettings),
+		exporterhelper.WithStart(prwe.Start),
 		exporterhelper.WithShutdown(prwe.Shutdown),
 	)
 
@@ -74,19 +125,25 @@ func createDefaultConfig() configmodels.Exporter {
 	qs := exporterhelper.CreateDefaultQueueSettings()
 	qs.Enabled = false
 
-	// TODO: re-enable retry by default after componenterror.combineErrors upstream can return a permanent error if it is combined from permanent errors
-	ts := exporterhelper.CreateDefaultRetrySettings()
-	ts.Enabled = false
-
 	return &Config{
 		ExporterSettings: configmodels.ExporterSettings{
 			TypeVal: typeStr,
 			NameVal: typeStr,
 		},
-		Namespace:       "",
-		TimeoutSettings: exporterhelper.CreateDefaultTimeoutSettings(),
-		RetrySettings:   ts,
-		QueueSettings:   qs,
+		Namespace:         "",
+		WAL:               prw.CreateDefaultWALSettings(),
+		Shards:            prw.CreateDefaultShardSettings(),
+		Metadata:          prw.CreateDefaultMetadataSettings(),
+		DeltaToCumulative: prw.CreateDefaultDeltaToCumulativeSettings(),
+		Tenant:            prw.CreateDefaultTenantSettings(),
+		Naming:            prw.CreateDefaultNamingSettings(),
+		Staleness:         prw.CreateDefaultStalenessSettings(),
+		Exemplars:         prw.CreateDefaultExemplarSettings(),
+		Duplicates:        prw.CreateDefaultDuplicateSettings(),
+		Protocol:          prw.CreateDefaultProtocolSettings(),
+		TimeoutSettings:   exporterhelper.CreateDefaultTimeoutSettings(),
+		RetrySettings:     exporterhelper.CreateDefaultRetrySettings(),
+		QueueSettings:     qs,
 		HTTPClientSettings: confighttp.HTTPClientSettings{
 			Endpoint: "http://some.url:9411/api/prom/push",
 			// We almost read 0 bytes, so no need to tune ReadBufferSize.
@@ -11,6 +11,7 @@ import (
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/config/configmodels"
 	"go.opentelemetry.io/collector/config/configtls"
+	prw "go.opentelemetry.io/collector/exporter/prometheusremotewriteexporter"
 )
 
 //Tests whether or not the default Exporter factory can instantiate a properly interfaced Exporter with default conditions
@@ -35,6 +36,21 @@ func Test_createMetricsExporter(t *testing.T) {
 		Insecure:   false,
 		ServerName: "",
 	}
+	tenantHeaderConfig := createDefaultConfig().(*Config)
+	tenantHeaderConfig.Tenant.Enabled = true
+	tenantHeaderConfig.HTTPClientSettings.Headers = map[string]string{"x-scope-orgid": "234"}
+	unknownNamingConfig := createDefaultConfig().(*Config)
+	unknownNamingConfig.Naming.Mode = "camel"
+	stalenessIntervalConfig := createDefaultConfig().(*Config)
+	stalenessIntervalConfig.Staleness.Enabled = true
+	stalenessIntervalConfig.Staleness.Interval = 0
+	unknownDuplicatePolicyConfig := createDefaultConfig().(*Config)
+	unknownDuplicatePolicyConfig.Duplicates.Policy = "random"
+	unknownCompressionConfig := createDefaultConfig().(*Config)
+	unknownCompressionConfig.Protocol.Compression = "lz4"
+	protocolHeaderConfig := createDefaultConfig().(*Config)
+	protocolHeaderConfig.Protocol.Version = prw.ProtocolVersion2
+	protocolHeaderConfig.HTTPClientSettings.Headers = map[string]string{"x-prometheus-remote-write-version": "0.1.0"}
 	tests := []struct {
 		name        string
 		cfg         configmodels.Exporter
@@ -61,6 +77,36 @@ func Test_createMetricsExporter(t *testing.T) {
 			component.ExporterCreateParams{},
 			true,
 		},
+		{"tenant_header_conflict_case",
+			tenantHeaderConfig,
+			component.ExporterCreateParams{},
+			true,
+		},
+		{"unknown_naming_mode_case",
+			unknownNamingConfig,
+			component.ExporterCreateParams{},
+			true,
+		},
+		{"staleness_interval_case",
+			stalenessIntervalConfig,
+			component.ExporterCreateParams{},
+			true,
+		},
+		{"unknown_duplicate_policy_case",
+			unknownDuplicatePolicyConfig,
+			component.ExporterCreateParams{},
+			true,
+		},
+		{"unknown_compression_case",
+			unknownCompressionConfig,
+			component.ExporterCreateParams{},
+			true,
+		},
+		{"protocol_header_conflict_case",
+			protocolHeaderConfig,
+			component.ExporterCreateParams{},
+			true,
+		},
 	}
 	// run tests
 	for _, tt := range tests {
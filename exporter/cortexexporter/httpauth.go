@@ -0,0 +1,212 @@
+package cortexexporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// newAuthRoundTripper returns a http.RoundTripper that authenticates each request as configured in cfg before sending
+// it with the transport of client, or the transport of client itself if no authentication is configured. Debug
+// information of AWS Sig V4 is logged to logger.
+func newAuthRoundTripper(cfg *Config, client *http.Client, logger *zap.Logger) (http.RoundTripper, error) {
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	auth := cfg.HTTPAuth
+	basic := auth.Basic.Username != ""
+	bearer := auth.BearerToken.Token != "" || auth.BearerToken.TokenFile != ""
+	oauth := auth.OAuth2.TokenURL != ""
+	configured := 0
+	for _, enabled := range []bool{cfg.AuthSettings.Enabled, basic, bearer, oauth} {
+		if enabled {
+			configured++
+		}
+	}
+	if configured > 1 {
+		return nil, errors.New("invalid authentication configuration: only one authentication method can be configured")
+	}
+
+	switch {
+	case cfg.AuthSettings.Enabled:
+		return NewAuth(cfg.AuthSettings, &http.Client{Transport: transport}, logger)
+	case basic:
+		password, err := newSecret("password", auth.Basic.Password, auth.Basic.PasswordFile)
+		if err != nil {
+			return nil, err
+		}
+		return &basicAuthRoundTripper{transport: transport, username: auth.Basic.Username, password: password}, nil
+	case bearer:
+		token, err := newSecret("token", auth.BearerToken.Token, auth.BearerToken.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		return &bearerTokenRoundTripper{transport: transport, token: token}, nil
+	case oauth:
+		source, err := newOAuth2TokenSource(auth.OAuth2, &http.Client{Transport: transport, Timeout: client.Timeout})
+		if err != nil {
+			return nil, err
+		}
+		return &oauth2.Transport{Source: source, Base: transport}, nil
+	}
+	return transport, nil
+}
+
+// secret returns the current value of a secret.
+type secret func() (string, error)
+
+// newSecret returns the secret named name, which is either value or read from the file at path. The file is read
+// once to check that it is readable.
+func newSecret(name, value, path string) (secret, error) {
+	if value != "" && path != "" {
+		return nil, fmt.Errorf("invalid authentication configuration: %s and %s_file cannot both be set", name, name)
+	}
+	if path == "" {
+		return func() (string, error) { return value, nil }, nil
+	}
+	f := &secretFile{path: path}
+	if _, err := f.read(); err != nil {
+		return nil, err
+	}
+	return f.read, nil
+}
+
+// secretFile reads a secret from a file, and reads it again whenever the file is modified.
+type secretFile struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	value   string
+}
+
+// read returns the secret held by the file, without leading and trailing white space.
+func (f *secretFile) read() (string, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.value != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.value, nil
+	}
+	content, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSpace(string(content))
+	if value == "" {
+		return "", fmt.Errorf("secret file %s is empty", f.path)
+	}
+	f.modTime, f.size, f.value = info.ModTime(), info.Size(), value
+	return value, nil
+}
+
+// basicAuthRoundTripper is a http.RoundTripper that sets the credentials of HTTP basic authentication on each request.
+type basicAuthRoundTripper struct {
+	transport http.RoundTripper
+	username  string
+	password  secret
+}
+
+// RoundTrip sets the credentials of basic authentication on a copy of req, and sends it.
+func (rt *basicAuthRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	password, err := rt.password()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.SetBasicAuth(rt.username, password)
+	return rt.transport.RoundTrip(req)
+}
+
+// bearerTokenRoundTripper is a http.RoundTripper that sets a bearer token in the Authorization header of each request.
+type bearerTokenRoundTripper struct {
+	transport http.RoundTripper
+	token     secret
+}
+
+// RoundTrip sets the bearer token on a copy of req, and sends it.
+func (rt *bearerTokenRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := rt.token()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return rt.transport.RoundTrip(req)
+}
+
+// oauth2TokenSource is an oauth2.TokenSource obtaining tokens with the OAuth2 client credentials flow. Tokens are
+// cached until they expire, or until the client secret file is modified.
+type oauth2TokenSource struct {
+	settings     OAuth2Settings
+	clientSecret secret
+	ctx          context.Context
+
+	mu         sync.Mutex
+	source     oauth2.TokenSource
+	lastSecret string
+}
+
+// newOAuth2TokenSource returns a token source obtaining tokens from the token endpoint of settings with client.
+func newOAuth2TokenSource(settings OAuth2Settings, client *http.Client) (*oauth2TokenSource, error) {
+	if settings.ClientID == "" {
+		return nil, errors.New("invalid authentication configuration: client_id must be set for OAuth2")
+	}
+	if _, err := url.ParseRequestURI(settings.TokenURL); err != nil {
+		return nil, fmt.Errorf("invalid authentication configuration: invalid token_url: %v", err)
+	}
+	clientSecret, err := newSecret("client_secret", settings.ClientSecret, settings.ClientSecretFile)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2TokenSource{
+		settings:     settings,
+		clientSecret: clientSecret,
+		ctx:          context.WithValue(context.Background(), oauth2.HTTPClient, client),
+	}, nil
+}
+
+// Token returns a cached token, or obtains a new one from the token endpoint if it expired.
+func (s *oauth2TokenSource) Token() (*oauth2.Token, error) {
+	clientSecret, err := s.clientSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.source == nil || clientSecret != s.lastSecret {
+		params := url.Values{}
+		for k, v := range s.settings.EndpointParams {
+			params.Set(k, v)
+		}
+		cfg := clientcredentials.Config{
+			ClientID:       s.settings.ClientID,
+			ClientSecret:   clientSecret,
+			TokenURL:       s.settings.TokenURL,
+			Scopes:         s.settings.Scopes,
+			EndpointParams: params,
+		}
+		s.source = cfg.TokenSource(s.ctx)
+		s.lastSecret = clientSecret
+	}
+	return s.source.Token()
+}
@@ -0,0 +1,236 @@
+package cortexexporter
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// writeSecretFile writes secret to the file at path, and sets its modification time to modTime so that rewriting the
+// file within the resolution of modification times is detected.
+func writeSecretFile(t *testing.T, path, secret string, modTime time.Time) {
+	require.NoError(t, ioutil.WriteFile(path, []byte(secret+"\n"), 0600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+// newAuthHeaderServer returns a stand-in for Cortex recording the Authorization header of each request.
+func newAuthHeaderServer(headers *[]string) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		*headers = append(*headers, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func sendRequest(t *testing.T, rt http.RoundTripper, url string) {
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	require.NoError(t, err)
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Empty(t, req.Header.Get("Authorization"), "the original request must not be modified")
+}
+
+// Test_newAuthRoundTripper checks that invalid authentication configurations are rejected.
+func Test_newAuthRoundTripper(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cortexexporter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	secretPath := filepath.Join(dir, "secret")
+	writeSecretFile(t, secretPath, "secret", time.Now())
+
+	tests := []struct {
+		name        string
+		auth        HTTPAuthSettings
+		aws         AuthSettings
+		returnError bool
+	}{
+		{"no_auth", HTTPAuthSettings{}, AuthSettings{}, false},
+		{"basic", HTTPAuthSettings{Basic: BasicAuthSettings{Username: "user", Password: "password"}}, AuthSettings{},
+			false},
+		{"basic_password_file", HTTPAuthSettings{Basic: BasicAuthSettings{Username: "user", PasswordFile: secretPath}},
+			AuthSettings{}, false},
+		{"basic_password_and_file",
+			HTTPAuthSettings{Basic: BasicAuthSettings{Username: "user", Password: "password", PasswordFile: secretPath}},
+			AuthSettings{}, true},
+		{"bearer_token_missing_file",
+			HTTPAuthSettings{BearerToken: BearerTokenSettings{TokenFile: filepath.Join(dir, "missing")}},
+			AuthSettings{}, true},
+		{"oauth2_without_client_id",
+			HTTPAuthSettings{OAuth2: OAuth2Settings{TokenURL: "http://localhost/token"}}, AuthSettings{}, true},
+		{"oauth2_invalid_token_url",
+			HTTPAuthSettings{OAuth2: OAuth2Settings{ClientID: "id", TokenURL: "token"}}, AuthSettings{}, true},
+		{"multiple_methods",
+			HTTPAuthSettings{
+				Basic:       BasicAuthSettings{Username: "user", Password: "password"},
+				BearerToken: BearerTokenSettings{Token: "token"},
+			},
+			AuthSettings{}, true},
+		{"aws_and_basic", HTTPAuthSettings{Basic: BasicAuthSettings{Username: "user", Password: "password"}},
+			AuthSettings{Enabled: true, Region: "region", Service: "service"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.HTTPAuth = tt.auth
+			cfg.AuthSettings = tt.aws
+			client := &http.Client{Transport: http.DefaultTransport}
+			rt, err := newAuthRoundTripper(cfg, client, zap.NewNop())
+			if tt.returnError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, rt)
+		})
+	}
+}
+
+// Test_basicAuthRoundTripper checks that basic authentication is set on each request, and that the password is read
+// again when its file is modified.
+func Test_basicAuthRoundTripper(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cortexexporter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	passwordPath := filepath.Join(dir, "password")
+	modTime := time.Now().Add(-time.Hour)
+	writeSecretFile(t, passwordPath, "password1", modTime)
+
+	var headers []string
+	server := newAuthHeaderServer(&headers)
+	defer server.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.HTTPAuth.Basic = BasicAuthSettings{Username: "user", PasswordFile: passwordPath}
+	rt, err := newAuthRoundTripper(cfg, &http.Client{Transport: http.DefaultTransport}, zap.NewNop())
+	require.NoError(t, err)
+
+	sendRequest(t, rt, server.URL)
+	writeSecretFile(t, passwordPath, "password2", modTime.Add(time.Second))
+	sendRequest(t, rt, server.URL)
+
+	first, _ := http.NewRequest(http.MethodGet, "/", nil)
+	first.SetBasicAuth("user", "password1")
+	second, _ := http.NewRequest(http.MethodGet, "/", nil)
+	second.SetBasicAuth("user", "password2")
+	assert.Equal(t, []string{first.Header.Get("Authorization"), second.Header.Get("Authorization")}, headers)
+}
+
+// Test_bearerTokenRoundTripper checks that the bearer token is set on each request, and that a rotated token file is
+// read again.
+func Test_bearerTokenRoundTripper(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cortexexporter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	tokenPath := filepath.Join(dir, "token")
+	modTime := time.Now().Add(-time.Hour)
+	writeSecretFile(t, tokenPath, "token1", modTime)
+
+	var headers []string
+	server := newAuthHeaderServer(&headers)
+	defer server.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.HTTPAuth.BearerToken = BearerTokenSettings{TokenFile: tokenPath}
+	rt, err := newAuthRoundTripper(cfg, &http.Client{Transport: http.DefaultTransport}, zap.NewNop())
+	require.NoError(t, err)
+
+	sendRequest(t, rt, server.URL)
+	sendRequest(t, rt, server.URL)
+	writeSecretFile(t, tokenPath, "token2", modTime.Add(time.Second))
+	sendRequest(t, rt, server.URL)
+
+	assert.Equal(t, []string{"Bearer token1", "Bearer token1", "Bearer token2"}, headers)
+
+	// a token that can no longer be read fails the request instead of sending it without authentication
+	require.NoError(t, os.Remove(tokenPath))
+	req, err := http.NewRequest(http.MethodPost, server.URL, nil)
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req)
+	assert.Error(t, err)
+	assert.Len(t, headers, 3)
+}
+
+// Test_oauth2RoundTripper checks that tokens are obtained with the client credentials flow from a local stand-in of
+// the token endpoint, cached until they expire, and obtained again when the client secret is rotated.
+func Test_oauth2RoundTripper(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cortexexporter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	secretPath := filepath.Join(dir, "client_secret")
+	modTime := time.Now().Add(-time.Hour)
+	writeSecretFile(t, secretPath, "secret1", modTime)
+
+	var mu sync.Mutex
+	issued := 0
+	expiresIn := 3600
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		id, secret, ok := r.BasicAuth()
+		if !ok {
+			id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+		}
+		if id != "client" || r.PostForm.Get("grant_type") != "client_credentials" ||
+			r.PostForm.Get("scope") != "write" || r.PostForm.Get("audience") != "cortex" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		issued++
+		token := map[string]interface{}{
+			"access_token": secret + "-" + strconv.Itoa(issued),
+			"token_type":   "Bearer",
+			"expires_in":   expiresIn,
+		}
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(token)
+	}))
+	defer tokenServer.Close()
+
+	var headers []string
+	server := newAuthHeaderServer(&headers)
+	defer server.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.HTTPAuth.OAuth2 = OAuth2Settings{
+		ClientID:         "client",
+		ClientSecretFile: secretPath,
+		TokenURL:         tokenServer.URL,
+		Scopes:           []string{"write"},
+		EndpointParams:   map[string]string{"audience": "cortex"},
+	}
+	rt, err := newAuthRoundTripper(cfg, &http.Client{Transport: http.DefaultTransport}, zap.NewNop())
+	require.NoError(t, err)
+
+	// the token is cached until it expires
+	sendRequest(t, rt, server.URL)
+	sendRequest(t, rt, server.URL)
+	// rotating the client secret obtains a new token
+	writeSecretFile(t, secretPath, "secret2", modTime.Add(time.Second))
+	sendRequest(t, rt, server.URL)
+
+	assert.Equal(t, []string{"Bearer secret1-1", "Bearer secret1-1", "Bearer secret2-2"}, headers)
+
+	// tokens expiring soon are refreshed before each request
+	mu.Lock()
+	expiresIn = 1
+	mu.Unlock()
+	writeSecretFile(t, secretPath, "secret3", modTime.Add(2*time.Second))
+	sendRequest(t, rt, server.URL)
+	sendRequest(t, rt, server.URL)
+	assert.Equal(t, []string{"Bearer secret3-3", "Bearer secret3-4"}, headers[3:])
+}
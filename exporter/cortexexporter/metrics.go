@@ -0,0 +1,32 @@
+package cortexexporter
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"go.opentelemetry.io/collector/obsreport"
+)
+
+var (
+	tagExporterName, _ = tag.NewKey(obsreport.ExporterKey)
+
+	mSigningFailures = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "sigv4_signing_failures"),
+		"Number of requests that failed to be signed with AWS Signature Version 4.",
+		stats.UnitDimensionless)
+)
+
+// MetricViews returns the metric views of the Cortex exporter, in addition to those of the Prometheus remote write
+// exporter.
+func MetricViews() []*view.View {
+	return []*view.View{
+		{
+			Name:        mSigningFailures.Name(),
+			Measure:     mSigningFailures,
+			Description: mSigningFailures.Description(),
+			TagKeys:     []tag.Key{tagExporterName},
+			Aggregation: view.Sum(),
+		},
+	}
+}
@@ -4,10 +4,20 @@ go 1.14
 
 replace go.opentelemetry.io/collector => ./internal/opentelemetry-collector
 
+// the service of the collector registers the views of the Cortex exporter, so the collector requires this module back
+replace github.com/open-o11y/opentelemetry-collector-o11y => ./
+
 require (
+	github.com/antonmedv/expr v1.8.9 // indirect
 	github.com/aws/aws-sdk-go v1.31.9
 	github.com/open-telemetry/opentelemetry-proto v0.4.0 // indirect
+	github.com/prometheus/common v0.11.1
+	github.com/prometheus/prometheus v1.8.2-0.20200626085723-c448ada63d83
+	github.com/spf13/viper v1.7.1
 	github.com/stretchr/testify v1.6.1
 	github.com/tidwall/gjson v1.6.1 // indirect
+	go.opencensus.io v0.22.4
 	go.opentelemetry.io/collector v0.9.0
+	go.uber.org/zap v1.15.0
+	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d
 )
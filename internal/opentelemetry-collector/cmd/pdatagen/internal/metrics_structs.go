@@ -303,6 +303,8 @@ var intExemplar = &messageStruct{
 			originFieldName: "FilteredLabels",
 			returnSlice:     stringMap,
 		},
+		spanIDField,
+		traceIDField,
 	},
 }
 
@@ -326,6 +328,8 @@ var doubleExemplar = &messageStruct{
 			originFieldName: "FilteredLabels",
 			returnSlice:     stringMap,
 		},
+		spanIDField,
+		traceIDField,
 	},
 }
 
@@ -2274,6 +2274,34 @@ func (ms IntExemplar) FilteredLabels() StringMap {
 	return newStringMap(&(*ms.orig).FilteredLabels)
 }
 
+// SpanID returns the spanid associated with this IntExemplar.
+//
+// Important: This causes a runtime error if IsNil() returns "true".
+func (ms IntExemplar) SpanID() SpanID {
+	return SpanID((*ms.orig).SpanId)
+}
+
+// SetSpanID replaces the spanid associated with this IntExemplar.
+//
+// Important: This causes a runtime error if IsNil() returns "true".
+func (ms IntExemplar) SetSpanID(v SpanID) {
+	(*ms.orig).SpanId = []byte(v)
+}
+
+// TraceID returns the traceid associated with this IntExemplar.
+//
+// Important: This causes a runtime error if IsNil() returns "true".
+func (ms IntExemplar) TraceID() TraceID {
+	return TraceID((*ms.orig).TraceId)
+}
+
+// SetTraceID replaces the traceid associated with this IntExemplar.
+//
+// Important: This causes a runtime error if IsNil() returns "true".
+func (ms IntExemplar) SetTraceID(v TraceID) {
+	(*ms.orig).TraceId = []byte(v)
+}
+
 // CopyTo copies all properties from the current struct to the dest.
 func (ms IntExemplar) CopyTo(dest IntExemplar) {
 	if ms.IsNil() {
@@ -2286,6 +2314,8 @@ func (ms IntExemplar) CopyTo(dest IntExemplar) {
 	dest.SetTimestamp(ms.Timestamp())
 	dest.SetValue(ms.Value())
 	ms.FilteredLabels().CopyTo(dest.FilteredLabels())
+	dest.SetSpanID(ms.SpanID())
+	dest.SetTraceID(ms.TraceID())
 }
 
 // DoubleExemplarSlice logically represents a slice of DoubleExemplar.
@@ -2487,6 +2517,34 @@ func (ms DoubleExemplar) FilteredLabels() StringMap {
 	return newStringMap(&(*ms.orig).FilteredLabels)
 }
 
+// SpanID returns the spanid associated with this DoubleExemplar.
+//
+// Important: This causes a runtime error if IsNil() returns "true".
+func (ms DoubleExemplar) SpanID() SpanID {
+	return SpanID((*ms.orig).SpanId)
+}
+
+// SetSpanID replaces the spanid associated with this DoubleExemplar.
+//
+// Important: This causes a runtime error if IsNil() returns "true".
+func (ms DoubleExemplar) SetSpanID(v SpanID) {
+	(*ms.orig).SpanId = []byte(v)
+}
+
+// TraceID returns the traceid associated with this DoubleExemplar.
+//
+// Important: This causes a runtime error if IsNil() returns "true".
+func (ms DoubleExemplar) TraceID() TraceID {
+	return TraceID((*ms.orig).TraceId)
+}
+
+// SetTraceID replaces the traceid associated with this DoubleExemplar.
+//
+// Important: This causes a runtime error if IsNil() returns "true".
+func (ms DoubleExemplar) SetTraceID(v TraceID) {
+	(*ms.orig).TraceId = []byte(v)
+}
+
 // CopyTo copies all properties from the current struct to the dest.
 func (ms DoubleExemplar) CopyTo(dest DoubleExemplar) {
 	if ms.IsNil() {
@@ -2499,4 +2557,6 @@ func (ms DoubleExemplar) CopyTo(dest DoubleExemplar) {
 	dest.SetTimestamp(ms.Timestamp())
 	dest.SetValue(ms.Value())
 	ms.FilteredLabels().CopyTo(dest.FilteredLabels())
+	dest.SetSpanID(ms.SpanID())
+	dest.SetTraceID(ms.TraceID())
 }
@@ -1613,6 +1613,24 @@ func TestIntExemplar_FilteredLabels(t *testing.T) {
 	assert.EqualValues(t, testValFilteredLabels, ms.FilteredLabels())
 }
 
+func TestIntExemplar_SpanID(t *testing.T) {
+	ms := NewIntExemplar()
+	ms.InitEmpty()
+	assert.EqualValues(t, NewSpanID(nil), ms.SpanID())
+	testValSpanID := NewSpanID([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	ms.SetSpanID(testValSpanID)
+	assert.EqualValues(t, testValSpanID, ms.SpanID())
+}
+
+func TestIntExemplar_TraceID(t *testing.T) {
+	ms := NewIntExemplar()
+	ms.InitEmpty()
+	assert.EqualValues(t, NewTraceID(nil), ms.TraceID())
+	testValTraceID := NewTraceID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 8, 7, 6, 5, 4, 3, 2, 1})
+	ms.SetTraceID(testValTraceID)
+	assert.EqualValues(t, testValTraceID, ms.TraceID())
+}
+
 func TestDoubleExemplarSlice(t *testing.T) {
 	es := NewDoubleExemplarSlice()
 	assert.EqualValues(t, 0, es.Len())
@@ -1773,6 +1791,24 @@ func TestDoubleExemplar_FilteredLabels(t *testing.T) {
 	assert.EqualValues(t, testValFilteredLabels, ms.FilteredLabels())
 }
 
+func TestDoubleExemplar_SpanID(t *testing.T) {
+	ms := NewDoubleExemplar()
+	ms.InitEmpty()
+	assert.EqualValues(t, NewSpanID(nil), ms.SpanID())
+	testValSpanID := NewSpanID([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	ms.SetSpanID(testValSpanID)
+	assert.EqualValues(t, testValSpanID, ms.SpanID())
+}
+
+func TestDoubleExemplar_TraceID(t *testing.T) {
+	ms := NewDoubleExemplar()
+	ms.InitEmpty()
+	assert.EqualValues(t, NewTraceID(nil), ms.TraceID())
+	testValTraceID := NewTraceID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 8, 7, 6, 5, 4, 3, 2, 1})
+	ms.SetTraceID(testValTraceID)
+	assert.EqualValues(t, testValTraceID, ms.TraceID())
+}
+
 func generateTestResourceMetricsSlice() ResourceMetricsSlice {
 	tv := NewResourceMetricsSlice()
 	fillTestResourceMetricsSlice(tv)
@@ -2065,6 +2101,8 @@ func fillTestIntExemplar(tv IntExemplar) {
 	tv.SetTimestamp(TimestampUnixNano(1234567890))
 	tv.SetValue(int64(-17))
 	fillTestStringMap(tv.FilteredLabels())
+	tv.SetSpanID(NewSpanID([]byte{1, 2, 3, 4, 5, 6, 7, 8}))
+	tv.SetTraceID(NewTraceID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 8, 7, 6, 5, 4, 3, 2, 1}))
 }
 
 func generateTestDoubleExemplarSlice() DoubleExemplarSlice {
@@ -2091,4 +2129,6 @@ func fillTestDoubleExemplar(tv DoubleExemplar) {
 	tv.SetTimestamp(TimestampUnixNano(1234567890))
 	tv.SetValue(float64(17.13))
 	fillTestStringMap(tv.FilteredLabels())
+	tv.SetSpanID(NewSpanID([]byte{1, 2, 3, 4, 5, 6, 7, 8}))
+	tv.SetTraceID(NewTraceID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 8, 7, 6, 5, 4, 3, 2, 1}))
 }
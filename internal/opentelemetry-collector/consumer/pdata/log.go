@@ -81,6 +81,18 @@ func (ld Logs) LogRecordCount() int {
 	return logCount
 }
 
+// Size returns size in bytes.
+func (ld Logs) Size() int {
+	size := 0
+	for i := 0; i < len(*ld.orig); i++ {
+		if (*ld.orig)[i] == nil {
+			continue
+		}
+		size += (*ld.orig)[i].Size()
+	}
+	return size
+}
+
 func (ld Logs) ResourceLogs() ResourceLogsSlice {
 	return ResourceLogsSlice(ld)
 }
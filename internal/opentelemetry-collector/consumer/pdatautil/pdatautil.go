@@ -24,6 +24,7 @@ import (
 
 	"go.opentelemetry.io/collector/consumer/consumerdata"
 	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/internal/data"
 	"go.opentelemetry.io/collector/internal/dataold"
 	"go.opentelemetry.io/collector/translator/internaldata"
 )
@@ -38,6 +39,9 @@ func MetricsToMetricsData(md pdata.Metrics) []consumerdata.MetricsData {
 	if ims, ok := md.InternalOpaque.(dataold.MetricData); ok {
 		return internaldata.MetricDataToOC(ims)
 	}
+	if ims, ok := md.InternalOpaque.(data.MetricData); ok {
+		return internaldata.MetricDataToOC(internaldata.MetricDataToOldMetricData(ims))
+	}
 	panic("Unsupported metrics type.")
 }
 
@@ -48,7 +52,8 @@ func MetricsFromMetricsData(ocmds []consumerdata.MetricsData) pdata.Metrics {
 	return pdata.Metrics{InternalOpaque: ocmds}
 }
 
-// MetricsToOldInternalMetrics returns the `data.MetricData` representation of the `pdata.Metrics`.
+// MetricsToOldInternalMetrics returns the `data.MetricData` representation of the `pdata.Metrics`. Metrics of the new
+// model are converted, so that components supporting only the old model can consume them.
 //
 // This is a temporary function that will be removed when the new internal pdata.Metrics will be finalized.
 func MetricsToOldInternalMetrics(md pdata.Metrics) dataold.MetricData {
@@ -58,6 +63,9 @@ func MetricsToOldInternalMetrics(md pdata.Metrics) dataold.MetricData {
 	if cmd, ok := md.InternalOpaque.([]consumerdata.MetricsData); ok {
 		return internaldata.OCSliceToMetricData(cmd)
 	}
+	if ims, ok := md.InternalOpaque.(data.MetricData); ok {
+		return internaldata.MetricDataToOldMetricData(ims)
+	}
 	panic("Unsupported metrics type.")
 }
 
@@ -68,10 +76,43 @@ func MetricsFromOldInternalMetrics(md dataold.MetricData) pdata.Metrics {
 	return pdata.Metrics{InternalOpaque: md}
 }
 
+// MetricsToInternalMetrics returns the `data.MetricData` representation of the `pdata.Metrics`. Metrics of the old
+// models are converted, skipping the summaries that the new model cannot represent.
+//
+// This is a temporary function that will be removed when the new internal pdata.Metrics will be finalized.
+func MetricsToInternalMetrics(md pdata.Metrics) data.MetricData {
+	if ims, ok := md.InternalOpaque.(data.MetricData); ok {
+		return ims
+	}
+	return internaldata.OldMetricDataToMetricData(MetricsToOldInternalMetrics(md))
+}
+
+// MetricsToInternalMetricsAndSummaries returns the `data.MetricData` representation of the `pdata.Metrics` like
+// MetricsToInternalMetrics, and the summaries of metrics of the old models, which the new model cannot represent, in
+// the old model. Metrics of the old models are converted once, and their summaries are not copied.
+//
+// This is a temporary function that will be removed when the new internal pdata.Metrics will be finalized.
+func MetricsToInternalMetricsAndSummaries(md pdata.Metrics) (data.MetricData, dataold.MetricData) {
+	if ims, ok := md.InternalOpaque.(data.MetricData); ok {
+		return ims, dataold.NewMetricData()
+	}
+	return internaldata.OldMetricDataToMetricDataAndSummaries(MetricsToOldInternalMetrics(md))
+}
+
+// MetricsFromInternalMetrics returns the `pdata.Metrics` representation of the `data.MetricData`.
+//
+// This is a temporary function that will be removed when the new internal pdata.Metrics will be finalized.
+func MetricsFromInternalMetrics(md data.MetricData) pdata.Metrics {
+	return pdata.Metrics{InternalOpaque: md}
+}
+
 // CloneMetrics returns a clone of the given `pdata.Metrics`.
 //
 // This is a temporary function that will be removed when the new internal pdata.Metrics will be finalized.
 func CloneMetrics(md pdata.Metrics) pdata.Metrics {
+	if ims, ok := md.InternalOpaque.(data.MetricData); ok {
+		return pdata.Metrics{InternalOpaque: ims.Clone()}
+	}
 	if ims, ok := md.InternalOpaque.(dataold.MetricData); ok {
 		return pdata.Metrics{InternalOpaque: ims.Clone()}
 	}
@@ -86,6 +127,9 @@ func CloneMetrics(md pdata.Metrics) pdata.Metrics {
 }
 
 func MetricCount(md pdata.Metrics) int {
+	if ims, ok := md.InternalOpaque.(data.MetricData); ok {
+		return ims.MetricCount()
+	}
 	if ims, ok := md.InternalOpaque.(dataold.MetricData); ok {
 		return ims.MetricCount()
 	}
@@ -100,6 +144,9 @@ func MetricCount(md pdata.Metrics) int {
 }
 
 func MetricAndDataPointCount(md pdata.Metrics) (int, int) {
+	if ims, ok := md.InternalOpaque.(data.MetricData); ok {
+		return ims.MetricAndDataPointCount()
+	}
 	if ims, ok := md.InternalOpaque.(dataold.MetricData); ok {
 		return ims.MetricAndDataPointCount()
 	}
@@ -24,9 +24,36 @@ import (
 
 	"go.opentelemetry.io/collector/consumer/consumerdata"
 	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/internal/data"
+	otlpmetrics "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1"
 	"go.opentelemetry.io/collector/internal/dataold/testdataold"
 )
 
+func generateInternalMetricsTwoMetrics() data.MetricData {
+	return data.MetricDataFromOtlp([]*otlpmetrics.ResourceMetrics{
+		{
+			InstrumentationLibraryMetrics: []*otlpmetrics.InstrumentationLibraryMetrics{
+				{
+					Metrics: []*otlpmetrics.Metric{
+						{
+							Name: "gauge",
+							Data: &otlpmetrics.Metric_IntGauge{IntGauge: &otlpmetrics.IntGauge{
+								DataPoints: []*otlpmetrics.IntDataPoint{{Value: 1}, {Value: 2}},
+							}},
+						},
+						{
+							Name: "sum",
+							Data: &otlpmetrics.Metric_DoubleSum{DoubleSum: &otlpmetrics.DoubleSum{
+								DataPoints: []*otlpmetrics.DoubleDataPoint{{Value: 1.5}},
+							}},
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
 func TestMetricCount(t *testing.T) {
 	metrics := pdata.Metrics{InternalOpaque: testdataold.GenerateMetricDataTwoMetrics()}
 	assert.Equal(t, 2, MetricCount(metrics))
@@ -47,6 +74,9 @@ func TestMetricCount(t *testing.T) {
 		},
 	}}
 	assert.Equal(t, 1, MetricCount(metrics))
+
+	metrics = MetricsFromInternalMetrics(generateInternalMetricsTwoMetrics())
+	assert.Equal(t, 2, MetricCount(metrics))
 }
 
 func TestMetricAndDataPointCount(t *testing.T) {
@@ -75,4 +105,40 @@ func TestMetricAndDataPointCount(t *testing.T) {
 	metricsCount, dataPointsCount = MetricAndDataPointCount(metrics)
 	assert.Equal(t, 1, metricsCount)
 	assert.Equal(t, 3, dataPointsCount)
+
+	metrics = MetricsFromInternalMetrics(generateInternalMetricsTwoMetrics())
+	metricsCount, dataPointsCount = MetricAndDataPointCount(metrics)
+	assert.Equal(t, 2, metricsCount)
+	assert.Equal(t, 3, dataPointsCount)
+}
+
+func TestMetricsToInternalMetrics(t *testing.T) {
+	md := generateInternalMetricsTwoMetrics()
+	assert.Equal(t, md, MetricsToInternalMetrics(MetricsFromInternalMetrics(md)))
+
+	metrics := pdata.Metrics{InternalOpaque: testdataold.GenerateMetricDataTwoMetrics()}
+	internal := MetricsToInternalMetrics(metrics)
+	assert.Equal(t, 2, internal.MetricCount())
+
+	clone := CloneMetrics(MetricsFromInternalMetrics(md))
+	assert.Equal(t, md, clone.InternalOpaque)
+}
+
+func TestMetricsToOldInternalMetrics(t *testing.T) {
+	md := generateInternalMetricsTwoMetrics()
+	old := MetricsToOldInternalMetrics(MetricsFromInternalMetrics(md))
+	assert.Equal(t, 2, old.MetricCount())
+	assert.Equal(t, 2, len(MetricsToMetricsData(MetricsFromInternalMetrics(md))[0].Metrics))
+}
+
+func TestMetricsToInternalMetricsAndSummaries(t *testing.T) {
+	md := generateInternalMetricsTwoMetrics()
+	internal, summaries := MetricsToInternalMetricsAndSummaries(MetricsFromInternalMetrics(md))
+	assert.Equal(t, md, internal)
+	assert.Equal(t, 0, summaries.MetricCount())
+
+	old := testdataold.GenerateMetricDataWithCountersHistogramAndSummary()
+	internal, summaries = MetricsToInternalMetricsAndSummaries(pdata.Metrics{InternalOpaque: old})
+	assert.Equal(t, old.MetricCount()-1, internal.MetricCount())
+	assert.Equal(t, 1, summaries.MetricCount())
 }
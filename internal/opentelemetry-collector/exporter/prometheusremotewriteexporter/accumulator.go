@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"go.opencensus.io/stats"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+)
+
+// deltaAccumulator converts the Samples of delta TimeSeries to cumulative ones by summing the Samples of each
+// TimeSeries, identified by its signature. It is safe for concurrent use.
+type deltaAccumulator struct {
+	settings   DeltaToCumulativeSettings
+	logger     *zap.Logger
+	metricsCtx context.Context
+	// now returns the current time, and is replaced in tests
+	now func() time.Time
+
+	mu        sync.Mutex
+	series    map[string]*accumulatedSeries
+	lastSweep time.Time
+}
+
+// accumulatedSeries is the state of a cumulative TimeSeries.
+type accumulatedSeries struct {
+	// end is the time of the last delta Sample accumulated, before which the next delta Sample must not start
+	end   pdata.TimestampUnixNano
+	value float64
+	// updated is when the last delta Sample was accumulated, which is used for eviction
+	updated time.Time
+}
+
+func newDeltaAccumulator(settings DeltaToCumulativeSettings, logger *zap.Logger,
+	metricsCtx context.Context) *deltaAccumulator {
+	return &deltaAccumulator{
+		settings:   settings,
+		logger:     logger,
+		metricsCtx: metricsCtx,
+		now:        time.Now,
+		series:     map[string]*accumulatedSeries{},
+		lastSweep:  time.Now(),
+	}
+}
+
+// accumulate adds the value of sample, a delta over the interval from start to end, to the cumulative TimeSeries of
+// labels and kind, and sets the value of sample to the cumulative value. It returns false if sample must be dropped
+// because it ends before or starts before the end of the last Sample accumulated, or because the maximum number of TimeSeries
+// is reached. A Sample at the same time as the last one is assumed to be resent by a retry, and is not added again.
+func (a *deltaAccumulator) accumulate(kind string, labels []prompb.Label, start, end pdata.TimestampUnixNano,
+	sample *prompb.Sample) bool {
+	sig := timeSeriesSignature(kind, &labels)
+	now := a.now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.evictStale(now)
+	s, ok := a.series[sig]
+	if !ok {
+		if a.settings.MaxSeries > 0 && len(a.series) >= a.settings.MaxSeries {
+			a.drop("maximum number of delta TimeSeries reached", sig)
+			return false
+		}
+		// the first delta Sample of a TimeSeries is its cumulative value
+		a.series[sig] = &accumulatedSeries{end: end, value: sample.Value, updated: now}
+		stats.Record(a.metricsCtx, mDeltaSeries.M(int64(len(a.series))))
+		return true
+	}
+
+	switch {
+	case end == s.end:
+		// resent by a retry, the value was already added
+	case end < s.end:
+		a.drop("delta Sample older than the last one accumulated", sig)
+		return false
+	case start != 0 && start < s.end:
+		a.drop("delta Sample overlapping the last one accumulated", sig)
+		return false
+	default:
+		s.value += sample.Value
+		s.end = end
+	}
+	s.updated = now
+	sample.Value = s.value
+	return true
+}
+
+// evictStale evicts the TimeSeries that were not updated for the maximum staleness. TimeSeries are swept at most
+// every half of the maximum staleness, so they are evicted at most one and a half times the maximum staleness after
+// their last update. The next delta Sample of an evicted TimeSeries starts a new cumulative TimeSeries, which
+// Prometheus handles as a counter reset. a.mu must be held.
+func (a *deltaAccumulator) evictStale(now time.Time) {
+	if a.settings.MaxStaleness <= 0 || now.Sub(a.lastSweep) < a.settings.MaxStaleness/2 {
+		return
+	}
+	a.lastSweep = now
+	evicted := 0
+	for sig, s := range a.series {
+		if now.Sub(s.updated) >= a.settings.MaxStaleness {
+			delete(a.series, sig)
+			evicted++
+		}
+	}
+	if evicted > 0 {
+		stats.Record(a.metricsCtx, mDeltaEvictedSeries.M(int64(evicted)))
+	}
+	stats.Record(a.metricsCtx, mDeltaSeries.M(int64(len(a.series))))
+}
+
+// drop records a delta Sample dropped for reason. a.mu must be held.
+func (a *deltaAccumulator) drop(reason, sig string) {
+	a.logger.Debug("Dropping delta Sample", zap.String("reason", reason), zap.String("series", sig))
+	stats.Record(a.metricsCtx, mDeltaDroppedSamples.M(1))
+}
+
+// addDeltaSample adds sample to its TimeSeries in tsMap like addSample. If acc is not nil, sample is a delta over the
+// interval from start to end, and is converted to a cumulative Sample by acc first, or skipped if acc drops it.
+func addDeltaSample(tsMap map[string]*prompb.TimeSeries, acc *deltaAccumulator, sample *prompb.Sample,
+	labels []prompb.Label, kind string, start, end pdata.TimestampUnixNano) {
+	if acc != nil && !acc.accumulate(kind, labels, start, end, sample) {
+		return
+	}
+	addSample(tsMap, sample, labels, kind)
+}
+
+// isDelta returns whether metric is a sum or histogram with delta aggregation temporality.
+func isDelta(metric pdata.Metric) bool {
+	switch metric.DataType() {
+	case pdata.MetricDataIntSum:
+		sum := metric.IntSumData()
+		return !sum.IsNil() && sum.AggregationTemporality() == pdata.AggregationTemporalityDelta
+	case pdata.MetricDataDoubleSum:
+		sum := metric.DoubleSumData()
+		return !sum.IsNil() && sum.AggregationTemporality() == pdata.AggregationTemporalityDelta
+	case pdata.MetricDataIntHistogram:
+		histogram := metric.IntHistogramData()
+		return !histogram.IsNil() && histogram.AggregationTemporality() == pdata.AggregationTemporalityDelta
+	case pdata.MetricDataDoubleHistogram:
+		histogram := metric.DoubleHistogramData()
+		return !histogram.IsNil() && histogram.AggregationTemporality() == pdata.AggregationTemporalityDelta
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+)
+
+// Test_deltaAccumulator checks that delta Samples are summed per TimeSeries, and that Samples that are out of order,
+// overlapping or exceeding the maximum number of TimeSeries are dropped.
+func Test_deltaAccumulator(t *testing.T) {
+	series1 := getPromLabels(label11, value11, label12, value12)
+	series2 := getPromLabels(label21, value21, label22, value22)
+	series3 := getPromLabels(label31, value31, label32, value32)
+
+	tests := []struct {
+		name     string
+		labels   []prompb.Label
+		kind     string
+		start    pdata.TimestampUnixNano
+		end      pdata.TimestampUnixNano
+		value    float64
+		expected float64
+		ok       bool
+	}{
+		{"first_sample", series1, typeIntSum, 0, 10, 1, 1, true},
+		{"consecutive_sample", series1, typeIntSum, 10, 20, 2, 3, true},
+		{"sample_without_start", series1, typeIntSum, 0, 30, 3, 6, true},
+		{"resent_sample", series1, typeIntSum, 20, 30, 3, 6, true},
+		{"older_sample", series1, typeIntSum, 10, 20, 2, 0, false},
+		{"overlapping_sample", series1, typeIntSum, 25, 40, 4, 0, false},
+		{"gap_between_samples", series1, typeIntSum, 50, 60, 4, 10, true},
+		{"other_kind", series1, typeDoubleSum, 0, 10, 1.5, 1.5, true},
+		{"other_series", series2, typeIntSum, 0, 10, 5, 5, true},
+		{"series_limit", series3, typeIntSum, 0, 10, 1, 0, false},
+	}
+
+	acc := newDeltaAccumulator(DeltaToCumulativeSettings{Enabled: true, MaxSeries: 3}, zap.NewNop(),
+		context.Background())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sample := &prompb.Sample{Value: tt.value}
+			ok := acc.accumulate(tt.kind, tt.labels, tt.start, tt.end, sample)
+			assert.Equal(t, tt.ok, ok)
+			if ok {
+				assert.Equal(t, tt.expected, sample.Value)
+			}
+		})
+	}
+}
+
+// Test_deltaAccumulatorEviction checks that TimeSeries not updated within the maximum staleness are evicted, and that
+// their next Sample starts a new cumulative TimeSeries.
+func Test_deltaAccumulatorEviction(t *testing.T) {
+	now := time.Unix(0, 0)
+	acc := newDeltaAccumulator(DeltaToCumulativeSettings{Enabled: true, MaxStaleness: time.Minute, MaxSeries: 1},
+		zap.NewNop(), context.Background())
+	acc.now = func() time.Time { return now }
+	acc.lastSweep = now
+
+	stale := getPromLabels(label11, value11)
+	fresh := getPromLabels(label21, value21)
+
+	assert.True(t, acc.accumulate(typeIntSum, stale, 0, 10, &prompb.Sample{Value: 1}))
+	now = now.Add(30 * time.Second)
+	assert.True(t, acc.accumulate(typeIntSum, stale, 10, 20, &prompb.Sample{Value: 1}))
+	assert.False(t, acc.accumulate(typeIntSum, fresh, 0, 20, &prompb.Sample{Value: 1}))
+
+	// the stale TimeSeries is evicted once it was not updated for a minute, freeing room for a new one
+	now = now.Add(time.Minute)
+	assert.True(t, acc.accumulate(typeIntSum, fresh, 0, 30, &prompb.Sample{Value: 1}))
+	assert.Len(t, acc.series, 1)
+
+	now = now.Add(2 * time.Minute)
+	sample := &prompb.Sample{Value: 2}
+	assert.True(t, acc.accumulate(typeIntSum, stale, 20, 40, sample))
+	assert.Equal(t, float64(2), sample.Value)
+}
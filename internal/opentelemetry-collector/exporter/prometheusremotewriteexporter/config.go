@@ -15,6 +15,13 @@
 package prometheusremotewriteexporter
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/pkg/relabel"
+
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/config/configmodels"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
@@ -32,5 +39,385 @@ type Config struct {
 	// See: https://prometheus.io/docs/practices/naming/#metric-names
 	Namespace string `mapstructure:"namespace"`
 
+	// ResourceAttributes defines how resource attributes are attached to each exported TimeSeries as labels.
+	ResourceAttributes ResourceAttributesSettings `mapstructure:"resource_attributes"`
+
+	// WAL defines the on-disk write-ahead log that buffers WriteRequests until they are accepted by the endpoint.
+	WAL WALSettings `mapstructure:"wal"`
+
+	// Shards defines how exported TimeSeries are split into requests that are sent concurrently.
+	Shards ShardSettings `mapstructure:"shards"`
+
+	// Metadata defines whether and how metric metadata is sent with remote write.
+	Metadata MetadataSettings `mapstructure:"metadata"`
+
+	// DeltaToCumulative defines whether and how delta sums and histograms are converted to cumulative ones.
+	DeltaToCumulative DeltaToCumulativeSettings `mapstructure:"delta_to_cumulative"`
+
+	// Tenant defines how TimeSeries are routed to the tenants of a multi-tenant endpoint.
+	Tenant TenantSettings `mapstructure:"tenant"`
+
+	// Naming defines how metric names are translated to Prometheus metric names.
+	Naming NamingSettings `mapstructure:"naming"`
+
+	// Staleness defines whether and when staleness markers are sent for TimeSeries that are no longer exported.
+	Staleness StalenessSettings `mapstructure:"staleness"`
+
+	// Exemplars defines whether and how many exemplars of histograms and sums are sent with their TimeSeries.
+	Exemplars ExemplarSettings `mapstructure:"exemplars"`
+
+	// Duplicates defines which of the Samples of a TimeSeries with the same timestamp is sent.
+	Duplicates DuplicateSettings `mapstructure:"duplicates"`
+
+	// Protocol defines the remote write protocol version and compression of requests.
+	Protocol ProtocolSettings `mapstructure:"protocol"`
+
+	// WriteRelabelConfigs are the relabel configs applied to each TimeSeries before it is sent, with the semantics of
+	// the write_relabel_configs of Prometheus. They are parsed from WriteRelabelConfigsPlaceholder by the custom
+	// unmarshaler of the factory, as they define their own YAML unmarshaling.
+	WriteRelabelConfigs []*relabel.Config `mapstructure:"-"`
+
+	// WriteRelabelConfigsPlaceholder holds the write_relabel_configs setting until it is parsed, so that the key is
+	// known to the check rejecting unknown keys.
+	WriteRelabelConfigsPlaceholder interface{} `mapstructure:"write_relabel_configs"`
+
 	HTTPClientSettings confighttp.HTTPClientSettings `mapstructure:",squash"`
 }
+
+// ResourceAttributesSettings defines which resource attributes are converted to labels and how they are named.
+type ResourceAttributesSettings struct {
+	// IncludeAll indicates whether every resource attribute should be converted to a label. Include is ignored if set.
+	IncludeAll bool `mapstructure:"include_all"`
+	// Include is the list of resource attribute keys converted to labels.
+	Include []string `mapstructure:"include"`
+	// Rename maps resource attribute keys to label names. Label names, renamed or not, are sanitized.
+	Rename map[string]string `mapstructure:"rename"`
+	// JobInstance indicates whether the job and instance labels should be derived from the service.namespace,
+	// service.name and service.instance.id resource attributes.
+	JobInstance bool `mapstructure:"job_instance"`
+}
+
+// WALSettings defines configuration for the write-ahead log. When enabled, every batch is persisted to disk before
+// PushMetrics returns, and is sent to the endpoint in the background until it is accepted, dropped for exceeding the
+// size or age limit, or rejected with a permanent error. Pending batches are replayed on start.
+type WALSettings struct {
+	// Enabled indicates whether batches should be written to the write-ahead log before being sent.
+	Enabled bool `mapstructure:"enabled"`
+	// Directory is the path of the directory holding the segment files and checkpoint of the write-ahead log.
+	Directory string `mapstructure:"directory"`
+	// SegmentSize is the size in bytes after which a new segment file is started.
+	SegmentSize int64 `mapstructure:"segment_size"`
+	// MaxSize is the maximum size in bytes of all segment files. Oldest segments are dropped once it is exceeded.
+	MaxSize int64 `mapstructure:"max_size"`
+	// MaxAge is the maximum age of a segment file. Segments last written longer ago are dropped. Zero disables the
+	// age limit.
+	MaxAge time.Duration `mapstructure:"max_age"`
+}
+
+// CreateDefaultWALSettings returns the default settings for WALSettings.
+func CreateDefaultWALSettings() WALSettings {
+	return WALSettings{
+		Enabled:     false,
+		SegmentSize: 16 * 1024 * 1024,
+		MaxSize:     256 * 1024 * 1024,
+		MaxAge:      2 * time.Hour,
+	}
+}
+
+// ShardSettings defines how exported TimeSeries are split into shards by the hash of their labels, and how each shard
+// is batched into requests. Shards are sent concurrently, and the number of shards is adjusted between MinShards and
+// MaxShards based on the observed send latency.
+type ShardSettings struct {
+	// MinShards is the minimum number of shards.
+	MinShards int `mapstructure:"min_shards"`
+	// MaxShards is the maximum number of shards.
+	MaxShards int `mapstructure:"max_shards"`
+	// MaxSamplesPerSend is the maximum number of samples in a single request. Zero disables the limit.
+	MaxSamplesPerSend int `mapstructure:"max_samples_per_send"`
+	// MaxBytesPerSend is the maximum size in bytes of a single uncompressed request. Zero disables the limit.
+	MaxBytesPerSend int `mapstructure:"max_bytes_per_send"`
+}
+
+// CreateDefaultShardSettings returns the default settings for ShardSettings.
+func CreateDefaultShardSettings() ShardSettings {
+	return ShardSettings{
+		MinShards:         1,
+		MaxShards:         10,
+		MaxSamplesPerSend: 500,
+		MaxBytesPerSend:   4 * 1024 * 1024,
+	}
+}
+
+// MetadataSettings defines how the metadata of metric families, i.e. their type, description and unit, is sent to the
+// remote write endpoint.
+type MetadataSettings struct {
+	// Enabled indicates whether metric metadata is sent.
+	Enabled bool `mapstructure:"enabled"`
+	// SendInterval is the interval at which the metadata of every metric family exported so far is sent in a separate
+	// request, like Prometheus does. If zero, the metadata of each batch is sent inline with its TimeSeries.
+	SendInterval time.Duration `mapstructure:"send_interval"`
+	// MaxStaleness is the duration after which the metadata of a metric family that was not exported again is no
+	// longer sent on the interval. Zero disables eviction.
+	MaxStaleness time.Duration `mapstructure:"max_staleness"`
+	// MaxFamilies is the maximum number of metric families whose metadata is kept to be sent on the interval. The
+	// metadata of new metric families is not sent on the interval once it is reached. Zero disables the limit.
+	MaxFamilies int `mapstructure:"max_families"`
+}
+
+// CreateDefaultMetadataSettings returns the default settings for MetadataSettings.
+func CreateDefaultMetadataSettings() MetadataSettings {
+	return MetadataSettings{
+		Enabled:      false,
+		SendInterval: 0,
+		MaxStaleness: 10 * time.Minute,
+		MaxFamilies:  10000,
+	}
+}
+
+// DeltaToCumulativeSettings defines how delta sums and histograms, which are otherwise dropped or exported as gauges, are
+// converted to cumulative ones. The exporter sums the delta Samples of each TimeSeries it exports, so the state of
+// every TimeSeries is kept in memory, and the converted values are only correct if the delta points of a TimeSeries
+// are all sent to the same exporter.
+type DeltaToCumulativeSettings struct {
+	// Enabled indicates whether delta sums and histograms should be converted to cumulative ones.
+	Enabled bool `mapstructure:"enabled"`
+	// MaxStaleness is the duration after which a TimeSeries that was not updated is evicted. Its next delta Sample
+	// starts a new cumulative TimeSeries. Zero disables eviction.
+	MaxStaleness time.Duration `mapstructure:"max_staleness"`
+	// MaxSeries is the maximum number of TimeSeries kept in memory. Delta Samples of new TimeSeries are dropped once it
+	// is reached. Zero disables the limit.
+	MaxSeries int `mapstructure:"max_series"`
+}
+
+// CreateDefaultDeltaToCumulativeSettings returns the default settings for DeltaToCumulativeSettings.
+func CreateDefaultDeltaToCumulativeSettings() DeltaToCumulativeSettings {
+	return DeltaToCumulativeSettings{
+		Enabled:      false,
+		MaxStaleness: 5 * time.Minute,
+		MaxSeries:    100000,
+	}
+}
+
+// TenantSettings defines how TimeSeries are routed to the tenants of a multi-tenant endpoint such as Cortex. The
+// TimeSeries of each tenant are sent in separate requests, which carry the tenant in a header.
+type TenantSettings struct {
+	// Enabled indicates whether TimeSeries should be routed to tenants.
+	Enabled bool `mapstructure:"enabled"`
+	// Label is the name of the data point label or resource attribute holding the tenant of a TimeSeries. A data
+	// point label takes precedence over a resource attribute.
+	Label string `mapstructure:"label"`
+	// Default is the tenant of TimeSeries without a tenant label or attribute. If empty, they are sent without the
+	// header.
+	Default string `mapstructure:"default"`
+	// Header is the name of the header carrying the tenant of a request.
+	Header string `mapstructure:"header"`
+}
+
+// CreateDefaultTenantSettings returns the default settings for TenantSettings.
+func CreateDefaultTenantSettings() TenantSettings {
+	return TenantSettings{
+		Enabled: false,
+		Label:   "tenant",
+		Default: "",
+		Header:  "X-Scope-OrgID",
+	}
+}
+
+// Validate checks that the settings are complete if enabled, and that the tenant header is not one of the static
+// headers, which would replace the tenant of every request.
+func (s TenantSettings) Validate(headers map[string]string) error {
+	if !s.Enabled {
+		return nil
+	}
+	if s.Label == "" {
+		return errors.New("invalid tenant configuration: label must be set")
+	}
+	if s.Header == "" {
+		return errors.New("invalid tenant configuration: header must be set")
+	}
+	for name := range headers {
+		if strings.EqualFold(name, s.Header) {
+			return fmt.Errorf("invalid tenant configuration: header %s cannot also be set in headers", s.Header)
+		}
+	}
+	return nil
+}
+
+// StalenessSettings defines how TimeSeries that are no longer exported, such as those of a terminated pod, are marked
+// stale so that Prometheus queries stop returning them right away instead of after the lookback delta. The exporter
+// keeps track of every TimeSeries it exports, and sends a staleness marker for each one that was not exported again
+// within the interval.
+type StalenessSettings struct {
+	// Enabled indicates whether staleness markers are sent.
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is the duration after which a TimeSeries that was not exported again is marked stale. It must be longer
+	// than the interval at which the TimeSeries are exported.
+	Interval time.Duration `mapstructure:"interval"`
+	// MaxSeries is the maximum number of TimeSeries kept track of. New TimeSeries are not marked stale once it is
+	// reached. Zero disables the limit.
+	MaxSeries int `mapstructure:"max_series"`
+}
+
+// CreateDefaultStalenessSettings returns the default settings for StalenessSettings.
+func CreateDefaultStalenessSettings() StalenessSettings {
+	return StalenessSettings{
+		Enabled:   false,
+		Interval:  2 * time.Minute,
+		MaxSeries: 100000,
+	}
+}
+
+// Validate checks that the interval is positive if staleness markers are enabled.
+func (s StalenessSettings) Validate() error {
+	if s.Enabled && s.Interval <= 0 {
+		return errors.New("invalid staleness configuration: interval must be positive")
+	}
+	return nil
+}
+
+// ExemplarSettings defines how the exemplars of histogram and sum data points, which link a measurement to the trace
+// and span it was recorded in, are sent as remote write exemplars. The exemplars of a histogram data point are sent
+// with the bucket their value falls in, and carry the trace_id and span_id labels.
+type ExemplarSettings struct {
+	// Enabled indicates whether exemplars are sent.
+	Enabled bool `mapstructure:"enabled"`
+	// MaxPerSeries is the maximum number of exemplars sent with a TimeSeries in each batch. The most recent exemplars
+	// are kept. Zero disables the limit.
+	MaxPerSeries int `mapstructure:"max_per_series"`
+}
+
+// CreateDefaultExemplarSettings returns the default settings for ExemplarSettings.
+func CreateDefaultExemplarSettings() ExemplarSettings {
+	return ExemplarSettings{
+		Enabled:      false,
+		MaxPerSeries: 1,
+	}
+}
+
+// Modes of translation of metric names to Prometheus metric names.
+const (
+	// NamingModeSanitize replaces the characters of metric names other than letters and digits with underscores, and
+	// suffixes counters with _total.
+	NamingModeSanitize = "sanitize"
+	// NamingModeNormalize follows the Prometheus naming conventions: runs of characters other than letters and digits
+	// are replaced with a single underscore, units are appended in words, and counters are suffixed with _total once.
+	NamingModeNormalize = "normalize"
+	// NamingModeRaw keeps metric names as they are, for endpoints accepting UTF-8 metric names.
+	NamingModeRaw = "raw"
+)
+
+// NamingSettings defines how metric names are translated to Prometheus metric names. The namespace is prefixed to
+// metric names in every mode.
+type NamingSettings struct {
+	// Mode is one of sanitize, normalize or raw.
+	Mode string `mapstructure:"mode"`
+}
+
+// CreateDefaultNamingSettings returns the default settings for NamingSettings.
+func CreateDefaultNamingSettings() NamingSettings {
+	return NamingSettings{
+		Mode: NamingModeSanitize,
+	}
+}
+
+// Validate checks that the naming mode is known.
+func (s NamingSettings) Validate() error {
+	switch s.Mode {
+	case NamingModeSanitize, NamingModeNormalize, NamingModeRaw:
+		return nil
+	}
+	return fmt.Errorf("invalid naming configuration: unknown mode %q", s.Mode)
+}
+
+// Policies for choosing which of the Samples of a TimeSeries with the same timestamp is sent.
+const (
+	// DuplicatePolicyLastWins sends the Sample converted last.
+	DuplicatePolicyLastWins = "last_wins"
+	// DuplicatePolicyFirstWins sends the Sample converted first.
+	DuplicatePolicyFirstWins = "first_wins"
+)
+
+// DuplicateSettings defines how the Samples of a TimeSeries with the same timestamp in a batch, such as those of
+// batches merged by the batch processor, are deduplicated. The Samples of each TimeSeries are sorted by timestamp and
+// the duplicates are dropped before sending, as remote write endpoints reject whole requests holding out of order
+// Samples.
+type DuplicateSettings struct {
+	// Policy is one of last_wins or first_wins.
+	Policy string `mapstructure:"policy"`
+}
+
+// CreateDefaultDuplicateSettings returns the default settings for DuplicateSettings.
+func CreateDefaultDuplicateSettings() DuplicateSettings {
+	return DuplicateSettings{
+		Policy: DuplicatePolicyLastWins,
+	}
+}
+
+// Validate checks that the duplicate policy is known.
+func (s DuplicateSettings) Validate() error {
+	switch s.Policy {
+	case DuplicatePolicyLastWins, DuplicatePolicyFirstWins:
+		return nil
+	}
+	return fmt.Errorf("invalid duplicates configuration: unknown policy %q", s.Policy)
+}
+
+// Versions of the remote write protocol.
+const (
+	// ProtocolVersion1 is the remote write 1.0 protocol, supported by every remote write endpoint.
+	ProtocolVersion1 = "1.0"
+	// ProtocolVersion2 is the remote write 2.0 protocol, which interns label names and values in a symbol table.
+	ProtocolVersion2 = "2.0"
+)
+
+// Compressions of remote write requests.
+const (
+	// CompressionSnappy is the block format of Snappy, supported by every remote write endpoint.
+	CompressionSnappy = "snappy"
+	// CompressionGzip is gzip, for endpoints behind proxies decompressing requests.
+	CompressionGzip = "gzip"
+	// CompressionZstd is Zstandard.
+	CompressionZstd = "zstd"
+)
+
+// ProtocolSettings defines the protocol version and compression of remote write requests. If the endpoint rejects a
+// request with 415 Unsupported Media Type, or acknowledges a remote write 2.0 request without reporting the Samples
+// written as remote write 1.0 endpoints do, the request and all the following ones are sent with remote write 1.0 and
+// Snappy compression instead.
+type ProtocolSettings struct {
+	// Version is the remote write protocol version, 1.0 or 2.0.
+	Version string `mapstructure:"version"`
+	// Compression is one of snappy, gzip or zstd.
+	Compression string `mapstructure:"compression"`
+}
+
+// CreateDefaultProtocolSettings returns the default settings for ProtocolSettings.
+func CreateDefaultProtocolSettings() ProtocolSettings {
+	return ProtocolSettings{
+		Version:     ProtocolVersion1,
+		Compression: CompressionSnappy,
+	}
+}
+
+// Validate checks that the version and compression are known, and that headers do not override the headers set for
+// them.
+func (s ProtocolSettings) Validate(headers map[string]string) error {
+	switch s.Version {
+	case ProtocolVersion1, ProtocolVersion2:
+	default:
+		return fmt.Errorf("invalid protocol configuration: unknown version %q", s.Version)
+	}
+	switch s.Compression {
+	case CompressionSnappy, CompressionGzip, CompressionZstd:
+	default:
+		return fmt.Errorf("invalid protocol configuration: unknown compression %q", s.Compression)
+	}
+	for name := range headers {
+		if (s.Version != ProtocolVersion1 && (strings.EqualFold(name, remoteWriteVersionHeader) ||
+			strings.EqualFold(name, "Content-Type"))) ||
+			(s.Compression != CompressionSnappy && strings.EqualFold(name, "Content-Encoding")) {
+			return fmt.Errorf("invalid protocol configuration: header %s cannot be set in headers", name)
+		}
+	}
+	return nil
+}
@@ -19,6 +19,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/relabel"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -67,6 +69,51 @@ func Test_loadConfig(t *testing.T) {
 				MaxElapsedTime:  10 * time.Minute,
 			},
 			Namespace: "test-space",
+			ResourceAttributes: ResourceAttributesSettings{
+				Include:     []string{"host.name", "k8s.pod.name"},
+				Rename:      map[string]string{"k8s.pod.name": "pod"},
+				JobInstance: true,
+			},
+			WAL: WALSettings{
+				Enabled:     true,
+				Directory:   "/var/lib/otelcol/wal",
+				SegmentSize: 1024 * 1024,
+				MaxSize:     10 * 1024 * 1024,
+				MaxAge:      30 * time.Minute,
+			},
+			Shards: ShardSettings{
+				MinShards:         2,
+				MaxShards:         20,
+				MaxSamplesPerSend: 1000,
+				MaxBytesPerSend:   1024 * 1024,
+			},
+			Metadata: MetadataSettings{
+				Enabled:      true,
+				SendInterval: time.Minute,
+				MaxStaleness: 5 * time.Minute,
+				MaxFamilies:  2000,
+			},
+			DeltaToCumulative: DeltaToCumulativeSettings{
+				Enabled:      true,
+				MaxStaleness: 10 * time.Minute,
+				MaxSeries:    5000,
+			},
+			Tenant: CreateDefaultTenantSettings(),
+			Naming: NamingSettings{Mode: NamingModeNormalize},
+			Staleness: StalenessSettings{
+				Enabled:   true,
+				Interval:  90 * time.Second,
+				MaxSeries: 20000,
+			},
+			Exemplars: ExemplarSettings{
+				Enabled:      true,
+				MaxPerSeries: 5,
+			},
+			Duplicates: DuplicateSettings{Policy: DuplicatePolicyFirstWins},
+			Protocol: ProtocolSettings{
+				Version:     ProtocolVersion1,
+				Compression: CompressionZstd,
+			},
 			HTTPClientSettings: confighttp.HTTPClientSettings{
 				Endpoint: "localhost:8888",
 				TLSSetting: configtls.TLSClientSetting{
@@ -86,4 +133,27 @@ func Test_loadConfig(t *testing.T) {
 					"x-scope-orgid":                   "234"},
 			},
 		})
+
+	e2 := cfg.Exporters["prometheusremotewrite/3"].(*Config)
+	assert.Equal(t, TenantSettings{
+		Enabled: true,
+		Label:   "k8s.namespace.name",
+		Default: "anonymous",
+		Header:  "X-Tenant",
+	}, e2.Tenant)
+
+	// checks that relabel configs are parsed with the defaults of Prometheus
+	defaults := relabel.DefaultRelabelConfig
+	drop, labelDrop, hashMod := defaults, defaults, defaults
+	drop.SourceLabels = model.LabelNames{"__name__"}
+	drop.Regex = relabel.MustNewRegexp("go_.*")
+	drop.Action = relabel.Drop
+	labelDrop.Regex = relabel.MustNewRegexp("k8s_pod_uid")
+	labelDrop.Action = relabel.LabelDrop
+	hashMod.SourceLabels = model.LabelNames{"instance"}
+	hashMod.TargetLabel = "shard"
+	hashMod.Modulus = 4
+	hashMod.Action = relabel.HashMod
+	assert.Equal(t, []*relabel.Config{&drop, &labelDrop, &hashMod}, e2.WriteRelabelConfigs)
+	assert.Nil(t, e2.WriteRelabelConfigsPlaceholder)
 }
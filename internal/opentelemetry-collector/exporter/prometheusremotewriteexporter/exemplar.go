@@ -0,0 +1,295 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/prometheus/prometheus/prompb"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+)
+
+// Field numbers of the Prometheus remote write protocol for exemplars.
+const (
+	timeSeriesExemplarsField = 3
+	exemplarLabelsField      = 1
+	exemplarValueField       = 2
+	exemplarTimestampField   = 3
+	labelNameField           = 1
+	labelValueField          = 2
+)
+
+const (
+	traceIDLabel = "trace_id"
+	spanIDLabel  = "span_id"
+
+	// exemplarMaxLabelSetLength is the maximum total length of the label names and values of an exemplar accepted by
+	// Prometheus and Cortex.
+	exemplarMaxLabelSetLength = 128
+)
+
+// exemplar is an exemplar sent with a TimeSeries. The vendored prompb package predates exemplar support in the remote
+// write protocol, so exemplars are encoded by marshalExemplar instead.
+type exemplar struct {
+	Labels    []prompb.Label
+	Value     float64
+	Timestamp int64
+}
+
+// exemplarSet collects the exemplars of the TimeSeries of a batch by TimeSeries signature, until they are attached to
+// their TimeSeries. A nil exemplarSet discards every exemplar.
+type exemplarSet struct {
+	maxPerSeries int
+	series       map[string][]exemplar
+}
+
+// newExemplarSet returns an exemplarSet for a batch, or nil if exemplars are disabled.
+func newExemplarSet(settings ExemplarSettings) *exemplarSet {
+	if !settings.Enabled {
+		return nil
+	}
+	return &exemplarSet{
+		maxPerSeries: settings.MaxPerSeries,
+		series:       map[string][]exemplar{},
+	}
+}
+
+// add adds exemplars to the TimeSeries with the label set labels.
+func (s *exemplarSet) add(kind string, labels []prompb.Label, exemplars []exemplar) {
+	if s == nil || len(exemplars) == 0 {
+		return
+	}
+	sig := timeSeriesSignature(kind, &labels)
+	s.series[sig] = append(s.series[sig], exemplars...)
+}
+
+// addIntPoint adds the exemplars of an int sum data point to its TimeSeries.
+func (s *exemplarSet) addIntPoint(kind string, labels []prompb.Label, pt pdata.IntDataPoint) {
+	if s == nil {
+		return
+	}
+	s.add(kind, labels, intExemplars(pt.Exemplars(), pt.Timestamp()))
+}
+
+// addDoublePoint adds the exemplars of a double sum data point to its TimeSeries.
+func (s *exemplarSet) addDoublePoint(kind string, labels []prompb.Label, pt pdata.DoubleDataPoint) {
+	if s == nil {
+		return
+	}
+	s.add(kind, labels, doubleExemplars(pt.Exemplars(), pt.Timestamp()))
+}
+
+// addIntHistogramPoint adds each exemplar of an int histogram data point to the TimeSeries of the bucket its value
+// falls in.
+func (s *exemplarSet) addIntHistogramPoint(kind, baseName string, resourceLabels []prompb.Label,
+	pt pdata.IntHistogramDataPoint) {
+	if s == nil {
+		return
+	}
+	s.addBuckets(kind, baseName, resourceLabels, pt.LabelsMap(), pt.ExplicitBounds(),
+		intExemplars(pt.Exemplars(), pt.Timestamp()))
+}
+
+// addDoubleHistogramPoint adds each exemplar of a double histogram data point to the TimeSeries of the bucket its
+// value falls in.
+func (s *exemplarSet) addDoubleHistogramPoint(kind, baseName string, resourceLabels []prompb.Label,
+	pt pdata.DoubleHistogramDataPoint) {
+	if s == nil {
+		return
+	}
+	s.addBuckets(kind, baseName, resourceLabels, pt.LabelsMap(), pt.ExplicitBounds(),
+		doubleExemplars(pt.Exemplars(), pt.Timestamp()))
+}
+
+// addBuckets adds each of exemplars to the TimeSeries of the histogram bucket its value falls in.
+func (s *exemplarSet) addBuckets(kind, baseName string, resourceLabels []prompb.Label, pointLabels pdata.StringMap,
+	bounds []float64, exemplars []exemplar) {
+	for _, e := range exemplars {
+		labels := createLabelSet(resourceLabels, pointLabels, nameStr, baseName+bucketStr, leStr,
+			bucketBound(bounds, e.Value))
+		s.add(kind, labels, []exemplar{e})
+	}
+}
+
+// attach encodes the exemplars collected for each TimeSeries of tsMap into the TimeSeries, keeping the most recent
+// ones up to the limit per TimeSeries. Exemplars of TimeSeries not in tsMap, such as those of the first delta data
+// point of a TimeSeries converted to cumulative, are discarded.
+func (s *exemplarSet) attach(tsMap map[string]*prompb.TimeSeries) {
+	if s == nil {
+		return
+	}
+	for sig, exemplars := range s.series {
+		ts, ok := tsMap[sig]
+		if !ok {
+			continue
+		}
+		sort.SliceStable(exemplars, func(i, j int) bool { return exemplars[i].Timestamp < exemplars[j].Timestamp })
+		if s.maxPerSeries > 0 && len(exemplars) > s.maxPerSeries {
+			exemplars = exemplars[len(exemplars)-s.maxPerSeries:]
+		}
+		for _, e := range exemplars {
+			ts.XXX_unrecognized = appendBytesField(ts.XXX_unrecognized, timeSeriesExemplarsField, marshalExemplar(e))
+		}
+	}
+}
+
+// bucketBound returns the le label value of the histogram bucket value falls in.
+func bucketBound(bounds []float64, value float64) string {
+	for _, bound := range bounds {
+		if value <= bound {
+			return strconv.FormatFloat(bound, 'f', -1, 64)
+		}
+	}
+	return pInfStr
+}
+
+// intExemplars converts exemplars, defaulting their timestamp to the one of their data point. Exemplars without
+// labels are dropped, as they are rejected by Cortex.
+func intExemplars(exemplars pdata.IntExemplarSlice, timestamp pdata.TimestampUnixNano) []exemplar {
+	var converted []exemplar
+	for i := 0; i < exemplars.Len(); i++ {
+		e := exemplars.At(i)
+		if e.IsNil() {
+			continue
+		}
+		if ce, ok := newExemplar(float64(e.Value()), e.Timestamp(), timestamp, e.TraceID(), e.SpanID(),
+			e.FilteredLabels()); ok {
+			converted = append(converted, ce)
+		}
+	}
+	return converted
+}
+
+// doubleExemplars converts exemplars like intExemplars.
+func doubleExemplars(exemplars pdata.DoubleExemplarSlice, timestamp pdata.TimestampUnixNano) []exemplar {
+	var converted []exemplar
+	for i := 0; i < exemplars.Len(); i++ {
+		e := exemplars.At(i)
+		if e.IsNil() {
+			continue
+		}
+		if ce, ok := newExemplar(e.Value(), e.Timestamp(), timestamp, e.TraceID(), e.SpanID(),
+			e.FilteredLabels()); ok {
+			converted = append(converted, ce)
+		}
+	}
+	return converted
+}
+
+// newExemplar returns an exemplar labeled with the trace and span IDs as hex strings, followed by as many of the
+// filtered labels, in order of name, as fit in the label set length limit. It returns false if the exemplar has no
+// labels.
+func newExemplar(value float64, timestamp, pointTimestamp pdata.TimestampUnixNano, traceID pdata.TraceID,
+	spanID pdata.SpanID, filtered pdata.StringMap) (exemplar, bool) {
+	if timestamp == 0 {
+		timestamp = pointTimestamp
+	}
+	e := exemplar{
+		Value:     value,
+		Timestamp: convertTimeStamp(uint64(timestamp)),
+	}
+
+	length := 0
+	addLabel := func(name, value string) {
+		if length+len(name)+len(value) > exemplarMaxLabelSetLength {
+			return
+		}
+		length += len(name) + len(value)
+		e.Labels = append(e.Labels, prompb.Label{Name: name, Value: value})
+	}
+	if len(traceID.Bytes()) != 0 {
+		addLabel(traceIDLabel, traceID.String())
+	}
+	if len(spanID.Bytes()) != 0 {
+		addLabel(spanIDLabel, spanID.String())
+	}
+	keys := make([]string, 0, filtered.Len())
+	filtered.ForEach(func(k string, _ pdata.StringValue) {
+		keys = append(keys, k)
+	})
+	sort.Strings(keys)
+	for _, k := range keys {
+		if k == traceIDLabel || k == spanIDLabel {
+			continue
+		}
+		v, _ := filtered.Get(k)
+		addLabel(sanitize(k), v.Value())
+	}
+	return e, len(e.Labels) != 0
+}
+
+// marshalExemplar encodes e as an Exemplar message of the remote write protocol.
+func marshalExemplar(e exemplar) []byte {
+	var data []byte
+	for _, l := range e.Labels {
+		var label []byte
+		label = appendBytesField(label, labelNameField, []byte(l.Name))
+		label = appendBytesField(label, labelValueField, []byte(l.Value))
+		data = appendBytesField(data, exemplarLabelsField, label)
+	}
+	data = append(data, proto.EncodeVarint(exemplarValueField<<3|proto.WireFixed64)...)
+	var value [8]byte
+	binary.LittleEndian.PutUint64(value[:], math.Float64bits(e.Value))
+	data = append(data, value[:]...)
+	data = append(data, proto.EncodeVarint(exemplarTimestampField<<3|proto.WireVarint)...)
+	return append(data, proto.EncodeVarint(uint64(e.Timestamp))...)
+}
+
+// appendBytesField appends value to data as the length-delimited field number.
+func appendBytesField(data []byte, number int, value []byte) []byte {
+	data = append(data, proto.EncodeVarint(uint64(number<<3|proto.WireBytes))...)
+	data = append(data, proto.EncodeVarint(uint64(len(value)))...)
+	return append(data, value...)
+}
+
+// unmarshalExemplars decodes the exemplars encoded into a TimeSeries by exemplarSet.attach.
+func unmarshalExemplars(data []byte) ([]exemplar, error) {
+	fields, err := unmarshalFields(data)
+	if err != nil {
+		return nil, err
+	}
+	var exemplars []exemplar
+	for _, field := range fields {
+		if field.number != timeSeriesExemplarsField {
+			continue
+		}
+		exemplarFields, err := unmarshalFields(field.bytes)
+		if err != nil {
+			return nil, err
+		}
+		e := exemplar{}
+		for _, ef := range exemplarFields {
+			switch ef.number {
+			case exemplarLabelsField:
+				label := prompb.Label{}
+				if err := label.Unmarshal(ef.bytes); err != nil {
+					return nil, err
+				}
+				e.Labels = append(e.Labels, label)
+			case exemplarValueField:
+				e.Value = math.Float64frombits(ef.value)
+			case exemplarTimestampField:
+				e.Timestamp = int64(ef.value)
+			}
+		}
+		exemplars = append(exemplars, e)
+	}
+	return exemplars, nil
+}
@@ -0,0 +1,204 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/consumer/pdatautil"
+	otlpmetrics "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1"
+)
+
+var (
+	traceID = []byte{1, 2, 3, 4, 5, 6, 7, 8, 8, 7, 6, 5, 4, 3, 2, 1}
+	spanID  = []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	// exemplars recorded one and two seconds after time1
+	exemplarTime2   = time1 + uint64(time.Second)
+	exemplarTime3   = time1 + uint64(2*time.Second)
+	exemplarMsTime2 = msTime1 + 1000
+	exemplarMsTime3 = msTime1 + 2000
+)
+
+// decodeExemplars decodes the exemplars encoded into the unrecognized fields of a TimeSeries.
+func decodeExemplars(t *testing.T, data []byte) []exemplar {
+	exemplars, err := unmarshalExemplars(data)
+	require.NoError(t, err)
+	return exemplars
+}
+
+func getDoubleExemplar(value float64, ts uint64, labels ...string) *otlpmetrics.DoubleExemplar {
+	return &otlpmetrics.DoubleExemplar{
+		FilteredLabels: getLabels(labels...),
+		TimeUnixNano:   ts,
+		Value:          value,
+		SpanId:         spanID,
+		TraceId:        traceID,
+	}
+}
+
+// Test_newExemplar checks that exemplars are labeled with the trace and span IDs and as many filtered labels as fit in
+// the label set length limit, and that their timestamp defaults to the one of their data point.
+func Test_newExemplar(t *testing.T) {
+	filtered := getLabelsMap(getLabels("user.id", "42", "zone", strings.Repeat("z", 100)))
+	e, ok := newExemplar(1.5, 0, pdata.TimestampUnixNano(time1), pdata.NewTraceID(traceID), pdata.NewSpanID(spanID),
+		filtered)
+	require.True(t, ok)
+	assert.Equal(t, exemplar{
+		Labels: getPromLabels(traceIDLabel, "01020304050607080807060504030201", spanIDLabel, "0102030405060708",
+			"user_id", "42"),
+		Value:     1.5,
+		Timestamp: msTime1,
+	}, e)
+
+	e, ok = newExemplar(1, pdata.TimestampUnixNano(exemplarTime2), pdata.TimestampUnixNano(time1),
+		pdata.NewTraceID(nil), pdata.NewSpanID(nil), getLabelsMap(getLabels("user.id", "42")))
+	require.True(t, ok)
+	assert.Equal(t, exemplarMsTime2, e.Timestamp)
+	assert.Equal(t, getPromLabels("user_id", "42"), e.Labels)
+
+	_, ok = newExemplar(1, 0, 0, pdata.NewTraceID(nil), pdata.NewSpanID(nil), getLabelsMap(nil))
+	assert.False(t, ok)
+}
+
+// Test_exemplarSet checks that exemplars are attached to the TimeSeries they were added for, the most recent ones up
+// to the limit per TimeSeries, and that a nil exemplarSet discards them.
+func Test_exemplarSet(t *testing.T) {
+	labels := getPromLabels(nameStr, "a")
+	tsMap := map[string]*prompb.TimeSeries{
+		timeSeriesSignature("kind", &labels): getTimeSeries(labels, getSample(1, msTime1)),
+	}
+	set := newExemplarSet(ExemplarSettings{Enabled: true, MaxPerSeries: 2})
+	set.add("kind", labels, []exemplar{
+		{Labels: getPromLabels(traceIDLabel, "c"), Value: 3, Timestamp: exemplarMsTime3},
+		{Labels: getPromLabels(traceIDLabel, "a"), Value: 1, Timestamp: msTime1},
+		{Labels: getPromLabels(traceIDLabel, "b"), Value: 2, Timestamp: exemplarMsTime2},
+	})
+	// not exported
+	set.add("kind", getPromLabels(nameStr, "b"), []exemplar{{Labels: getPromLabels(traceIDLabel, "d")}})
+	set.attach(tsMap)
+
+	for _, ts := range tsMap {
+		assert.Equal(t, []exemplar{
+			{Labels: getPromLabels(traceIDLabel, "b"), Value: 2, Timestamp: exemplarMsTime2},
+			{Labels: getPromLabels(traceIDLabel, "c"), Value: 3, Timestamp: exemplarMsTime3},
+		}, decodeExemplars(t, ts.XXX_unrecognized))
+
+		// sent with the first chunk of the TimeSeries
+		ts.Samples = append(ts.Samples, getSample(2, msTime2))
+		shards, err := shardTimeSeries(tsMap, 1, 1, 0)
+		require.NoError(t, err)
+		require.Len(t, shards, 1)
+		require.Len(t, shards[0], 2)
+		assert.Equal(t, ts.XXX_unrecognized, shards[0][0].Timeseries[0].XXX_unrecognized)
+		assert.Empty(t, shards[0][1].Timeseries[0].XXX_unrecognized)
+	}
+
+	assert.Nil(t, newExemplarSet(ExemplarSettings{Enabled: false}))
+	var disabled *exemplarSet
+	disabled.add("kind", labels, []exemplar{{Labels: getPromLabels(traceIDLabel, "a")}})
+	disabled.attach(tsMap)
+}
+
+// Test_PushMetricsExemplars checks that the exemplars of histogram data points are sent with the bucket their value
+// falls in, and those of sum data points with their TimeSeries, up to the limit per TimeSeries.
+func Test_PushMetricsExemplars(t *testing.T) {
+	var mu sync.Mutex
+	sent := map[string][]exemplar{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		dest, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		wr := &prompb.WriteRequest{}
+		require.NoError(t, proto.Unmarshal(dest, wr))
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, ts := range wr.Timeseries {
+			if len(ts.XXX_unrecognized) == 0 {
+				continue
+			}
+			sig := ""
+			for _, l := range ts.Labels {
+				if l.Name == nameStr || l.Name == leStr {
+					sig += l.Value
+				}
+			}
+			sent[sig] = decodeExemplars(t, ts.XXX_unrecognized)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	histogramPoint := getDoubleHistogramPoint(lbs1, time1, 13.5, 3, []float64{1, 5}, []uint64{1, 1, 1})
+	histogramPoint.Exemplars = []*otlpmetrics.DoubleExemplar{
+		getDoubleExemplar(0.5, time1),
+		getDoubleExemplar(3, time1),
+		getDoubleExemplar(10, time1),
+	}
+	sumPoint := getDoublePoint(lbs1, floatVal1, exemplarTime3)
+	sumPoint.Exemplars = []*otlpmetrics.DoubleExemplar{
+		getDoubleExemplar(1, time1),
+		getDoubleExemplar(2, exemplarTime3),
+		getDoubleExemplar(3, exemplarTime2),
+	}
+	gaugePoint := getDoublePoint(lbs1, floatVal1, time1)
+	gaugePoint.Exemplars = []*otlpmetrics.DoubleExemplar{getDoubleExemplar(1, time1)}
+	md := pdatautil.MetricsFromInternalMetrics(getInternalMetrics(
+		getDoubleHistogramMetric("latency", otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			histogramPoint),
+		getDoubleSumMetric("requests", otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, true,
+			sumPoint),
+		getDoubleGaugeMetric("temperature", gaugePoint),
+	))
+
+	prwe, err := NewPrwExporter("", server.URL, http.DefaultClient,
+		WithExemplars(ExemplarSettings{Enabled: true, MaxPerSeries: 2}))
+	require.NoError(t, err)
+	_, err = prwe.PushMetrics(context.Background(), md)
+	require.NoError(t, err)
+
+	expected := func(value float64, ts int64) exemplar {
+		return exemplar{
+			Labels: getPromLabels(traceIDLabel, pdata.NewTraceID(traceID).String(), spanIDLabel,
+				pdata.NewSpanID(spanID).String()),
+			Value:     value,
+			Timestamp: ts,
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, map[string][]exemplar{
+		"latency_bucket1":    {expected(0.5, msTime1)},
+		"latency_bucket5":    {expected(3, msTime1)},
+		"latency_bucket+Inf": {expected(10, msTime1)},
+		"requests_total":     {expected(3, exemplarMsTime2), expected(2, exemplarMsTime3)},
+	}, sent)
+}
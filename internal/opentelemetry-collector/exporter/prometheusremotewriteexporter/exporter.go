@@ -17,36 +17,185 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
 	"sync"
+	"time"
 
-	"github.com/gogo/protobuf/proto"
-	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/pkg/relabel"
 	"github.com/prometheus/prometheus/prompb"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
 
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenterror"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/consumer/pdata"
 	"go.opentelemetry.io/collector/consumer/pdatautil"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
 	otlp "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1old"
 	"go.opentelemetry.io/collector/internal/dataold"
+	"go.opentelemetry.io/collector/obsreport"
+)
+
+const (
+	walInitialBackoff = 1 * time.Second
+	walMaxBackoff     = 30 * time.Second
 )
 
 // PrwExporter converts OTLP metrics to Prometheus remote write TimeSeries and sends them to a remote endpoint
 type PrwExporter struct {
-	namespace   string
-	endpointURL *url.URL
-	client      *http.Client
-	wg          *sync.WaitGroup
-	closeChan   chan struct{}
+	namespace         string
+	endpointURL       *url.URL
+	client            *http.Client
+	wg                *sync.WaitGroup
+	closeChan         chan struct{}
+	resourceSettings  ResourceAttributesSettings
+	name              string
+	typ               string
+	logger            *zap.Logger
+	metricsCtx        context.Context
+	walSettings       WALSettings
+	wal               *wal
+	bgWG              sync.WaitGroup
+	shardSettings     ShardSettings
+	shards            *shardManager
+	metadataSettings  MetadataSettings
+	metadata          *metadataCache
+	deltaSettings     DeltaToCumulativeSettings
+	accumulator       *deltaAccumulator
+	tenantSettings    TenantSettings
+	namingSettings    NamingSettings
+	stalenessSettings StalenessSettings
+	staleness         *stalenessTracker
+	exemplarSettings  ExemplarSettings
+	duplicateSettings DuplicateSettings
+	protocolSettings  ProtocolSettings
+	protocol          *protocolNegotiator
+	relabelConfigs    []*relabel.Config
+}
+
+// Option applies optional settings to a PrwExporter.
+type Option func(*PrwExporter)
+
+// WithResourceAttributes sets how resource attributes are converted to labels of each exported TimeSeries.
+func WithResourceAttributes(settings ResourceAttributesSettings) Option {
+	return func(prwe *PrwExporter) {
+		prwe.resourceSettings = settings
+	}
+}
+
+// WithName sets the name of the exporter, which is used to tag the metrics recorded by the exporter.
+func WithName(name string) Option {
+	return func(prwe *PrwExporter) {
+		prwe.name = name
+	}
+}
+
+// WithType sets the type of the exporter, which is used to tag the metrics recorded by the exporter. Exporters built
+// on this one, such as the Cortex exporter, set their own type so that their metrics can be told apart.
+func WithType(typ string) Option {
+	return func(prwe *PrwExporter) {
+		prwe.typ = typ
+	}
+}
+
+// WithLogger sets the logger used by the exporter. A nil logger is ignored.
+func WithLogger(logger *zap.Logger) Option {
+	return func(prwe *PrwExporter) {
+		if logger != nil {
+			prwe.logger = logger
+		}
+	}
+}
+
+// WithWAL sets the write-ahead log settings. The write-ahead log is opened on Start if it is enabled.
+func WithWAL(settings WALSettings) Option {
+	return func(prwe *PrwExporter) {
+		prwe.walSettings = settings
+	}
+}
+
+// WithShards sets how exported TimeSeries are split into shards and requests.
+func WithShards(settings ShardSettings) Option {
+	return func(prwe *PrwExporter) {
+		prwe.shardSettings = settings
+	}
+}
+
+// WithMetadata sets whether and how metric metadata is sent with remote write.
+func WithMetadata(settings MetadataSettings) Option {
+	return func(prwe *PrwExporter) {
+		prwe.metadataSettings = settings
+	}
+}
+
+// WithDeltaToCumulative sets whether and how delta sums and histograms are converted to cumulative ones.
+func WithDeltaToCumulative(settings DeltaToCumulativeSettings) Option {
+	return func(prwe *PrwExporter) {
+		prwe.deltaSettings = settings
+	}
+}
+
+// WithTenant sets whether and how exported TimeSeries are routed to the tenants of a multi-tenant endpoint.
+func WithTenant(settings TenantSettings) Option {
+	return func(prwe *PrwExporter) {
+		prwe.tenantSettings = settings
+	}
+}
+
+// WithNaming sets how metric names are translated to Prometheus metric names.
+func WithNaming(settings NamingSettings) Option {
+	return func(prwe *PrwExporter) {
+		prwe.namingSettings = settings
+	}
+}
+
+// WithStaleness sets whether and when staleness markers are sent for TimeSeries that are no longer exported.
+func WithStaleness(settings StalenessSettings) Option {
+	return func(prwe *PrwExporter) {
+		prwe.stalenessSettings = settings
+	}
+}
+
+// WithExemplars sets whether and how many exemplars of histograms and sums are sent with their TimeSeries.
+func WithExemplars(settings ExemplarSettings) Option {
+	return func(prwe *PrwExporter) {
+		prwe.exemplarSettings = settings
+	}
+}
+
+// WithDuplicates sets which of the Samples of a TimeSeries with the same timestamp is sent.
+func WithDuplicates(settings DuplicateSettings) Option {
+	return func(prwe *PrwExporter) {
+		prwe.duplicateSettings = settings
+	}
+}
+
+// WithProtocol sets the remote write protocol version and compression of requests.
+func WithProtocol(settings ProtocolSettings) Option {
+	return func(prwe *PrwExporter) {
+		prwe.protocolSettings = settings
+	}
+}
+
+// WithWriteRelabelConfigs sets the relabel configs applied to each TimeSeries before it is sent.
+func WithWriteRelabelConfigs(cfgs []*relabel.Config) Option {
+	return func(prwe *PrwExporter) {
+		prwe.relabelConfigs = cfgs
+	}
 }
 
 // NewPrwExporter initializes a new PrwExporter instance and sets fields accordingly.
 // client parameter cannot be nil.
-func NewPrwExporter(namespace string, endpoint string, client *http.Client) (*PrwExporter, error) {
+func NewPrwExporter(namespace string, endpoint string, client *http.Client, options ...Option) (*PrwExporter, error) {
 
 	if client == nil {
 		return nil, fmt.Errorf("http client cannot be nil")
@@ -57,21 +206,75 @@ func NewPrwExporter(namespace string, endpoint string, client *http.Client) (*Pr
 		return nil, fmt.Errorf("invalid endpoint")
 	}
 
-	return &PrwExporter{
+	prwe := &PrwExporter{
 		namespace:   namespace,
 		endpointURL: endpointURL,
 		client:      client,
 		wg:          new(sync.WaitGroup),
 		closeChan:   make(chan struct{}),
-	}, nil
+		typ:         typeStr,
+		logger:      zap.NewNop(),
+	}
+	for _, option := range options {
+		option(prwe)
+	}
+	prwe.metadata = newMetadataCache(prwe.metadataSettings)
+	if prwe.metricsCtx, err = tag.New(context.Background(),
+		tag.Insert(tagExporterName, prwe.name), tag.Insert(tagExporterType, prwe.typ)); err != nil {
+		return nil, err
+	}
+	prwe.shards = newShardManager(prwe.shardSettings, prwe.logger)
+	stats.Record(prwe.metricsCtx, mShards.M(int64(prwe.shards.numShards())))
+	if prwe.protocol, err = newProtocolNegotiator(prwe.protocolSettings, prwe.logger); err != nil {
+		return nil, err
+	}
+	if prwe.deltaSettings.Enabled {
+		prwe.accumulator = newDeltaAccumulator(prwe.deltaSettings, prwe.logger, prwe.metricsCtx)
+	}
+	if prwe.stalenessSettings.Enabled {
+		if err := prwe.stalenessSettings.Validate(); err != nil {
+			return nil, err
+		}
+		prwe.staleness = newStalenessTracker(prwe.stalenessSettings, prwe.logger, prwe.metricsCtx)
+	}
+	return prwe, nil
+}
+
+// Start opens the write-ahead log if it is enabled, and starts sending the records it holds, including those left
+// pending by a previous run, to the remote endpoint. It also starts sending metric metadata if it is sent on an
+// interval, and staleness markers if they are enabled.
+func (prwe *PrwExporter) Start(_ context.Context, _ component.Host) error {
+	// the write-ahead log is opened first, as the goroutines sending metadata and staleness markers export through it
+	if prwe.walSettings.Enabled {
+		w, err := openWAL(prwe.walSettings, prwe.logger, prwe.metricsCtx)
+		if err != nil {
+			return err
+		}
+		prwe.wal = w
+		prwe.bgWG.Add(1)
+		go prwe.sendWAL()
+	}
+	if prwe.metadataSettings.Enabled && prwe.metadataSettings.SendInterval > 0 {
+		prwe.bgWG.Add(1)
+		go prwe.sendMetadata()
+	}
+	if prwe.staleness != nil {
+		prwe.bgWG.Add(1)
+		go prwe.sendStaleMarkers()
+	}
+	return nil
 }
 
 // Shutdown stops the exporter from accepting incoming calls(and return error), and wait for current export operations
-// to finish before returning
+// to finish before returning. Records in the write-ahead log that are not sent yet are kept for the next run.
 func (prwe *PrwExporter) Shutdown(context.Context) error {
 	close(prwe.closeChan)
 	prwe.wg.Wait()
-	return nil
+	prwe.bgWG.Wait()
+	if prwe.wal == nil {
+		return nil
+	}
+	return prwe.wal.close()
 }
 
 // PushMetrics converts metrics to Prometheus remote write TimeSeries and send to remote endpoint. It maintain a map of
@@ -85,45 +288,54 @@ func (prwe *PrwExporter) PushMetrics(ctx context.Context, md pdata.Metrics) (int
 		return pdatautil.MetricCount(md), fmt.Errorf("shutdown has been called")
 	default:
 		tsMap := map[string]*prompb.TimeSeries{}
+		metadata := map[string]*metricMetadata{}
+		exemplars := newExemplarSet(prwe.exemplarSettings)
 		dropped := 0
 		errs := []error{}
 
-		resourceMetrics := dataold.MetricDataToOtlp(pdatautil.MetricsToOldInternalMetrics(md))
-		for _, resourceMetric := range resourceMetrics {
-			if resourceMetric == nil {
+		// the new internal model has no summary type, so the summaries of metrics of the old models are split from
+		// them and translated from the old model
+		metrics, summaries := pdatautil.MetricsToInternalMetricsAndSummaries(md)
+		rms := metrics.ResourceMetrics()
+		for i := 0; i < rms.Len(); i++ {
+			rm := rms.At(i)
+			if rm.IsNil() {
 				continue
 			}
-			// TODO: add resource attributes as labels, probably in next PR
-			for _, instrumentationMetrics := range resourceMetric.InstrumentationLibraryMetrics {
-				if instrumentationMetrics == nil {
+			resourceLabels := prwe.createResourceLabels(rm.Resource())
+			ilms := rm.InstrumentationLibraryMetrics()
+			for j := 0; j < ilms.Len(); j++ {
+				ilm := ilms.At(j)
+				if ilm.IsNil() {
 					continue
 				}
 				// TODO: decide if instrumentation library information should be exported as labels
-				for _, metric := range instrumentationMetrics.Metrics {
-					if metric == nil {
+				metricSlice := ilm.Metrics()
+				for k := 0; k < metricSlice.Len(); k++ {
+					metric := metricSlice.At(k)
+					if metric.IsNil() {
 						continue
 					}
-					// check for valid type and temporality combination
-					if ok := validateMetrics(metric.MetricDescriptor); !ok {
+					// check for valid type and temporality combination, deltas are valid if converted to cumulative
+					if ok := validateMetrics(metric); !ok && prwe.accumulatorFor(metric) == nil {
 						dropped++
 						errs = append(errs, fmt.Errorf("invalid temporality and type combination"))
 						continue
 					}
+					if prwe.metadataSettings.Enabled {
+						md := getMetricMetadata(metric, prwe.namespace, prwe.namingSettings.Mode)
+						metadata[md.MetricFamilyName] = md
+					}
 					// handle individual metric based on type
-					switch metric.GetMetricDescriptor().GetType() {
-					case otlp.MetricDescriptor_MONOTONIC_INT64, otlp.MetricDescriptor_INT64,
-						otlp.MetricDescriptor_MONOTONIC_DOUBLE, otlp.MetricDescriptor_DOUBLE:
-						if err := prwe.handleScalarMetric(tsMap, metric); err != nil {
-							dropped++
-							errs = append(errs, err)
-						}
-					case otlp.MetricDescriptor_HISTOGRAM:
-						if err := prwe.handleHistogramMetric(tsMap, metric); err != nil {
+					switch metric.DataType() {
+					case pdata.MetricDataIntGauge, pdata.MetricDataDoubleGauge,
+						pdata.MetricDataIntSum, pdata.MetricDataDoubleSum:
+						if err := prwe.handleScalarMetric(tsMap, exemplars, metric, resourceLabels); err != nil {
 							dropped++
 							errs = append(errs, err)
 						}
-					case otlp.MetricDescriptor_SUMMARY:
-						if err := prwe.handleSummaryMetric(tsMap, metric); err != nil {
+					case pdata.MetricDataIntHistogram, pdata.MetricDataDoubleHistogram:
+						if err := prwe.handleHistogramMetric(tsMap, exemplars, metric, resourceLabels); err != nil {
 							dropped++
 							errs = append(errs, err)
 						}
@@ -135,166 +347,309 @@ func (prwe *PrwExporter) PushMetrics(ctx context.Context, md pdata.Metrics) (int
 			}
 		}
 
-		if err := prwe.export(ctx, tsMap); err != nil {
-			dropped = pdatautil.MetricCount(md)
-			errs = append(errs, err)
+		summaryDropped, summaryErrs := prwe.handleSummaryMetrics(tsMap, metadata, dataold.MetricDataToOtlp(summaries))
+		dropped += summaryDropped
+		errs = append(errs, summaryErrs...)
+
+		exemplars.attach(tsMap)
+		converted := len(tsMap)
+		tsMap = prwe.relabelTimeSeries(tsMap)
+		prwe.sortSamples(tsMap)
+		if prwe.staleness != nil {
+			prwe.staleness.observe(tsMap)
+		}
+		// nothing is left to send once the write relabel configs dropped every TimeSeries, which is not an error
+		if len(tsMap) != 0 || converted == 0 {
+			if err := prwe.export(ctx, tsMap, sortMetadata(metadata)); err != nil {
+				// the whole batch is retried, and metrics that cannot be converted are dropped again by the next
+				// attempt
+				if !consumererror.IsPermanent(err) {
+					return pdatautil.MetricCount(md), err
+				}
+				dropped = pdatautil.MetricCount(md)
+				errs = append(errs, err)
+			}
 		}
 
+		// retrying cannot convert the dropped metrics, and the others were sent
 		if dropped != 0 {
-			return dropped, componenterror.CombineErrors(errs)
+			err := componenterror.CombineErrors(errs)
+			if !consumererror.IsPermanent(err) {
+				err = consumererror.Permanent(err)
+			}
+			return dropped, err
 		}
 
 		return 0, nil
 	}
 }
 
-// handleScalarMetric processes data points in a single OTLP scalar metric by adding the each point as a Sample into
-// its corresponding TimeSeries in tsMap.
-// tsMap and metric cannot be nil, and metric must have a non-nil descriptor
-func (prwe *PrwExporter) handleScalarMetric(tsMap map[string]*prompb.TimeSeries, metric *otlp.Metric) error {
-	mType := metric.MetricDescriptor.Type
+// accumulatorFor returns the accumulator converting the delta Samples of metric to cumulative ones, or nil if metric
+// is not a delta or delta-to-cumulative conversion is disabled.
+func (prwe *PrwExporter) accumulatorFor(metric pdata.Metric) *deltaAccumulator {
+	if prwe.accumulator == nil || !isDelta(metric) {
+		return nil
+	}
+	return prwe.accumulator
+}
 
-	switch mType {
+// handleScalarMetric processes data points in a single OTLP gauge or sum metric by adding the each point as a Sample
+// into its corresponding TimeSeries in tsMap, and the exemplars of sum data points into exemplars.
+// tsMap and metric cannot be nil.
+func (prwe *PrwExporter) handleScalarMetric(tsMap map[string]*prompb.TimeSeries, exemplars *exemplarSet,
+	metric pdata.Metric, resourceLabels []prompb.Label) error {
+	name := getPromMetricName(metric, prwe.namespace, prwe.namingSettings.Mode)
+	kind := metric.DataType().String()
+	acc := prwe.accumulatorFor(metric)
+
+	switch metric.DataType() {
 	// int points
-	case otlp.MetricDescriptor_MONOTONIC_INT64, otlp.MetricDescriptor_INT64:
-		if metric.Int64DataPoints == nil {
-			return fmt.Errorf("nil data point field in metric %v", metric.GetMetricDescriptor().Name)
+	case pdata.MetricDataIntGauge:
+		gauge := metric.IntGaugeData()
+		if gauge.IsNil() {
+			return fmt.Errorf("nil data field in metric %v", metric.Name())
 		}
-
-		for _, pt := range metric.Int64DataPoints {
-			if pt == nil {
-				continue
-			}
-			// create parameters for addSample
-			name := getPromMetricName(metric.GetMetricDescriptor(), prwe.namespace)
-			labels := createLabelSet(pt.GetLabels(), nameStr, name)
-			sample := &prompb.Sample{
-				Value: float64(pt.Value),
-				// convert ns to ms
-				Timestamp: convertTimeStamp(pt.TimeUnixNano),
-			}
-
-			addSample(tsMap, sample, labels, metric.GetMetricDescriptor().GetType())
+		addIntDataPoints(tsMap, nil, acc, gauge.DataPoints(), name, kind, resourceLabels)
+		return nil
+	case pdata.MetricDataIntSum:
+		sum := metric.IntSumData()
+		if sum.IsNil() {
+			return fmt.Errorf("nil data field in metric %v", metric.Name())
 		}
+		addIntDataPoints(tsMap, exemplars, acc, sum.DataPoints(), name, kind, resourceLabels)
 		return nil
 
 	// double points
-	case otlp.MetricDescriptor_MONOTONIC_DOUBLE, otlp.MetricDescriptor_DOUBLE:
-		if metric.DoubleDataPoints == nil {
-			return fmt.Errorf("nil data point field in metric %v", metric.GetMetricDescriptor().Name)
+	case pdata.MetricDataDoubleGauge:
+		gauge := metric.DoubleGaugeData()
+		if gauge.IsNil() {
+			return fmt.Errorf("nil data field in metric %v", metric.Name())
 		}
-		for _, pt := range metric.DoubleDataPoints {
-			if pt == nil {
-				continue
-			}
-			// create parameters for addSample
-			name := getPromMetricName(metric.GetMetricDescriptor(), prwe.namespace)
-			labels := createLabelSet(pt.GetLabels(), nameStr, name)
-			sample := &prompb.Sample{
-				Value:     pt.Value,
-				Timestamp: convertTimeStamp(pt.TimeUnixNano),
-			}
-
-			addSample(tsMap, sample, labels, metric.GetMetricDescriptor().GetType())
+		addDoubleDataPoints(tsMap, nil, acc, gauge.DataPoints(), name, kind, resourceLabels)
+		return nil
+	case pdata.MetricDataDoubleSum:
+		sum := metric.DoubleSumData()
+		if sum.IsNil() {
+			return fmt.Errorf("nil data field in metric %v", metric.Name())
 		}
+		addDoubleDataPoints(tsMap, exemplars, acc, sum.DataPoints(), name, kind, resourceLabels)
 		return nil
 	}
 	return fmt.Errorf("invalid metric type: wants int or double data points")
 }
 
-// handleHistogramMetric processes data points in a single OTLP histogram metric by mapping the sum, count and each
-// bucket of every data point as a Sample, and adding each Sample to its corresponding TimeSeries.
-// tsMap and metric cannot be nil.
-func (prwe *PrwExporter) handleHistogramMetric(tsMap map[string]*prompb.TimeSeries, metric *otlp.Metric) error {
-
-	if metric.HistogramDataPoints == nil {
-		return fmt.Errorf("invalid metric type: wants histogram points")
+// addIntDataPoints adds each of points as a Sample of the TimeSeries named name into tsMap, and its exemplars into
+// exemplars. If acc is not nil, the points are deltas converted to cumulative Samples by acc.
+func addIntDataPoints(tsMap map[string]*prompb.TimeSeries, exemplars *exemplarSet, acc *deltaAccumulator,
+	points pdata.IntDataPointSlice, name, kind string,
+	resourceLabels []prompb.Label) {
+	for i := 0; i < points.Len(); i++ {
+		pt := points.At(i)
+		if pt.IsNil() {
+			continue
+		}
+		labels := createLabelSet(resourceLabels, pt.LabelsMap(), nameStr, name)
+		sample := &prompb.Sample{
+			Value: float64(pt.Value()),
+			// convert ns to ms
+			Timestamp: convertTimeStamp(uint64(pt.Timestamp())),
+		}
+		addDeltaSample(tsMap, acc, sample, labels, kind, pt.StartTime(), pt.Timestamp())
+		exemplars.addIntPoint(kind, labels, pt)
 	}
+}
 
-	for _, pt := range metric.HistogramDataPoints {
-		if pt == nil {
+// addDoubleDataPoints adds each of points as a Sample of the TimeSeries named name into tsMap, and its exemplars into
+// exemplars. If acc is not nil, the points are deltas converted to cumulative Samples by acc.
+func addDoubleDataPoints(tsMap map[string]*prompb.TimeSeries, exemplars *exemplarSet, acc *deltaAccumulator,
+	points pdata.DoubleDataPointSlice, name, kind string,
+	resourceLabels []prompb.Label) {
+	for i := 0; i < points.Len(); i++ {
+		pt := points.At(i)
+		if pt.IsNil() {
 			continue
 		}
-		time := convertTimeStamp(pt.TimeUnixNano)
-		mType := metric.GetMetricDescriptor().GetType()
+		labels := createLabelSet(resourceLabels, pt.LabelsMap(), nameStr, name)
+		sample := &prompb.Sample{
+			Value:     pt.Value(),
+			Timestamp: convertTimeStamp(uint64(pt.Timestamp())),
+		}
+		addDeltaSample(tsMap, acc, sample, labels, kind, pt.StartTime(), pt.Timestamp())
+		exemplars.addDoublePoint(kind, labels, pt)
+	}
+}
 
-		// sum, count, and buckets of the histogram should append suffix to baseName
-		baseName := getPromMetricName(metric.GetMetricDescriptor(), prwe.namespace)
+// handleHistogramMetric processes data points in a single OTLP histogram metric by mapping the sum, count and each
+// bucket of every data point as a Sample, and adding each Sample to its corresponding TimeSeries. The exemplars of
+// every data point are added into exemplars.
+// tsMap and metric cannot be nil.
+func (prwe *PrwExporter) handleHistogramMetric(tsMap map[string]*prompb.TimeSeries, exemplars *exemplarSet,
+	metric pdata.Metric, resourceLabels []prompb.Label) error {
+	// sum, count, and buckets of the histogram should append suffix to baseName
+	baseName := getPromMetricName(metric, prwe.namespace, prwe.namingSettings.Mode)
+	kind := metric.DataType().String()
+	acc := prwe.accumulatorFor(metric)
 
-		// treat sum as a sample in an individual TimeSeries
-		sum := &prompb.Sample{
-			Value:     pt.GetSum(),
-			Timestamp: time,
+	switch metric.DataType() {
+	case pdata.MetricDataIntHistogram:
+		histogram := metric.IntHistogramData()
+		if histogram.IsNil() {
+			return fmt.Errorf("invalid metric type: wants histogram points")
 		}
-		sumlabels := createLabelSet(pt.GetLabels(), nameStr, baseName+sumStr)
-		addSample(tsMap, sum, sumlabels, mType)
-
-		// treat count as a sample in an individual TimeSeries
-		count := &prompb.Sample{
-			Value:     float64(pt.GetCount()),
-			Timestamp: time,
+		points := histogram.DataPoints()
+		for i := 0; i < points.Len(); i++ {
+			pt := points.At(i)
+			if pt.IsNil() {
+				continue
+			}
+			addHistogramSamples(tsMap, acc, pt.LabelsMap(), pt.StartTime(), pt.Timestamp(), float64(pt.Sum()), pt.Count(),
+				pt.BucketCounts(), pt.ExplicitBounds(), baseName, kind, resourceLabels)
+			exemplars.addIntHistogramPoint(kind, baseName, resourceLabels, pt)
+		}
+		return nil
+	case pdata.MetricDataDoubleHistogram:
+		histogram := metric.DoubleHistogramData()
+		if histogram.IsNil() {
+			return fmt.Errorf("invalid metric type: wants histogram points")
 		}
-		countlabels := createLabelSet(pt.GetLabels(), nameStr, baseName+countStr)
-		addSample(tsMap, count, countlabels, mType)
+		points := histogram.DataPoints()
+		for i := 0; i < points.Len(); i++ {
+			pt := points.At(i)
+			if pt.IsNil() {
+				continue
+			}
+			addHistogramSamples(tsMap, acc, pt.LabelsMap(), pt.StartTime(), pt.Timestamp(), pt.Sum(), pt.Count(),
+				pt.BucketCounts(), pt.ExplicitBounds(), baseName, kind, resourceLabels)
+			exemplars.addDoubleHistogramPoint(kind, baseName, resourceLabels, pt)
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid metric type: wants histogram points")
+}
 
-		// count for +Inf bound
-		var totalCount uint64
+// addHistogramSamples adds the sum, count and each bucket of a histogram data point as Samples into their
+// corresponding TimeSeries in tsMap. Buckets without an explicit bound are only counted in the +Inf bucket. If acc is
+// not nil, the data point is a delta and its Samples are converted to cumulative ones by acc.
+func addHistogramSamples(tsMap map[string]*prompb.TimeSeries, acc *deltaAccumulator, pointLabels pdata.StringMap,
+	start, timestamp pdata.TimestampUnixNano, sumValue float64, countValue uint64, bucketCounts []uint64, bounds []float64,
+	baseName, kind string, resourceLabels []prompb.Label) {
+	time := convertTimeStamp(uint64(timestamp))
 
-		// process each bucket
-		for le, bk := range pt.GetBuckets() {
-			bucket := &prompb.Sample{
-				Value:     float64(bk.Count),
-				Timestamp: time,
-			}
-			boundStr := strconv.FormatFloat(pt.GetExplicitBounds()[le], 'f', -1, 64)
-			labels := createLabelSet(pt.GetLabels(), nameStr, baseName+bucketStr, leStr, boundStr)
-			addSample(tsMap, bucket, labels, mType)
+	// treat sum as a sample in an individual TimeSeries
+	sum := &prompb.Sample{
+		Value:     sumValue,
+		Timestamp: time,
+	}
+	sumlabels := createLabelSet(resourceLabels, pointLabels, nameStr, baseName+sumStr)
+	addDeltaSample(tsMap, acc, sum, sumlabels, kind, start, timestamp)
+
+	// treat count as a sample in an individual TimeSeries
+	count := &prompb.Sample{
+		Value:     float64(countValue),
+		Timestamp: time,
+	}
+	countlabels := createLabelSet(resourceLabels, pointLabels, nameStr, baseName+countStr)
+	addDeltaSample(tsMap, acc, count, countlabels, kind, start, timestamp)
 
-			totalCount += bk.GetCount()
+	// count for +Inf bound
+	var totalCount uint64
+
+	// process each bucket
+	for le, bucketCount := range bucketCounts {
+		totalCount += bucketCount
+		if le >= len(bounds) {
+			continue
 		}
-		// add le=+Inf bucket
-		infBucket := &prompb.Sample{
-			Value:     float64(totalCount),
+		bucket := &prompb.Sample{
+			Value:     float64(bucketCount),
 			Timestamp: time,
 		}
-		infLabels := createLabelSet(pt.GetLabels(), nameStr, baseName+bucketStr, leStr, pInfStr)
-		addSample(tsMap, infBucket, infLabels, mType)
+		boundStr := strconv.FormatFloat(bounds[le], 'f', -1, 64)
+		labels := createLabelSet(resourceLabels, pointLabels, nameStr, baseName+bucketStr, leStr, boundStr)
+		addDeltaSample(tsMap, acc, bucket, labels, kind, start, timestamp)
 	}
-	return nil
+	// add le=+Inf bucket
+	infBucket := &prompb.Sample{
+		Value:     float64(totalCount),
+		Timestamp: time,
+	}
+	infLabels := createLabelSet(resourceLabels, pointLabels, nameStr, baseName+bucketStr, leStr, pInfStr)
+	addDeltaSample(tsMap, acc, infBucket, infLabels, kind, start, timestamp)
+}
+
+// handleSummaryMetrics processes the summaries split from the metrics of the old models into resourceMetrics, and adds
+// their metadata to metadata if enabled. It returns the number of summaries dropped and the reasons they were dropped.
+func (prwe *PrwExporter) handleSummaryMetrics(tsMap map[string]*prompb.TimeSeries,
+	metadata map[string]*metricMetadata, resourceMetrics []*otlp.ResourceMetrics) (int, []error) {
+	dropped := 0
+	var errs []error
+	for _, resourceMetric := range resourceMetrics {
+		if resourceMetric == nil {
+			continue
+		}
+		resourceLabels := prwe.createResourceLabels(pdata.DeprecatedNewResource(&resourceMetric.Resource))
+		for _, instrumentationMetrics := range resourceMetric.InstrumentationLibraryMetrics {
+			if instrumentationMetrics == nil {
+				continue
+			}
+			for _, metric := range instrumentationMetrics.Metrics {
+				desc := metric.GetMetricDescriptor()
+				if desc.GetTemporality() != otlp.MetricDescriptor_CUMULATIVE {
+					dropped++
+					errs = append(errs, fmt.Errorf("invalid temporality and type combination"))
+					continue
+				}
+				if prwe.metadataSettings.Enabled {
+					md := getSummaryMetadata(desc, prwe.namespace, prwe.namingSettings.Mode)
+					metadata[md.MetricFamilyName] = md
+				}
+				if err := prwe.handleSummaryMetric(tsMap, metric, resourceLabels); err != nil {
+					dropped++
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	return dropped, errs
 }
 
 // handleSummaryMetric processes data points in a single OTLP summary metric by mapping the sum, count and each
 // quantile of every data point as a Sample, and adding each Sample to its corresponding TimeSeries.
 // tsMap and metric cannot be nil.
-func (prwe *PrwExporter) handleSummaryMetric(tsMap map[string]*prompb.TimeSeries, metric *otlp.Metric) error {
+func (prwe *PrwExporter) handleSummaryMetric(tsMap map[string]*prompb.TimeSeries, metric *otlp.Metric,
+	resourceLabels []prompb.Label) error {
 
 	if metric.SummaryDataPoints == nil {
 		return fmt.Errorf("invalid metric type: wants summary points")
 	}
 
-	for _, pt := range metric.SummaryDataPoints {
+	// sum and count of the Summary should append suffix to baseName
+	desc := metric.GetMetricDescriptor()
+	baseName := buildPromMetricName(desc.GetName(), desc.GetUnit(), prwe.namespace, metricTypeSummary,
+		prwe.namingSettings.Mode)
 
+	for _, pt := range metric.SummaryDataPoints {
+		if pt == nil {
+			continue
+		}
 		time := convertTimeStamp(pt.TimeUnixNano)
-		mType := metric.GetMetricDescriptor().GetType()
-
-		// sum and count of the Summary should append suffix to baseName
-		baseName := getPromMetricName(metric.GetMetricDescriptor(), prwe.namespace)
+		pointLabels := pdata.DeprecatedNewStringMap(&pt.Labels)
 
 		// treat sum as sample in an individual TimeSeries
 		sum := &prompb.Sample{
 			Value:     pt.GetSum(),
 			Timestamp: time,
 		}
-		sumlabels := createLabelSet(pt.GetLabels(), nameStr, baseName+sumStr)
-		addSample(tsMap, sum, sumlabels, mType)
+		sumlabels := createLabelSet(resourceLabels, pointLabels, nameStr, baseName+sumStr)
+		addSample(tsMap, sum, sumlabels, summaryStr)
 
 		// treat count as a sample in an individual TimeSeries
 		count := &prompb.Sample{
 			Value:     float64(pt.GetCount()),
 			Timestamp: time,
 		}
-		countlabels := createLabelSet(pt.GetLabels(), nameStr, baseName+countStr)
-		addSample(tsMap, count, countlabels, mType)
+		countlabels := createLabelSet(resourceLabels, pointLabels, nameStr, baseName+countStr)
+		addSample(tsMap, count, countlabels, summaryStr)
 
 		// process each percentile/quantile
 		for _, qt := range pt.GetPercentileValues() {
@@ -303,49 +658,285 @@ func (prwe *PrwExporter) handleSummaryMetric(tsMap map[string]*prompb.TimeSeries
 				Timestamp: time,
 			}
 			percentileStr := strconv.FormatFloat(qt.Percentile, 'f', -1, 64)
-			qtlabels := createLabelSet(pt.GetLabels(), nameStr, baseName, quantileStr, percentileStr)
-			addSample(tsMap, quantile, qtlabels, mType)
+			qtlabels := createLabelSet(resourceLabels, pointLabels, nameStr, baseName, quantileStr, percentileStr)
+			addSample(tsMap, quantile, qtlabels, summaryStr)
 		}
 	}
 	return nil
 }
 
-// export sends a Snappy-compressed WriteRequest containing TimeSeries to a remote write endpoint in order
-func (prwe *PrwExporter) export(ctx context.Context, tsMap map[string]*prompb.TimeSeries) error {
-	//Calls the helper function to convert the TsMap to the desired format
-	req, err := wrapTimeSeries(tsMap)
-	if err != nil {
+// export splits the TimeSeries of tsMap by tenant and into shards, and sends each shard to a remote write endpoint as
+// WriteRequests in the negotiated protocol. Shards are sent concurrently, and the requests of a shard are sent in order. If the
+// write-ahead log is enabled, the WriteRequests are appended to the log instead and sent in the background. The
+// metadata of the metric families of each tenant is sent with its first request, or kept to be sent on an interval.
+func (prwe *PrwExporter) export(ctx context.Context, tsMap map[string]*prompb.TimeSeries,
+	metadata []*metricMetadata) error {
+	shards := make([][]tenantRequest, prwe.shards.numShards())
+	for tenant, series := range prwe.splitByTenant(tsMap) {
+		tenantShards, err := shardTimeSeries(series, len(shards), prwe.shardSettings.MaxSamplesPerSend,
+			prwe.shardSettings.MaxBytesPerSend)
+		if err != nil {
+			return err
+		}
+		tenantMetadata := prwe.filterMetadata(metadata, series)
+		// metadata sent on an interval is only sent once the metric families were exported
+		if prwe.metadataSettings.SendInterval > 0 {
+			defer prwe.metadata.update(tenant, tenantMetadata)
+			tenantMetadata = nil
+		}
+		for i, requests := range tenantShards {
+			for j, req := range requests {
+				tr := tenantRequest{tenant: tenant, req: req}
+				if i == 0 && j == 0 {
+					tr.metadata = tenantMetadata
+				}
+				shards[i] = append(shards[i], tr)
+			}
+		}
+	}
+
+	if prwe.wal != nil {
+		for _, requests := range shards {
+			for _, tr := range requests {
+				//Converts the WriteRequest and its metadata into bytes array
+				data, err := marshalWriteRequest(tr.req, tr.metadata)
+				if err != nil {
+					return err
+				}
+				if data, err = marshalTenantRecord(tr.tenant, countSamples(tr.req), data); err != nil {
+					return err
+				}
+				if err = prwe.wal.write(data); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	var mu sync.Mutex
+	var sendTime time.Duration
+	sent := 0
+	errs := []error{}
+	wg := new(sync.WaitGroup)
+	for _, requests := range shards {
+		if len(requests) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(requests []tenantRequest) {
+			defer wg.Done()
+			for _, tr := range requests {
+				start := time.Now()
+				data, err := marshalWriteRequest(tr.req, tr.metadata)
+				if err == nil {
+					err = prwe.send(ctx, tr.tenant, data)
+				}
+				prwe.recordSamples(tr.tenant, countSamples(tr.req), err)
+				mu.Lock()
+				sendTime += time.Since(start)
+				sent++
+				if err != nil {
+					errs = append(errs, err)
+				}
+				mu.Unlock()
+			}
+		}(requests)
+	}
+	wg.Wait()
+
+	prwe.shards.observe(sent, sendTime/time.Duration(sent))
+	stats.Record(prwe.metricsCtx, mShards.M(int64(prwe.shards.numShards())))
+	return combineExportErrors(errs)
+}
+
+// sendMetadata sends the metadata of every metric family exported so far on the configured interval, until Shutdown
+// is called.
+func (prwe *PrwExporter) sendMetadata() {
+	defer prwe.bgWG.Done()
+	ticker := time.NewTicker(prwe.metadataSettings.SendInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-prwe.closeChan:
+			return
+		case <-ticker.C:
+		}
+		for tenant, metadata := range prwe.metadata.get() {
+			data, err := marshalWriteRequest(&prompb.WriteRequest{}, metadata)
+			if err == nil {
+				err = prwe.send(context.Background(), tenant, data)
+			}
+			if err != nil {
+				prwe.logger.Warn("Failed to send metric metadata", zap.String("tenant", tenant), zap.Error(err))
+			}
+		}
+	}
+}
+
+// combineExportErrors combines the errors of sending the requests of an export. The combined error is permanent if
+// every error is permanent, and otherwise delays the retry by the longest delay requested by the endpoint, if any.
+func combineExportErrors(errs []error) error {
+	if len(errs) <= 1 {
+		err := componenterror.CombineErrors(errs)
+		if rae, ok := err.(*retryAfterError); ok && rae.delay > 0 {
+			return exporterhelper.NewThrottleRetry(err, rae.delay)
+		}
 		return err
 	}
-	//Uses proto.Marshal to convert the WriteRequest into bytes array
-	data, err := proto.Marshal(req)
+	permanent := true
+	var delay time.Duration
+	for _, err := range errs {
+		if !consumererror.IsPermanent(err) {
+			permanent = false
+		}
+		if rae, ok := err.(*retryAfterError); ok && rae.delay > delay {
+			delay = rae.delay
+		}
+	}
+	err := componenterror.CombineErrors(errs)
+	switch {
+	case permanent:
+		return consumererror.Permanent(err)
+	case delay > 0:
+		return exporterhelper.NewThrottleRetry(err, delay)
+	}
+	return err
+}
+
+// sendWAL sends the records of the write-ahead log in order until Shutdown is called. A record is acknowledged once
+// it is accepted by the endpoint or rejected with a permanent error; otherwise it is retried with exponential backoff.
+func (prwe *PrwExporter) sendWAL() {
+	defer prwe.bgWG.Done()
+
+	// cancel in-flight requests on shutdown, the record is sent again on the next run
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-prwe.closeChan
+		cancel()
+	}()
+
+	backoff := walInitialBackoff
+	for {
+		record, pos, err := prwe.wal.next()
+		if err == errWALEmpty {
+			select {
+			case <-prwe.closeChan:
+				return
+			case <-prwe.wal.notify:
+			}
+			continue
+		}
+
+		tenant, samples, data := unmarshalTenantRecord(record)
+		if err = prwe.send(ctx, tenant, data); err != nil && !consumererror.IsPermanent(err) {
+			delay := backoff
+			if rae, ok := err.(*retryAfterError); ok && rae.delay > delay {
+				delay = rae.delay
+			}
+			prwe.logger.Warn("Failed to send write-ahead log record, will retry", zap.Duration("backoff", delay),
+				zap.Error(err))
+			prwe.wal.rewind()
+			select {
+			case <-prwe.closeChan:
+				return
+			case <-time.After(delay):
+			}
+			if backoff *= 2; backoff > walMaxBackoff {
+				backoff = walMaxBackoff
+			}
+			continue
+		}
+		if err != nil {
+			prwe.logger.Error("Dropping write-ahead log record rejected by the endpoint", zap.Error(err))
+		}
+		prwe.recordSamples(tenant, samples, err)
+
+		backoff = walInitialBackoff
+		if err = prwe.wal.ack(pos); err != nil {
+			prwe.logger.Warn("Failed to write write-ahead log checkpoint", zap.Error(err))
+		}
+		stats.Record(prwe.metricsCtx, mWALReplayedRecords.M(1))
+	}
+}
+
+// send sends data, a marshaled WriteRequest and its metadata, to the remote write endpoint on behalf of tenant if not
+// empty, encoded in the protocol negotiated with the endpoint. If the endpoint does not support the protocol, data is
+// sent again in the protocol every endpoint supports.
+func (prwe *PrwExporter) send(ctx context.Context, tenant string, data []byte) error {
+	err := prwe.sendWith(ctx, tenant, data, prwe.protocol.current())
+	if unsupported, ok := err.(*unsupportedProtocolError); ok {
+		prwe.protocol.fallBack(unsupported.error)
+		return prwe.sendWith(ctx, tenant, data, baseProtocol)
+	}
+	return err
+}
+
+// unsupportedProtocolError is the error of a request in a protocol the endpoint does not support.
+type unsupportedProtocolError struct {
+	error
+}
+
+// sendWith sends data to the remote write endpoint encoded in the protocol of settings. It returns an
+// unsupportedProtocolError if the endpoint does not support the protocol, unless it is the base protocol. The request
+// is traced and recorded in the metrics of the exporter by its HTTP status code.
+func (prwe *PrwExporter) sendWith(ctx context.Context, tenant string, data []byte,
+	settings ProtocolSettings) (err error) {
+	encoded, samples, err := prwe.protocol.encode(data, settings)
 	if err != nil {
-		return err
+		return consumererror.Permanent(err)
 	}
-	buf := make([]byte, len(data), cap(data))
-	compressedData := snappy.Encode(buf, data)
+
+	// the exporter tag is also read by the round trippers of the client, such as the SigV4 one of Cortex
+	if tagCtx, tagErr := tag.New(ctx, tag.Upsert(tagExporterName, prwe.name)); tagErr == nil {
+		ctx = tagCtx
+	}
+	ctx, span := trace.StartSpan(ctx, obsreport.ExporterKey+"/"+prwe.name+"/WriteRequestSent")
+	span.AddAttributes(
+		trace.StringAttribute("protocol.version", settings.Version),
+		trace.StringAttribute("protocol.compression", settings.Compression),
+		trace.Int64Attribute("sent_bytes", int64(len(encoded))))
+	defer func() {
+		if err != nil {
+			span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()})
+		}
+		span.End()
+	}()
 
 	//Create the HTTP POST request to send to the endpoint
-	httpReq, err := http.NewRequest("POST", prwe.endpointURL.String(), bytes.NewReader(compressedData))
+	httpReq, err := http.NewRequest("POST", prwe.endpointURL.String(), bytes.NewReader(encoded))
 	if err != nil {
 		return err
 	}
 
 	// Add necessary headers specified by:
 	// https://cortexmetrics.io/docs/apis/#remote-api
-	httpReq.Header.Add("Content-Encoding", "snappy")
-	httpReq.Header.Set("Content-Type", "application/x-protobuf")
-	httpReq.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	httpReq.Header.Add("Content-Encoding", settings.Compression)
+	httpReq.Header.Set("Content-Type", contentType(settings.Version))
+	httpReq.Header.Set(remoteWriteVersionHeader, versionHeader(settings.Version))
+	if tenant != "" && prwe.tenantSettings.Header != "" {
+		httpReq.Header.Set(prwe.tenantSettings.Header, tenant)
+	}
 
+	// the timeout of the request is the timeout of the client
 	httpReq = httpReq.WithContext(ctx)
 
-	_, cancel := context.WithTimeout(context.Background(), prwe.client.Timeout)
-	defer cancel()
-
+	start := time.Now()
 	httpResp, err := prwe.client.Do(httpReq)
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(httpResp.StatusCode)
+	}
+	span.AddAttributes(trace.StringAttribute("http.status_code", status))
+	prwe.recordRequest(status, len(encoded), time.Since(start))
 	if err != nil {
 		return err
 	}
+	defer func() {
+		io.Copy(ioutil.Discard, httpResp.Body)
+		httpResp.Body.Close()
+	}()
 
 	if httpResp.StatusCode/100 != 2 {
 		scanner := bufio.NewScanner(io.LimitReader(httpResp.Body, 256))
@@ -354,7 +945,88 @@ func (prwe *PrwExporter) export(ctx context.Context, tsMap map[string]*prompb.Ti
 			line = scanner.Text()
 		}
 		errMsg := "server returned HTTP status " + httpResp.Status + ": " + line
-		return fmt.Errorf(errMsg)
+		if httpResp.StatusCode == http.StatusUnsupportedMediaType && settings != baseProtocol {
+			return &unsupportedProtocolError{errors.New(errMsg)}
+		}
+		return classifyHTTPError(httpResp, errors.New(errMsg))
+	}
+	// remote write 1.0 endpoints ignore the fields of remote write 2.0 requests, and write nothing
+	if settings.Version == ProtocolVersion2 && samples > 0 && httpResp.Header.Get(samplesWrittenHeader) == "" {
+		return &unsupportedProtocolError{fmt.Errorf("server did not report the samples written")}
 	}
 	return nil
 }
+
+// recordRequest records a request of size bytes sent to the endpoint, which took latency and completed with the HTTP
+// status code status, or "error" if no response was received.
+func (prwe *PrwExporter) recordRequest(status string, size int, latency time.Duration) {
+	stats.Record(prwe.metricsCtx, mSentBytes.M(int64(size)))
+	ctx, err := tag.New(prwe.metricsCtx, tag.Insert(tagStatusCode, status))
+	if err != nil {
+		return
+	}
+	stats.Record(ctx, mRequestLatency.M(float64(latency)/float64(time.Millisecond)))
+}
+
+// recordSamples records the number of Samples of a request sent to tenant, or that failed to be sent if err is not
+// nil. Samples failing with a retryable error are recorded as retried, as they are sent again.
+func (prwe *PrwExporter) recordSamples(tenant string, samples int, err error) {
+	if samples == 0 {
+		return
+	}
+	measure := mSentSamples
+	switch {
+	case err == nil:
+	case consumererror.IsPermanent(err):
+		measure = mFailedSamples
+	default:
+		measure = mRetriedSamples
+	}
+	stats.Record(prwe.metricsCtx, measure.M(int64(samples)), mRequestSamples.M(int64(samples)))
+
+	if !prwe.tenantSettings.Enabled {
+		return
+	}
+	ctx, tagErr := tag.New(prwe.metricsCtx, tag.Insert(tagTenant, tenant))
+	if tagErr != nil {
+		return
+	}
+	if err != nil {
+		stats.Record(ctx, mTenantFailedSamples.M(int64(samples)))
+		return
+	}
+	stats.Record(ctx, mTenantSentSamples.M(int64(samples)))
+}
+
+// retryAfterError is a retryable error for which the endpoint requested a minimum delay before retrying.
+type retryAfterError struct {
+	error
+	delay time.Duration
+}
+
+// classifyHTTPError classifies the error of a request that failed with a non-2xx response. Client errors other than
+// 429 Too Many Requests are permanent since resending the same data fails again; other errors are retryable and carry
+// the delay of the Retry-After header, if any.
+func classifyHTTPError(resp *http.Response, err error) error {
+	if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+		return consumererror.Permanent(err)
+	}
+	if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		return &retryAfterError{error: err, delay: delay}
+	}
+	return err
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, seconds >= 0
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date), true
+	}
+	return 0, false
+}
@@ -16,28 +16,40 @@ package prometheusremotewriteexporter
 
 import (
 	"context"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	proto "github.com/gogo/protobuf/proto"
 	"github.com/golang/snappy"
 	"github.com/prometheus/prometheus/prompb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
 
+	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/config/configmodels"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/consumer/pdata"
 	"go.opentelemetry.io/collector/consumer/pdatautil"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	commonpb "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/common/v1"
+	otlpmetrics "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1"
 	otlp "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1old"
+	resourcepb "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/resource/v1"
 	"go.opentelemetry.io/collector/internal/dataold"
 	"go.opentelemetry.io/collector/internal/dataold/testdataold"
+	"go.opentelemetry.io/collector/translator/conventions"
+	"go.opentelemetry.io/collector/translator/internaldata"
 )
 
 // TODO: add bucket and histogram test cases for Test_PushMetrics
@@ -45,122 +57,113 @@ import (
 // Test_handleScalarMetric checks whether data points within a single scalar metric can be added to a map of
 // TimeSeries correctly.
 // Test cases are two data point belonging to the same TimeSeries, two data point belonging different TimeSeries,
-// and nil data points case.
+// gauges and non-monotonic sums, and nil data points case.
 func Test_handleScalarMetric(t *testing.T) {
+	cumulative := otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
 	sameTs := map[string]*prompb.TimeSeries{
 		// string signature of the data point is the key of the map
-		typeMonotonicInt64 + "-__name__-same_ts_int_points_total" + lb1Sig: getTimeSeries(
+		typeIntSum + "-__name__-same_ts_int_points_total" + lb1Sig: getTimeSeries(
 			getPromLabels(label11, value11, label12, value12, nameStr, "same_ts_int_points_total"),
 			getSample(float64(intVal1), msTime1),
 			getSample(float64(intVal2), msTime1)),
 	}
 	differentTs := map[string]*prompb.TimeSeries{
-		typeMonotonicDouble + "-__name__-different_ts_double_points_total" + lb1Sig: getTimeSeries(
+		typeDoubleSum + "-__name__-different_ts_double_points_total" + lb1Sig: getTimeSeries(
 			getPromLabels(label11, value11, label12, value12, nameStr, "different_ts_double_points_total"),
 			getSample(floatVal1, msTime1)),
-		typeMonotonicDouble + "-__name__-different_ts_double_points_total" + lb2Sig: getTimeSeries(
+		typeDoubleSum + "-__name__-different_ts_double_points_total" + lb2Sig: getTimeSeries(
 			getPromLabels(label21, value21, label22, value22, nameStr, "different_ts_double_points_total"),
 			getSample(floatVal2, msTime2)),
 	}
+	gauge := map[string]*prompb.TimeSeries{
+		typeIntGauge + "-__name__-int_gauge" + lb1Sig: getTimeSeries(
+			getPromLabels(label11, value11, label12, value12, nameStr, "int_gauge"),
+			getSample(float64(intVal1), msTime1)),
+	}
+	nonMonotonicSum := map[string]*prompb.TimeSeries{
+		typeDoubleSum + "-__name__-double_sum" + lb2Sig: getTimeSeries(
+			getPromLabels(label21, value21, label22, value22, nameStr, "double_sum"),
+			getSample(floatVal2, msTime2)),
+	}
 
 	tests := []struct {
 		name        string
-		m           *otlp.Metric
+		m           *otlpmetrics.Metric
 		returnError bool
 		want        map[string]*prompb.TimeSeries
 	}{
 		{
-			"invalid_nil_array",
-			&otlp.Metric{
-				MetricDescriptor:    getDescriptor("invalid_nil_array", monotonicInt64Comb, validCombinations),
-				Int64DataPoints:     nil,
-				DoubleDataPoints:    nil,
-				HistogramDataPoints: nil,
-				SummaryDataPoints:   nil,
+			"invalid_nil_data",
+			&otlpmetrics.Metric{
+				Name: "invalid_nil_data",
+				Data: &otlpmetrics.Metric_IntSum{},
 			},
 			true,
 			map[string]*prompb.TimeSeries{},
 		},
 		{
 			"invalid_metric_type",
-			&otlp.Metric{
-				MetricDescriptor:    getDescriptor("invalid_nil_array", histogramComb, validCombinations),
-				Int64DataPoints:     nil,
-				DoubleDataPoints:    nil,
-				HistogramDataPoints: nil,
-				SummaryDataPoints:   nil,
-			},
+			getDoubleHistogramMetric("invalid_metric_type", cumulative),
 			true,
 			map[string]*prompb.TimeSeries{},
 		},
 		{
 			"int_nil_point",
-			&otlp.Metric{
-				MetricDescriptor:    getDescriptor("int_nil_point", monotonicInt64Comb, validCombinations),
-				Int64DataPoints:     []*otlp.Int64DataPoint{nil},
-				DoubleDataPoints:    nil,
-				HistogramDataPoints: nil,
-				SummaryDataPoints:   nil,
-			},
+			getIntSumMetric("int_nil_point", cumulative, true, nil),
 			false,
 			map[string]*prompb.TimeSeries{},
 		},
 		{
 			"double_nil_point",
-			&otlp.Metric{
-				MetricDescriptor:    getDescriptor("double_nil_point", monotonicDoubleComb, validCombinations),
-				Int64DataPoints:     nil,
-				DoubleDataPoints:    []*otlp.DoubleDataPoint{nil},
-				HistogramDataPoints: nil,
-				SummaryDataPoints:   nil,
-			},
+			getDoubleSumMetric("double_nil_point", cumulative, true, nil),
 			false,
 			map[string]*prompb.TimeSeries{},
 		},
 		{
 			"same_ts_int_points",
-			&otlp.Metric{
-				MetricDescriptor: getDescriptor("same_ts_int_points", monotonicInt64Comb, validCombinations),
-				Int64DataPoints: []*otlp.Int64DataPoint{
-					getIntDataPoint(lbs1, intVal1, time1),
-					getIntDataPoint(lbs1, intVal2, time1),
-				},
-				DoubleDataPoints:    nil,
-				HistogramDataPoints: nil,
-				SummaryDataPoints:   nil,
-			},
+			getIntSumMetric("same_ts_int_points", cumulative, true,
+				getIntPoint(lbs1, intVal1, time1),
+				getIntPoint(lbs1, intVal2, time1),
+			),
 			false,
 			sameTs,
 		},
 		{
 			"different_ts_double_points",
-			&otlp.Metric{
-				MetricDescriptor: getDescriptor("different_ts_double_points", monotonicDoubleComb, validCombinations),
-				Int64DataPoints:  nil,
-				DoubleDataPoints: []*otlp.DoubleDataPoint{
-					getDoubleDataPoint(lbs1, floatVal1, time1),
-					getDoubleDataPoint(lbs2, floatVal2, time2),
-				},
-				HistogramDataPoints: nil,
-				SummaryDataPoints:   nil,
-			},
+			getDoubleSumMetric("different_ts_double_points", cumulative, true,
+				getDoublePoint(lbs1, floatVal1, time1),
+				getDoublePoint(lbs2, floatVal2, time2),
+			),
 			false,
 			differentTs,
 		},
+		{
+			"int_gauge",
+			getIntGaugeMetric("int_gauge", getIntPoint(lbs1, intVal1, time1)),
+			false,
+			gauge,
+		},
+		{
+			"non_monotonic_double_sum",
+			getDoubleSumMetric("double_sum", otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA, false,
+				getDoublePoint(lbs2, floatVal2, time2)),
+			false,
+			nonMonotonicSum,
+		},
 	}
 	// run tests
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tsMap := map[string]*prompb.TimeSeries{}
 			prw := &PrwExporter{}
-			ok := prw.handleScalarMetric(tsMap, tt.m)
+			ok := prw.handleScalarMetric(tsMap, nil, getMetric(tt.m), nil)
 			if tt.returnError {
 				assert.Error(t, ok)
 				return
 			}
 			assert.Exactly(t, len(tt.want), len(tsMap))
 			for k, v := range tsMap {
-				require.NotNil(t, tt.want[k])
+				require.NotNil(t, tt.want[k], k)
 				assert.ElementsMatch(t, tt.want[k].Labels, v.Labels)
 				assert.ElementsMatch(t, tt.want[k].Samples, v.Samples)
 			}
@@ -170,30 +173,32 @@ func Test_handleScalarMetric(t *testing.T) {
 
 // Test_handleHistogramMetric checks whether data points(sum, count, buckets) within a single Histogram metric can be
 // added to a map of TimeSeries correctly.
-// Test cases are a histogram data point with two buckets and nil data points case.
+// Test cases are int and double histogram data points with two buckets, and nil data points case.
 func Test_handleHistogramMetric(t *testing.T) {
+	cumulative := otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
 	sum := "sum"
 	count := "count"
 	bucket1 := "bucket1"
 	bucket2 := "bucket2"
 	bucketInf := "bucketInf"
-	histPoint := getHistogramDataPoint(
-		lbs1,
-		time1,
-		floatVal2,
-		uint64(intVal2), []float64{floatVal1, floatVal2},
-		[]uint64{uint64(intVal1), uint64(intVal1)})
+	bounds := []float64{floatVal1, floatVal2}
+	// the last bucket has no explicit bound and is only counted in the +Inf bucket
+	buckets := []uint64{uint64(intVal1), uint64(intVal1), uint64(intVal1)}
+	doublePoint := getDoubleHistogramPoint(lbs1, time1, floatVal2, uint64(intVal2), bounds, buckets)
+	intPoint := getIntHistogramPoint(lbs1, time1, intVal2, uint64(intVal2), bounds, buckets)
 
 	// string signature of the data point is the key of the map
-	sigs := map[string]string{
-		sum:   typeHistogram + "-" + nameStr + "-" + name1 + "_sum" + lb1Sig,
-		count: typeHistogram + "-" + nameStr + "-" + name1 + "_count" + lb1Sig,
-		bucket1: typeHistogram + "-" + nameStr + "-" + name1 + "_bucket" + "-" + "le-" +
-			strconv.FormatFloat(floatVal1, 'f', -1, 64) + lb1Sig,
-		bucket2: typeHistogram + "-" + nameStr + "-" + name1 + "_bucket" + "-" + "le-" +
-			strconv.FormatFloat(floatVal2, 'f', -1, 64) + lb1Sig,
-		bucketInf: typeHistogram + "-" + nameStr + "-" + name1 + "_bucket" + "-" + "le-" +
-			"+Inf" + lb1Sig,
+	sigs := func(kind string) map[string]string {
+		return map[string]string{
+			sum:   kind + "-" + nameStr + "-" + name1 + "_sum" + lb1Sig,
+			count: kind + "-" + nameStr + "-" + name1 + "_count" + lb1Sig,
+			bucket1: kind + "-" + nameStr + "-" + name1 + "_bucket" + "-" + "le-" +
+				strconv.FormatFloat(floatVal1, 'f', -1, 64) + lb1Sig,
+			bucket2: kind + "-" + nameStr + "-" + name1 + "_bucket" + "-" + "le-" +
+				strconv.FormatFloat(floatVal2, 'f', -1, 64) + lb1Sig,
+			bucketInf: kind + "-" + nameStr + "-" + name1 + "_bucket" + "-" + "le-" +
+				"+Inf" + lb1Sig,
+		}
 	}
 	labels := map[string][]prompb.Label{
 		sum:   append(promLbs1, getPromLabels(nameStr, name1+"_sum")...),
@@ -205,53 +210,54 @@ func Test_handleHistogramMetric(t *testing.T) {
 		bucketInf: append(promLbs1, getPromLabels(nameStr, name1+"_bucket", "le",
 			"+Inf")...),
 	}
+	want := func(kind string) map[string]*prompb.TimeSeries {
+		sigs := sigs(kind)
+		return map[string]*prompb.TimeSeries{
+			sigs[sum]:       getTimeSeries(labels[sum], getSample(floatVal2, msTime1)),
+			sigs[count]:     getTimeSeries(labels[count], getSample(float64(intVal2), msTime1)),
+			sigs[bucket1]:   getTimeSeries(labels[bucket1], getSample(float64(intVal1), msTime1)),
+			sigs[bucket2]:   getTimeSeries(labels[bucket2], getSample(float64(intVal1), msTime1)),
+			sigs[bucketInf]: getTimeSeries(labels[bucketInf], getSample(float64(3*intVal1), msTime1)),
+		}
+	}
 	tests := []struct {
 		name        string
-		m           otlp.Metric
+		m           *otlpmetrics.Metric
 		returnError bool
 		want        map[string]*prompb.TimeSeries
 	}{
 		{
-			"invalid_nil_array",
-			otlp.Metric{
-				MetricDescriptor:    getDescriptor("invalid_nil_array", histogramComb, validCombinations),
-				Int64DataPoints:     nil,
-				DoubleDataPoints:    nil,
-				HistogramDataPoints: nil,
-				SummaryDataPoints:   nil,
+			"invalid_nil_data",
+			&otlpmetrics.Metric{
+				Name: "invalid_nil_data",
+				Data: &otlpmetrics.Metric_DoubleHistogram{},
 			},
 			true,
 			map[string]*prompb.TimeSeries{},
 		},
+		{
+			"invalid_metric_type",
+			getIntGaugeMetric("invalid_metric_type"),
+			true,
+			map[string]*prompb.TimeSeries{},
+		},
 		{
 			"hist_nil_pt",
-			otlp.Metric{
-				MetricDescriptor:    getDescriptor("hist_nil_pt", histogramComb, validCombinations),
-				Int64DataPoints:     nil,
-				DoubleDataPoints:    nil,
-				HistogramDataPoints: []*otlp.HistogramDataPoint{nil},
-				SummaryDataPoints:   nil,
-			},
+			getDoubleHistogramMetric("hist_nil_pt", cumulative, nil),
 			false,
 			map[string]*prompb.TimeSeries{},
 		},
 		{
-			"single_histogram_point",
-			otlp.Metric{
-				MetricDescriptor:    getDescriptor(name1+"", histogramComb, validCombinations),
-				Int64DataPoints:     nil,
-				DoubleDataPoints:    nil,
-				HistogramDataPoints: []*otlp.HistogramDataPoint{histPoint},
-				SummaryDataPoints:   nil,
-			},
+			"single_double_histogram_point",
+			getDoubleHistogramMetric(name1, cumulative, doublePoint),
 			false,
-			map[string]*prompb.TimeSeries{
-				sigs[sum]:       getTimeSeries(labels[sum], getSample(floatVal2, msTime1)),
-				sigs[count]:     getTimeSeries(labels[count], getSample(float64(intVal2), msTime1)),
-				sigs[bucket1]:   getTimeSeries(labels[bucket1], getSample(float64(intVal1), msTime1)),
-				sigs[bucket2]:   getTimeSeries(labels[bucket2], getSample(float64(intVal1), msTime1)),
-				sigs[bucketInf]: getTimeSeries(labels[bucketInf], getSample(float64(intVal2), msTime1)),
-			},
+			want(typeDoubleHistogram),
+		},
+		{
+			"single_int_histogram_point",
+			getIntHistogramMetric(name1, cumulative, intPoint),
+			false,
+			want(typeIntHistogram),
 		},
 	}
 
@@ -260,7 +266,7 @@ func Test_handleHistogramMetric(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tsMap := map[string]*prompb.TimeSeries{}
 			prw := &PrwExporter{}
-			ok := prw.handleHistogramMetric(tsMap, &tt.m)
+			ok := prw.handleHistogramMetric(tsMap, nil, getMetric(tt.m), nil)
 			if tt.returnError {
 				assert.Error(t, ok)
 				return
@@ -343,7 +349,7 @@ func Test_handleSummaryMetric(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tsMap := map[string]*prompb.TimeSeries{}
 			prw := &PrwExporter{}
-			ok := prw.handleSummaryMetric(tsMap, &tt.m)
+			ok := prw.handleSummaryMetric(tsMap, &tt.m, nil)
 			if tt.returnError {
 				assert.Error(t, ok)
 				return
@@ -546,7 +552,7 @@ func runExportPipeline(t *testing.T, ts *prompb.TimeSeries, endpoint *url.URL) e
 	if err != nil {
 		return err
 	}
-	err = prwe.export(context.Background(), testmap)
+	err = prwe.export(context.Background(), testmap, nil)
 	return err
 }
 
@@ -823,3 +829,475 @@ func Test_PushMetrics(t *testing.T) {
 		})
 	}
 }
+
+// Test_PushMetricsResourceAttributes checks that points with the same labels from two different resources are exported
+// as two different TimeSeries carrying the job and instance labels derived from their resources.
+func Test_PushMetricsResourceAttributes(t *testing.T) {
+	getResourceMetrics := func(instance string) *otlp.ResourceMetrics {
+		return &otlp.ResourceMetrics{
+			Resource: &resourcepb.Resource{
+				Attributes: []*commonpb.KeyValue{
+					{
+						Key:   conventions.AttributeServiceName,
+						Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "checkout"}},
+					},
+					{
+						Key:   conventions.AttributeServiceInstance,
+						Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: instance}},
+					},
+				},
+			},
+			InstrumentationLibraryMetrics: []*otlp.InstrumentationLibraryMetrics{
+				{
+					Metrics: []*otlp.Metric{
+						{
+							MetricDescriptor: getDescriptor("gauge", 0, []combination{
+								{otlp.MetricDescriptor_INT64, otlp.MetricDescriptor_INSTANTANEOUS},
+							}),
+							Int64DataPoints: []*otlp.Int64DataPoint{getIntDataPoint(lbs1, intVal1, time1)},
+						},
+					},
+				},
+			},
+		}
+	}
+	md := pdatautil.MetricsFromOldInternalMetrics(dataold.MetricDataFromOtlp([]*otlp.ResourceMetrics{
+		getResourceMetrics("pod-1"),
+		getResourceMetrics("pod-2"),
+	}))
+
+	var got []prompb.TimeSeries
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		dest, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		wr := &prompb.WriteRequest{}
+		require.NoError(t, proto.Unmarshal(dest, wr))
+		got = wr.Timeseries
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	prwe, err := NewPrwExporter("", server.URL, http.DefaultClient,
+		WithResourceAttributes(ResourceAttributesSettings{JobInstance: true}))
+	require.NoError(t, err)
+	dropped, err := prwe.PushMetrics(context.Background(), md)
+	require.NoError(t, err)
+	assert.Equal(t, 0, dropped)
+
+	require.Len(t, got, 2)
+	instances := []string{}
+	for _, ts := range got {
+		assert.Contains(t, ts.Labels, getLabel(jobStr, "checkout"))
+		for _, lb := range ts.Labels {
+			if lb.Name == instanceStr {
+				instances = append(instances, lb.Value)
+			}
+		}
+	}
+	assert.ElementsMatch(t, []string{"pod-1", "pod-2"}, instances)
+}
+
+// Test_PushMetricsWAL checks that a batch written to the write-ahead log while the endpoint is down is kept across a
+// restart of the exporter, and sent once the endpoint is back.
+func Test_PushMetricsWAL(t *testing.T) {
+	batch := testdataold.GenerateMetricDataManyMetricsSameResource(10)
+	setCumulative(&batch)
+	md := pdatautil.MetricsFromOldInternalMetrics(batch)
+
+	var up atomic.Bool
+	var attempts atomic.Int32
+	received := make(chan *prompb.WriteRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Inc()
+		if !up.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		dest, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		wr := &prompb.WriteRequest{}
+		require.NoError(t, proto.Unmarshal(dest, wr))
+		received <- wr
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "wal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	settings := CreateDefaultWALSettings()
+	settings.Enabled = true
+	settings.Directory = dir
+
+	prwe, err := NewPrwExporter("", server.URL, http.DefaultClient, WithWAL(settings))
+	require.NoError(t, err)
+	require.NoError(t, prwe.Start(context.Background(), componenttest.NewNopHost()))
+	dropped, err := prwe.PushMetrics(context.Background(), md)
+	require.NoError(t, err)
+	assert.Equal(t, 0, dropped)
+	assert.Eventually(t, func() bool { return attempts.Load() > 0 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, prwe.Shutdown(context.Background()))
+
+	up.Store(true)
+	prwe, err = NewPrwExporter("", server.URL, http.DefaultClient, WithWAL(settings))
+	require.NoError(t, err)
+	require.NoError(t, prwe.Start(context.Background(), componenttest.NewNopHost()))
+	defer prwe.Shutdown(context.Background())
+
+	select {
+	case wr := <-received:
+		assert.Len(t, wr.Timeseries, 2)
+	case <-time.After(5 * time.Second):
+		t.Fatal("write-ahead log was not replayed")
+	}
+}
+
+// Test_exportShards checks that TimeSeries are sent in multiple requests within the size limits when sharding is
+// configured, and that every sample reaches the endpoint.
+func Test_exportShards(t *testing.T) {
+	var mu sync.Mutex
+	requests, samples := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		dest, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		wr := &prompb.WriteRequest{}
+		require.NoError(t, proto.Unmarshal(dest, wr))
+
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		for _, ts := range wr.Timeseries {
+			assert.LessOrEqual(t, len(ts.Samples), 2)
+			samples += len(ts.Samples)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	prwe, err := NewPrwExporter("", server.URL, http.DefaultClient,
+		WithShards(ShardSettings{MinShards: 4, MaxShards: 4, MaxSamplesPerSend: 2}))
+	require.NoError(t, err)
+	require.NoError(t, prwe.export(context.Background(), getTsMap(20, 3), nil))
+
+	assert.Equal(t, 60, samples)
+	// 60 samples need at least 30 requests of at most 2 samples
+	assert.GreaterOrEqual(t, requests, 30)
+}
+
+// Test_classifyHTTPError checks that client errors other than 429 are permanent, and that the Retry-After header of
+// retryable errors is honoured.
+func Test_classifyHTTPError(t *testing.T) {
+	tests := []struct {
+		name          string
+		code          int
+		retryAfter    string
+		wantPermanent bool
+		wantDelay     time.Duration
+	}{
+		{"bad_request_case", http.StatusBadRequest, "", true, 0},
+		{"forbidden_case", http.StatusForbidden, "", true, 0},
+		{"too_many_requests_case", http.StatusTooManyRequests, "", false, 0},
+		{"too_many_requests_retry_after_case", http.StatusTooManyRequests, "30", false, 30 * time.Second},
+		{"unavailable_case", http.StatusServiceUnavailable, "", false, 0},
+		{"unavailable_retry_after_case", http.StatusServiceUnavailable, "5", false, 5 * time.Second},
+		{"invalid_retry_after_case", http.StatusInternalServerError, "soon", false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.code, Header: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+			err := classifyHTTPError(resp, errors.New("test error"))
+			assert.Equal(t, tt.wantPermanent, consumererror.IsPermanent(err))
+			rae, ok := err.(*retryAfterError)
+			assert.Equal(t, tt.wantDelay > 0, ok)
+			if ok {
+				assert.Equal(t, tt.wantDelay, rae.delay)
+			}
+		})
+	}
+}
+
+// Test_parseRetryAfter checks that both forms of the Retry-After header are parsed.
+func Test_parseRetryAfter(t *testing.T) {
+	delay, ok := parseRetryAfter("120")
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, delay)
+
+	delay, ok = parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.InDelta(t, time.Hour.Seconds(), delay.Seconds(), 5)
+
+	_, ok = parseRetryAfter("")
+	assert.False(t, ok)
+	_, ok = parseRetryAfter("-1")
+	assert.False(t, ok)
+}
+
+// Test_combineExportErrors checks that combined errors are only permanent if every error is permanent.
+func Test_combineExportErrors(t *testing.T) {
+	permanent := consumererror.Permanent(errors.New("permanent"))
+	retryable := errors.New("retryable")
+	throttled := &retryAfterError{error: errors.New("throttled"), delay: time.Second}
+
+	assert.NoError(t, combineExportErrors(nil))
+	assert.Equal(t, permanent, combineExportErrors([]error{permanent}))
+	assert.Equal(t, retryable, combineExportErrors([]error{retryable}))
+	assert.Equal(t, exporterhelper.NewThrottleRetry(throttled, time.Second), combineExportErrors([]error{throttled}))
+	assert.True(t, consumererror.IsPermanent(combineExportErrors([]error{permanent, permanent})))
+	assert.False(t, consumererror.IsPermanent(combineExportErrors([]error{permanent, retryable})))
+	assert.Equal(t, exporterhelper.NewThrottleRetry(errors.New("[throttled; retryable]"), time.Second),
+		combineExportErrors([]error{throttled, retryable}))
+}
+
+// Test_PushMetricsErrors checks that metrics which cannot be converted are dropped with a permanent error, while the
+// whole batch is retryable if the endpoint is unavailable.
+func Test_PushMetricsErrors(t *testing.T) {
+	batch := testdataold.GenerateMetricDataManyMetricsSameResource(10)
+	setCumulative(&batch)
+	validBatch := pdatautil.MetricsFromOldInternalMetrics(batch)
+	invalidTypeBatch := pdatautil.MetricsFromOldInternalMetrics(testdataold.GenerateMetricDataMetricTypeInvalid())
+
+	tests := []struct {
+		name          string
+		md            pdata.Metrics
+		code          int
+		wantDropped   int
+		wantPermanent bool
+	}{
+		{"invalid_metrics_case", invalidTypeBatch, http.StatusAccepted, 1, true},
+		{"bad_request_case", validBatch, http.StatusBadRequest, 10, true},
+		{"unavailable_case", validBatch, http.StatusServiceUnavailable, 10, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.code)
+			}))
+			defer server.Close()
+
+			prwe, err := NewPrwExporter("", server.URL, http.DefaultClient)
+			require.NoError(t, err)
+			dropped, err := prwe.PushMetrics(context.Background(), tt.md)
+			require.Error(t, err)
+			assert.Equal(t, tt.wantDropped, dropped)
+			assert.Equal(t, tt.wantPermanent, consumererror.IsPermanent(err))
+		})
+	}
+}
+
+// Test_PushMetricsInternalMetrics checks that metrics of the new internal model are exported, and that metrics with an
+// invalid temporality are dropped.
+func Test_PushMetricsInternalMetrics(t *testing.T) {
+	cumulative := otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+	md := pdatautil.MetricsFromInternalMetrics(getInternalMetrics(
+		getIntGaugeMetric("gauge", getIntPoint(lbs1, intVal1, time1)),
+		getDoubleSumMetric("counter", cumulative, true, getDoublePoint(lbs1, floatVal1, time1),
+			getDoublePoint(lbs2, floatVal2, time1)),
+		getIntSumMetric("delta_counter", otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA, true,
+			getIntPoint(lbs1, intVal1, time1)),
+		getDoubleHistogramMetric("histogram", cumulative,
+			getDoubleHistogramPoint(lbs1, time1, floatVal1, uint64(intVal2), []float64{floatVal1},
+				[]uint64{uint64(intVal1), uint64(intVal1)})),
+	))
+
+	var names []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		dest, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		wr := &prompb.WriteRequest{}
+		require.NoError(t, proto.Unmarshal(dest, wr))
+		for _, ts := range wr.Timeseries {
+			for _, l := range ts.Labels {
+				if l.Name == nameStr {
+					names = append(names, l.Value)
+				}
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	prwe, err := NewPrwExporter("", server.URL, http.DefaultClient)
+	require.NoError(t, err)
+	dropped, err := prwe.PushMetrics(context.Background(), md)
+	assert.Equal(t, 1, dropped)
+	assert.True(t, consumererror.IsPermanent(err))
+	assert.ElementsMatch(t, []string{"gauge", "counter_total", "counter_total", "histogram_sum", "histogram_count",
+		"histogram_bucket", "histogram_bucket"}, names)
+}
+
+// Test_PushMetricsDeltaToCumulative checks that delta sums and histograms are exported as cumulative TimeSeries if
+// delta-to-cumulative conversion is enabled.
+func Test_PushMetricsDeltaToCumulative(t *testing.T) {
+	delta := otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA
+	getMetrics := func(ts uint64) pdata.Metrics {
+		return pdatautil.MetricsFromInternalMetrics(getInternalMetrics(
+			getIntSumMetric("counter", delta, true, getIntPoint(lbs1, intVal2, ts)),
+			getDoubleSumMetric("updown", delta, false, getDoublePoint(lbs1, -floatVal1, ts)),
+			getDoubleHistogramMetric("histogram", delta,
+				getDoubleHistogramPoint(lbs1, ts, floatVal2, uint64(intVal2), []float64{floatVal1},
+					[]uint64{uint64(intVal1), uint64(intVal1)})),
+		))
+	}
+
+	values := map[string]float64{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		dest, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		wr := &prompb.WriteRequest{}
+		require.NoError(t, proto.Unmarshal(dest, wr))
+		for _, ts := range wr.Timeseries {
+			name := ""
+			for _, l := range ts.Labels {
+				if l.Name == nameStr || l.Name == leStr {
+					name += l.Value
+				}
+			}
+			values[name] = ts.Samples[len(ts.Samples)-1].Value
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	prwe, err := NewPrwExporter("", server.URL, http.DefaultClient,
+		WithDeltaToCumulative(DeltaToCumulativeSettings{Enabled: true}))
+	require.NoError(t, err)
+	for i := uint64(1); i <= 3; i++ {
+		dropped, err := prwe.PushMetrics(context.Background(), getMetrics(time1+i))
+		require.NoError(t, err)
+		assert.Equal(t, 0, dropped)
+	}
+	assert.Equal(t, map[string]float64{
+		"counter_total":              6,
+		"updown":                     -3,
+		"histogram_sum":              6,
+		"histogram_count":            6,
+		"histogram_bucket1":          3,
+		"histogram_bucket" + pInfStr: 6,
+	}, values)
+
+	// delta sums and histograms are dropped unless converted
+	prwe, err = NewPrwExporter("", server.URL, http.DefaultClient)
+	require.NoError(t, err)
+	dropped, err := prwe.PushMetrics(context.Background(), getMetrics(time1))
+	assert.Equal(t, 2, dropped)
+	assert.True(t, consumererror.IsPermanent(err))
+}
+
+// roundTripperFunc returns a successful response to every request without sending it.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// pushOldModel exports md the way the exporter did before translating metrics from the new internal model: the
+// metrics are read from the old model without being converted, and the name of a metric is built for each of its data
+// points. It is the baseline of BenchmarkPushMetrics.
+func pushOldModel(ctx context.Context, prwe *PrwExporter, md pdata.Metrics) error {
+	tsMap := map[string]*prompb.TimeSeries{}
+	for _, rm := range dataold.MetricDataToOtlp(pdatautil.MetricsToOldInternalMetrics(md)) {
+		if rm == nil {
+			continue
+		}
+		resourceLabels := prwe.createResourceLabels(pdata.DeprecatedNewResource(&rm.Resource))
+		for _, ilm := range rm.InstrumentationLibraryMetrics {
+			if ilm == nil {
+				continue
+			}
+			for _, metric := range ilm.Metrics {
+				desc := metric.GetMetricDescriptor()
+				kind := desc.GetType().String()
+				switch desc.GetType() {
+				case otlp.MetricDescriptor_MONOTONIC_INT64, otlp.MetricDescriptor_MONOTONIC_DOUBLE,
+					otlp.MetricDescriptor_INT64, otlp.MetricDescriptor_DOUBLE:
+					typ := metricTypeGauge
+					if desc.GetType() == otlp.MetricDescriptor_MONOTONIC_INT64 ||
+						desc.GetType() == otlp.MetricDescriptor_MONOTONIC_DOUBLE {
+						typ = metricTypeCounter
+					}
+					for _, pt := range metric.Int64DataPoints {
+						name := buildPromMetricName(desc.GetName(), desc.GetUnit(), prwe.namespace, typ,
+							prwe.namingSettings.Mode)
+						labels := createLabelSet(resourceLabels, pdata.DeprecatedNewStringMap(&pt.Labels), nameStr, name)
+						addSample(tsMap, &prompb.Sample{Value: float64(pt.Value), Timestamp: convertTimeStamp(pt.TimeUnixNano)},
+							labels, kind)
+					}
+					for _, pt := range metric.DoubleDataPoints {
+						name := buildPromMetricName(desc.GetName(), desc.GetUnit(), prwe.namespace, typ,
+							prwe.namingSettings.Mode)
+						labels := createLabelSet(resourceLabels, pdata.DeprecatedNewStringMap(&pt.Labels), nameStr, name)
+						addSample(tsMap, &prompb.Sample{Value: pt.Value, Timestamp: convertTimeStamp(pt.TimeUnixNano)},
+							labels, kind)
+					}
+				case otlp.MetricDescriptor_HISTOGRAM:
+					for _, pt := range metric.HistogramDataPoints {
+						name := buildPromMetricName(desc.GetName(), desc.GetUnit(), prwe.namespace, metricTypeHistogram,
+							prwe.namingSettings.Mode)
+						counts := make([]uint64, 0, len(pt.Buckets))
+						for _, bucket := range pt.Buckets {
+							counts = append(counts, bucket.GetCount())
+						}
+						addHistogramSamples(tsMap, nil, pdata.DeprecatedNewStringMap(&pt.Labels),
+							pdata.TimestampUnixNano(pt.StartTimeUnixNano), pdata.TimestampUnixNano(pt.TimeUnixNano), pt.Sum,
+							pt.Count, counts, pt.ExplicitBounds, name, kind, resourceLabels)
+					}
+				case otlp.MetricDescriptor_SUMMARY:
+					if err := prwe.handleSummaryMetric(tsMap, metric, resourceLabels); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return prwe.export(ctx, tsMap, nil)
+}
+
+// BenchmarkPushMetrics compares exporting metrics of the old internal model, which are converted to the new one, and
+// of the new internal model with the baseline of reading metrics of the old model without converting them.
+func BenchmarkPushMetrics(b *testing.B) {
+	client := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+	})}
+	batch := testdataold.GenerateMetricDataManyMetricsSameResource(100)
+	setCumulative(&batch)
+	push := func(ctx context.Context, prwe *PrwExporter, md pdata.Metrics) error {
+		_, err := prwe.PushMetrics(ctx, md)
+		return err
+	}
+
+	benchmarks := []struct {
+		name string
+		md   pdata.Metrics
+		push func(context.Context, *PrwExporter, pdata.Metrics) error
+	}{
+		{"baseline", pdatautil.MetricsFromOldInternalMetrics(batch), pushOldModel},
+		{"old_model", pdatautil.MetricsFromOldInternalMetrics(batch), push},
+		{"internal_model", pdatautil.MetricsFromInternalMetrics(internaldata.OldMetricDataToMetricData(batch)), push},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			prwe, err := NewPrwExporter("", "http://localhost/api/prom/push", client)
+			require.NoError(b, err)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := bm.push(context.Background(), prwe, bm.md); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
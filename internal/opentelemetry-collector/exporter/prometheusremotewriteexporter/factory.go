@@ -18,6 +18,8 @@ import (
 	"context"
 	"errors"
 
+	"github.com/spf13/viper"
+
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/config/configmodels"
@@ -33,10 +35,29 @@ func NewFactory() component.ExporterFactory {
 	return exporterhelper.NewFactory(
 		typeStr,
 		createDefaultConfig,
-		exporterhelper.WithMetrics(createMetricsExporter))
+		exporterhelper.WithMetrics(createMetricsExporter),
+		exporterhelper.WithCustomUnmarshaler(customUnmarshaler))
 }
 
-func createMetricsExporter(_ context.Context, _ component.ExporterCreateParams,
+// customUnmarshaler parses write_relabel_configs with the YAML unmarshaling of Prometheus, after the other settings.
+func customUnmarshaler(componentViperSection *viper.Viper, intoCfg interface{}) error {
+	if componentViperSection == nil {
+		return nil
+	}
+	if err := componentViperSection.UnmarshalExact(intoCfg); err != nil {
+		return err
+	}
+	cfg := intoCfg.(*Config)
+	relabelConfigs, err := ParseWriteRelabelConfigs(cfg.WriteRelabelConfigsPlaceholder)
+	if err != nil {
+		return err
+	}
+	cfg.WriteRelabelConfigs = relabelConfigs
+	cfg.WriteRelabelConfigsPlaceholder = nil
+	return nil
+}
+
+func createMetricsExporter(_ context.Context, params component.ExporterCreateParams,
 	cfg configmodels.Exporter) (component.MetricsExporter, error) {
 
 	prwCfg, ok := cfg.(*Config)
@@ -44,13 +65,44 @@ func createMetricsExporter(_ context.Context, _ component.ExporterCreateParams,
 		return nil, errors.New("invalid configuration")
 	}
 
+	if err := prwCfg.Tenant.Validate(prwCfg.HTTPClientSettings.Headers); err != nil {
+		return nil, err
+	}
+	if err := prwCfg.Naming.Validate(); err != nil {
+		return nil, err
+	}
+	if err := prwCfg.Staleness.Validate(); err != nil {
+		return nil, err
+	}
+	if err := prwCfg.Duplicates.Validate(); err != nil {
+		return nil, err
+	}
+	if err := prwCfg.Protocol.Validate(prwCfg.HTTPClientSettings.Headers); err != nil {
+		return nil, err
+	}
+
 	client, err := prwCfg.HTTPClientSettings.ToClient()
 
 	if err != nil {
 		return nil, err
 	}
 
-	prwe, err := NewPrwExporter(prwCfg.Namespace, prwCfg.HTTPClientSettings.Endpoint, client)
+	prwe, err := NewPrwExporter(prwCfg.Namespace, prwCfg.HTTPClientSettings.Endpoint, client,
+		WithResourceAttributes(prwCfg.ResourceAttributes),
+		WithName(prwCfg.Name()),
+		WithType(string(prwCfg.Type())),
+		WithLogger(params.Logger),
+		WithWAL(prwCfg.WAL),
+		WithShards(prwCfg.Shards),
+		WithMetadata(prwCfg.Metadata),
+		WithDeltaToCumulative(prwCfg.DeltaToCumulative),
+		WithTenant(prwCfg.Tenant),
+		WithNaming(prwCfg.Naming),
+		WithStaleness(prwCfg.Staleness),
+		WithExemplars(prwCfg.Exemplars),
+		WithDuplicates(prwCfg.Duplicates),
+		WithProtocol(prwCfg.Protocol),
+		WithWriteRelabelConfigs(prwCfg.WriteRelabelConfigs))
 
 	if err != nil {
 		return nil, err
@@ -62,6 +114,7 @@ func createMetricsExporter(_ context.Context, _ component.ExporterCreateParams,
 		exporterhelper.WithTimeout(prwCfg.TimeoutSettings),
 		exporterhelper.WithQueue(prwCfg.QueueSettings),
 		exporterhelper.WithRetry(prwCfg.RetrySettings),
+		exporterhelper.WithStart(prwe.Start),
 		exporterhelper.WithShutdown(prwe.Shutdown),
 	)
 	return prwexp, err
@@ -77,7 +130,17 @@ func createDefaultConfig() configmodels.Exporter {
 			TypeVal: typeStr,
 			NameVal: typeStr,
 		},
-		Namespace: "",
+		Namespace:         "",
+		WAL:               CreateDefaultWALSettings(),
+		Shards:            CreateDefaultShardSettings(),
+		Metadata:          CreateDefaultMetadataSettings(),
+		DeltaToCumulative: CreateDefaultDeltaToCumulativeSettings(),
+		Tenant:            CreateDefaultTenantSettings(),
+		Naming:            CreateDefaultNamingSettings(),
+		Staleness:         CreateDefaultStalenessSettings(),
+		Exemplars:         CreateDefaultExemplarSettings(),
+		Duplicates:        CreateDefaultDuplicateSettings(),
+		Protocol:          CreateDefaultProtocolSettings(),
 
 		TimeoutSettings: exporterhelper.CreateDefaultTimeoutSettings(),
 		RetrySettings:   exporterhelper.CreateDefaultRetrySettings(),
@@ -49,6 +49,21 @@ func Test_createMetricsExporter(t *testing.T) {
 		Insecure:   false,
 		ServerName: "",
 	}
+	tenantHeaderConfig := createDefaultConfig().(*Config)
+	tenantHeaderConfig.Tenant.Enabled = true
+	tenantHeaderConfig.HTTPClientSettings.Headers = map[string]string{"x-scope-orgid": "234"}
+	unknownNamingConfig := createDefaultConfig().(*Config)
+	unknownNamingConfig.Naming.Mode = "camel"
+	stalenessIntervalConfig := createDefaultConfig().(*Config)
+	stalenessIntervalConfig.Staleness.Enabled = true
+	stalenessIntervalConfig.Staleness.Interval = 0
+	unknownDuplicatePolicyConfig := createDefaultConfig().(*Config)
+	unknownDuplicatePolicyConfig.Duplicates.Policy = "random"
+	unknownCompressionConfig := createDefaultConfig().(*Config)
+	unknownCompressionConfig.Protocol.Compression = "lz4"
+	protocolHeaderConfig := createDefaultConfig().(*Config)
+	protocolHeaderConfig.Protocol.Version = ProtocolVersion2
+	protocolHeaderConfig.HTTPClientSettings.Headers = map[string]string{"x-prometheus-remote-write-version": "0.1.0"}
 	tests := []struct {
 		name        string
 		cfg         configmodels.Exporter
@@ -75,6 +90,36 @@ func Test_createMetricsExporter(t *testing.T) {
 			component.ExporterCreateParams{},
 			true,
 		},
+		{"tenant_header_conflict_case",
+			tenantHeaderConfig,
+			component.ExporterCreateParams{},
+			true,
+		},
+		{"unknown_naming_mode_case",
+			unknownNamingConfig,
+			component.ExporterCreateParams{},
+			true,
+		},
+		{"staleness_interval_case",
+			stalenessIntervalConfig,
+			component.ExporterCreateParams{},
+			true,
+		},
+		{"unknown_duplicate_policy_case",
+			unknownDuplicatePolicyConfig,
+			component.ExporterCreateParams{},
+			true,
+		},
+		{"unknown_compression_case",
+			unknownCompressionConfig,
+			component.ExporterCreateParams{},
+			true,
+		},
+		{"protocol_header_conflict_case",
+			protocolHeaderConfig,
+			component.ExporterCreateParams{},
+			true,
+		},
 	}
 	// run tests
 	for _, tt := range tests {
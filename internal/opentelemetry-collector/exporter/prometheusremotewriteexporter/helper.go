@@ -24,8 +24,9 @@ import (
 
 	"github.com/prometheus/prometheus/prompb"
 
-	common "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/common/v1"
-	otlp "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1old"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/translator/conventions"
+	tracetranslator "go.opentelemetry.io/collector/translator/trace"
 )
 
 const (
@@ -36,9 +37,13 @@ const (
 	leStr       = "le"
 	quantileStr = "quantile"
 	pInfStr     = "+Inf"
+	// summaryStr is the kind of summary TimeSeries in their signature, as summaries have no pdata.MetricDataType
+	summaryStr  = "Summary"
 	totalStr    = "total"
 	delimeter   = "_"
 	keyStr      = "key"
+	jobStr      = "job"
+	instanceStr = "instance"
 )
 
 // ByLabelName enables the usage of sort.Sort() with a slice of labels
@@ -48,34 +53,56 @@ func (a ByLabelName) Len() int           { return len(a) }
 func (a ByLabelName) Less(i, j int) bool { return a[i].Name < a[j].Name }
 func (a ByLabelName) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 
-// validateMetrics returns a bool representing whether the metric has a valid type and temporality combination.
-func validateMetrics(desc *otlp.MetricDescriptor) bool {
-
-	if desc == nil {
+// validateMetrics returns a bool representing whether the metric has a valid type and temporality combination. Gauges
+// and non-monotonic sums are exported as gauges whatever their temporality, while monotonic sums and histograms must be
+// cumulative.
+func validateMetrics(metric pdata.Metric) bool {
+	if metric.IsNil() {
 		return false
 	}
 
-	switch desc.GetType() {
-	case otlp.MetricDescriptor_MONOTONIC_DOUBLE, otlp.MetricDescriptor_MONOTONIC_INT64,
-		otlp.MetricDescriptor_HISTOGRAM, otlp.MetricDescriptor_SUMMARY:
-		return desc.GetTemporality() == otlp.MetricDescriptor_CUMULATIVE
-	case otlp.MetricDescriptor_INT64, otlp.MetricDescriptor_DOUBLE:
+	switch metric.DataType() {
+	case pdata.MetricDataIntGauge, pdata.MetricDataDoubleGauge:
 		return true
+	case pdata.MetricDataIntSum:
+		sum := metric.IntSumData()
+		return !sum.IsNil() && (!sum.IsMonotonic() || sum.AggregationTemporality() == pdata.AggregationTemporalityCumulative)
+	case pdata.MetricDataDoubleSum:
+		sum := metric.DoubleSumData()
+		return !sum.IsNil() && (!sum.IsMonotonic() || sum.AggregationTemporality() == pdata.AggregationTemporalityCumulative)
+	case pdata.MetricDataIntHistogram:
+		histogram := metric.IntHistogramData()
+		return !histogram.IsNil() && histogram.AggregationTemporality() == pdata.AggregationTemporalityCumulative
+	case pdata.MetricDataDoubleHistogram:
+		histogram := metric.DoubleHistogramData()
+		return !histogram.IsNil() && histogram.AggregationTemporality() == pdata.AggregationTemporalityCumulative
 	}
 
 	return false
 }
 
+// isCounter returns whether metric is a monotonic sum, which is exported as a Prometheus counter.
+func isCounter(metric pdata.Metric) bool {
+	switch metric.DataType() {
+	case pdata.MetricDataIntSum:
+		sum := metric.IntSumData()
+		return !sum.IsNil() && sum.IsMonotonic()
+	case pdata.MetricDataDoubleSum:
+		sum := metric.DoubleSumData()
+		return !sum.IsNil() && sum.IsMonotonic()
+	}
+	return false
+}
+
 // addSample finds a TimeSeries in tsMap that corresponds to the label set labels, and add sample to the TimeSeries; it
 // creates a new TimeSeries in the map if not found. tsMap is unmodified if either of its parameters is nil.
-func addSample(tsMap map[string]*prompb.TimeSeries, sample *prompb.Sample, labels []prompb.Label,
-	ty otlp.MetricDescriptor_Type) {
+func addSample(tsMap map[string]*prompb.TimeSeries, sample *prompb.Sample, labels []prompb.Label, kind string) {
 
 	if sample == nil || labels == nil || tsMap == nil {
 		return
 	}
 
-	sig := timeSeriesSignature(ty, &labels)
+	sig := timeSeriesSignature(kind, &labels)
 	ts, ok := tsMap[sig]
 
 	if ok {
@@ -90,12 +117,14 @@ func addSample(tsMap map[string]*prompb.TimeSeries, sample *prompb.Sample, label
 }
 
 // timeSeries return a string signature in the form of:
-// 		TYPE-label1-value1- ...  -labelN-valueN
-// the label slice should not contain duplicate label names; this method sorts the slice by label name before creating
-// the signature.
-func timeSeriesSignature(t otlp.MetricDescriptor_Type, labels *[]prompb.Label) string {
+//
+//	TYPE-label1-value1- ...  -labelN-valueN
+//
+// where TYPE is the kind of the metric, such as the name of its pdata.MetricDataType. The label slice should not contain
+// duplicate label names; this method sorts the slice by label name before creating the signature.
+func timeSeriesSignature(kind string, labels *[]prompb.Label) string {
 	b := strings.Builder{}
-	b.WriteString(t.String())
+	b.WriteString(kind)
 
 	sort.Sort(ByLabelName(*labels))
 
@@ -109,20 +138,20 @@ func timeSeriesSignature(t otlp.MetricDescriptor_Type, labels *[]prompb.Label) s
 	return b.String()
 }
 
-// createLabelSet creates a slice of Cortex Label with OTLP labels and paris of string values.
+// createLabelSet creates a slice of Cortex Label with resource labels, OTLP labels and paris of string values.
 // Unpaired string value is ignored. String pairs overwrites OTLP labels if collision happens, and the overwrite is
-// logged. Resultant label names are sanitized.
-func createLabelSet(labels []*common.StringKeyValue, extras ...string) []prompb.Label {
+// logged. OTLP labels overwrite resource labels of the same name. Resultant label names are sanitized.
+func createLabelSet(resourceLabels []prompb.Label, labels pdata.StringMap, extras ...string) []prompb.Label {
 
 	// map ensures no duplicate label name
-	l := map[string]prompb.Label{}
+	l := make(map[string]prompb.Label, labels.Len()+len(extras)/2)
 
-	for _, lb := range labels {
-		l[lb.Key] = prompb.Label{
-			Name:  sanitize(lb.Key),
-			Value: lb.Value,
+	labels.ForEach(func(key string, value pdata.StringValue) {
+		l[key] = prompb.Label{
+			Name:  sanitize(key),
+			Value: value.Value(),
 		}
-	}
+	})
 
 	for i := 0; i < len(extras); i += 2 {
 		if i+1 >= len(extras) {
@@ -143,25 +172,117 @@ func createLabelSet(labels []*common.StringKeyValue, extras ...string) []prompb.
 		}
 	}
 
-	s := make([]prompb.Label, 0, len(l))
+	s := make([]prompb.Label, 0, len(l)+len(resourceLabels))
+	names := make(map[string]struct{}, len(l))
 
 	for _, lb := range l {
 		s = append(s, lb)
+		names[lb.Name] = struct{}{}
+	}
+
+	// resource labels have the lowest precedence
+	for _, lb := range resourceLabels {
+		if _, found := names[lb.Name]; found {
+			continue
+		}
+		s = append(s, lb)
+		names[lb.Name] = struct{}{}
 	}
 
 	return s
 }
 
-// getPromMetricName creates a Prometheus metric name by attaching namespace prefix, and _total suffix for Monotonic
-// metrics.
-func getPromMetricName(desc *otlp.MetricDescriptor, ns string) string {
+// createResourceLabels converts the attributes of resource to a slice of Cortex Label according to settings. Included
+// attributes are renamed, then sanitized, and job and instance labels are derived from the service attributes if
+// enabled. Attributes with empty values are skipped.
+func createResourceLabels(resource pdata.Resource, settings ResourceAttributesSettings) []prompb.Label {
+	if resource.IsNil() {
+		return nil
+	}
+	attrs := resource.Attributes()
+	l := map[string]string{}
+
+	addAttribute := func(key string, value pdata.AttributeValue) {
+		v := tracetranslator.AttributeValueToString(value, false)
+		if v == "" {
+			return
+		}
+		name, ok := settings.Rename[key]
+		if !ok {
+			name = key
+		}
+		l[sanitize(name)] = v
+	}
 
-	if desc == nil {
+	if settings.IncludeAll {
+		attrs.ForEach(addAttribute)
+	} else {
+		for _, key := range settings.Include {
+			if value, ok := attrs.Get(key); ok {
+				addAttribute(key, value)
+			}
+		}
+	}
+
+	if settings.JobInstance {
+		if job := getJob(attrs); job != "" {
+			l[jobStr] = job
+		}
+		if instance, ok := attrs.Get(conventions.AttributeServiceInstance); ok {
+			if v := tracetranslator.AttributeValueToString(instance, false); v != "" {
+				l[instanceStr] = v
+			}
+		}
+	}
+
+	s := make([]prompb.Label, 0, len(l))
+	for name, value := range l {
+		s = append(s, prompb.Label{
+			Name:  name,
+			Value: value,
+		})
+	}
+	return s
+}
+
+// getJob returns the value of the job label derived from the service.namespace and service.name attributes, in the
+// form of namespace/name, or only name if the namespace is absent.
+func getJob(attrs pdata.AttributeMap) string {
+	name, ok := attrs.Get(conventions.AttributeServiceName)
+	if !ok {
+		return ""
+	}
+	job := tracetranslator.AttributeValueToString(name, false)
+	if job == "" {
 		return ""
 	}
-	// whether _total suffix should be applied
-	isCounter := desc.Type == otlp.MetricDescriptor_MONOTONIC_INT64 ||
-		desc.Type == otlp.MetricDescriptor_MONOTONIC_DOUBLE
+	if namespace, ok := attrs.Get(conventions.AttributeServiceNamespace); ok {
+		if ns := tracetranslator.AttributeValueToString(namespace, false); ns != "" {
+			job = ns + "/" + job
+		}
+	}
+	return job
+}
+
+// getPromMetricName creates a Prometheus metric name from metric according to the naming mode, attaching the namespace
+// prefix.
+func getPromMetricName(metric pdata.Metric, ns string, mode string) string {
+	if metric.IsNil() {
+		return ""
+	}
+	return buildPromMetricName(metric.Name(), metric.Unit(), ns, getMetricType(metric), mode)
+}
+
+// buildPromMetricName creates a Prometheus metric name from the name and unit of a metric family of type typ according
+// to the naming mode, attaching the namespace prefix. In the default sanitize mode, the unit is ignored and the _total
+// suffix is attached to counters.
+func buildPromMetricName(name, unit, ns string, typ metricType, mode string) string {
+	switch mode {
+	case NamingModeRaw:
+		return rawMetricName(name, ns)
+	case NamingModeNormalize:
+		return normalizeMetricName(name, unit, ns, typ)
+	}
 
 	b := strings.Builder{}
 
@@ -170,18 +291,9 @@ func getPromMetricName(desc *otlp.MetricDescriptor, ns string) string {
 	if b.Len() > 0 {
 		b.WriteString(delimeter)
 	}
-	b.WriteString(desc.GetName())
-
-	// Including units makes two metrics with the same name and label set belong to two different TimeSeries if the
-	// units are different.
-	/*
-		if b.Len() > 0 && len(desc.GetUnit()) > 0{
-			fmt.Fprintf(&b, delimeter)
-			fmt.Fprintf(&b, desc.GetUnit())
-		}
-	*/
+	b.WriteString(name)
 
-	if b.Len() > 0 && isCounter {
+	if b.Len() > 0 && typ == metricTypeCounter {
 		b.WriteString(delimeter)
 		b.WriteString(totalStr)
 	}
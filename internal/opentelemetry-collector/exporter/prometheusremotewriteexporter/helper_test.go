@@ -15,55 +15,50 @@
 package prometheusremotewriteexporter
 
 import (
-	"strconv"
 	"testing"
 
 	"github.com/prometheus/prometheus/prompb"
 	"github.com/stretchr/testify/assert"
 
+	"go.opentelemetry.io/collector/consumer/pdata"
 	common "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/common/v1"
-	otlp "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1old"
+	otlpmetrics "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1"
+	"go.opentelemetry.io/collector/translator/conventions"
 )
 
 // Test_validateMetrics checks validateMetrics return true if a type and temporality combination is valid, false
 // otherwise.
 func Test_validateMetrics(t *testing.T) {
-	// define a single test
-	type combTest struct {
-		name string
-		desc *otlp.MetricDescriptor
-		want bool
-	}
+	unspecified := otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED
+	delta := otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA
+	cumulative := otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
 
-	tests := []combTest{}
-
-	// append true cases
-	for i := range validCombinations {
-		name := "valid_" + strconv.Itoa(i)
-		desc := getDescriptor(name, i, validCombinations)
-		tests = append(tests, combTest{
-			name,
-			desc,
-			true,
-		})
-	}
-	// append false cases
-	for i := range invalidCombinations {
-		name := "invalid_" + strconv.Itoa(i)
-		desc := getDescriptor(name, i, invalidCombinations)
-		tests = append(tests, combTest{
-			name,
-			desc,
-			false,
-		})
+	tests := []struct {
+		name   string
+		metric pdata.Metric
+		want   bool
+	}{
+		{"valid_int_gauge", getMetric(getIntGaugeMetric("m")), true},
+		{"valid_double_gauge", getMetric(getDoubleGaugeMetric("m")), true},
+		{"valid_monotonic_int_sum", getMetric(getIntSumMetric("m", cumulative, true)), true},
+		{"valid_monotonic_double_sum", getMetric(getDoubleSumMetric("m", cumulative, true)), true},
+		{"valid_delta_int_sum", getMetric(getIntSumMetric("m", delta, false)), true},
+		{"valid_unspecified_double_sum", getMetric(getDoubleSumMetric("m", unspecified, false)), true},
+		{"valid_int_histogram", getMetric(getIntHistogramMetric("m", cumulative)), true},
+		{"valid_double_histogram", getMetric(getDoubleHistogramMetric("m", cumulative)), true},
+		{"invalid_delta_monotonic_int_sum", getMetric(getIntSumMetric("m", delta, true)), false},
+		{"invalid_unspecified_monotonic_double_sum", getMetric(getDoubleSumMetric("m", unspecified, true)), false},
+		{"invalid_delta_int_histogram", getMetric(getIntHistogramMetric("m", delta)), false},
+		{"invalid_delta_double_histogram", getMetric(getDoubleHistogramMetric("m", delta)), false},
+		{"invalid_nil_sum", getMetric(&otlpmetrics.Metric{Data: &otlpmetrics.Metric_IntSum{}}), false},
+		{"invalid_no_data", getMetric(&otlpmetrics.Metric{}), false},
+		{"invalid_nil", pdata.NewMetric(), false},
 	}
-	// append nil case
-	tests = append(tests, combTest{"invalid_nil", nil, false})
 
 	// run tests
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := validateMetrics(tt.desc)
+			got := validateMetrics(tt.metric)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -75,7 +70,7 @@ func Test_validateMetrics(t *testing.T) {
 // case.
 func Test_addSample(t *testing.T) {
 	type testCase struct {
-		desc   otlp.MetricDescriptor_Type
+		kind   string
 		sample prompb.Sample
 		labels []prompb.Label
 	}
@@ -90,12 +85,12 @@ func Test_addSample(t *testing.T) {
 			"two_points_same_ts_same_metric",
 			map[string]*prompb.TimeSeries{},
 			[]testCase{
-				{otlp.MetricDescriptor_INT64,
+				{typeIntGauge,
 					getSample(float64(intVal1), msTime1),
 					promLbs1,
 				},
 				{
-					otlp.MetricDescriptor_INT64,
+					typeIntGauge,
 					getSample(float64(intVal2), msTime2),
 					promLbs1,
 				},
@@ -106,11 +101,11 @@ func Test_addSample(t *testing.T) {
 			"two_points_different_ts_same_metric",
 			map[string]*prompb.TimeSeries{},
 			[]testCase{
-				{otlp.MetricDescriptor_INT64,
+				{typeIntGauge,
 					getSample(float64(intVal1), msTime1),
 					promLbs1,
 				},
-				{otlp.MetricDescriptor_INT64,
+				{typeIntGauge,
 					getSample(float64(intVal1), msTime2),
 					promLbs2,
 				},
@@ -120,14 +115,14 @@ func Test_addSample(t *testing.T) {
 	}
 	t.Run("nil_case", func(t *testing.T) {
 		tsMap := map[string]*prompb.TimeSeries{}
-		addSample(tsMap, nil, nil, 0)
+		addSample(tsMap, nil, nil, "")
 		assert.Exactly(t, tsMap, map[string]*prompb.TimeSeries{})
 	})
 	// run tests
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			addSample(tt.orig, &tt.testCase[0].sample, tt.testCase[0].labels, tt.testCase[0].desc)
-			addSample(tt.orig, &tt.testCase[1].sample, tt.testCase[1].labels, tt.testCase[1].desc)
+			addSample(tt.orig, &tt.testCase[0].sample, tt.testCase[0].labels, tt.testCase[0].kind)
+			addSample(tt.orig, &tt.testCase[1].sample, tt.testCase[1].labels, tt.testCase[1].kind)
 			assert.Exactly(t, tt.want, tt.orig)
 		})
 	}
@@ -139,40 +134,39 @@ func Test_timeSeriesSignature(t *testing.T) {
 	tests := []struct {
 		name string
 		lbs  []prompb.Label
-		desc otlp.MetricDescriptor_Type
+		kind string
 		want string
 	}{
 		{
 			"int64_signature",
 			promLbs1,
-			otlp.MetricDescriptor_INT64,
-			typeInt64 + lb1Sig,
+			typeIntGauge,
+			typeIntGauge + lb1Sig,
 		},
 		{
 			"histogram_signature",
 			promLbs2,
-			otlp.MetricDescriptor_HISTOGRAM,
-			typeHistogram + lb2Sig,
+			typeDoubleHistogram,
+			typeDoubleHistogram + lb2Sig,
 		},
 		{
 			"unordered_signature",
 			getPromLabels(label22, value22, label21, value21),
-			otlp.MetricDescriptor_HISTOGRAM,
-			typeHistogram + lb2Sig,
+			typeDoubleHistogram,
+			typeDoubleHistogram + lb2Sig,
 		},
-		// descriptor type cannot be nil, as checked by validateMetrics
 		{
 			"nil_case",
 			nil,
-			otlp.MetricDescriptor_HISTOGRAM,
-			typeHistogram,
+			typeDoubleHistogram,
+			typeDoubleHistogram,
 		},
 	}
 
 	// run tests
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.EqualValues(t, tt.want, timeSeriesSignature(tt.desc, &tt.lbs))
+			assert.EqualValues(t, tt.want, timeSeriesSignature(tt.kind, &tt.lbs))
 		})
 	}
 }
@@ -181,25 +175,29 @@ func Test_timeSeriesSignature(t *testing.T) {
 // collision happens. It does not check whether labels are not sorted
 func Test_createLabelSet(t *testing.T) {
 	tests := []struct {
-		name   string
-		orig   []*common.StringKeyValue
-		extras []string
-		want   []prompb.Label
+		name     string
+		resource []prompb.Label
+		orig     []*common.StringKeyValue
+		extras   []string
+		want     []prompb.Label
 	}{
 		{
 			"labels_clean",
+			nil,
 			lbs1,
 			[]string{label31, value31, label32, value32},
 			getPromLabels(label11, value11, label12, value12, label31, value31, label32, value32),
 		},
 		{
 			"labels_duplicate_in_extras",
+			nil,
 			lbs1,
 			[]string{label11, value31},
 			getPromLabels(label11, value31, label12, value12),
 		},
 		{
 			"labels_dirty",
+			nil,
 			lbs1Dirty,
 			[]string{label31 + dirty1, value31, label32, value32},
 			getPromLabels(label11+"_", value11, "key_"+label12, value12, label31+"_", value31, label32, value32),
@@ -207,26 +205,127 @@ func Test_createLabelSet(t *testing.T) {
 		{
 			"no_original_case",
 			nil,
+			nil,
 			[]string{label31, value31, label32, value32},
 			getPromLabels(label31, value31, label32, value32),
 		},
 		{
 			"empty_extra_case",
+			nil,
 			lbs1,
 			[]string{"", ""},
 			getPromLabels(label11, value11, label12, value12, "", ""),
 		},
 		{
 			"single_left_over_case",
+			nil,
 			lbs1,
 			[]string{label31, value31, label32},
 			getPromLabels(label11, value11, label12, value12, label31, value31),
 		},
+		{
+			"resource_labels_case",
+			getPromLabels(label21, value21, label22, value22),
+			lbs1,
+			[]string{label31, value31},
+			getPromLabels(label11, value11, label12, value12, label31, value31, label21, value21, label22, value22),
+		},
+		{
+			"resource_labels_overwritten_case",
+			getPromLabels(label11, value21, label31, value21),
+			lbs1,
+			[]string{label31, value31},
+			getPromLabels(label11, value11, label12, value12, label31, value31),
+		},
+	}
+	// run tests
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tt.want, createLabelSet(tt.resource, getLabelsMap(tt.orig), tt.extras...))
+		})
+	}
+}
+
+// Test_createResourceLabels checks resource attributes are included, renamed and sanitized according to the settings,
+// and that job and instance labels are derived from the service attributes.
+func Test_createResourceLabels(t *testing.T) {
+	resource := pdata.NewResource()
+	resource.InitEmpty()
+	resource.Attributes().InitFromMap(map[string]pdata.AttributeValue{
+		conventions.AttributeServiceName:      pdata.NewAttributeValueString("checkout"),
+		conventions.AttributeServiceNamespace: pdata.NewAttributeValueString("shop"),
+		conventions.AttributeServiceInstance:  pdata.NewAttributeValueString("10.0.0.1:8080"),
+		conventions.AttributeHostName:         pdata.NewAttributeValueString("host-1"),
+		conventions.AttributeK8sPod:           pdata.NewAttributeValueString("checkout-5d8f"),
+		"replicas":                            pdata.NewAttributeValueInt(3),
+		"empty":                               pdata.NewAttributeValueString(""),
+	})
+
+	tests := []struct {
+		name     string
+		resource pdata.Resource
+		settings ResourceAttributesSettings
+		want     []prompb.Label
+	}{
+		{
+			"nil_resource",
+			pdata.NewResource(),
+			ResourceAttributesSettings{IncludeAll: true, JobInstance: true},
+			nil,
+		},
+		{
+			"no_settings",
+			resource,
+			ResourceAttributesSettings{},
+			[]prompb.Label{},
+		},
+		{
+			"include",
+			resource,
+			ResourceAttributesSettings{Include: []string{conventions.AttributeHostName, "replicas", "empty", "missing"}},
+			getPromLabels("host_name", "host-1", "replicas", "3"),
+		},
+		{
+			"rename",
+			resource,
+			ResourceAttributesSettings{
+				Include: []string{conventions.AttributeK8sPod},
+				Rename:  map[string]string{conventions.AttributeK8sPod: "pod"},
+			},
+			getPromLabels("pod", "checkout-5d8f"),
+		},
+		{
+			"rename_sanitized",
+			resource,
+			ResourceAttributesSettings{
+				Include: []string{conventions.AttributeK8sPod},
+				Rename:  map[string]string{conventions.AttributeK8sPod: "k8s.pod"},
+			},
+			getPromLabels("k8s_pod", "checkout-5d8f"),
+		},
+		{
+			"job_instance",
+			resource,
+			ResourceAttributesSettings{JobInstance: true},
+			getPromLabels(jobStr, "shop/checkout", instanceStr, "10.0.0.1:8080"),
+		},
+		{
+			"include_all",
+			resource,
+			ResourceAttributesSettings{IncludeAll: true, Include: []string{conventions.AttributeHostName}},
+			getPromLabels("service_name", "checkout", "service_namespace", "shop", "service_instance_id", "10.0.0.1:8080",
+				"host_name", "host-1", "k8s_pod_name", "checkout-5d8f", "replicas", "3"),
+		},
 	}
 	// run tests
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.ElementsMatch(t, tt.want, createLabelSet(tt.orig, tt.extras...))
+			got := createResourceLabels(tt.resource, tt.settings)
+			if tt.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+			assert.ElementsMatch(t, tt.want, got)
 		})
 	}
 }
@@ -235,39 +334,46 @@ func Test_createLabelSet(t *testing.T) {
 // Test cases are empty namespace, monotonic metrics that require a total suffix, and metric names that contains
 // invalid characters.
 func Test_getPromMetricName(t *testing.T) {
+	cumulative := otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
 	tests := []struct {
-		name string
-		desc *otlp.MetricDescriptor
-		ns   string
-		want string
+		name   string
+		metric pdata.Metric
+		ns     string
+		want   string
 	}{
 		{
 			"nil_case",
-			nil,
+			pdata.NewMetric(),
 			ns1,
 			"",
 		},
 		{
 			"normal_case",
-			getDescriptor(name1, histogramComb, validCombinations),
+			getMetric(getDoubleHistogramMetric(name1, cumulative)),
 			ns1,
 			"test_ns_valid_single_int_point",
 		},
 		{
 			"empty_namespace",
-			getDescriptor(name1, summaryComb, validCombinations),
+			getMetric(getIntGaugeMetric(name1)),
 			"",
 			"valid_single_int_point",
 		},
 		{
 			"total_suffix",
-			getDescriptor(name1, monotonicInt64Comb, validCombinations),
+			getMetric(getIntSumMetric(name1, cumulative, true)),
 			ns1,
 			"test_ns_valid_single_int_point_total",
 		},
+		{
+			"non_monotonic_sum",
+			getMetric(getDoubleSumMetric(name1, cumulative, false)),
+			ns1,
+			"test_ns_valid_single_int_point",
+		},
 		{
 			"dirty_string",
-			getDescriptor(name1+dirty1, monotonicInt64Comb, validCombinations),
+			getMetric(getDoubleSumMetric(name1+dirty1, cumulative, true)),
 			"7" + ns1,
 			"key_7test_ns_valid_single_int_point__total",
 		},
@@ -275,7 +381,7 @@ func Test_getPromMetricName(t *testing.T) {
 	// run tests
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.want, getPromMetricName(tt.desc, tt.ns))
+			assert.Equal(t, tt.want, getPromMetricName(tt.metric, tt.ns, NamingModeSanitize))
 		})
 	}
 
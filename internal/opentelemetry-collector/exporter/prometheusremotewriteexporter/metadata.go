@@ -0,0 +1,267 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/prometheus/prometheus/prompb"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+	otlp "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1old"
+)
+
+// metricType is the type of a metric family in the Prometheus remote write protocol.
+type metricType int32
+
+// Values of the MetricMetadata.MetricType enum of the Prometheus remote write protocol.
+const (
+	metricTypeUnknown   metricType = 0
+	metricTypeCounter   metricType = 1
+	metricTypeGauge     metricType = 2
+	metricTypeHistogram metricType = 3
+	metricTypeSummary   metricType = 5
+)
+
+// Field numbers of the Prometheus remote write protocol for metric metadata.
+const (
+	writeRequestMetadataField = 3
+	metadataTypeField         = 1
+	metadataFamilyNameField   = 2
+	metadataHelpField         = 4
+	metadataUnitField         = 5
+)
+
+// metricMetadata is the metadata of a metric family sent with remote write. The vendored prompb package predates
+// metadata support in the remote write protocol, so metadata is encoded by marshalWriteRequest instead.
+type metricMetadata struct {
+	Type             metricType
+	MetricFamilyName string
+	Help             string
+	Unit             string
+}
+
+// getMetricMetadata returns the metadata of the metric family of metric, named according to the naming mode.
+func getMetricMetadata(metric pdata.Metric, ns string, mode string) *metricMetadata {
+	return &metricMetadata{
+		Type:             getMetricType(metric),
+		MetricFamilyName: getPromMetricName(metric, ns, mode),
+		Help:             metric.Description(),
+		Unit:             metric.Unit(),
+	}
+}
+
+// getSummaryMetadata returns the metadata of the metric family of the summary described by desc, named according to
+// the naming mode.
+func getSummaryMetadata(desc *otlp.MetricDescriptor, ns string, mode string) *metricMetadata {
+	return &metricMetadata{
+		Type:             metricTypeSummary,
+		MetricFamilyName: buildPromMetricName(desc.GetName(), desc.GetUnit(), ns, metricTypeSummary, mode),
+		Help:             desc.GetDescription(),
+		Unit:             desc.GetUnit(),
+	}
+}
+
+// getMetricType maps the type of an OTLP metric to the type of a Prometheus metric family.
+func getMetricType(metric pdata.Metric) metricType {
+	switch metric.DataType() {
+	case pdata.MetricDataIntSum, pdata.MetricDataDoubleSum:
+		if isCounter(metric) {
+			return metricTypeCounter
+		}
+		return metricTypeGauge
+	case pdata.MetricDataIntGauge, pdata.MetricDataDoubleGauge:
+		return metricTypeGauge
+	case pdata.MetricDataIntHistogram, pdata.MetricDataDoubleHistogram:
+		return metricTypeHistogram
+	}
+	return metricTypeUnknown
+}
+
+// marshalWriteRequest marshals req followed by metadata as the metadata field of the WriteRequest.
+func marshalWriteRequest(req *prompb.WriteRequest, metadata []*metricMetadata) ([]byte, error) {
+	data, err := proto.Marshal(req)
+	if err != nil || len(metadata) == 0 {
+		return data, err
+	}
+
+	buf := proto.NewBuffer(data)
+	for _, md := range metadata {
+		entry := proto.NewBuffer(nil)
+		if md.Type != metricTypeUnknown {
+			if err = entry.EncodeVarint(metadataTypeField<<3 | proto.WireVarint); err != nil {
+				return nil, err
+			}
+			if err = entry.EncodeVarint(uint64(md.Type)); err != nil {
+				return nil, err
+			}
+		}
+		for _, field := range []struct {
+			number int
+			value  string
+		}{
+			{metadataFamilyNameField, md.MetricFamilyName},
+			{metadataHelpField, md.Help},
+			{metadataUnitField, md.Unit},
+		} {
+			if field.value == "" {
+				continue
+			}
+			if err = entry.EncodeVarint(uint64(field.number<<3 | proto.WireBytes)); err != nil {
+				return nil, err
+			}
+			if err = entry.EncodeStringBytes(field.value); err != nil {
+				return nil, err
+			}
+		}
+
+		if err = buf.EncodeVarint(writeRequestMetadataField<<3 | proto.WireBytes); err != nil {
+			return nil, err
+		}
+		if err = buf.EncodeRawBytes(entry.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalMetadata decodes the metadata encoded into a WriteRequest by marshalWriteRequest.
+func unmarshalMetadata(data []byte) ([]*metricMetadata, error) {
+	fields, err := unmarshalFields(data)
+	if err != nil {
+		return nil, err
+	}
+	var metadata []*metricMetadata
+	for _, field := range fields {
+		if field.number != writeRequestMetadataField {
+			continue
+		}
+		entryFields, err := unmarshalFields(field.bytes)
+		if err != nil {
+			return nil, err
+		}
+		md := &metricMetadata{}
+		for _, ef := range entryFields {
+			switch ef.number {
+			case metadataTypeField:
+				md.Type = metricType(ef.value)
+			case metadataFamilyNameField:
+				md.MetricFamilyName = string(ef.bytes)
+			case metadataHelpField:
+				md.Help = string(ef.bytes)
+			case metadataUnitField:
+				md.Unit = string(ef.bytes)
+			}
+		}
+		metadata = append(metadata, md)
+	}
+	return metadata, nil
+}
+
+// sortMetadata returns the values of metadata sorted by metric family name.
+func sortMetadata(metadata map[string]*metricMetadata) []*metricMetadata {
+	sorted := make([]*metricMetadata, 0, len(metadata))
+	for _, md := range metadata {
+		sorted = append(sorted, md)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].MetricFamilyName < sorted[j].MetricFamilyName })
+	return sorted
+}
+
+// metadataCache keeps the latest metadata of every metric family exported to each tenant, to be sent on an interval.
+// The metadata of metric families that are no longer exported is evicted after the maximum staleness, and the metadata
+// of new metric families is not kept once the maximum number of metric families is reached.
+type metadataCache struct {
+	settings MetadataSettings
+	// now returns the current time, and is replaced in tests
+	now func() time.Time
+
+	mu       sync.Mutex
+	tenants  map[string]map[string]*cachedMetadata
+	families int
+}
+
+// cachedMetadata is the metadata of a metric family, with the time it was last exported.
+type cachedMetadata struct {
+	metadata *metricMetadata
+	updated  time.Time
+}
+
+func newMetadataCache(settings MetadataSettings) *metadataCache {
+	return &metadataCache{
+		settings: settings,
+		now:      time.Now,
+		tenants:  map[string]map[string]*cachedMetadata{},
+	}
+}
+
+// update stores the metadata exported to tenant, replacing the previous metadata of the same metric families.
+func (c *metadataCache) update(tenant string, metadata []*metricMetadata) {
+	if len(metadata) == 0 {
+		return
+	}
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	families := c.tenants[tenant]
+	if families == nil {
+		families = map[string]*cachedMetadata{}
+		c.tenants[tenant] = families
+	}
+	for _, md := range metadata {
+		if cached, ok := families[md.MetricFamilyName]; ok {
+			cached.metadata = md
+			cached.updated = now
+			continue
+		}
+		if c.settings.MaxFamilies > 0 && c.families >= c.settings.MaxFamilies {
+			continue
+		}
+		families[md.MetricFamilyName] = &cachedMetadata{metadata: md, updated: now}
+		c.families++
+	}
+	if len(families) == 0 {
+		delete(c.tenants, tenant)
+	}
+}
+
+// get evicts the metadata of the metric families that were not exported for the maximum staleness, and returns the
+// metadata of every other metric family exported to each tenant, sorted by name.
+func (c *metadataCache) get() map[string][]*metricMetadata {
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	metadata := make(map[string][]*metricMetadata, len(c.tenants))
+	for tenant, families := range c.tenants {
+		kept := make(map[string]*metricMetadata, len(families))
+		for name, cached := range families {
+			if c.settings.MaxStaleness > 0 && now.Sub(cached.updated) >= c.settings.MaxStaleness {
+				delete(families, name)
+				c.families--
+				continue
+			}
+			kept[name] = cached.metadata
+		}
+		if len(families) == 0 {
+			delete(c.tenants, tenant)
+			continue
+		}
+		metadata[tenant] = sortMetadata(kept)
+	}
+	return metadata
+}
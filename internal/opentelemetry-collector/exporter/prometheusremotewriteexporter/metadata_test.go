@@ -0,0 +1,269 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/consumer/pdatautil"
+	otlpmetrics "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1"
+	otlp "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1old"
+	"go.opentelemetry.io/collector/internal/dataold"
+	"go.opentelemetry.io/collector/internal/dataold/testdataold"
+)
+
+// decodeFields decodes the varint and length-delimited fields of a marshaled protobuf message.
+func decodeFields(t *testing.T, data []byte) (keys []uint64, values [][]byte) {
+	for len(data) > 0 {
+		key, n := proto.DecodeVarint(data)
+		require.NotZero(t, n)
+		data = data[n:]
+		v, n := proto.DecodeVarint(data)
+		require.NotZero(t, n)
+		data = data[n:]
+		if key&7 == proto.WireVarint {
+			keys, values = append(keys, key), append(values, proto.EncodeVarint(v))
+			continue
+		}
+		require.Equal(t, uint64(proto.WireBytes), key&7)
+		require.LessOrEqual(t, v, uint64(len(data)))
+		keys, values = append(keys, key), append(values, data[:v])
+		data = data[v:]
+	}
+	return keys, values
+}
+
+// decodeMetadata decodes the metadata that the vendored prompb package leaves as unrecognized fields of req.
+func decodeMetadata(t *testing.T, req *prompb.WriteRequest) []*metricMetadata {
+	var metadata []*metricMetadata
+	keys, entries := decodeFields(t, req.XXX_unrecognized)
+	for i, entry := range entries {
+		require.Equal(t, uint64(writeRequestMetadataField<<3|proto.WireBytes), keys[i])
+		md := &metricMetadata{}
+		fieldKeys, fieldValues := decodeFields(t, entry)
+		for j, value := range fieldValues {
+			switch fieldKeys[j] >> 3 {
+			case metadataTypeField:
+				v, _ := proto.DecodeVarint(value)
+				md.Type = metricType(v)
+			case metadataFamilyNameField:
+				md.MetricFamilyName = string(value)
+			case metadataHelpField:
+				md.Help = string(value)
+			case metadataUnitField:
+				md.Unit = string(value)
+			}
+		}
+		metadata = append(metadata, md)
+	}
+	return metadata
+}
+
+// Test_getMetricMetadata checks that OTLP metric types are mapped to the corresponding Prometheus metric types.
+func Test_getMetricMetadata(t *testing.T) {
+	cumulative := otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+	withMetadata := func(m *otlpmetrics.Metric, description, unit string) pdata.Metric {
+		m.Description, m.Unit = description, unit
+		return getMetric(m)
+	}
+	tests := []struct {
+		name     string
+		metric   pdata.Metric
+		expected *metricMetadata
+	}{
+		{
+			"counter_case",
+			withMetadata(getIntSumMetric("requests", cumulative, true), "Number of requests.", "1"),
+			&metricMetadata{Type: metricTypeCounter, MetricFamilyName: "ns_requests_total", Help: "Number of requests.",
+				Unit: "1"},
+		},
+		{
+			"non_monotonic_sum_case",
+			withMetadata(getDoubleSumMetric("queue_size", cumulative, false), "", "1"),
+			&metricMetadata{Type: metricTypeGauge, MetricFamilyName: "ns_queue_size", Unit: "1"},
+		},
+		{
+			"gauge_case",
+			withMetadata(getDoubleGaugeMetric("temperature"), "", "C"),
+			&metricMetadata{Type: metricTypeGauge, MetricFamilyName: "ns_temperature", Unit: "C"},
+		},
+		{
+			"histogram_case",
+			withMetadata(getIntHistogramMetric("latency", cumulative), "", "ms"),
+			&metricMetadata{Type: metricTypeHistogram, MetricFamilyName: "ns_latency", Unit: "ms"},
+		},
+		{
+			"no_data_case",
+			getMetric(&otlpmetrics.Metric{Name: "invalid"}),
+			&metricMetadata{Type: metricTypeUnknown, MetricFamilyName: "ns_invalid"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, getMetricMetadata(tt.metric, "ns", NamingModeSanitize))
+		})
+	}
+}
+
+// Test_getSummaryMetadata checks that summaries are mapped to Prometheus summaries.
+func Test_getSummaryMetadata(t *testing.T) {
+	desc := &otlp.MetricDescriptor{Name: "size", Description: "Size of requests.", Unit: "By",
+		Type: otlp.MetricDescriptor_SUMMARY}
+	assert.Equal(t, &metricMetadata{Type: metricTypeSummary, MetricFamilyName: "ns_size", Help: "Size of requests.",
+		Unit: "By"}, getSummaryMetadata(desc, "ns", NamingModeSanitize))
+}
+
+// Test_marshalWriteRequest checks that metadata is encoded as the metadata field of the WriteRequest without altering
+// its TimeSeries.
+func Test_marshalWriteRequest(t *testing.T) {
+	ts := getTimeSeries(getPromLabels(label11, value11), getSample(floatVal1, msTime1))
+	req := &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{*ts}}
+	metadata := []*metricMetadata{
+		{Type: metricTypeCounter, MetricFamilyName: "a_total", Help: "help a", Unit: "s"},
+		{Type: metricTypeUnknown, MetricFamilyName: "b"},
+	}
+
+	data, err := marshalWriteRequest(req, metadata)
+	require.NoError(t, err)
+	got := &prompb.WriteRequest{}
+	require.NoError(t, proto.Unmarshal(data, got))
+	assert.Equal(t, req.Timeseries, got.Timeseries)
+	assert.Equal(t, metadata, decodeMetadata(t, got))
+	decoded, err := unmarshalMetadata(got.XXX_unrecognized)
+	require.NoError(t, err)
+	assert.Equal(t, metadata, decoded)
+
+	data, err = marshalWriteRequest(req, nil)
+	require.NoError(t, err)
+	expected, err := proto.Marshal(req)
+	require.NoError(t, err)
+	assert.Equal(t, expected, data)
+}
+
+// Test_metadataCache checks that the latest metadata of each metric family is kept for each tenant.
+func Test_metadataCache(t *testing.T) {
+	c := newMetadataCache(MetadataSettings{})
+	assert.Empty(t, c.get())
+	c.update("", []*metricMetadata{{MetricFamilyName: "b", Help: "old"}, {MetricFamilyName: "a"}})
+	c.update("", []*metricMetadata{{MetricFamilyName: "b", Help: "new"}})
+	c.update("tenant", []*metricMetadata{{MetricFamilyName: "c"}})
+	c.update("empty", nil)
+	assert.Equal(t, map[string][]*metricMetadata{
+		"":       {{MetricFamilyName: "a"}, {MetricFamilyName: "b", Help: "new"}},
+		"tenant": {{MetricFamilyName: "c"}},
+	}, c.get())
+}
+
+// Test_metadataCacheLimits checks that the metadata of metric families that are no longer exported is evicted, and
+// that the metadata of new metric families is not kept once the maximum number of metric families is reached.
+func Test_metadataCacheLimits(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := newMetadataCache(MetadataSettings{MaxStaleness: time.Minute, MaxFamilies: 2})
+	c.now = func() time.Time { return now }
+
+	c.update("", []*metricMetadata{{MetricFamilyName: "a"}, {MetricFamilyName: "b"}})
+	c.update("tenant", []*metricMetadata{{MetricFamilyName: "c"}})
+	assert.Equal(t, map[string][]*metricMetadata{
+		"": {{MetricFamilyName: "a"}, {MetricFamilyName: "b"}},
+	}, c.get())
+
+	now = now.Add(30 * time.Second)
+	c.update("", []*metricMetadata{{MetricFamilyName: "b", Help: "new"}})
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, map[string][]*metricMetadata{
+		"": {{MetricFamilyName: "b", Help: "new"}},
+	}, c.get())
+
+	// the family of the evicted metadata is available again
+	c.update("tenant", []*metricMetadata{{MetricFamilyName: "c"}})
+	now = now.Add(time.Minute)
+	assert.Equal(t, map[string][]*metricMetadata{}, c.get())
+	assert.Equal(t, 0, c.families)
+}
+
+// Test_PushMetricsMetadata checks that metadata is sent inline with the TimeSeries, or in separate requests when a send
+// interval is configured.
+func Test_PushMetricsMetadata(t *testing.T) {
+	batch := testdataold.GenerateMetricDataOneMetric()
+	setCumulative(&batch)
+	otlpBatch := dataold.MetricDataToOtlp(batch)
+	desc := otlpBatch[0].InstrumentationLibraryMetrics[0].Metrics[0].MetricDescriptor
+	desc.Description = "test description"
+	desc.Unit = "1"
+	md := pdatautil.MetricsFromOldInternalMetrics(dataold.MetricDataFromOtlp(otlpBatch))
+	metric := pdatautil.MetricsToInternalMetrics(md).ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).
+		Metrics().At(0)
+	expected := []*metricMetadata{getMetricMetadata(metric, "", NamingModeSanitize)}
+
+	tests := []struct {
+		name         string
+		settings     MetadataSettings
+		wantInline   bool
+		wantInterval bool
+	}{
+		{"disabled_case", MetadataSettings{}, false, false},
+		{"inline_case", MetadataSettings{Enabled: true}, true, false},
+		{"interval_case", MetadataSettings{Enabled: true, SendInterval: 10 * time.Millisecond}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := make(chan *prompb.WriteRequest, 100)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				require.NoError(t, err)
+				dest, err := snappy.Decode(nil, body)
+				require.NoError(t, err)
+				wr := &prompb.WriteRequest{}
+				require.NoError(t, proto.Unmarshal(dest, wr))
+				requests <- wr
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer server.Close()
+
+			prwe, err := NewPrwExporter("", server.URL, http.DefaultClient, WithMetadata(tt.settings))
+			require.NoError(t, err)
+			require.NoError(t, prwe.Start(context.Background(), componenttest.NewNopHost()))
+			_, err = prwe.PushMetrics(context.Background(), md)
+			require.NoError(t, err)
+
+			wr := <-requests
+			assert.NotEmpty(t, wr.Timeseries)
+			if tt.wantInline {
+				assert.Equal(t, expected, decodeMetadata(t, wr))
+			} else {
+				assert.Empty(t, decodeMetadata(t, wr))
+			}
+			if tt.wantInterval {
+				wr = <-requests
+				assert.Empty(t, wr.Timeseries)
+				assert.Equal(t, expected, decodeMetadata(t, wr))
+			}
+			require.NoError(t, prwe.Shutdown(context.Background()))
+		})
+	}
+}
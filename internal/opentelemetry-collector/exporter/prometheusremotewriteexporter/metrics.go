@@ -0,0 +1,291 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"go.opentelemetry.io/collector/obsreport"
+)
+
+var (
+	tagExporterName, _ = tag.NewKey(obsreport.ExporterKey)
+	tagExporterType, _ = tag.NewKey("exporter_type")
+	tagTenant, _       = tag.NewKey("tenant")
+	tagStatusCode, _   = tag.NewKey("status_code")
+
+	mWALSizeBytes = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "wal_size_bytes"),
+		"Size of all segments of the write-ahead log.",
+		stats.UnitBytes)
+	mWALPendingBytes = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "wal_pending_bytes"),
+		"Size of the records in the write-ahead log not yet sent to the endpoint.",
+		stats.UnitBytes)
+	mWALWrittenBytes = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "wal_written_bytes"),
+		"Number of bytes written to the write-ahead log.",
+		stats.UnitBytes)
+	mWALDroppedBytes = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "wal_dropped_bytes"),
+		"Number of bytes dropped from the write-ahead log before being sent, due to size or age limits or corruption.",
+		stats.UnitBytes)
+	mWALReplayedRecords = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "wal_replayed_records"),
+		"Number of records read from the write-ahead log and sent to the endpoint.",
+		stats.UnitDimensionless)
+	mDeltaSeries = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "delta_series"),
+		"Number of delta TimeSeries accumulated into cumulative ones.",
+		stats.UnitDimensionless)
+	mDeltaEvictedSeries = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "delta_evicted_series"),
+		"Number of delta TimeSeries evicted from the accumulator for not being updated within the maximum staleness.",
+		stats.UnitDimensionless)
+	mDeltaDroppedSamples = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "delta_dropped_samples"),
+		"Number of delta Samples dropped for being out of order, overlapping, or exceeding the maximum number of TimeSeries.",
+		stats.UnitDimensionless)
+	mStalenessSeries = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "staleness_series"),
+		"Number of TimeSeries kept track of to be marked stale when no longer exported.",
+		stats.UnitDimensionless)
+	mStalenessMarkers = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "staleness_markers"),
+		"Number of staleness markers sent for TimeSeries no longer exported.",
+		stats.UnitDimensionless)
+	mStalenessUntrackedSeries = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "staleness_untracked_series"),
+		"Number of TimeSeries not kept track of for exceeding the maximum number of TimeSeries.",
+		stats.UnitDimensionless)
+	mDuplicateSamples = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "duplicate_samples"),
+		"Number of Samples dropped for having the same timestamp as another Sample of their TimeSeries in a batch.",
+		stats.UnitDimensionless)
+	mRelabelDroppedSeries = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "relabel_dropped_series"),
+		"Number of TimeSeries dropped by the write relabel configs.",
+		stats.UnitDimensionless)
+	mSentSamples = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "sent_samples"),
+		"Number of Samples sent to the endpoint.",
+		stats.UnitDimensionless)
+	mFailedSamples = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "failed_samples"),
+		"Number of Samples that failed to be sent to the endpoint with a non-retryable error.",
+		stats.UnitDimensionless)
+	mRetriedSamples = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "retried_samples"),
+		"Number of Samples that failed to be sent to the endpoint with a retryable error.",
+		stats.UnitDimensionless)
+	mRequestSamples = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "request_samples"),
+		"Number of Samples in each request sent to the endpoint.",
+		stats.UnitDimensionless)
+	mSentBytes = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "sent_bytes"),
+		"Number of compressed bytes of the requests sent to the endpoint.",
+		stats.UnitBytes)
+	mRequestLatency = stats.Float64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "request_latency"),
+		"Time taken by each request sent to the endpoint, by HTTP status code.",
+		stats.UnitMilliseconds)
+	mShards = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "shards"),
+		"Number of shards sending requests to the endpoint concurrently.",
+		stats.UnitDimensionless)
+	mTenantSentSamples = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "tenant_sent_samples"),
+		"Number of Samples sent to each tenant.",
+		stats.UnitDimensionless)
+	mTenantFailedSamples = stats.Int64(
+		obsreport.BuildExporterCustomMetricName(typeStr, "tenant_failed_samples"),
+		"Number of Samples that failed to be sent to each tenant.",
+		stats.UnitDimensionless)
+)
+
+// MetricViews returns the metric views of the Prometheus remote write exporter. The metrics are tagged with the name
+// and the type of the exporter that recorded them.
+func MetricViews() []*view.View {
+	tagKeys := []tag.Key{tagExporterName, tagExporterType}
+	tenantTagKeys := []tag.Key{tagExporterName, tagExporterType, tagTenant}
+	statusTagKeys := []tag.Key{tagExporterName, tagExporterType, tagStatusCode}
+
+	return []*view.View{
+		{
+			Name:        mWALSizeBytes.Name(),
+			Measure:     mWALSizeBytes,
+			Description: mWALSizeBytes.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        mWALPendingBytes.Name(),
+			Measure:     mWALPendingBytes,
+			Description: mWALPendingBytes.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        mWALWrittenBytes.Name(),
+			Measure:     mWALWrittenBytes,
+			Description: mWALWrittenBytes.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mWALDroppedBytes.Name(),
+			Measure:     mWALDroppedBytes,
+			Description: mWALDroppedBytes.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mWALReplayedRecords.Name(),
+			Measure:     mWALReplayedRecords,
+			Description: mWALReplayedRecords.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mDeltaSeries.Name(),
+			Measure:     mDeltaSeries,
+			Description: mDeltaSeries.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        mDeltaEvictedSeries.Name(),
+			Measure:     mDeltaEvictedSeries,
+			Description: mDeltaEvictedSeries.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mDeltaDroppedSamples.Name(),
+			Measure:     mDeltaDroppedSamples,
+			Description: mDeltaDroppedSamples.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mStalenessSeries.Name(),
+			Measure:     mStalenessSeries,
+			Description: mStalenessSeries.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        mStalenessMarkers.Name(),
+			Measure:     mStalenessMarkers,
+			Description: mStalenessMarkers.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mStalenessUntrackedSeries.Name(),
+			Measure:     mStalenessUntrackedSeries,
+			Description: mStalenessUntrackedSeries.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mDuplicateSamples.Name(),
+			Measure:     mDuplicateSamples,
+			Description: mDuplicateSamples.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mRelabelDroppedSeries.Name(),
+			Measure:     mRelabelDroppedSeries,
+			Description: mRelabelDroppedSeries.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mSentSamples.Name(),
+			Measure:     mSentSamples,
+			Description: mSentSamples.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mFailedSamples.Name(),
+			Measure:     mFailedSamples,
+			Description: mFailedSamples.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mRetriedSamples.Name(),
+			Measure:     mRetriedSamples,
+			Description: mRetriedSamples.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mRequestSamples.Name(),
+			Measure:     mRequestSamples,
+			Description: mRequestSamples.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.Distribution(1, 10, 100, 500, 1000, 2000, 5000, 10000, 50000),
+		},
+		{
+			Name:        mSentBytes.Name(),
+			Measure:     mSentBytes,
+			Description: mSentBytes.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        obsreport.BuildExporterCustomMetricName(typeStr, "requests"),
+			Measure:     mRequestLatency,
+			Description: "Number of requests sent to the endpoint, by HTTP status code.",
+			TagKeys:     statusTagKeys,
+			Aggregation: view.Count(),
+		},
+		{
+			Name:        mRequestLatency.Name(),
+			Measure:     mRequestLatency,
+			Description: mRequestLatency.Description(),
+			TagKeys:     statusTagKeys,
+			Aggregation: view.Distribution(5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000),
+		},
+		{
+			Name:        mShards.Name(),
+			Measure:     mShards,
+			Description: mShards.Description(),
+			TagKeys:     tagKeys,
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        mTenantSentSamples.Name(),
+			Measure:     mTenantSentSamples,
+			Description: mTenantSentSamples.Description(),
+			TagKeys:     tenantTagKeys,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mTenantFailedSamples.Name(),
+			Measure:     mTenantFailedSamples,
+			Description: mTenantFailedSamples.Description(),
+			TagKeys:     tenantTagKeys,
+			Aggregation: view.Sum(),
+		},
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"go.opentelemetry.io/collector/consumer/pdatautil"
+	"go.opentelemetry.io/collector/obsreport"
+)
+
+// getViewRows returns the rows of the view named metric of the exporter named name, keyed by their status code if any.
+func getViewRows(t *testing.T, metric, name string) map[string]view.AggregationData {
+	rows, err := view.RetrieveData(obsreport.BuildExporterCustomMetricName(typeStr, metric))
+	require.NoError(t, err)
+	data := map[string]view.AggregationData{}
+	for _, row := range rows {
+		tags := map[tag.Key]string{}
+		for _, tg := range row.Tags {
+			tags[tg.Key] = tg.Value
+		}
+		if tags[tagExporterName] == name {
+			data[tags[tagStatusCode]] = row.Data
+		}
+	}
+	return data
+}
+
+// Test_PushMetricsTelemetry checks that the Samples, bytes and requests sent to the endpoint and the number of shards
+// are recorded in the metrics of the exporter.
+func Test_PushMetricsTelemetry(t *testing.T) {
+	views := MetricViews()
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	var status int32 = http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer server.Close()
+
+	md := pdatautil.MetricsFromInternalMetrics(getInternalMetrics(
+		getDoubleGaugeMetric("gauge", getDoublePoint(lbs1, 1, time1), getDoublePoint(lbs1, 2, time1+2000000)),
+	))
+
+	const name = "telemetry"
+	prwe, err := NewPrwExporter("", server.URL, http.DefaultClient, WithName(name))
+	require.NoError(t, err)
+	_, err = prwe.PushMetrics(context.Background(), md)
+	require.NoError(t, err)
+	atomic.StoreInt32(&status, http.StatusBadRequest)
+	_, err = prwe.PushMetrics(context.Background(), md)
+	require.Error(t, err)
+
+	assert.Equal(t, &view.SumData{Value: 2}, getViewRows(t, "sent_samples", name)[""])
+	assert.Equal(t, &view.SumData{Value: 2}, getViewRows(t, "failed_samples", name)[""])
+	assert.Empty(t, getViewRows(t, "retried_samples", name))
+	assert.Equal(t, &view.LastValueData{Value: 1}, getViewRows(t, "shards", name)[""])
+
+	requests := getViewRows(t, "requests", name)
+	assert.Equal(t, &view.CountData{Value: 1}, requests["204"])
+	assert.Equal(t, &view.CountData{Value: 1}, requests["400"])
+
+	samples := getViewRows(t, "request_samples", name)[""].(*view.DistributionData)
+	assert.EqualValues(t, 2, samples.Count)
+	assert.EqualValues(t, 2, samples.Mean)
+
+	sentBytes := getViewRows(t, "sent_bytes", name)[""].(*view.SumData)
+	assert.Greater(t, sentBytes.Value, float64(0))
+}
+
+// Test_MetricsExporterType checks that the metrics of the exporter are tagged with its type, which defaults to that of
+// the Prometheus remote write exporter.
+func Test_MetricsExporterType(t *testing.T) {
+	views := MetricViews()
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	_, err := NewPrwExporter("", "http://localhost:9009", http.DefaultClient, WithName("type/default"))
+	require.NoError(t, err)
+	_, err = NewPrwExporter("", "http://localhost:9009", http.DefaultClient, WithName("type/custom"), WithType("custom"))
+	require.NoError(t, err)
+
+	rows, err := view.RetrieveData(obsreport.BuildExporterCustomMetricName(typeStr, "shards"))
+	require.NoError(t, err)
+	types := map[string]string{}
+	for _, row := range rows {
+		tags := map[tag.Key]string{}
+		for _, tg := range row.Tags {
+			tags[tg.Key] = tg.Value
+		}
+		types[tags[tagExporterName]] = tags[tagExporterType]
+	}
+	assert.Equal(t, typeStr, types["type/default"])
+	assert.Equal(t, "custom", types["type/custom"])
+}
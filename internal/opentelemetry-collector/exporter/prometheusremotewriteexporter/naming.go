@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	ratioStr = "ratio"
+	perStr   = "per"
+)
+
+// unitSuffixes maps UCUM units, as used by OpenTelemetry, to the words Prometheus metric names are suffixed with.
+var unitSuffixes = map[string]string{
+	// time
+	"d":   "days",
+	"h":   "hours",
+	"min": "minutes",
+	"s":   "seconds",
+	"ms":  "milliseconds",
+	"us":  "microseconds",
+	"ns":  "nanoseconds",
+
+	// bytes
+	"By":   "bytes",
+	"KiBy": "kibibytes",
+	"MiBy": "mebibytes",
+	"GiBy": "gibibytes",
+	"TiBy": "tebibytes",
+	"KBy":  "kilobytes",
+	"MBy":  "megabytes",
+	"GBy":  "gigabytes",
+	"TBy":  "terabytes",
+
+	// SI
+	"m":   "meters",
+	"V":   "volts",
+	"A":   "amperes",
+	"J":   "joules",
+	"W":   "watts",
+	"g":   "grams",
+	"Cel": "celsius",
+	"Hz":  "hertz",
+
+	// misc
+	"%": "percent",
+	"$": "dollars",
+}
+
+// perUnitSuffixes maps the UCUM units a unit is divided by, as in m/s, to the words following "per" in Prometheus
+// metric names.
+var perUnitSuffixes = map[string]string{
+	"s":  "second",
+	"m":  "minute",
+	"h":  "hour",
+	"d":  "day",
+	"w":  "week",
+	"mo": "month",
+	"y":  "year",
+}
+
+// rawMetricName returns name with the namespace prefix, without any other change, for endpoints accepting UTF-8
+// metric names.
+func rawMetricName(name, ns string) string {
+	if ns == "" || name == "" {
+		return name
+	}
+	return ns + delimeter + name
+}
+
+// normalizeMetricName creates a Prometheus metric name following the Prometheus naming conventions from the name and
+// unit of a metric family of type typ:
+//   - every run of characters other than letters and digits is replaced with a single underscore
+//   - the unit is appended in words, such as milliseconds for ms or meters_per_second for m/s, unless the name already
+//     ends with it; annotations in curly braces are ignored, and unknown units are appended as they are
+//   - gauges with the unit 1 are suffixed with ratio
+//   - counters are suffixed with _total exactly once
+func normalizeMetricName(name, unit, ns string, typ metricType) string {
+	if name == "" {
+		return ""
+	}
+	tokens := splitNameTokens(name)
+	if ns != "" {
+		tokens = append(splitNameTokens(ns), tokens...)
+	}
+	// any _total the name of a counter already ends with is moved after the unit
+	if typ == metricTypeCounter && len(tokens) > 1 && tokens[len(tokens)-1] == totalStr {
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	unit = strings.TrimSpace(removeAnnotations(unit))
+	if unit == "1" {
+		if typ == metricTypeGauge {
+			tokens = appendIfMissing(tokens, ratioStr)
+		}
+	} else if unit != "" {
+		main, per := unit, ""
+		if i := strings.Index(unit, "/"); i >= 0 {
+			main, per = strings.TrimSpace(unit[:i]), strings.TrimSpace(unit[i+1:])
+		}
+		if suffix := unitSuffix(main, unitSuffixes); suffix != "" {
+			tokens = appendIfMissing(tokens, suffix)
+		}
+		if suffix := unitSuffix(per, perUnitSuffixes); suffix != "" {
+			tokens = appendIfMissing(tokens, perStr, suffix)
+		}
+	}
+
+	if typ == metricTypeCounter {
+		tokens = append(tokens, totalStr)
+	}
+	return sanitize(strings.Join(tokens, delimeter))
+}
+
+// splitNameTokens splits s into the runs of letters and digits it is made of.
+func splitNameTokens(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// removeAnnotations removes the annotations in curly braces of unit, such as {requests} in {requests}/s.
+func removeAnnotations(unit string) string {
+	b := strings.Builder{}
+	depth := 0
+	for _, r := range unit {
+		switch {
+		case r == '{':
+			depth++
+		case r == '}' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// unitSuffix returns the words of unit in suffixes, or unit itself with its characters other than letters and digits
+// replaced with underscores if unknown.
+func unitSuffix(unit string, suffixes map[string]string) string {
+	if unit == "" {
+		return ""
+	}
+	if suffix, ok := suffixes[unit]; ok {
+		return suffix
+	}
+	return strings.Join(splitNameTokens(unit), delimeter)
+}
+
+// appendIfMissing appends the tokens of suffix to tokens, unless tokens already end with them.
+func appendIfMissing(tokens []string, suffix ...string) []string {
+	var suffixTokens []string
+	for _, s := range suffix {
+		suffixTokens = append(suffixTokens, strings.Split(s, delimeter)...)
+	}
+	if len(tokens) >= len(suffixTokens) {
+		tail := tokens[len(tokens)-len(suffixTokens):]
+		if strings.Join(tail, delimeter) == strings.Join(suffixTokens, delimeter) {
+			return tokens
+		}
+	}
+	return append(tokens, suffixTokens...)
+}
@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	otlpmetrics "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1"
+)
+
+// Test_normalizeMetricName checks that metric names follow the Prometheus naming conventions in the normalize mode.
+func Test_normalizeMetricName(t *testing.T) {
+	tests := []struct {
+		name   string
+		metric string
+		unit   string
+		ns     string
+		typ    metricType
+		want   string
+	}{
+		{"milliseconds", "http.server.duration", "ms", "", metricTypeHistogram, "http_server_duration_milliseconds"},
+		{"bytes", "system.memory.usage", "By", "", metricTypeGauge, "system_memory_usage_bytes"},
+		{"ratio", "system.cpu.utilization", "1", "", metricTypeGauge, "system_cpu_utilization_ratio"},
+		{"dimensionless_counter", "http.requests", "1", "", metricTypeCounter, "http_requests_total"},
+		{"counter_with_unit", "network.io", "By", "", metricTypeCounter, "network_io_bytes_total"},
+		{"no_double_total", "requests_total", "", "", metricTypeCounter, "requests_total"},
+		{"total_moved_after_unit", "cpu.time.total", "s", "", metricTypeCounter, "cpu_time_seconds_total"},
+		{"unit_already_in_name", "request_duration_seconds", "s", "", metricTypeSummary, "request_duration_seconds"},
+		{"per_unit", "speed", "m/s", "", metricTypeGauge, "speed_meters_per_second"},
+		{"annotation", "queue.throughput", "{messages}/s", "", metricTypeGauge, "queue_throughput_per_second"},
+		{"only_annotation", "queue.length", "{messages}", "", metricTypeGauge, "queue_length"},
+		{"unknown_unit", "wind.speed", "kn", "", metricTypeGauge, "wind_speed_kn"},
+		{"namespace", "up", "", "my-app", metricTypeGauge, "my_app_up"},
+		{"collapsed_separators", "a..b__c", "", "", metricTypeGauge, "a_b_c"},
+		{"leading_digit", "5xx.errors", "", "", metricTypeGauge, "key_5xx_errors"},
+		{"empty_name", "", "ms", "ns", metricTypeGauge, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, normalizeMetricName(tt.metric, tt.unit, tt.ns, tt.typ))
+		})
+	}
+}
+
+// Test_buildPromMetricNameModes checks that each naming mode is applied to metric names, and that the sanitize mode
+// is the default.
+func Test_buildPromMetricNameModes(t *testing.T) {
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{"", "ns_http_server_duration_total"},
+		{NamingModeSanitize, "ns_http_server_duration_total"},
+		{NamingModeNormalize, "ns_http_server_duration_milliseconds_total"},
+		{NamingModeRaw, "ns_http.server.duration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			assert.Equal(t, tt.want, buildPromMetricName("http.server.duration", "ms", "ns", metricTypeCounter, tt.mode))
+		})
+	}
+	assert.Equal(t, "http.server.duration", rawMetricName("http.server.duration", ""))
+}
+
+// Test_getPromMetricNameNormalize checks that the unit and type of metrics are used to name them in the normalize mode.
+func Test_getPromMetricNameNormalize(t *testing.T) {
+	cumulative := otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+	counter := getMetric(getDoubleSumMetric("process.cpu.time", cumulative, true))
+	counter.SetUnit("s")
+	assert.Equal(t, "process_cpu_time_seconds_total", getPromMetricName(counter, "", NamingModeNormalize))
+
+	gauge := getMetric(getDoubleSumMetric("process.memory.usage", cumulative, false))
+	gauge.SetUnit("By")
+	assert.Equal(t, "process_memory_usage_bytes", getPromMetricName(gauge, "", NamingModeNormalize))
+}
+
+// Test_NamingSettingsValidate checks that unknown naming modes are rejected.
+func Test_NamingSettingsValidate(t *testing.T) {
+	for _, mode := range []string{NamingModeSanitize, NamingModeNormalize, NamingModeRaw} {
+		assert.NoError(t, NamingSettings{Mode: mode}.Validate())
+	}
+	assert.Error(t, NamingSettings{Mode: "camel"}.Validate())
+	assert.Error(t, NamingSettings{}.Validate())
+}
@@ -0,0 +1,335 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync/atomic"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/prometheus/prometheus/prompb"
+	"go.uber.org/zap"
+)
+
+const (
+	remoteWriteVersionHeader = "X-Prometheus-Remote-Write-Version"
+	// samplesWrittenHeader is set by remote write 2.0 endpoints on every response.
+	samplesWrittenHeader = "X-Prometheus-Remote-Write-Samples-Written"
+)
+
+// Field numbers of the io.prometheus.write.v2.Request message of the remote write 2.0 protocol.
+const (
+	v2RequestSymbolsField       = 4
+	v2RequestTimeSeriesField    = 5
+	v2TimeSeriesLabelsRefsField = 1
+	v2TimeSeriesSamplesField    = 2
+	v2TimeSeriesExemplarsField  = 4
+	v2TimeSeriesMetadataField   = 5
+	v2SampleValueField          = 1
+	v2SampleTimestampField      = 2
+	v2ExemplarLabelsRefsField   = 1
+	v2ExemplarValueField        = 2
+	v2ExemplarTimestampField    = 3
+	v2MetadataTypeField         = 1
+	v2MetadataHelpRefField      = 3
+	v2MetadataUnitRefField      = 4
+)
+
+// baseProtocol is the protocol every remote write endpoint supports.
+var baseProtocol = ProtocolSettings{Version: ProtocolVersion1, Compression: CompressionSnappy}
+
+// protocolNegotiator chooses the protocol of each request, and encodes requests in it. It falls back to baseProtocol
+// for good once the endpoint does not support the configured protocol.
+type protocolNegotiator struct {
+	settings ProtocolSettings
+	logger   *zap.Logger
+	fellBack int32
+	zstd     *zstd.Encoder
+}
+
+// newProtocolNegotiator returns a protocolNegotiator for settings. Unset settings default to baseProtocol.
+func newProtocolNegotiator(settings ProtocolSettings, logger *zap.Logger) (*protocolNegotiator, error) {
+	if settings.Version == "" {
+		settings.Version = baseProtocol.Version
+	}
+	if settings.Compression == "" {
+		settings.Compression = baseProtocol.Compression
+	}
+	if err := settings.Validate(nil); err != nil {
+		return nil, err
+	}
+	n := &protocolNegotiator{settings: settings, logger: logger}
+	if settings.Compression == CompressionZstd {
+		encoder, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, err
+		}
+		n.zstd = encoder
+	}
+	return n, nil
+}
+
+// current returns the protocol of the next request.
+func (n *protocolNegotiator) current() ProtocolSettings {
+	if atomic.LoadInt32(&n.fellBack) != 0 {
+		return baseProtocol
+	}
+	return n.settings
+}
+
+// fallBack makes baseProtocol the protocol of every following request, since the endpoint does not support the
+// configured one for reason.
+func (n *protocolNegotiator) fallBack(reason error) {
+	if atomic.CompareAndSwapInt32(&n.fellBack, 0, 1) {
+		n.logger.Warn("Remote write endpoint does not support the configured protocol, falling back",
+			zap.String("version", baseProtocol.Version), zap.String("compression", baseProtocol.Compression),
+			zap.Error(reason))
+	}
+}
+
+// encode converts data, a marshaled remote write 1.0 WriteRequest and its metadata, to the protocol version of settings
+// and compresses it. It returns the encoded request and the number of Samples it holds.
+func (n *protocolNegotiator) encode(data []byte, settings ProtocolSettings) ([]byte, int, error) {
+	samples := -1
+	if settings.Version == ProtocolVersion2 {
+		var err error
+		if data, samples, err = marshalWriteRequestV2(data); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	switch settings.Compression {
+	case CompressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, 0, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, 0, err
+		}
+		return buf.Bytes(), samples, nil
+	case CompressionZstd:
+		if n.zstd == nil {
+			return nil, 0, fmt.Errorf("zstd compression is not configured")
+		}
+		return n.zstd.EncodeAll(data, nil), samples, nil
+	}
+	return snappy.Encode(nil, data), samples, nil
+}
+
+// contentType returns the Content-Type header of requests of the protocol version.
+func contentType(version string) string {
+	if version == ProtocolVersion2 {
+		return "application/x-protobuf;proto=io.prometheus.write.v2.Request"
+	}
+	return "application/x-protobuf"
+}
+
+// versionHeader returns the X-Prometheus-Remote-Write-Version header of requests of the protocol version.
+func versionHeader(version string) string {
+	if version == ProtocolVersion2 {
+		return "2.0.0"
+	}
+	return "0.1.0"
+}
+
+// symbolTable interns the strings of a remote write 2.0 request. The empty string is always the first symbol.
+type symbolTable struct {
+	symbols []string
+	refs    map[string]uint32
+}
+
+func newSymbolTable() *symbolTable {
+	return &symbolTable{symbols: []string{""}, refs: map[string]uint32{"": 0}}
+}
+
+// ref returns the reference of s, adding it to the table if needed.
+func (t *symbolTable) ref(s string) uint32 {
+	if ref, ok := t.refs[s]; ok {
+		return ref
+	}
+	ref := uint32(len(t.symbols))
+	t.symbols = append(t.symbols, s)
+	t.refs[s] = ref
+	return ref
+}
+
+// labelRefs returns the references of the names and values of labels, in the order of labels.
+func (t *symbolTable) labelRefs(labels []prompb.Label) []byte {
+	var refs []byte
+	for _, l := range labels {
+		refs = append(refs, proto.EncodeVarint(uint64(t.ref(l.Name)))...)
+		refs = append(refs, proto.EncodeVarint(uint64(t.ref(l.Value)))...)
+	}
+	return refs
+}
+
+// marshalWriteRequestV2 converts data, a marshaled remote write 1.0 WriteRequest with its exemplars and metadata, to a
+// remote write 2.0 Request. The metadata of a metric family is attached to each of its TimeSeries in the request. It
+// returns the Request and the number of Samples it holds.
+func marshalWriteRequestV2(data []byte) ([]byte, int, error) {
+	req := &prompb.WriteRequest{}
+	if err := proto.Unmarshal(data, req); err != nil {
+		return nil, 0, err
+	}
+	metadata, err := unmarshalMetadata(req.XXX_unrecognized)
+	if err != nil {
+		return nil, 0, err
+	}
+	families := make(map[string]*metricMetadata, len(metadata))
+	for _, md := range metadata {
+		families[md.MetricFamilyName] = md
+	}
+
+	symbols := newSymbolTable()
+	var series []byte
+	samples := 0
+	for _, ts := range req.Timeseries {
+		var encoded []byte
+		encoded = appendBytesField(encoded, v2TimeSeriesLabelsRefsField, symbols.labelRefs(ts.Labels))
+		for _, sample := range ts.Samples {
+			encoded = appendBytesField(encoded, v2TimeSeriesSamplesField,
+				marshalV2Point(v2SampleValueField, sample.Value, v2SampleTimestampField, sample.Timestamp))
+		}
+		samples += len(ts.Samples)
+
+		exemplars, err := unmarshalExemplars(ts.XXX_unrecognized)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, e := range exemplars {
+			exemplar := appendBytesField(nil, v2ExemplarLabelsRefsField, symbols.labelRefs(e.Labels))
+			exemplar = append(exemplar,
+				marshalV2Point(v2ExemplarValueField, e.Value, v2ExemplarTimestampField, e.Timestamp)...)
+			encoded = appendBytesField(encoded, v2TimeSeriesExemplarsField, exemplar)
+		}
+
+		if md := familyMetadata(families, ts.Labels); md != nil {
+			var encodedMetadata []byte
+			if md.Type != metricTypeUnknown {
+				encodedMetadata = append(encodedMetadata, proto.EncodeVarint(v2MetadataTypeField<<3|proto.WireVarint)...)
+				encodedMetadata = append(encodedMetadata, proto.EncodeVarint(uint64(md.Type))...)
+			}
+			for _, field := range []struct {
+				number int
+				value  string
+			}{
+				{v2MetadataHelpRefField, md.Help},
+				{v2MetadataUnitRefField, md.Unit},
+			} {
+				if field.value == "" {
+					continue
+				}
+				encodedMetadata = append(encodedMetadata, proto.EncodeVarint(uint64(field.number<<3|proto.WireVarint))...)
+				encodedMetadata = append(encodedMetadata, proto.EncodeVarint(uint64(symbols.ref(field.value)))...)
+			}
+			encoded = appendBytesField(encoded, v2TimeSeriesMetadataField, encodedMetadata)
+		}
+		series = appendBytesField(series, v2RequestTimeSeriesField, encoded)
+	}
+
+	var out []byte
+	for _, symbol := range symbols.symbols {
+		out = appendBytesField(out, v2RequestSymbolsField, []byte(symbol))
+	}
+	return append(out, series...), samples, nil
+}
+
+// marshalV2Point encodes a double value and an int64 timestamp as the fields of a remote write 2.0 Sample or
+// Exemplar.
+func marshalV2Point(valueField int, value float64, timestampField int, timestamp int64) []byte {
+	data := proto.EncodeVarint(uint64(valueField<<3 | proto.WireFixed64))
+	var encoded [8]byte
+	binary.LittleEndian.PutUint64(encoded[:], math.Float64bits(value))
+	data = append(data, encoded[:]...)
+	data = append(data, proto.EncodeVarint(uint64(timestampField<<3|proto.WireVarint))...)
+	return append(data, proto.EncodeVarint(uint64(timestamp))...)
+}
+
+// familyMetadata returns the metadata of the metric family of the TimeSeries with labels, or nil if there is none.
+func familyMetadata(families map[string]*metricMetadata, labels []prompb.Label) *metricMetadata {
+	if len(families) == 0 {
+		return nil
+	}
+	for _, l := range labels {
+		if l.Name != nameStr {
+			continue
+		}
+		// the TimeSeries of histograms and summaries are named after their family with a suffix
+		for _, suffix := range []string{"", sumStr, countStr, bucketStr} {
+			if len(l.Value) < len(suffix) || l.Value[len(l.Value)-len(suffix):] != suffix {
+				continue
+			}
+			if md, ok := families[l.Value[:len(l.Value)-len(suffix)]]; ok {
+				return md
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// protoField is a field of an encoded protobuf message.
+type protoField struct {
+	number int
+	// value holds the value of varint and fixed64 fields
+	value uint64
+	// bytes holds the value of length-delimited fields
+	bytes []byte
+}
+
+// unmarshalFields decodes the varint, fixed64 and length-delimited fields of an encoded protobuf message.
+func unmarshalFields(data []byte) ([]protoField, error) {
+	var fields []protoField
+	for len(data) > 0 {
+		key, n := proto.DecodeVarint(data)
+		if n == 0 {
+			return nil, fmt.Errorf("invalid field key")
+		}
+		data = data[n:]
+		field := protoField{number: int(key >> 3)}
+		switch key & 7 {
+		case proto.WireVarint:
+			if field.value, n = proto.DecodeVarint(data); n == 0 {
+				return nil, fmt.Errorf("invalid varint of field %d", field.number)
+			}
+			data = data[n:]
+		case proto.WireFixed64:
+			if len(data) < 8 {
+				return nil, fmt.Errorf("invalid fixed64 of field %d", field.number)
+			}
+			field.value = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		case proto.WireBytes:
+			length, n := proto.DecodeVarint(data)
+			if n == 0 || uint64(len(data)-n) < length {
+				return nil, fmt.Errorf("invalid length of field %d", field.number)
+			}
+			field.bytes = data[n : n+int(length)]
+			data = data[n+int(length):]
+		default:
+			return nil, fmt.Errorf("unsupported wire type of field %d", field.number)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
@@ -0,0 +1,332 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// v2TimeSeries is a decoded TimeSeries of a remote write 2.0 Request, with the references to symbols resolved.
+type v2TimeSeries struct {
+	Labels    []prompb.Label
+	Samples   []prompb.Sample
+	Exemplars []exemplar
+	Metadata  *metricMetadata
+}
+
+// decodeV2Request decodes a remote write 2.0 Request.
+func decodeV2Request(t *testing.T, data []byte) []v2TimeSeries {
+	fields, err := unmarshalFields(data)
+	require.NoError(t, err)
+	var symbols []string
+	var encodedSeries [][]byte
+	for _, field := range fields {
+		switch field.number {
+		case v2RequestSymbolsField:
+			symbols = append(symbols, string(field.bytes))
+		case v2RequestTimeSeriesField:
+			encodedSeries = append(encodedSeries, field.bytes)
+		default:
+			t.Fatalf("unexpected request field %d", field.number)
+		}
+	}
+	require.NotEmpty(t, symbols)
+	assert.Equal(t, "", symbols[0])
+
+	symbol := func(ref uint64) string {
+		require.Less(t, ref, uint64(len(symbols)))
+		return symbols[ref]
+	}
+	labels := func(refs []byte) []prompb.Label {
+		var decoded []prompb.Label
+		for len(refs) > 0 {
+			name, n := proto.DecodeVarint(refs)
+			value, m := proto.DecodeVarint(refs[n:])
+			decoded = append(decoded, prompb.Label{Name: symbol(name), Value: symbol(value)})
+			refs = refs[n+m:]
+		}
+		return decoded
+	}
+
+	var series []v2TimeSeries
+	for _, encoded := range encodedSeries {
+		tsFields, err := unmarshalFields(encoded)
+		require.NoError(t, err)
+		ts := v2TimeSeries{}
+		for _, field := range tsFields {
+			if field.number == v2TimeSeriesLabelsRefsField {
+				ts.Labels = labels(field.bytes)
+				continue
+			}
+			nested, err := unmarshalFields(field.bytes)
+			require.NoError(t, err)
+			switch field.number {
+			case v2TimeSeriesSamplesField:
+				sample := prompb.Sample{}
+				for _, f := range nested {
+					switch f.number {
+					case v2SampleValueField:
+						sample.Value = math.Float64frombits(f.value)
+					case v2SampleTimestampField:
+						sample.Timestamp = int64(f.value)
+					}
+				}
+				ts.Samples = append(ts.Samples, sample)
+			case v2TimeSeriesExemplarsField:
+				e := exemplar{}
+				for _, f := range nested {
+					switch f.number {
+					case v2ExemplarLabelsRefsField:
+						e.Labels = labels(f.bytes)
+					case v2ExemplarValueField:
+						e.Value = math.Float64frombits(f.value)
+					case v2ExemplarTimestampField:
+						e.Timestamp = int64(f.value)
+					}
+				}
+				ts.Exemplars = append(ts.Exemplars, e)
+			case v2TimeSeriesMetadataField:
+				ts.Metadata = &metricMetadata{}
+				for _, f := range nested {
+					switch f.number {
+					case v2MetadataTypeField:
+						ts.Metadata.Type = metricType(f.value)
+					case v2MetadataHelpRefField:
+						ts.Metadata.Help = symbol(f.value)
+					case v2MetadataUnitRefField:
+						ts.Metadata.Unit = symbol(f.value)
+					}
+				}
+			default:
+				t.Fatalf("unexpected time series field %d", field.number)
+			}
+		}
+		series = append(series, ts)
+	}
+	return series
+}
+
+// getProtocolRequest returns a marshaled remote write 1.0 WriteRequest with a histogram bucket carrying an exemplar,
+// a gauge, and the metadata of the histogram.
+func getProtocolRequest(t *testing.T) []byte {
+	bucket := getTimeSeries(getPromLabels(nameStr, "latency_bucket", leStr, "5", "service", "api"),
+		getSample(3, msTime1), getSample(4, msTime1+1))
+	set := newExemplarSet(ExemplarSettings{Enabled: true})
+	set.add("histogram", bucket.Labels, []exemplar{
+		{Labels: getPromLabels(traceIDLabel, "0102", spanIDLabel, "03"), Value: 2.5, Timestamp: msTime1},
+	})
+	set.attach(map[string]*prompb.TimeSeries{timeSeriesSignature("histogram", &bucket.Labels): bucket})
+	gauge := getTimeSeries(getPromLabels(nameStr, "temperature", "service", "api"), getSample(20, msTime1))
+
+	data, err := marshalWriteRequest(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{*bucket, *gauge}},
+		[]*metricMetadata{{Type: metricTypeHistogram, MetricFamilyName: "latency", Help: "Request latency.", Unit: "s"}})
+	require.NoError(t, err)
+	return data
+}
+
+// Test_marshalWriteRequestV2 checks that labels, Samples, exemplars and the metadata of their metric family are kept
+// when converting a remote write 1.0 WriteRequest to a remote write 2.0 Request.
+func Test_marshalWriteRequestV2(t *testing.T) {
+	data, samples, err := marshalWriteRequestV2(getProtocolRequest(t))
+	require.NoError(t, err)
+	assert.Equal(t, 3, samples)
+	assert.Equal(t, []v2TimeSeries{
+		{
+			Labels:  getPromLabels(nameStr, "latency_bucket", leStr, "5", "service", "api"),
+			Samples: []prompb.Sample{getSample(3, msTime1), getSample(4, msTime1+1)},
+			Exemplars: []exemplar{
+				{Labels: getPromLabels(traceIDLabel, "0102", spanIDLabel, "03"), Value: 2.5, Timestamp: msTime1},
+			},
+			Metadata: &metricMetadata{Type: metricTypeHistogram, Help: "Request latency.", Unit: "s"},
+		},
+		{
+			Labels:  getPromLabels(nameStr, "temperature", "service", "api"),
+			Samples: []prompb.Sample{getSample(20, msTime1)},
+		},
+	}, decodeV2Request(t, data))
+
+	_, _, err = marshalWriteRequestV2([]byte{0xff})
+	assert.Error(t, err)
+}
+
+// protocolServer is a remote write endpoint decoding requests of the protocols it supports.
+type protocolServer struct {
+	*httptest.Server
+	t         *testing.T
+	supported map[ProtocolSettings]bool
+	// legacy indicates whether the endpoint acknowledges remote write 2.0 requests like a remote write 1.0 endpoint
+	legacy bool
+
+	mu       sync.Mutex
+	received []ProtocolSettings
+	v1       []*prompb.WriteRequest
+	v2       [][]v2TimeSeries
+}
+
+func newProtocolServer(t *testing.T, legacy bool, supported ...ProtocolSettings) *protocolServer {
+	s := &protocolServer{t: t, legacy: legacy, supported: map[ProtocolSettings]bool{}}
+	for _, settings := range supported {
+		s.supported[settings] = true
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *protocolServer) handle(w http.ResponseWriter, r *http.Request) {
+	t := s.t
+	settings := ProtocolSettings{Version: ProtocolVersion1, Compression: r.Header.Get("Content-Encoding")}
+	if r.Header.Get(remoteWriteVersionHeader) == "2.0.0" {
+		settings.Version = ProtocolVersion2
+	}
+	assert.Equal(t, contentType(settings.Version), r.Header.Get("Content-Type"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.received = append(s.received, settings)
+	if !s.supported[settings] && !(s.legacy && settings.Version == ProtocolVersion2) {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	require.NoError(t, err)
+	var data []byte
+	switch settings.Compression {
+	case CompressionSnappy:
+		data, err = snappy.Decode(nil, body)
+	case CompressionGzip:
+		var reader *gzip.Reader
+		if reader, err = gzip.NewReader(bytes.NewReader(body)); err == nil {
+			data, err = ioutil.ReadAll(reader)
+		}
+	case CompressionZstd:
+		var decoder *zstd.Decoder
+		if decoder, err = zstd.NewReader(nil); err == nil {
+			data, err = decoder.DecodeAll(body, nil)
+			decoder.Close()
+		}
+	}
+	require.NoError(t, err)
+
+	if settings.Version == ProtocolVersion2 && !s.legacy {
+		s.v2 = append(s.v2, decodeV2Request(t, data))
+		w.Header().Set(samplesWrittenHeader, "3")
+	} else {
+		req := &prompb.WriteRequest{}
+		require.NoError(t, proto.Unmarshal(data, req))
+		s.v1 = append(s.v1, req)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Test_sendProtocols checks that requests are sent with the configured protocol version and compression.
+func Test_sendProtocols(t *testing.T) {
+	for _, version := range []string{ProtocolVersion1, ProtocolVersion2} {
+		for _, compression := range []string{CompressionSnappy, CompressionGzip, CompressionZstd} {
+			settings := ProtocolSettings{Version: version, Compression: compression}
+			t.Run(version+"_"+compression, func(t *testing.T) {
+				server := newProtocolServer(t, false, settings)
+				defer server.Close()
+				prwe, err := NewPrwExporter("", server.URL, http.DefaultClient, WithProtocol(settings))
+				require.NoError(t, err)
+
+				require.NoError(t, prwe.send(context.Background(), "", getProtocolRequest(t)))
+				assert.Equal(t, []ProtocolSettings{settings}, server.received)
+				if version == ProtocolVersion2 {
+					require.Len(t, server.v2, 1)
+					assert.Len(t, server.v2[0], 2)
+					return
+				}
+				require.Len(t, server.v1, 1)
+				assert.Len(t, server.v1[0].Timeseries, 2)
+			})
+		}
+	}
+}
+
+// Test_sendProtocolFallback checks that requests are sent with remote write 1.0 and Snappy compression once the
+// endpoint does not support the configured protocol.
+func Test_sendProtocolFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings ProtocolSettings
+		legacy   bool
+	}{
+		{"unsupported_compression", ProtocolSettings{Version: ProtocolVersion1, Compression: CompressionZstd}, false},
+		{"unsupported_version", ProtocolSettings{Version: ProtocolVersion2, Compression: CompressionSnappy}, false},
+		{"legacy_endpoint", ProtocolSettings{Version: ProtocolVersion2, Compression: CompressionSnappy}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newProtocolServer(t, tt.legacy, baseProtocol)
+			defer server.Close()
+			prwe, err := NewPrwExporter("", server.URL, http.DefaultClient, WithProtocol(tt.settings))
+			require.NoError(t, err)
+
+			require.NoError(t, prwe.send(context.Background(), "", getProtocolRequest(t)))
+			require.NoError(t, prwe.send(context.Background(), "", getProtocolRequest(t)))
+			assert.Equal(t, []ProtocolSettings{tt.settings, baseProtocol, baseProtocol}, server.received)
+			if tt.legacy {
+				// the remote write 2.0 request was acknowledged without writing anything
+				require.Len(t, server.v1, 3)
+				assert.Empty(t, server.v1[0].Timeseries)
+				return
+			}
+			require.Len(t, server.v1, 2)
+			assert.Len(t, server.v1[0].Timeseries, 2)
+		})
+	}
+
+	// the base protocol does not fall back
+	server := newProtocolServer(t, false)
+	defer server.Close()
+	prwe, err := NewPrwExporter("", server.URL, http.DefaultClient)
+	require.NoError(t, err)
+	assert.Error(t, prwe.send(context.Background(), "", getProtocolRequest(t)))
+	assert.Equal(t, []ProtocolSettings{baseProtocol}, server.received)
+}
+
+// Test_ProtocolSettingsValidate checks that versions and compressions must be known, and that the headers set for them
+// cannot be overridden.
+func Test_ProtocolSettingsValidate(t *testing.T) {
+	assert.NoError(t, CreateDefaultProtocolSettings().Validate(
+		map[string]string{"x-prometheus-remote-write-version": "0.1.0"}))
+	assert.NoError(t, ProtocolSettings{Version: ProtocolVersion2, Compression: CompressionZstd}.Validate(nil))
+	assert.Error(t, ProtocolSettings{Version: "3.0", Compression: CompressionSnappy}.Validate(nil))
+	assert.Error(t, ProtocolSettings{Version: ProtocolVersion1, Compression: "lz4"}.Validate(nil))
+	assert.Error(t, ProtocolSettings{Version: ProtocolVersion1, Compression: CompressionGzip}.Validate(
+		map[string]string{"content-encoding": "snappy"}))
+	assert.Error(t, ProtocolSettings{Version: ProtocolVersion2, Compression: CompressionSnappy}.Validate(
+		map[string]string{"Content-Type": "application/x-protobuf"}))
+
+	_, err := newProtocolNegotiator(ProtocolSettings{Version: "3.0"}, zap.NewNop())
+	assert.Error(t, err)
+}
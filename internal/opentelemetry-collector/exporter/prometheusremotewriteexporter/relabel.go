@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/relabel"
+	"github.com/prometheus/prometheus/prompb"
+	"go.opencensus.io/stats"
+	"gopkg.in/yaml.v2"
+)
+
+// ParseWriteRelabelConfigs parses the write_relabel_configs setting as decoded by viper, with the YAML unmarshaling
+// of Prometheus which applies the defaults of each relabel config and validates it.
+func ParseWriteRelabelConfigs(raw interface{}) ([]*relabel.Config, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	out, err := yaml.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal write_relabel_configs to yaml: %w", err)
+	}
+	var cfgs []*relabel.Config
+	if err = yaml.UnmarshalStrict(out, &cfgs); err != nil {
+		return nil, fmt.Errorf("failed to parse write_relabel_configs: %w", err)
+	}
+	for _, cfg := range cfgs {
+		if cfg == nil {
+			return nil, errors.New("failed to parse write_relabel_configs: empty relabel config")
+		}
+	}
+	return cfgs, nil
+}
+
+// relabelTimeSeries applies the write relabel configs to the labels of each TimeSeries of tsMap, and returns the
+// relabeled TimeSeries keyed by their new signature. TimeSeries dropped by the configs are recorded, and TimeSeries
+// ending up with the same labels are merged. The internal tenant label is kept out of relabeling.
+func (prwe *PrwExporter) relabelTimeSeries(tsMap map[string]*prompb.TimeSeries) map[string]*prompb.TimeSeries {
+	if len(prwe.relabelConfigs) == 0 {
+		return tsMap
+	}
+	relabeled := make(map[string]*prompb.TimeSeries, len(tsMap))
+	dropped := 0
+	for sig, ts := range tsMap {
+		lbs, ok := relabelLabels(ts.Labels, prwe.relabelConfigs)
+		if !ok {
+			dropped++
+			continue
+		}
+		ts.Labels = lbs
+		newSig := timeSeriesSignature(signatureKind(sig), &ts.Labels)
+		if existing, ok := relabeled[newSig]; ok {
+			existing.Samples = append(existing.Samples, ts.Samples...)
+			existing.XXX_unrecognized = append(existing.XXX_unrecognized, ts.XXX_unrecognized...)
+			continue
+		}
+		relabeled[newSig] = ts
+	}
+	if dropped > 0 {
+		stats.Record(prwe.metricsCtx, mRelabelDroppedSeries.M(int64(dropped)))
+	}
+	return relabeled
+}
+
+// relabelLabels applies cfgs to lbs with the semantics of Prometheus. It returns false if the TimeSeries is dropped,
+// or left without a metric name.
+func relabelLabels(lbs []prompb.Label, cfgs []*relabel.Config) ([]prompb.Label, bool) {
+	var tenant *prompb.Label
+	promLabels := make(labels.Labels, 0, len(lbs))
+	for i := range lbs {
+		if lbs[i].Name == tenantLabel {
+			tenant = &lbs[i]
+			continue
+		}
+		promLabels = append(promLabels, labels.Label{Name: lbs[i].Name, Value: lbs[i].Value})
+	}
+	promLabels = relabel.Process(promLabels, cfgs...)
+	if promLabels == nil || promLabels.Get(nameStr) == "" {
+		return nil, false
+	}
+
+	relabeled := make([]prompb.Label, 0, len(promLabels)+1)
+	for _, l := range promLabels {
+		relabeled = append(relabeled, prompb.Label{Name: l.Name, Value: l.Value})
+	}
+	if tenant != nil {
+		relabeled = append(relabeled, *tenant)
+	}
+	return relabeled, true
+}
+
+// signatureKind returns the kind of the TimeSeries of the signature sig, as built by timeSeriesSignature.
+func signatureKind(sig string) string {
+	if i := strings.IndexByte(sig, '-'); i >= 0 {
+		return sig[:i]
+	}
+	return sig
+}
@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/relabel"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/consumer/pdatautil"
+)
+
+// Test_ParseWriteRelabelConfigs checks that relabel configs are validated with the YAML unmarshaling of Prometheus.
+func Test_ParseWriteRelabelConfigs(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         interface{}
+		returnError bool
+	}{
+		{"unset", nil, false},
+		{"valid", []interface{}{map[interface{}]interface{}{"regex": "k8s_.*", "action": "labeldrop"}}, false},
+		{"unknown_action", []interface{}{map[interface{}]interface{}{"action": "rename"}}, true},
+		{"invalid_regex", []interface{}{map[interface{}]interface{}{"regex": "(", "action": "drop"}}, true},
+		{"unknown_field", []interface{}{map[interface{}]interface{}{"source": "instance"}}, true},
+		{"hashmod_without_modulus", []interface{}{map[interface{}]interface{}{"target_label": "shard",
+			"action": "hashmod"}}, true},
+		{"empty", []interface{}{nil}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWriteRelabelConfigs(tt.raw)
+			if tt.returnError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// Test_relabelTimeSeries checks that relabel configs are applied to each TimeSeries, that dropped TimeSeries are
+// removed, that TimeSeries ending up with the same labels are merged, and that the tenant label is left untouched.
+func Test_relabelTimeSeries(t *testing.T) {
+	dropGo := &relabel.Config{
+		SourceLabels: []model.LabelName{nameStr},
+		Regex:        relabel.MustNewRegexp("go_.*"),
+		Action:       relabel.Drop,
+		Separator:    ";",
+	}
+	dropPod := &relabel.Config{
+		Regex:  relabel.MustNewRegexp("pod"),
+		Action: relabel.LabelDrop,
+	}
+	dropAll := &relabel.Config{
+		Regex:  relabel.MustNewRegexp(".*"),
+		Action: relabel.LabelDrop,
+	}
+
+	tsMap := func() map[string]*prompb.TimeSeries {
+		series := []*prompb.TimeSeries{
+			getTimeSeries(getPromLabels(nameStr, "go_goroutines"), getSample(1, msTime1)),
+			getTimeSeries(getPromLabels(nameStr, "requests", "pod", "a", tenantLabel, "team"), getSample(1, msTime1)),
+			getTimeSeries(getPromLabels(nameStr, "requests", "pod", "b", tenantLabel, "team"), getSample(2, msTime2)),
+		}
+		m := map[string]*prompb.TimeSeries{}
+		for _, ts := range series {
+			m[timeSeriesSignature(typeIntGauge, &ts.Labels)] = ts
+		}
+		return m
+	}
+
+	prwe, err := NewPrwExporter("", "http://localhost", http.DefaultClient)
+	require.NoError(t, err)
+	assert.Len(t, prwe.relabelTimeSeries(tsMap()), 3)
+
+	prwe.relabelConfigs = []*relabel.Config{dropGo, dropPod}
+	relabeled := prwe.relabelTimeSeries(tsMap())
+	labels := getPromLabels(nameStr, "requests", tenantLabel, "team")
+	sig := timeSeriesSignature(typeIntGauge, &labels)
+	require.Len(t, relabeled, 1)
+	require.Contains(t, relabeled, sig)
+	assert.Equal(t, labels, relabeled[sig].Labels)
+	assert.ElementsMatch(t, []prompb.Sample{getSample(1, msTime1), getSample(2, msTime2)}, relabeled[sig].Samples)
+
+	// TimeSeries left without a metric name are dropped
+	prwe.relabelConfigs = []*relabel.Config{dropAll}
+	assert.Empty(t, prwe.relabelTimeSeries(tsMap()))
+}
+
+// Test_PushMetricsRelabelDropAll checks that a batch whose TimeSeries are all dropped by the write relabel configs is
+// not sent, and is not reported as dropped.
+func Test_PushMetricsRelabelDropAll(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dropAll := &relabel.Config{
+		SourceLabels: []model.LabelName{nameStr},
+		Regex:        relabel.MustNewRegexp(".*"),
+		Action:       relabel.Drop,
+		Separator:    ";",
+	}
+	md := pdatautil.MetricsFromInternalMetrics(getInternalMetrics(
+		getDoubleGaugeMetric("gauge", getDoublePoint(lbs1, 1, time1)),
+		getIntGaugeMetric("int_gauge", getIntPoint(lbs2, 2, time2)),
+	))
+
+	prwe, err := NewPrwExporter("", server.URL, http.DefaultClient,
+		WithWriteRelabelConfigs([]*relabel.Config{dropAll}))
+	require.NoError(t, err)
+	dropped, err := prwe.PushMetrics(context.Background(), md)
+	require.NoError(t, err)
+	assert.Equal(t, 0, dropped)
+	assert.Zero(t, atomic.LoadInt32(&requests))
+}
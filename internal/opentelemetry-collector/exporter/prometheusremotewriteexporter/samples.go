@@ -0,0 +1,65 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"sort"
+
+	"github.com/prometheus/prometheus/prompb"
+	"go.opencensus.io/stats"
+	"go.uber.org/zap"
+)
+
+// sortSamples sorts the Samples of each TimeSeries of tsMap by timestamp, and drops the Samples with the same
+// timestamp as another Sample of their TimeSeries according to the duplicate policy. The dropped Samples are logged
+// and recorded rather than failing the batch.
+func (prwe *PrwExporter) sortSamples(tsMap map[string]*prompb.TimeSeries) {
+	dropped := 0
+	for sig, ts := range tsMap {
+		n := sortTimeSeriesSamples(ts, prwe.duplicateSettings.Policy)
+		if n > 0 {
+			prwe.logger.Debug("Dropping duplicate Samples", zap.String("series", sig), zap.Int("samples", n))
+			dropped += n
+		}
+	}
+	if dropped > 0 {
+		stats.Record(prwe.metricsCtx, mDuplicateSamples.M(int64(dropped)))
+	}
+}
+
+// sortTimeSeriesSamples sorts the Samples of ts by timestamp, keeping the order in which Samples with the same
+// timestamp were added, and keeps only the first or last of them according to policy. It returns the number of
+// Samples dropped.
+func sortTimeSeriesSamples(ts *prompb.TimeSeries, policy string) int {
+	samples := ts.Samples
+	if len(samples) < 2 {
+		return 0
+	}
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].Timestamp < samples[j].Timestamp })
+
+	kept := samples[:1]
+	for _, sample := range samples[1:] {
+		last := &kept[len(kept)-1]
+		if sample.Timestamp != last.Timestamp {
+			kept = append(kept, sample)
+			continue
+		}
+		if policy != DuplicatePolicyFirstWins {
+			*last = sample
+		}
+	}
+	ts.Samples = kept
+	return len(samples) - len(kept)
+}
@@ -0,0 +1,133 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+
+	"go.opentelemetry.io/collector/consumer/pdatautil"
+)
+
+// Test_sortTimeSeriesSamples checks that Samples are sorted by timestamp, and that only the first or last of the
+// Samples with the same timestamp is kept according to the duplicate policy.
+func Test_sortTimeSeriesSamples(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		samples []prompb.Sample
+		want    []prompb.Sample
+		dropped int
+	}{
+		{
+			"sorted",
+			DuplicatePolicyLastWins,
+			[]prompb.Sample{getSample(1, 1), getSample(2, 2)},
+			[]prompb.Sample{getSample(1, 1), getSample(2, 2)},
+			0,
+		},
+		{
+			"out_of_order",
+			DuplicatePolicyLastWins,
+			[]prompb.Sample{getSample(3, 3), getSample(1, 1), getSample(2, 2)},
+			[]prompb.Sample{getSample(1, 1), getSample(2, 2), getSample(3, 3)},
+			0,
+		},
+		{
+			"last_wins",
+			DuplicatePolicyLastWins,
+			[]prompb.Sample{getSample(2, 2), getSample(1, 1), getSample(3, 2), getSample(4, 2)},
+			[]prompb.Sample{getSample(1, 1), getSample(4, 2)},
+			2,
+		},
+		{
+			"first_wins",
+			DuplicatePolicyFirstWins,
+			[]prompb.Sample{getSample(2, 2), getSample(1, 1), getSample(3, 2), getSample(4, 2)},
+			[]prompb.Sample{getSample(1, 1), getSample(2, 2)},
+			2,
+		},
+		{
+			"default_policy",
+			"",
+			[]prompb.Sample{getSample(1, 1), getSample(2, 1)},
+			[]prompb.Sample{getSample(2, 1)},
+			1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := getTimeSeries(getPromLabels(nameStr, "a"), tt.samples...)
+			assert.Equal(t, tt.dropped, sortTimeSeriesSamples(ts, tt.policy))
+			assert.Equal(t, tt.want, ts.Samples)
+		})
+	}
+}
+
+// Test_PushMetricsDuplicates checks that Samples of the same TimeSeries are sent in order and without duplicate
+// timestamps, without failing the batch, and that the dropped Samples are recorded for the exporter.
+func Test_PushMetricsDuplicates(t *testing.T) {
+	views := MetricViews()
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	var mu sync.Mutex
+	var sent []prompb.TimeSeries
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		dest, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		wr := &prompb.WriteRequest{}
+		require.NoError(t, proto.Unmarshal(dest, wr))
+
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, wr.Timeseries...)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// the points of two merged batches
+	md := pdatautil.MetricsFromInternalMetrics(getInternalMetrics(
+		getDoubleGaugeMetric("gauge", getDoublePoint(lbs1, 2, time1+2000000), getDoublePoint(lbs1, 1, time1)),
+		getDoubleGaugeMetric("gauge", getDoublePoint(lbs1, 3, time1+2000000)),
+	))
+
+	const name = "duplicates"
+	prwe, err := NewPrwExporter("", server.URL, http.DefaultClient, WithName(name),
+		WithDuplicates(DuplicateSettings{Policy: DuplicatePolicyFirstWins}))
+	require.NoError(t, err)
+	dropped, err := prwe.PushMetrics(context.Background(), md)
+	require.NoError(t, err)
+	assert.Equal(t, 0, dropped)
+	assert.Equal(t, &view.SumData{Value: 1}, getViewRows(t, "duplicate_samples", name)[""])
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, sent, 1)
+	assert.Equal(t, []prompb.Sample{getSample(1, msTime1), getSample(2, msTime1+2)}, sent[0].Samples)
+}
@@ -0,0 +1,189 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"errors"
+	"hash/fnv"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/prometheus/prometheus/prompb"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+)
+
+const (
+	// shardTargetDuration is the time each shard should take to send its requests of a single export. The number of
+	// shards grows when sending takes longer, and shrinks when it takes shorter.
+	shardTargetDuration = 1 * time.Second
+	// shardEWMAWeight is the weight of the latest export in the moving averages used to compute the number of shards.
+	shardEWMAWeight = 0.2
+	// shardTolerance is the relative difference between the desired and current number of shards below which no
+	// resharding happens, so that the number of shards does not flap.
+	shardTolerance = 0.3
+)
+
+// shardManager keeps track of the number of shards that exported TimeSeries are split into, and adjusts it based on
+// the observed send latency, in a similar way to the queue manager of Prometheus.
+type shardManager struct {
+	settings ShardSettings
+	logger   *zap.Logger
+
+	mu sync.Mutex
+	// num is the current number of shards
+	num int
+	// requests is the moving average of the number of requests of an export
+	requests float64
+	// latency is the moving average of the time taken to send a single request
+	latency float64
+}
+
+// newShardManager returns a shardManager starting with the minimum number of shards. Invalid settings are replaced by
+// a single shard.
+func newShardManager(settings ShardSettings, logger *zap.Logger) *shardManager {
+	if settings.MinShards < 1 {
+		settings.MinShards = 1
+	}
+	if settings.MaxShards < settings.MinShards {
+		settings.MaxShards = settings.MinShards
+	}
+	return &shardManager{
+		settings: settings,
+		logger:   logger,
+		num:      settings.MinShards,
+	}
+}
+
+// numShards returns the current number of shards.
+func (sm *shardManager) numShards() int {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.num
+}
+
+// observe records that an export sent the given number of requests, each of which took latency on average, and
+// reshards if the number of shards needed to send them within shardTargetDuration is different enough from the
+// current one.
+func (sm *shardManager) observe(requests int, latency time.Duration) {
+	if requests == 0 {
+		return
+	}
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.requests == 0 {
+		sm.requests, sm.latency = float64(requests), latency.Seconds()
+	} else {
+		sm.requests = shardEWMAWeight*float64(requests) + (1-shardEWMAWeight)*sm.requests
+		sm.latency = shardEWMAWeight*latency.Seconds() + (1-shardEWMAWeight)*sm.latency
+	}
+
+	desired := int(math.Ceil(sm.requests * sm.latency / shardTargetDuration.Seconds()))
+	if desired < sm.settings.MinShards {
+		desired = sm.settings.MinShards
+	}
+	if desired > sm.settings.MaxShards {
+		desired = sm.settings.MaxShards
+	}
+	if desired == sm.num || math.Abs(float64(desired-sm.num))/float64(sm.num) < shardTolerance {
+		return
+	}
+	sm.logger.Info("Resharding remote write", zap.Int("from", sm.num), zap.Int("to", desired),
+		zap.Duration("latency", time.Duration(sm.latency*float64(time.Second))))
+	sm.num = desired
+}
+
+// shardTimeSeries splits the TimeSeries of tsMap into numShards shards by the hash of their signature, so that the
+// samples of a TimeSeries are always sent in order by the same shard, and batches each shard into WriteRequests holding
+// at most maxSamples samples and maxBytes marshaled bytes. A limit of zero or less disables it. A TimeSeries with more
+// than maxSamples samples is split across requests; a single TimeSeries larger than maxBytes is sent on its own.
+func shardTimeSeries(tsMap map[string]*prompb.TimeSeries, numShards, maxSamples,
+	maxBytes int) ([][]*prompb.WriteRequest, error) {
+	if len(tsMap) == 0 {
+		return nil, consumererror.Permanent(errors.New("invalid TsMap: cannot be empty map"))
+	}
+	if numShards < 1 {
+		numShards = 1
+	}
+
+	batchers := make([]requestBatcher, numShards)
+	for sig, ts := range tsMap {
+		h := fnv.New64a()
+		h.Write([]byte(sig))
+		b := &batchers[h.Sum64()%uint64(numShards)]
+
+		samples := ts.Samples
+		// the encoded exemplars of the TimeSeries are sent with its first chunk
+		exemplars := ts.XXX_unrecognized
+		for {
+			chunk := samples
+			if maxSamples > 0 && len(chunk) > maxSamples {
+				chunk = samples[:maxSamples]
+			}
+			b.add(prompb.TimeSeries{Labels: ts.Labels, Samples: chunk, XXX_unrecognized: exemplars}, maxSamples, maxBytes)
+			exemplars = nil
+			samples = samples[len(chunk):]
+			if len(samples) == 0 {
+				break
+			}
+		}
+	}
+
+	shards := make([][]*prompb.WriteRequest, 0, numShards)
+	for i := range batchers {
+		batchers[i].flush()
+		if len(batchers[i].requests) > 0 {
+			shards = append(shards, batchers[i].requests)
+		}
+	}
+	return shards, nil
+}
+
+// requestBatcher appends TimeSeries to a WriteRequest until adding another one would exceed a size limit.
+type requestBatcher struct {
+	requests []*prompb.WriteRequest
+	current  *prompb.WriteRequest
+	samples  int
+	bytes    int
+}
+
+func (b *requestBatcher) add(ts prompb.TimeSeries, maxSamples, maxBytes int) {
+	// the size of a TimeSeries in a WriteRequest includes its field tag and length prefix
+	size := ts.Size()
+	size += 1 + proto.SizeVarint(uint64(size))
+
+	if b.current != nil &&
+		((maxSamples > 0 && b.samples+len(ts.Samples) > maxSamples) || (maxBytes > 0 && b.bytes+size > maxBytes)) {
+		b.flush()
+	}
+	if b.current == nil {
+		b.current = &prompb.WriteRequest{}
+	}
+	b.current.Timeseries = append(b.current.Timeseries, ts)
+	b.samples += len(ts.Samples)
+	b.bytes += size
+}
+
+func (b *requestBatcher) flush() {
+	if b.current == nil {
+		return
+	}
+	b.requests = append(b.requests, b.current)
+	b.current, b.samples, b.bytes = nil, 0, 0
+}
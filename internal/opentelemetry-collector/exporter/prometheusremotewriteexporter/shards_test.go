@@ -0,0 +1,143 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// getTsMap returns a map of n TimeSeries with distinct labels, each holding the given number of samples.
+func getTsMap(n, samples int) map[string]*prompb.TimeSeries {
+	tsMap := map[string]*prompb.TimeSeries{}
+	for i := 0; i < n; i++ {
+		labels := getPromLabels(label11, strconv.Itoa(i))
+		ts := getTimeSeries(labels)
+		for j := 0; j < samples; j++ {
+			ts.Samples = append(ts.Samples, getSample(floatVal1, int64(j)))
+		}
+		tsMap[timeSeriesSignature(typeIntGauge, &labels)] = ts
+	}
+	return tsMap
+}
+
+// Test_shardTimeSeries checks that TimeSeries are split into requests within the size limits, without losing samples.
+func Test_shardTimeSeries(t *testing.T) {
+	tests := []struct {
+		name       string
+		tsMap      map[string]*prompb.TimeSeries
+		numShards  int
+		maxSamples int
+		maxBytes   int
+		wantShards int
+		// maximum number of requests per shard, or 0 to skip the check
+		wantMaxRequests int
+		returnError     bool
+	}{
+		{"empty_map_case", map[string]*prompb.TimeSeries{}, 1, 0, 0, 0, 0, true},
+		{"no_limit_case", getTsMap(10, 2), 1, 0, 0, 1, 1, false},
+		{"sample_limit_case", getTsMap(10, 2), 1, 4, 0, 1, 5, false},
+		{"large_series_split_case", getTsMap(1, 10), 1, 3, 0, 1, 4, false},
+		{"byte_limit_case", getTsMap(10, 1), 1, 0, 1, 1, 10, false},
+		{"many_shards_case", getTsMap(100, 1), 4, 0, 0, 4, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shards, err := shardTimeSeries(tt.tsMap, tt.numShards, tt.maxSamples, tt.maxBytes)
+			if tt.returnError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, shards, tt.wantShards)
+
+			wantSamples := 0
+			for _, ts := range tt.tsMap {
+				wantSamples += len(ts.Samples)
+			}
+			gotSamples := 0
+			for _, requests := range shards {
+				if tt.wantMaxRequests > 0 {
+					assert.LessOrEqual(t, len(requests), tt.wantMaxRequests)
+				}
+				for _, req := range requests {
+					samples := 0
+					for _, ts := range req.Timeseries {
+						samples += len(ts.Samples)
+					}
+					if tt.maxSamples > 0 {
+						assert.LessOrEqual(t, samples, tt.maxSamples)
+					}
+					gotSamples += samples
+				}
+			}
+			assert.Equal(t, wantSamples, gotSamples)
+		})
+	}
+}
+
+// Test_shardTimeSeriesStable checks that a TimeSeries is always assigned to the same shard.
+func Test_shardTimeSeriesStable(t *testing.T) {
+	tsMap := getTsMap(50, 1)
+	shardOf := func() map[string]int {
+		shards, err := shardTimeSeries(tsMap, 8, 0, 0)
+		require.NoError(t, err)
+		m := map[string]int{}
+		for i, requests := range shards {
+			for _, req := range requests {
+				for _, ts := range req.Timeseries {
+					m[ts.Labels[0].Value] = i
+				}
+			}
+		}
+		return m
+	}
+	assert.Equal(t, shardOf(), shardOf())
+}
+
+// Test_shardManager checks that the number of shards follows the send latency within the configured bounds.
+func Test_shardManager(t *testing.T) {
+	sm := newShardManager(ShardSettings{MinShards: 2, MaxShards: 8}, zap.NewNop())
+	assert.Equal(t, 2, sm.numShards())
+
+	// 10 requests of 500ms need 5 shards to be sent within a second
+	sm.observe(10, 500*time.Millisecond)
+	assert.Equal(t, 5, sm.numShards())
+
+	// a slightly different latency does not reshard
+	sm.observe(10, 550*time.Millisecond)
+	assert.Equal(t, 5, sm.numShards())
+
+	for i := 0; i < 20; i++ {
+		sm.observe(10, 5*time.Second)
+	}
+	assert.Equal(t, 8, sm.numShards())
+
+	for i := 0; i < 50; i++ {
+		sm.observe(10, time.Millisecond)
+	}
+	assert.Equal(t, 2, sm.numShards())
+
+	invalid := newShardManager(ShardSettings{}, zap.NewNop())
+	assert.Equal(t, 1, invalid.numShards())
+	invalid.observe(100, time.Minute)
+	assert.Equal(t, 1, invalid.numShards())
+}
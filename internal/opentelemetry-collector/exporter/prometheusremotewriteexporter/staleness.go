@@ -0,0 +1,169 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/pkg/value"
+	"github.com/prometheus/prometheus/prompb"
+	"go.opencensus.io/stats"
+	"go.uber.org/zap"
+)
+
+// stalenessTracker keeps track of the TimeSeries exported, identified by their signature, to mark those that are no
+// longer exported stale. It is safe for concurrent use.
+type stalenessTracker struct {
+	settings   StalenessSettings
+	logger     *zap.Logger
+	metricsCtx context.Context
+	// now returns the current time, and is replaced in tests
+	now func() time.Time
+
+	mu     sync.Mutex
+	series map[string]*trackedSeries
+	// untracked is whether the maximum number of TimeSeries was reached since it was last logged
+	untracked bool
+}
+
+// trackedSeries is the state of an exported TimeSeries.
+type trackedSeries struct {
+	// labels are a copy of the labels of the TimeSeries, including the internal tenant label
+	labels []prompb.Label
+	// timestamp is the timestamp in ms of the last Sample exported, which the staleness marker must follow
+	timestamp int64
+	// updated is when the TimeSeries was last exported
+	updated time.Time
+}
+
+func newStalenessTracker(settings StalenessSettings, logger *zap.Logger,
+	metricsCtx context.Context) *stalenessTracker {
+	return &stalenessTracker{
+		settings:   settings,
+		logger:     logger,
+		metricsCtx: metricsCtx,
+		now:        time.Now,
+		series:     map[string]*trackedSeries{},
+	}
+}
+
+// observe records that the TimeSeries of tsMap are exported. It must be called before the TimeSeries are split by
+// tenant, which removes their internal tenant label, so that staleness markers are routed to the same tenant.
+func (t *stalenessTracker) observe(tsMap map[string]*prompb.TimeSeries) {
+	now := t.now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	untracked := 0
+	for sig, ts := range tsMap {
+		timestamp := int64(math.MinInt64)
+		for _, sample := range ts.Samples {
+			if sample.Timestamp > timestamp {
+				timestamp = sample.Timestamp
+			}
+		}
+		s, ok := t.series[sig]
+		if !ok {
+			if t.settings.MaxSeries > 0 && len(t.series) >= t.settings.MaxSeries {
+				untracked++
+				continue
+			}
+			labels := make([]prompb.Label, len(ts.Labels))
+			copy(labels, ts.Labels)
+			s = &trackedSeries{labels: labels, timestamp: timestamp}
+			t.series[sig] = s
+		}
+		if timestamp > s.timestamp {
+			s.timestamp = timestamp
+		}
+		s.updated = now
+	}
+	if untracked > 0 {
+		if !t.untracked {
+			t.logger.Warn("Maximum number of TimeSeries tracked for staleness reached, new TimeSeries will not be "+
+				"marked stale", zap.Int("max_series", t.settings.MaxSeries))
+			t.untracked = true
+		}
+		stats.Record(t.metricsCtx, mStalenessUntrackedSeries.M(int64(untracked)))
+	}
+	stats.Record(t.metricsCtx, mStalenessSeries.M(int64(len(t.series))))
+}
+
+// sweep stops keeping track of the TimeSeries that were not exported within the interval, and returns a TimeSeries
+// holding a staleness marker for each of them, keyed by signature. The marker is timestamped with the current time,
+// or right after the last Sample exported if it is later.
+func (t *stalenessTracker) sweep() map[string]*prompb.TimeSeries {
+	now := t.now()
+	nowMs := convertTimeStamp(uint64(now.UnixNano()))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stale := map[string]*prompb.TimeSeries{}
+	for sig, s := range t.series {
+		if now.Sub(s.updated) < t.settings.Interval {
+			continue
+		}
+		delete(t.series, sig)
+		timestamp := nowMs
+		if s.timestamp >= timestamp {
+			timestamp = s.timestamp + 1
+		}
+		stale[sig] = &prompb.TimeSeries{
+			Labels:  s.labels,
+			Samples: []prompb.Sample{{Value: math.Float64frombits(value.StaleNaN), Timestamp: timestamp}},
+		}
+	}
+	if len(t.series) < t.settings.MaxSeries {
+		t.untracked = false
+	}
+	stats.Record(t.metricsCtx, mStalenessSeries.M(int64(len(t.series))))
+	return stale
+}
+
+// sendStaleMarkers sends a staleness marker for each TimeSeries that was not exported within the staleness interval,
+// every half of the interval, so that TimeSeries are marked stale at most one and a half times the interval after
+// they were last exported.
+func (prwe *PrwExporter) sendStaleMarkers() {
+	defer prwe.bgWG.Done()
+	ticker := time.NewTicker(prwe.stalenessSettings.Interval / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-prwe.closeChan:
+			return
+		case <-ticker.C:
+		}
+		prwe.exportStaleMarkers(context.Background())
+	}
+}
+
+// exportStaleMarkers exports a staleness marker for each TimeSeries that was not exported within the staleness
+// interval. Markers that fail to be sent are not retried, as the TimeSeries then goes stale after the lookback delta.
+func (prwe *PrwExporter) exportStaleMarkers(ctx context.Context) {
+	stale := prwe.staleness.sweep()
+	if len(stale) == 0 {
+		return
+	}
+	if err := prwe.export(ctx, stale, nil); err != nil {
+		prwe.logger.Warn("Failed to send staleness markers", zap.Int("series", len(stale)), zap.Error(err))
+		return
+	}
+	stats.Record(prwe.staleness.metricsCtx, mStalenessMarkers.M(int64(len(stale))))
+}
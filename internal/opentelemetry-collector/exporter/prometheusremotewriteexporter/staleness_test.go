@@ -0,0 +1,146 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/pkg/value"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// Test_stalenessTracker checks that TimeSeries not exported within the interval are marked stale once, after their
+// last Sample, and that new TimeSeries are not tracked beyond the maximum number of TimeSeries.
+func Test_stalenessTracker(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	tracker := newStalenessTracker(StalenessSettings{Enabled: true, Interval: time.Minute, MaxSeries: 2},
+		zap.NewNop(), context.Background())
+	tracker.now = func() time.Time { return now }
+	nowMs := now.UnixNano() / int64(time.Millisecond)
+
+	tracker.observe(map[string]*prompb.TimeSeries{
+		"a": getTimeSeries(getPromLabels(nameStr, "a", tenantLabel, "team"), getSample(1, nowMs-2000),
+			getSample(2, nowMs-1000)),
+		// Samples timestamped in the future are followed by the marker
+		"b": getTimeSeries(getPromLabels(nameStr, "b"), getSample(1, nowMs+3600000)),
+	})
+	// beyond the maximum number of TimeSeries
+	tracker.observe(map[string]*prompb.TimeSeries{
+		"c": getTimeSeries(getPromLabels(nameStr, "c"), getSample(1, nowMs)),
+	})
+	assert.Empty(t, tracker.sweep())
+
+	now = now.Add(30 * time.Second)
+	tracker.observe(map[string]*prompb.TimeSeries{
+		"b": getTimeSeries(getPromLabels(nameStr, "b"), getSample(1, nowMs+3601000)),
+	})
+	now = now.Add(30 * time.Second)
+	stale := tracker.sweep()
+	require.Len(t, stale, 1)
+	require.Contains(t, stale, "a")
+	assert.Equal(t, getPromLabels(nameStr, "a", tenantLabel, "team"), stale["a"].Labels)
+	require.Len(t, stale["a"].Samples, 1)
+	assert.True(t, value.IsStaleNaN(stale["a"].Samples[0].Value))
+	assert.Equal(t, nowMs+60000, stale["a"].Samples[0].Timestamp)
+	// marked stale once
+	assert.Empty(t, tracker.sweep())
+
+	now = now.Add(30 * time.Second)
+	stale = tracker.sweep()
+	require.Contains(t, stale, "b")
+	assert.Equal(t, nowMs+3601001, stale["b"].Samples[0].Timestamp)
+	assert.Empty(t, tracker.series)
+}
+
+// Test_stalenessTrackerCopiesLabels checks that the labels kept for staleness markers are not changed when the labels
+// of exported TimeSeries are, as splitting TimeSeries by tenant does.
+func Test_stalenessTrackerCopiesLabels(t *testing.T) {
+	tracker := newStalenessTracker(StalenessSettings{Enabled: true, Interval: time.Minute}, zap.NewNop(),
+		context.Background())
+	ts := getTimeSeries(getPromLabels(nameStr, "a", tenantLabel, "team"), getSample(1, msTime1))
+	tracker.observe(map[string]*prompb.TimeSeries{"a": ts})
+	ts.Labels[0].Value = "changed"
+	assert.Equal(t, getPromLabels(nameStr, "a", tenantLabel, "team"), tracker.series["a"].labels)
+}
+
+// Test_PushMetricsStaleness checks that a staleness marker is sent for each TimeSeries that is no longer exported, to
+// the tenant of the TimeSeries.
+func Test_PushMetricsStaleness(t *testing.T) {
+	var mu sync.Mutex
+	var stale []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		dest, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		wr := &prompb.WriteRequest{}
+		require.NoError(t, proto.Unmarshal(dest, wr))
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, ts := range wr.Timeseries {
+			for _, sample := range ts.Samples {
+				if !value.IsStaleNaN(sample.Value) {
+					continue
+				}
+				for _, l := range ts.Labels {
+					assert.NotEqual(t, tenantLabel, l.Name)
+					if l.Name == nameStr {
+						stale = append(stale, l.Value+"/"+r.Header.Get("X-Scope-OrgID"))
+					}
+				}
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	prwe, err := NewPrwExporter("", server.URL, http.DefaultClient,
+		WithStaleness(StalenessSettings{Enabled: true, Interval: time.Minute}),
+		WithTenant(TenantSettings{Enabled: true, Label: "tenant", Default: "anonymous", Header: "X-Scope-OrgID"}))
+	require.NoError(t, err)
+	now := time.Now()
+	prwe.staleness.now = func() time.Time { return now }
+
+	_, err = prwe.PushMetrics(context.Background(), getTenantMetrics())
+	require.NoError(t, err)
+	prwe.exportStaleMarkers(context.Background())
+	assert.Empty(t, stale)
+
+	now = now.Add(time.Minute)
+	prwe.exportStaleMarkers(context.Background())
+	mu.Lock()
+	defer mu.Unlock()
+	assert.ElementsMatch(t, []string{"gauge_a/team-a", "gauge_a/team-b", "gauge_b/anonymous"}, stale)
+}
+
+// Test_NewPrwExporterStaleness checks that staleness markers require a positive interval.
+func Test_NewPrwExporterStaleness(t *testing.T) {
+	_, err := NewPrwExporter("", "http://localhost", http.DefaultClient,
+		WithStaleness(StalenessSettings{Enabled: true}))
+	assert.Error(t, err)
+	assert.NoError(t, StalenessSettings{Enabled: false}.Validate())
+}
@@ -0,0 +1,190 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"github.com/gogo/protobuf/proto"
+	"github.com/prometheus/prometheus/prompb"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+	tracetranslator "go.opentelemetry.io/collector/translator/trace"
+)
+
+// tenantLabel is the internal label holding the tenant attribute of the resource of a TimeSeries, until TimeSeries
+// are split by tenant.
+const tenantLabel = "__tenant__"
+
+// Field numbers of the envelope of write-ahead log records holding the WriteRequest of a tenant. They are prepended to
+// the marshaled WriteRequest, and are above the field numbers of WriteRequest so that records written without the
+// envelope are told apart.
+const (
+	walSamplesField = 14
+	walTenantField  = 15
+)
+
+// tenantRequest is a WriteRequest of a tenant, with the metadata sent along with it.
+type tenantRequest struct {
+	tenant   string
+	req      *prompb.WriteRequest
+	metadata []*metricMetadata
+}
+
+// createResourceLabels returns the labels of resource, with the internal tenant label if TimeSeries are routed to
+// tenants and resource has the tenant attribute.
+func (prwe *PrwExporter) createResourceLabels(resource pdata.Resource) []prompb.Label {
+	labels := createResourceLabels(resource, prwe.resourceSettings)
+	if !prwe.tenantSettings.Enabled || resource.IsNil() {
+		return labels
+	}
+	if value, ok := resource.Attributes().Get(prwe.tenantSettings.Label); ok {
+		if tenant := tracetranslator.AttributeValueToString(value, false); tenant != "" {
+			labels = append(labels, prompb.Label{Name: tenantLabel, Value: tenant})
+		}
+	}
+	return labels
+}
+
+// splitByTenant splits the TimeSeries of tsMap by tenant, and removes the internal tenant label from them. The tenant
+// of a TimeSeries is the value of its tenant label, otherwise the tenant attribute of its resource, otherwise the
+// default tenant. Without tenants, every TimeSeries belongs to the empty tenant.
+func (prwe *PrwExporter) splitByTenant(tsMap map[string]*prompb.TimeSeries) map[string]map[string]*prompb.TimeSeries {
+	if !prwe.tenantSettings.Enabled {
+		return map[string]map[string]*prompb.TimeSeries{"": tsMap}
+	}
+	// an empty map is kept so that exporting it fails as without tenants
+	if len(tsMap) == 0 {
+		return map[string]map[string]*prompb.TimeSeries{prwe.tenantSettings.Default: tsMap}
+	}
+
+	name := sanitize(prwe.tenantSettings.Label)
+	tenants := map[string]map[string]*prompb.TimeSeries{}
+	for sig, ts := range tsMap {
+		var pointTenant, resourceTenant string
+		labels := ts.Labels[:0]
+		for _, l := range ts.Labels {
+			if l.Name == tenantLabel {
+				resourceTenant = l.Value
+				continue
+			}
+			if l.Name == name {
+				pointTenant = l.Value
+			}
+			labels = append(labels, l)
+		}
+		ts.Labels = labels
+
+		tenant := pointTenant
+		if tenant == "" {
+			tenant = resourceTenant
+		}
+		if tenant == "" {
+			tenant = prwe.tenantSettings.Default
+		}
+		if tenants[tenant] == nil {
+			tenants[tenant] = map[string]*prompb.TimeSeries{}
+		}
+		tenants[tenant][sig] = ts
+	}
+	return tenants
+}
+
+// filterMetadata returns the metadata of the metric families of the TimeSeries of tsMap. Without tenants, every
+// exported TimeSeries is sent to the same tenant, so metadata is returned as is.
+func (prwe *PrwExporter) filterMetadata(metadata []*metricMetadata,
+	tsMap map[string]*prompb.TimeSeries) []*metricMetadata {
+	if !prwe.tenantSettings.Enabled || len(metadata) == 0 {
+		return metadata
+	}
+	names := map[string]struct{}{}
+	for _, ts := range tsMap {
+		for _, l := range ts.Labels {
+			if l.Name == nameStr {
+				names[l.Value] = struct{}{}
+				break
+			}
+		}
+	}
+	var filtered []*metricMetadata
+	for _, md := range metadata {
+		// the TimeSeries of histograms and summaries are named after their family with a suffix
+		for _, suffix := range []string{"", sumStr, countStr, bucketStr} {
+			if _, ok := names[md.MetricFamilyName+suffix]; ok {
+				filtered = append(filtered, md)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// countSamples returns the number of Samples of req.
+func countSamples(req *prompb.WriteRequest) int {
+	samples := 0
+	for _, ts := range req.Timeseries {
+		samples += len(ts.Samples)
+	}
+	return samples
+}
+
+// marshalTenantRecord prepends the tenant, empty if tenants are disabled, and the number of Samples of a marshaled
+// WriteRequest to data, to be written to the write-ahead log.
+func marshalTenantRecord(tenant string, samples int, data []byte) ([]byte, error) {
+	buf := proto.NewBuffer(make([]byte, 0, len(tenant)+len(data)+16))
+	if err := buf.EncodeVarint(walSamplesField<<3 | proto.WireVarint); err != nil {
+		return nil, err
+	}
+	if err := buf.EncodeVarint(uint64(samples)); err != nil {
+		return nil, err
+	}
+	if err := buf.EncodeVarint(walTenantField<<3 | proto.WireBytes); err != nil {
+		return nil, err
+	}
+	if err := buf.EncodeStringBytes(tenant); err != nil {
+		return nil, err
+	}
+	return append(buf.Bytes(), data...), nil
+}
+
+// unmarshalTenantRecord returns the tenant, the number of Samples and the marshaled WriteRequest of a record of the
+// write-ahead log. Records written without the envelope are returned as is, with the empty tenant and no Samples.
+func unmarshalTenantRecord(record []byte) (string, int, []byte) {
+	tenant, samples := "", 0
+	for len(record) > 0 {
+		key, n := proto.DecodeVarint(record)
+		if n == 0 {
+			break
+		}
+		switch key {
+		case walSamplesField<<3 | proto.WireVarint:
+			value, m := proto.DecodeVarint(record[n:])
+			if m == 0 {
+				return tenant, samples, record
+			}
+			samples = int(value)
+			record = record[n+m:]
+		case walTenantField<<3 | proto.WireBytes:
+			length, m := proto.DecodeVarint(record[n:])
+			end := n + m + int(length)
+			if m == 0 || end > len(record) {
+				return tenant, samples, record
+			}
+			tenant = string(record[n+m : end])
+			record = record[end:]
+		default:
+			return tenant, samples, record
+		}
+	}
+	return tenant, samples, record
+}
@@ -0,0 +1,224 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/consumer/pdatautil"
+	"go.opentelemetry.io/collector/internal/data"
+	commonpb "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/common/v1"
+	otlpmetrics "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1"
+	otlpresource "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/resource/v1"
+)
+
+// Test_splitByTenant checks that TimeSeries are split by the tenant of their label, then of their resource, then the
+// default tenant, and that the internal tenant label is removed.
+func Test_splitByTenant(t *testing.T) {
+	prwe, err := NewPrwExporter("", "http://localhost", http.DefaultClient,
+		WithTenant(TenantSettings{Enabled: true, Label: "org.id", Default: "default"}))
+	require.NoError(t, err)
+
+	tsMap := map[string]*prompb.TimeSeries{
+		"point": getTimeSeries(getPromLabels(nameStr, "a", "org_id", "point", tenantLabel, "resource"),
+			getSample(1, msTime1)),
+		"resource": getTimeSeries(getPromLabels(nameStr, "b", tenantLabel, "resource"), getSample(2, msTime1)),
+		"default":  getTimeSeries(getPromLabels(nameStr, "c"), getSample(3, msTime1)),
+	}
+	tenants := prwe.splitByTenant(tsMap)
+	assert.Equal(t, map[string]map[string]*prompb.TimeSeries{
+		"point": {
+			"point": getTimeSeries(getPromLabels(nameStr, "a", "org_id", "point"), getSample(1, msTime1)),
+		},
+		"resource": {
+			"resource": getTimeSeries(getPromLabels(nameStr, "b"), getSample(2, msTime1)),
+		},
+		"default": {
+			"default": getTimeSeries(getPromLabels(nameStr, "c"), getSample(3, msTime1)),
+		},
+	}, tenants)
+
+	// without tenants, every TimeSeries belongs to the empty tenant
+	prwe, err = NewPrwExporter("", "http://localhost", http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]*prompb.TimeSeries{"": tsMap}, prwe.splitByTenant(tsMap))
+}
+
+// Test_filterMetadata checks that the metadata of a tenant only holds the metric families of its TimeSeries.
+func Test_filterMetadata(t *testing.T) {
+	prwe, err := NewPrwExporter("", "http://localhost", http.DefaultClient,
+		WithTenant(TenantSettings{Enabled: true, Label: "tenant"}))
+	require.NoError(t, err)
+
+	metadata := []*metricMetadata{
+		{MetricFamilyName: "counter_total"},
+		{MetricFamilyName: "histogram"},
+		{MetricFamilyName: "other"},
+	}
+	tsMap := map[string]*prompb.TimeSeries{
+		"counter": getTimeSeries(getPromLabels(nameStr, "counter_total")),
+		"bucket":  getTimeSeries(getPromLabels(nameStr, "histogram"+bucketStr, leStr, pInfStr)),
+	}
+	assert.Equal(t, metadata[:2], prwe.filterMetadata(metadata, tsMap))
+}
+
+// Test_tenantRecord checks that the tenant and the number of Samples of a write-ahead log record are read back, and
+// that records written without tenants are returned as is.
+func Test_tenantRecord(t *testing.T) {
+	req := &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		*getTimeSeries(getPromLabels(label11, value11), getSample(1, msTime1), getSample(2, msTime2)),
+	}}
+	data, err := marshalWriteRequest(req, []*metricMetadata{{MetricFamilyName: "a"}})
+	require.NoError(t, err)
+
+	record, err := marshalTenantRecord("tenant", countSamples(req), data)
+	require.NoError(t, err)
+	tenant, samples, got := unmarshalTenantRecord(record)
+	assert.Equal(t, "tenant", tenant)
+	assert.Equal(t, 2, samples)
+	assert.Equal(t, data, got)
+
+	tenant, samples, got = unmarshalTenantRecord(data)
+	assert.Equal(t, "", tenant)
+	assert.Equal(t, 0, samples)
+	assert.Equal(t, data, got)
+}
+
+// getTenantMetrics returns metrics of two resources, the first of which has the tenant attribute set to team-a and a
+// data point overriding it with the tenant label set to team-b.
+func getTenantMetrics() pdata.Metrics {
+	ts := uint64(time.Now().UnixNano())
+	return pdatautil.MetricsFromInternalMetrics(data.MetricDataFromOtlp([]*otlpmetrics.ResourceMetrics{
+		{
+			Resource: &otlpresource.Resource{Attributes: []*commonpb.KeyValue{{
+				Key:   "tenant",
+				Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "team-a"}},
+			}}},
+			InstrumentationLibraryMetrics: []*otlpmetrics.InstrumentationLibraryMetrics{{
+				Metrics: []*otlpmetrics.Metric{
+					getIntGaugeMetric("gauge_a", getIntPoint(lbs1, intVal1, ts),
+						getIntPoint(getLabels("tenant", "team-b"), intVal2, ts)),
+				},
+			}},
+		},
+		{
+			InstrumentationLibraryMetrics: []*otlpmetrics.InstrumentationLibraryMetrics{{
+				Metrics: []*otlpmetrics.Metric{getIntGaugeMetric("gauge_b", getIntPoint(lbs1, intVal1, ts))},
+			}},
+		},
+	}))
+}
+
+// Test_PushMetricsTenants checks that the TimeSeries of each tenant are sent in separate requests with the tenant
+// header, along with the metadata of their metric families, both directly and through the write-ahead log.
+func Test_PushMetricsTenants(t *testing.T) {
+	tests := []struct {
+		name string
+		wal  bool
+	}{
+		{"direct_case", false},
+		{"wal_case", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			series := map[string][]string{}
+			metadata := map[string][]string{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				require.NoError(t, err)
+				dest, err := snappy.Decode(nil, body)
+				require.NoError(t, err)
+				wr := &prompb.WriteRequest{}
+				require.NoError(t, proto.Unmarshal(dest, wr))
+
+				mu.Lock()
+				defer mu.Unlock()
+				tenant := r.Header.Get("X-Scope-OrgID")
+				for _, ts := range wr.Timeseries {
+					for _, l := range ts.Labels {
+						assert.NotEqual(t, tenantLabel, l.Name)
+						if l.Name == nameStr {
+							series[tenant] = append(series[tenant], l.Value)
+						}
+					}
+				}
+				for _, md := range decodeMetadata(t, wr) {
+					metadata[tenant] = append(metadata[tenant], md.MetricFamilyName)
+				}
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer server.Close()
+
+			options := []Option{
+				WithMetadata(MetadataSettings{Enabled: true}),
+				WithTenant(TenantSettings{Enabled: true, Label: "tenant", Default: "anonymous",
+					Header: "X-Scope-OrgID"}),
+			}
+			if tt.wal {
+				dir, err := ioutil.TempDir("", "wal")
+				require.NoError(t, err)
+				defer os.RemoveAll(dir)
+				settings := CreateDefaultWALSettings()
+				settings.Enabled = true
+				settings.Directory = dir
+				options = append(options, WithWAL(settings))
+			}
+
+			prwe, err := NewPrwExporter("", server.URL, http.DefaultClient, options...)
+			require.NoError(t, err)
+			require.NoError(t, prwe.Start(context.Background(), componenttest.NewNopHost()))
+			dropped, err := prwe.PushMetrics(context.Background(), getTenantMetrics())
+			require.NoError(t, err)
+			assert.Equal(t, 0, dropped)
+
+			expected := map[string][]string{
+				"team-a":    {"gauge_a"},
+				"team-b":    {"gauge_a"},
+				"anonymous": {"gauge_b"},
+			}
+			assert.Eventually(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return len(series) == len(expected)
+			}, 5*time.Second, 10*time.Millisecond)
+			require.NoError(t, prwe.Shutdown(context.Background()))
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, names := range series {
+				sort.Strings(names)
+			}
+			assert.Equal(t, expected, series)
+			assert.Equal(t, expected, metadata)
+		})
+	}
+}
@@ -19,7 +19,10 @@ import (
 
 	"github.com/prometheus/prometheus/prompb"
 
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/internal/data"
 	commonpb "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/common/v1"
+	otlpmetrics "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1"
 	otlp "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1old"
 	"go.opentelemetry.io/collector/internal/dataold"
 )
@@ -35,11 +38,15 @@ var (
 	msTime1 = int64(time1 / uint64(int64(time.Millisecond)/int64(time.Nanosecond)))
 	msTime2 = int64(time2 / uint64(int64(time.Millisecond)/int64(time.Nanosecond)))
 
-	typeInt64           = "INT64"
+	typeIntGauge        = pdata.MetricDataIntGauge.String()
+	typeIntSum          = pdata.MetricDataIntSum.String()
+	typeDoubleSum       = pdata.MetricDataDoubleSum.String()
+	typeIntHistogram    = pdata.MetricDataIntHistogram.String()
+	typeDoubleHistogram = pdata.MetricDataDoubleHistogram.String()
 	typeMonotonicInt64  = "MONOTONIC_INT64"
 	typeMonotonicDouble = "MONOTONIC_DOUBLE"
 	typeHistogram       = "HISTOGRAM"
-	typeSummary         = "SUMMARY"
+	typeSummary         = summaryStr
 
 	label11 = "test_label11"
 	value11 = "test_value11"
@@ -103,14 +110,14 @@ var (
 		{},
 	}
 	twoPointsSameTs = map[string]*prompb.TimeSeries{
-		typeInt64 + "-" + label11 + "-" + value11 + "-" + label12 + "-" + value12: getTimeSeries(getPromLabels(label11, value11, label12, value12),
+		typeIntGauge + "-" + label11 + "-" + value11 + "-" + label12 + "-" + value12: getTimeSeries(getPromLabels(label11, value11, label12, value12),
 			getSample(float64(intVal1), msTime1),
 			getSample(float64(intVal2), msTime2)),
 	}
 	twoPointsDifferentTs = map[string]*prompb.TimeSeries{
-		typeInt64 + "-" + label11 + "-" + value11 + "-" + label12 + "-" + value12: getTimeSeries(getPromLabels(label11, value11, label12, value12),
+		typeIntGauge + "-" + label11 + "-" + value11 + "-" + label12 + "-" + value12: getTimeSeries(getPromLabels(label11, value11, label12, value12),
 			getSample(float64(intVal1), msTime1)),
-		typeInt64 + "-" + label21 + "-" + value21 + "-" + label22 + "-" + value22: getTimeSeries(getPromLabels(label21, value21, label22, value22),
+		typeIntGauge + "-" + label21 + "-" + value21 + "-" + label22 + "-" + value22: getTimeSeries(getPromLabels(label21, value21, label22, value22),
 			getSample(float64(intVal1), msTime2)),
 	}
 )
@@ -193,6 +200,127 @@ func getSummaryDataPoint(labels []*commonpb.StringKeyValue, ts uint64, sum float
 	}
 }
 
+// new OTLP metrics
+func getLabelsMap(labels []*commonpb.StringKeyValue) pdata.StringMap {
+	return pdata.DeprecatedNewStringMap(&labels)
+}
+
+// getInternalMetrics returns metrics of the new internal model holding a single resource with the given metrics.
+func getInternalMetrics(metrics ...*otlpmetrics.Metric) data.MetricData {
+	return data.MetricDataFromOtlp([]*otlpmetrics.ResourceMetrics{
+		{
+			InstrumentationLibraryMetrics: []*otlpmetrics.InstrumentationLibraryMetrics{
+				{Metrics: metrics},
+			},
+		},
+	})
+}
+
+// getMetric returns the pdata.Metric backed by m.
+func getMetric(m *otlpmetrics.Metric) pdata.Metric {
+	return getInternalMetrics(m).ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0)
+}
+
+func getIntGaugeMetric(name string, points ...*otlpmetrics.IntDataPoint) *otlpmetrics.Metric {
+	return &otlpmetrics.Metric{
+		Name: name,
+		Data: &otlpmetrics.Metric_IntGauge{IntGauge: &otlpmetrics.IntGauge{DataPoints: points}},
+	}
+}
+
+func getDoubleGaugeMetric(name string, points ...*otlpmetrics.DoubleDataPoint) *otlpmetrics.Metric {
+	return &otlpmetrics.Metric{
+		Name: name,
+		Data: &otlpmetrics.Metric_DoubleGauge{DoubleGauge: &otlpmetrics.DoubleGauge{DataPoints: points}},
+	}
+}
+
+func getIntSumMetric(name string, temporality otlpmetrics.AggregationTemporality, monotonic bool,
+	points ...*otlpmetrics.IntDataPoint) *otlpmetrics.Metric {
+	return &otlpmetrics.Metric{
+		Name: name,
+		Data: &otlpmetrics.Metric_IntSum{IntSum: &otlpmetrics.IntSum{
+			DataPoints:             points,
+			AggregationTemporality: temporality,
+			IsMonotonic:            monotonic,
+		}},
+	}
+}
+
+func getDoubleSumMetric(name string, temporality otlpmetrics.AggregationTemporality, monotonic bool,
+	points ...*otlpmetrics.DoubleDataPoint) *otlpmetrics.Metric {
+	return &otlpmetrics.Metric{
+		Name: name,
+		Data: &otlpmetrics.Metric_DoubleSum{DoubleSum: &otlpmetrics.DoubleSum{
+			DataPoints:             points,
+			AggregationTemporality: temporality,
+			IsMonotonic:            monotonic,
+		}},
+	}
+}
+
+func getIntHistogramMetric(name string, temporality otlpmetrics.AggregationTemporality,
+	points ...*otlpmetrics.IntHistogramDataPoint) *otlpmetrics.Metric {
+	return &otlpmetrics.Metric{
+		Name: name,
+		Data: &otlpmetrics.Metric_IntHistogram{IntHistogram: &otlpmetrics.IntHistogram{
+			DataPoints:             points,
+			AggregationTemporality: temporality,
+		}},
+	}
+}
+
+func getDoubleHistogramMetric(name string, temporality otlpmetrics.AggregationTemporality,
+	points ...*otlpmetrics.DoubleHistogramDataPoint) *otlpmetrics.Metric {
+	return &otlpmetrics.Metric{
+		Name: name,
+		Data: &otlpmetrics.Metric_DoubleHistogram{DoubleHistogram: &otlpmetrics.DoubleHistogram{
+			DataPoints:             points,
+			AggregationTemporality: temporality,
+		}},
+	}
+}
+
+func getIntPoint(labels []*commonpb.StringKeyValue, value int64, ts uint64) *otlpmetrics.IntDataPoint {
+	return &otlpmetrics.IntDataPoint{
+		Labels:       labels,
+		TimeUnixNano: ts,
+		Value:        value,
+	}
+}
+
+func getDoublePoint(labels []*commonpb.StringKeyValue, value float64, ts uint64) *otlpmetrics.DoubleDataPoint {
+	return &otlpmetrics.DoubleDataPoint{
+		Labels:       labels,
+		TimeUnixNano: ts,
+		Value:        value,
+	}
+}
+
+func getIntHistogramPoint(labels []*commonpb.StringKeyValue, ts uint64, sum int64, count uint64, bounds []float64,
+	buckets []uint64) *otlpmetrics.IntHistogramDataPoint {
+	return &otlpmetrics.IntHistogramDataPoint{
+		Labels:         labels,
+		TimeUnixNano:   ts,
+		Count:          count,
+		Sum:            sum,
+		BucketCounts:   buckets,
+		ExplicitBounds: bounds,
+	}
+}
+
+func getDoubleHistogramPoint(labels []*commonpb.StringKeyValue, ts uint64, sum float64, count uint64,
+	bounds []float64, buckets []uint64) *otlpmetrics.DoubleHistogramDataPoint {
+	return &otlpmetrics.DoubleHistogramDataPoint{
+		Labels:         labels,
+		TimeUnixNano:   ts,
+		Count:          count,
+		Sum:            sum,
+		BucketCounts:   buckets,
+		ExplicitBounds: bounds,
+	}
+}
+
 // Prometheus TimeSeries
 func getPromLabels(lbs ...string) []prompb.Label {
 	pbLbs := prompb.Labels{
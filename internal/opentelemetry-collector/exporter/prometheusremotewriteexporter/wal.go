@@ -0,0 +1,472 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.uber.org/zap"
+)
+
+const (
+	walCheckpointFile   = "checkpoint"
+	walSegmentNameLen   = 8
+	walRecordHeaderSize = 8
+	walCheckpointSize   = 16
+)
+
+var (
+	errWALEmpty = errors.New("no pending record in the write-ahead log")
+	crcTable    = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// walPosition identifies a record in the write-ahead log by the index of its segment and its byte offset in the
+// segment.
+type walPosition struct {
+	segment int
+	offset  int64
+}
+
+// walSegment describes a segment file of the write-ahead log.
+type walSegment struct {
+	index   int
+	size    int64
+	modTime time.Time
+}
+
+// wal is a segmented, on-disk write-ahead log. Each record is stored as a 4 byte length and a 4 byte CRC32 checksum
+// followed by the payload. Records are appended to the head segment and read back in order. The position of the first
+// record not yet acknowledged is persisted as a checkpoint, so records that were not acknowledged before a restart are
+// replayed. Segments entirely before the checkpoint are deleted; segments exceeding the size or age limit are dropped
+// even if they are not acknowledged.
+type wal struct {
+	settings   WALSettings
+	logger     *zap.Logger
+	metricsCtx context.Context
+
+	mu         sync.Mutex
+	segments   []*walSegment
+	head       *os.File
+	reader     *os.File
+	readerIdx  int
+	readPos    walPosition
+	checkpoint walPosition
+	// notify receives a value whenever a record is written
+	notify chan struct{}
+}
+
+// openWAL opens the write-ahead log in the configured directory, creating it if it does not exist. A new head segment
+// is always started, so a partially written record from a previous run is never appended to.
+func openWAL(settings WALSettings, logger *zap.Logger, metricsCtx context.Context) (*wal, error) {
+	if settings.Directory == "" {
+		return nil, errors.New("write-ahead log directory cannot be empty")
+	}
+	if settings.SegmentSize <= 0 {
+		return nil, fmt.Errorf("invalid write-ahead log segment size %d", settings.SegmentSize)
+	}
+	if err := os.MkdirAll(settings.Directory, 0700); err != nil {
+		return nil, err
+	}
+
+	w := &wal{
+		settings:   settings,
+		logger:     logger,
+		metricsCtx: metricsCtx,
+		readerIdx:  -1,
+		notify:     make(chan struct{}, 1),
+	}
+	if err := w.loadSegments(); err != nil {
+		return nil, err
+	}
+	if err := w.loadCheckpoint(); err != nil {
+		return nil, err
+	}
+
+	next := 0
+	if len(w.segments) > 0 {
+		next = w.segments[len(w.segments)-1].index + 1
+	}
+	if err := w.createHead(next); err != nil {
+		return nil, err
+	}
+	w.readPos = w.checkpoint
+	w.enforceLimits()
+	w.recordSize()
+
+	if pending := w.pendingBytes(); pending > 0 {
+		w.logger.Info("Replaying write-ahead log", zap.String("directory", settings.Directory),
+			zap.Int64("pending_bytes", pending))
+	}
+	return w, nil
+}
+
+// loadSegments lists the segment files in the directory in order of their indices.
+func (w *wal) loadSegments() error {
+	files, err := ioutil.ReadDir(w.settings.Directory)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.IsDir() || len(f.Name()) != walSegmentNameLen {
+			continue
+		}
+		index, err := strconv.Atoi(f.Name())
+		if err != nil {
+			continue
+		}
+		w.segments = append(w.segments, &walSegment{
+			index:   index,
+			size:    f.Size(),
+			modTime: f.ModTime(),
+		})
+	}
+	sort.Slice(w.segments, func(i, j int) bool { return w.segments[i].index < w.segments[j].index })
+	return nil
+}
+
+// loadCheckpoint reads the persisted checkpoint and deletes segments that were already acknowledged. Without a
+// checkpoint, all segments are pending.
+func (w *wal) loadCheckpoint() error {
+	if len(w.segments) > 0 {
+		w.checkpoint = walPosition{segment: w.segments[0].index}
+	}
+	b, err := ioutil.ReadFile(filepath.Join(w.settings.Directory, walCheckpointFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(b) != walCheckpointSize {
+		w.logger.Warn("Ignoring invalid write-ahead log checkpoint", zap.Int("size", len(b)))
+		return nil
+	}
+	cp := walPosition{
+		segment: int(binary.BigEndian.Uint64(b[:8])),
+		offset:  int64(binary.BigEndian.Uint64(b[8:])),
+	}
+	for len(w.segments) > 0 && w.segments[0].index < cp.segment {
+		if err := w.removeSegment(w.segments[0].index); err != nil {
+			return err
+		}
+		w.segments = w.segments[1:]
+	}
+	if len(w.segments) > 0 && w.segments[0].index == cp.segment {
+		w.checkpoint = cp
+	} else if len(w.segments) > 0 {
+		w.checkpoint = walPosition{segment: w.segments[0].index}
+	}
+	return nil
+}
+
+// write appends data as a record to the head segment and syncs it to disk.
+func (w *wal) write(data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	size := int64(walRecordHeaderSize + len(data))
+	headSeg := w.segments[len(w.segments)-1]
+	if headSeg.size > 0 && headSeg.size+size > w.settings.SegmentSize {
+		if err := w.head.Close(); err != nil {
+			w.logger.Warn("Failed to close write-ahead log segment", zap.Error(err))
+		}
+		if err := w.createHead(headSeg.index + 1); err != nil {
+			return err
+		}
+		headSeg = w.segments[len(w.segments)-1]
+	}
+
+	buf := make([]byte, size)
+	binary.BigEndian.PutUint32(buf[:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.Checksum(data, crcTable))
+	copy(buf[walRecordHeaderSize:], data)
+
+	// a failed write is overwritten by the next one since the segment size is only updated on success
+	if _, err := w.head.WriteAt(buf, headSeg.size); err != nil {
+		return err
+	}
+	if err := w.head.Sync(); err != nil {
+		return err
+	}
+	headSeg.size += size
+	headSeg.modTime = time.Now()
+	stats.Record(w.metricsCtx, mWALWrittenBytes.M(size))
+
+	w.enforceLimits()
+	w.recordSize()
+
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// next returns the payload of the next record to be sent and the position following it. errWALEmpty is returned when
+// all records were read. Corrupted records are skipped along with the rest of their segment.
+func (w *wal) next() ([]byte, walPosition, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.enforceLimits()
+	for {
+		i := w.segmentPosition(w.readPos.segment)
+		if i < 0 {
+			// the segment was dropped, continue from the oldest retained segment
+			w.readPos = walPosition{segment: w.segments[0].index}
+			continue
+		}
+		seg := w.segments[i]
+		if w.readPos.offset >= seg.size {
+			if i == len(w.segments)-1 {
+				return nil, w.readPos, errWALEmpty
+			}
+			w.readPos = walPosition{segment: w.segments[i+1].index}
+			continue
+		}
+
+		data, err := w.readRecord(seg)
+		if err != nil {
+			w.logger.Warn("Skipping corrupted write-ahead log segment", zap.Int("segment", seg.index),
+				zap.Int64("offset", w.readPos.offset), zap.Error(err))
+			stats.Record(w.metricsCtx, mWALDroppedBytes.M(seg.size-w.readPos.offset))
+			w.readPos.offset = seg.size
+			continue
+		}
+		w.readPos.offset += int64(walRecordHeaderSize + len(data))
+		return data, w.readPos, nil
+	}
+}
+
+// readRecord reads the record at the read position of seg and verifies its checksum.
+func (w *wal) readRecord(seg *walSegment) ([]byte, error) {
+	if w.readerIdx != seg.index {
+		if w.reader != nil {
+			w.reader.Close()
+		}
+		f, err := os.Open(w.segmentPath(seg.index))
+		if err != nil {
+			w.reader, w.readerIdx = nil, -1
+			return nil, err
+		}
+		w.reader, w.readerIdx = f, seg.index
+	}
+
+	header := make([]byte, walRecordHeaderSize)
+	if _, err := w.reader.ReadAt(header, w.readPos.offset); err != nil {
+		return nil, err
+	}
+	length := int64(binary.BigEndian.Uint32(header[:4]))
+	if w.readPos.offset+walRecordHeaderSize+length > seg.size {
+		return nil, fmt.Errorf("record length %d exceeds segment size %d", length, seg.size)
+	}
+	data := make([]byte, length)
+	if _, err := w.reader.ReadAt(data, w.readPos.offset+walRecordHeaderSize); err != nil {
+		return nil, err
+	}
+	if crc32.Checksum(data, crcTable) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, errors.New("record checksum mismatch")
+	}
+	return data, nil
+}
+
+// ack moves the checkpoint to pos, which marks all records before pos as sent, persists it and deletes segments
+// that are entirely acknowledged.
+func (w *wal) ack(pos walPosition) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// the record may have been dropped by the size or age limit while it was being sent
+	if pos.segment < w.checkpoint.segment ||
+		(pos.segment == w.checkpoint.segment && pos.offset <= w.checkpoint.offset) {
+		return nil
+	}
+	w.checkpoint = pos
+	if err := w.writeCheckpoint(); err != nil {
+		return err
+	}
+	for len(w.segments) > 1 && w.segments[0].index < pos.segment {
+		if err := w.removeSegment(w.segments[0].index); err != nil {
+			return err
+		}
+		w.segments = w.segments[1:]
+	}
+	w.recordSize()
+	return nil
+}
+
+// rewind moves the read position back to the checkpoint, so that records read but not acknowledged are read again.
+func (w *wal) rewind() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.readPos = w.checkpoint
+}
+
+// close closes the open segment files.
+func (w *wal) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.reader != nil {
+		w.reader.Close()
+		w.reader, w.readerIdx = nil, -1
+	}
+	return w.head.Close()
+}
+
+// enforceLimits drops the oldest segments while the size or age limit is exceeded. The head segment is never dropped.
+// The lock must be held by the caller.
+func (w *wal) enforceLimits() {
+	var total int64
+	for _, seg := range w.segments {
+		total += seg.size
+	}
+	for len(w.segments) > 1 {
+		oldest := w.segments[0]
+		tooBig := w.settings.MaxSize > 0 && total > w.settings.MaxSize
+		tooOld := w.settings.MaxAge > 0 && time.Since(oldest.modTime) > w.settings.MaxAge
+		if !tooBig && !tooOld {
+			return
+		}
+
+		dropped := oldest.size
+		if w.checkpoint.segment == oldest.index {
+			dropped -= w.checkpoint.offset
+		} else if w.checkpoint.segment > oldest.index {
+			dropped = 0
+		}
+		if err := w.removeSegment(oldest.index); err != nil {
+			w.logger.Warn("Failed to remove write-ahead log segment", zap.Int("segment", oldest.index), zap.Error(err))
+			return
+		}
+		w.segments = w.segments[1:]
+		total -= oldest.size
+
+		if dropped > 0 {
+			w.logger.Warn("Dropped pending data from the write-ahead log", zap.Int("segment", oldest.index),
+				zap.Int64("bytes", dropped), zap.Bool("max_size_exceeded", tooBig), zap.Bool("max_age_exceeded", tooOld))
+			stats.Record(w.metricsCtx, mWALDroppedBytes.M(dropped))
+		}
+		if w.checkpoint.segment <= oldest.index {
+			w.checkpoint = walPosition{segment: w.segments[0].index}
+			if err := w.writeCheckpoint(); err != nil {
+				w.logger.Warn("Failed to write write-ahead log checkpoint", zap.Error(err))
+			}
+		}
+		if w.readPos.segment <= oldest.index {
+			w.readPos = walPosition{segment: w.segments[0].index}
+		}
+	}
+}
+
+// createHead creates a new empty head segment with the given index. The lock must be held by the caller.
+func (w *wal) createHead(index int) error {
+	f, err := os.OpenFile(w.segmentPath(index), os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	w.head = f
+	w.segments = append(w.segments, &walSegment{
+		index:   index,
+		modTime: time.Now(),
+	})
+	return nil
+}
+
+// removeSegment deletes the segment file with the given index. The lock must be held by the caller.
+func (w *wal) removeSegment(index int) error {
+	if w.readerIdx == index {
+		w.reader.Close()
+		w.reader, w.readerIdx = nil, -1
+	}
+	err := os.Remove(w.segmentPath(index))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// writeCheckpoint atomically persists the checkpoint. The lock must be held by the caller.
+func (w *wal) writeCheckpoint() error {
+	b := make([]byte, walCheckpointSize)
+	binary.BigEndian.PutUint64(b[:8], uint64(w.checkpoint.segment))
+	binary.BigEndian.PutUint64(b[8:], uint64(w.checkpoint.offset))
+
+	path := filepath.Join(w.settings.Directory, walCheckpointFile)
+	f, err := os.OpenFile(path+".tmp", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(b); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// segmentPosition returns the position of the segment with the given index in w.segments, or -1 if there is none.
+// The lock must be held by the caller.
+func (w *wal) segmentPosition(index int) int {
+	for i, seg := range w.segments {
+		if seg.index == index {
+			return i
+		}
+	}
+	return -1
+}
+
+// pendingBytes returns the number of bytes from the checkpoint to the end of the log. The lock must be held by the
+// caller.
+func (w *wal) pendingBytes() int64 {
+	var pending int64
+	for _, seg := range w.segments {
+		if seg.index >= w.checkpoint.segment {
+			pending += seg.size
+		}
+	}
+	if w.segmentPosition(w.checkpoint.segment) >= 0 {
+		pending -= w.checkpoint.offset
+	}
+	return pending
+}
+
+// recordSize records the size and the pending bytes of the log. The lock must be held by the caller.
+func (w *wal) recordSize() {
+	var total int64
+	for _, seg := range w.segments {
+		total += seg.size
+	}
+	stats.Record(w.metricsCtx, mWALSizeBytes.M(total), mWALPendingBytes.M(w.pendingBytes()))
+}
+
+func (w *wal) segmentPath(index int) string {
+	return filepath.Join(w.settings.Directory, fmt.Sprintf("%0*d", walSegmentNameLen, index))
+}
@@ -0,0 +1,152 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestWAL(t *testing.T, dir string, segmentSize, maxSize int64) *wal {
+	w, err := openWAL(WALSettings{
+		Enabled:     true,
+		Directory:   dir,
+		SegmentSize: segmentSize,
+		MaxSize:     maxSize,
+	}, zap.NewNop(), context.Background())
+	require.NoError(t, err)
+	return w
+}
+
+// readAll reads every pending record of w without acknowledging them.
+func readAll(t *testing.T, w *wal) []string {
+	var records []string
+	for {
+		data, _, err := w.next()
+		if err == errWALEmpty {
+			return records
+		}
+		require.NoError(t, err)
+		records = append(records, string(data))
+	}
+}
+
+// Test_openWAL checks that invalid settings are rejected.
+func Test_openWAL(t *testing.T) {
+	_, err := openWAL(WALSettings{Enabled: true, SegmentSize: 1024}, zap.NewNop(), context.Background())
+	assert.Error(t, err)
+
+	_, err = openWAL(WALSettings{Enabled: true, Directory: t.Name(), SegmentSize: 0}, zap.NewNop(),
+		context.Background())
+	assert.Error(t, err)
+}
+
+// Test_walWriteReadAck checks that records are read in order, and only records that are not acknowledged are read again
+// after a rewind.
+func Test_walWriteReadAck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	w := newTestWAL(t, dir, 1024, 0)
+	defer w.close()
+
+	for _, r := range []string{"a", "b", "c"} {
+		require.NoError(t, w.write([]byte(r)))
+	}
+
+	data, pos, err := w.next()
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(data))
+	require.NoError(t, w.ack(pos))
+
+	assert.Equal(t, []string{"b", "c"}, readAll(t, w))
+	w.rewind()
+	assert.Equal(t, []string{"b", "c"}, readAll(t, w))
+}
+
+// Test_walReplay checks that records not acknowledged before the log is closed are read again when it is reopened,
+// and that fully acknowledged segments are deleted.
+func Test_walReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// each record fills a segment
+	w := newTestWAL(t, dir, walRecordHeaderSize+1, 0)
+	for _, r := range []string{"a", "b", "c"} {
+		require.NoError(t, w.write([]byte(r)))
+	}
+	_, pos, err := w.next()
+	require.NoError(t, err)
+	_, pos, err = w.next()
+	require.NoError(t, err)
+	require.NoError(t, w.ack(pos))
+	require.NoError(t, w.close())
+
+	w = newTestWAL(t, dir, walRecordHeaderSize+1, 0)
+	defer w.close()
+	assert.Equal(t, []string{"c"}, readAll(t, w))
+
+	segments, err := filepath.Glob(filepath.Join(dir, "0*"))
+	require.NoError(t, err)
+	// the segment of "b" is retained since the checkpoint points to its end
+	assert.Len(t, segments, 3)
+}
+
+// Test_walMaxSize checks that the oldest segments are dropped once the size limit is exceeded.
+func Test_walMaxSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	w := newTestWAL(t, dir, walRecordHeaderSize+1, 2*(walRecordHeaderSize+1))
+	defer w.close()
+	for _, r := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, w.write([]byte(r)))
+	}
+	assert.Equal(t, []string{"c", "d"}, readAll(t, w))
+}
+
+// Test_walCorruptedRecord checks that a segment holding a corrupted record is skipped from that record on.
+func Test_walCorruptedRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	w := newTestWAL(t, dir, 2*(walRecordHeaderSize+1), 0)
+	for _, r := range []string{"a", "b", "c"} {
+		require.NoError(t, w.write([]byte(r)))
+	}
+	require.NoError(t, w.close())
+
+	// flip the payload of "b", the last record of the first segment
+	f, err := os.OpenFile(w.segmentPath(0), os.O_RDWR, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte("x"), 2*walRecordHeaderSize+1)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	w = newTestWAL(t, dir, 2*(walRecordHeaderSize+1), 0)
+	defer w.close()
+	assert.Equal(t, []string{"a", "c"}, readAll(t, w))
+}
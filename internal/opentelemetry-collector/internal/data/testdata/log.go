@@ -202,6 +202,16 @@ func GenerateLogDataTwoLogsSameResource() pdata.Logs {
 	return ld
 }
 
+func GenerateLogDataManyLogsSameResource(logsCount int) pdata.Logs {
+	ld := GenerateLogDataOneEmptyLogs()
+	rs0ill0 := ld.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0)
+	rs0ill0.Logs().Resize(logsCount)
+	for i := 0; i < logsCount; i++ {
+		fillLogOne(rs0ill0.Logs().At(i))
+	}
+	return ld
+}
+
 // GenerateLogOtlpSameResourceTwologs returns the OTLP representation of the GenerateLogOtlpSameResourceTwologs.
 func GenerateLogOtlpSameResourceTwoLogs() []*otlplogs.ResourceLogs {
 	return []*otlplogs.ResourceLogs{
@@ -0,0 +1,242 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filterexpr
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+	tracetranslator "go.opentelemetry.io/collector/translator/trace"
+)
+
+var errNoExpressions = errors.New("at least one expression must be specified")
+
+// Labels gives the values of the labels of a metric data point, or of the attributes of a resource, by name.
+type Labels interface {
+	Get(name string) (string, bool)
+}
+
+// MapLabels is the Labels of a map.
+type MapLabels map[string]string
+
+// Get returns the value of the label name.
+func (l MapLabels) Get(name string) (string, bool) {
+	value, ok := l[name]
+	return value, ok
+}
+
+// MetricDataPoint is a data point of a metric matched by a MetricMatcher.
+type MetricDataPoint struct {
+	// MetricName is the name of the metric.
+	MetricName string
+	// Value is the value of scalar data points, or the sum of the values of distributions.
+	Value float64
+	// Labels are the labels of the data point.
+	Labels Labels
+	// Resource are the attributes of the resource of the metric.
+	Resource Labels
+}
+
+// metricEnv is the environment of the expressions of a MetricMatcher. Expressions access the name of the metric with
+// MetricName, the value of the data point with Value, its labels with Label and HasLabel, and the attributes of its
+// resource with Resource and HasResource, e.g.:
+//
+//	MetricName == "http.requests" && Label("http.route") == "/health"
+//	HasResource("k8s.namespace.name") && Value > 100
+type metricEnv struct {
+	MetricName string
+	Value      float64
+	labels     Labels
+	resource   Labels
+}
+
+// Label returns the value of the label name of the data point, or the empty string if it has no such label.
+func (e *metricEnv) Label(name string) string {
+	return get(e.labels, name)
+}
+
+// HasLabel returns whether the data point has the label name.
+func (e *metricEnv) HasLabel(name string) bool {
+	return has(e.labels, name)
+}
+
+// Resource returns the value of the attribute name of the resource, or the empty string if it has no such attribute.
+func (e *metricEnv) Resource(name string) string {
+	return get(e.resource, name)
+}
+
+// HasResource returns whether the resource has the attribute name.
+func (e *metricEnv) HasResource(name string) bool {
+	return has(e.resource, name)
+}
+
+func get(labels Labels, name string) string {
+	if labels == nil {
+		return ""
+	}
+	value, _ := labels.Get(name)
+	return value
+}
+
+func has(labels Labels, name string) bool {
+	if labels == nil {
+		return false
+	}
+	_, ok := labels.Get(name)
+	return ok
+}
+
+// spanEnv is the environment of the expressions of a SpanMatcher. Expressions access the name of the span with
+// SpanName, the name of its service with Service, and its attributes with Attribute and HasAttribute, e.g.:
+//
+//	Service == "auth" && Attribute("http.route") == "/health"
+type spanEnv struct {
+	SpanName string
+	Service  string
+	attrs    pdata.AttributeMap
+}
+
+// Attribute returns the string representation of the value of the attribute name of the span, or the empty string if
+// it has no such attribute.
+func (e *spanEnv) Attribute(name string) string {
+	if e.attrs.Len() == 0 {
+		return ""
+	}
+	value, ok := e.attrs.Get(name)
+	if !ok {
+		return ""
+	}
+	return tracetranslator.AttributeValueToString(value, false)
+}
+
+// HasAttribute returns whether the span has the attribute name.
+func (e *spanEnv) HasAttribute(name string) bool {
+	if e.attrs.Len() == 0 {
+		return false
+	}
+	_, ok := e.attrs.Get(name)
+	return ok
+}
+
+// MetricMatcher matches metric data points with expressions.
+type MetricMatcher struct {
+	programs []*vm.Program
+}
+
+// NewMetricMatcher compiles the expressions of a MetricMatcher. See metricEnv for what expressions can access.
+func NewMetricMatcher(expressions []string) (*MetricMatcher, error) {
+	programs, err := compileAll(expressions, &metricEnv{})
+	if err != nil {
+		return nil, err
+	}
+	return &MetricMatcher{programs: programs}, nil
+}
+
+// MatchDataPoint returns whether any of the expressions matches dp.
+func (m *MetricMatcher) MatchDataPoint(dp MetricDataPoint) (bool, error) {
+	return matchAny(m.programs, &metricEnv{
+		MetricName: dp.MetricName,
+		Value:      dp.Value,
+		labels:     dp.Labels,
+		resource:   dp.Resource,
+	})
+}
+
+// SpanMatcher matches spans with expressions.
+type SpanMatcher struct {
+	programs []*vm.Program
+}
+
+// NewSpanMatcher compiles the expressions of a SpanMatcher. See spanEnv for what expressions can access.
+func NewSpanMatcher(expressions []string) (*SpanMatcher, error) {
+	programs, err := compileAll(expressions, &spanEnv{})
+	if err != nil {
+		return nil, err
+	}
+	return &SpanMatcher{programs: programs}, nil
+}
+
+// MatchSpan returns whether any of the expressions matches span of the service serviceName.
+func (m *SpanMatcher) MatchSpan(span pdata.Span, serviceName string) (bool, error) {
+	return matchAny(m.programs, &spanEnv{
+		SpanName: span.Name(),
+		Service:  serviceName,
+		attrs:    span.Attributes(),
+	})
+}
+
+func matchAny(programs []*vm.Program, env interface{}) (bool, error) {
+	for _, program := range programs {
+		result, err := expr.Run(program, env)
+		if err != nil {
+			return false, err
+		}
+		if result.(bool) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func compileAll(expressions []string, env interface{}) ([]*vm.Program, error) {
+	if len(expressions) == 0 {
+		return nil, errNoExpressions
+	}
+	programs := make([]*vm.Program, 0, len(expressions))
+	for _, expression := range expressions {
+		program, err := compile(expression, env)
+		if err != nil {
+			return nil, err
+		}
+		programs = append(programs, program)
+	}
+	return programs, nil
+}
+
+// programKey is the key of a compiled expression in the cache, as the same expression compiles differently for
+// metrics and spans.
+type programKey struct {
+	env        string
+	expression string
+}
+
+// programs caches the compiled expressions, which are shared by the matchers of every processor and are safe for
+// concurrent use.
+var programs = struct {
+	sync.Mutex
+	cache map[programKey]*vm.Program
+}{cache: map[programKey]*vm.Program{}}
+
+// compile returns the compiled expression, evaluating to a boolean within env.
+func compile(expression string, env interface{}) (*vm.Program, error) {
+	key := programKey{env: fmt.Sprintf("%T", env), expression: expression}
+
+	programs.Lock()
+	defer programs.Unlock()
+	if program, ok := programs.cache[key]; ok {
+		return program, nil
+	}
+	program, err := expr.Compile(expression, expr.Env(env), expr.AsBool())
+	if err != nil {
+		return nil, fmt.Errorf("error compiling expression %q: %w", expression, err)
+	}
+	programs.cache[key] = program
+	return program, nil
+}
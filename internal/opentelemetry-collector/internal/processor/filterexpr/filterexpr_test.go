@@ -0,0 +1,240 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filterexpr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+)
+
+func TestMetricMatcher(t *testing.T) {
+	dp := MetricDataPoint{
+		MetricName: "http.requests",
+		Value:      42,
+		Labels:     MapLabels{"http.route": "/health", "method": "GET"},
+		Resource:   MapLabels{"k8s.namespace.name": "monitoring"},
+	}
+
+	tests := []struct {
+		name        string
+		expressions []string
+		match       bool
+	}{
+		{
+			name:        "metric name",
+			expressions: []string{`MetricName == "http.requests"`},
+			match:       true,
+		},
+		{
+			name:        "label",
+			expressions: []string{`Label("http.route") == "/health"`},
+			match:       true,
+		},
+		{
+			name:        "missing label",
+			expressions: []string{`HasLabel("user_id")`},
+			match:       false,
+		},
+		{
+			name:        "resource attribute",
+			expressions: []string{`Resource("k8s.namespace.name") in ["kube-system", "monitoring"]`},
+			match:       true,
+		},
+		{
+			name:        "missing resource attribute",
+			expressions: []string{`HasResource("service.name")`},
+			match:       false,
+		},
+		{
+			name:        "value threshold",
+			expressions: []string{`Value > 100`},
+			match:       false,
+		},
+		{
+			name:        "regexp",
+			expressions: []string{`MetricName matches "^http\\." && Label("method") != "POST"`},
+			match:       true,
+		},
+		{
+			name:        "any expression",
+			expressions: []string{`Value > 100`, `Value < 50`},
+			match:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matcher, err := NewMetricMatcher(test.expressions)
+			require.NoError(t, err)
+
+			match, err := matcher.MatchDataPoint(dp)
+			require.NoError(t, err)
+			assert.Equal(t, test.match, match)
+		})
+	}
+}
+
+func TestMetricMatcherWithoutLabels(t *testing.T) {
+	matcher, err := NewMetricMatcher([]string{`Label("a") == "" && !HasLabel("a") && !HasResource("b")`})
+	require.NoError(t, err)
+
+	match, err := matcher.MatchDataPoint(MetricDataPoint{MetricName: "metric"})
+	require.NoError(t, err)
+	assert.True(t, match)
+}
+
+func TestSpanMatcher(t *testing.T) {
+	span := pdata.NewSpan()
+	span.InitEmpty()
+	span.SetName("GET /health")
+	span.Attributes().InitFromMap(map[string]pdata.AttributeValue{
+		"http.route":       pdata.NewAttributeValueString("/health"),
+		"http.status_code": pdata.NewAttributeValueInt(200),
+	})
+
+	tests := []struct {
+		name        string
+		expressions []string
+		match       bool
+	}{
+		{
+			name:        "span name and service",
+			expressions: []string{`SpanName == "GET /health" && Service == "svcA"`},
+			match:       true,
+		},
+		{
+			name:        "attribute",
+			expressions: []string{`Attribute("http.route") == "/health"`},
+			match:       true,
+		},
+		{
+			name:        "attribute converted to string",
+			expressions: []string{`Attribute("http.status_code") startsWith "5"`},
+			match:       false,
+		},
+		{
+			name:        "missing attribute",
+			expressions: []string{`HasAttribute("user_id")`},
+			match:       false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matcher, err := NewSpanMatcher(test.expressions)
+			require.NoError(t, err)
+
+			match, err := matcher.MatchSpan(span, "svcA")
+			require.NoError(t, err)
+			assert.Equal(t, test.match, match)
+		})
+	}
+}
+
+func TestInvalidExpressions(t *testing.T) {
+	tests := []struct {
+		name        string
+		expressions []string
+	}{
+		{
+			name: "no expressions",
+		},
+		{
+			name:        "syntax error",
+			expressions: []string{`MetricName ==`},
+		},
+		{
+			name:        "not a boolean",
+			expressions: []string{`MetricName`},
+		},
+		{
+			name:        "unknown variable",
+			expressions: []string{`SpanName == "name"`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matcher, err := NewMetricMatcher(test.expressions)
+			assert.Nil(t, matcher)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCompiledExpressionsAreCached(t *testing.T) {
+	expressions := []string{`Label("http.route") == "/health"`}
+	m1, err := NewMetricMatcher(expressions)
+	require.NoError(t, err)
+	m2, err := NewMetricMatcher(expressions)
+	require.NoError(t, err)
+	assert.Same(t, m1.programs[0], m2.programs[0])
+
+	// the same expression is compiled separately for spans
+	_, err = NewSpanMatcher(expressions)
+	assert.Error(t, err)
+}
+
+func BenchmarkMetricMatcher(b *testing.B) {
+	matcher, err := NewMetricMatcher([]string{
+		`MetricName == "http.requests" && Label("http.route") == "/health"`,
+		`Resource("k8s.namespace.name") == "kube-system"`,
+	})
+	require.NoError(b, err)
+	dp := MetricDataPoint{
+		MetricName: "http.requests",
+		Value:      42,
+		Labels:     MapLabels{"http.route": "/api", "method": "GET"},
+		Resource:   MapLabels{"k8s.namespace.name": "monitoring"},
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := matcher.MatchDataPoint(dp); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMetricMatcherCompile(b *testing.B) {
+	expressions := []string{`MetricName == "http.requests" && Label("http.route") == "/health"`}
+	for i := 0; i < b.N; i++ {
+		if _, err := NewMetricMatcher(expressions); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSpanMatcher(b *testing.B) {
+	matcher, err := NewSpanMatcher([]string{`Service == "svcA" && Attribute("http.route") == "/health"`})
+	require.NoError(b, err)
+	span := pdata.NewSpan()
+	span.InitEmpty()
+	span.SetName("GET /api")
+	span.Attributes().InitFromMap(map[string]pdata.AttributeValue{
+		"http.route": pdata.NewAttributeValueString("/api"),
+	})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := matcher.MatchSpan(span, "svcA"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filterexpr
+
+import (
+	"context"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"go.opentelemetry.io/collector/obsreport"
+)
+
+var (
+	tagProcessor, _ = tag.NewKey(obsreport.ProcessorKey)
+
+	mEvaluationErrors = stats.Int64(
+		obsreport.ProcessorKey+"/expression_evaluation_errors",
+		"Number of spans and data points whose filter expressions failed to be evaluated.",
+		stats.UnitDimensionless)
+)
+
+// RecordEvaluationError records that the filter expressions of a processor failed to be evaluated for a span or a
+// data point. ctx is the context given to the processor, which holds its name.
+func RecordEvaluationError(ctx context.Context) {
+	stats.Record(ctx, mEvaluationErrors.M(1))
+}
+
+// MetricViews returns the metric views of the filter expressions, tagged with the name of the processor.
+func MetricViews() []*view.View {
+	return []*view.View{
+		{
+			Name:        mEvaluationErrors.Name(),
+			Measure:     mEvaluationErrors,
+			Description: mEvaluationErrors.Description(),
+			TagKeys:     []tag.Key{tagProcessor},
+			Aggregation: view.Sum(),
+		},
+	}
+}
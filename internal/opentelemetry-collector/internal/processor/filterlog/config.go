@@ -0,0 +1,46 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filterlog
+
+import (
+	"go.opentelemetry.io/collector/internal/processor/filterset"
+	"go.opentelemetry.io/collector/internal/processor/filterspan"
+)
+
+// MatchProperties specifies the set of properties in a log record to match against and the
+// type of string pattern matching to use.
+// At least one of bodies, severity texts or attributes must be specified. It is supported
+// to have all specified, but this requires all of the properties to match for the
+// inclusion/exclusion to occur.
+type MatchProperties struct {
+	// Config configures the matching patterns used when matching log record properties.
+	filterset.Config `mapstructure:",squash"`
+
+	// Bodies specifies the list of string patterns to match the log record body against.
+	// A match occurs if the body, converted to a string, matches at least one string pattern in this list.
+	// This is an optional field.
+	Bodies []string `mapstructure:"bodies"`
+
+	// SeverityTexts specifies the list of string patterns to match the log record severity text against.
+	// A match occurs if the severity text matches at least one string pattern in this list.
+	// This is an optional field.
+	SeverityTexts []string `mapstructure:"severity_texts"`
+
+	// Attributes specifies the list of attributes to match against.
+	// All of these attributes must match exactly for a match to occur.
+	// Only match_type=strict is allowed if "attributes" are specified.
+	// This is an optional field.
+	Attributes []filterspan.Attribute `mapstructure:"attributes"`
+}
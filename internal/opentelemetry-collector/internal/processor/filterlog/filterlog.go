@@ -0,0 +1,151 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filterlog
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/internal/processor/filterhelper"
+	"go.opentelemetry.io/collector/internal/processor/filterset"
+	"go.opentelemetry.io/collector/internal/processor/filterspan"
+	tracetranslator "go.opentelemetry.io/collector/translator/trace"
+)
+
+var (
+	errAtLeastOneMatchFieldNeeded = errors.New(
+		`error creating processor. At least one ` +
+			`of "bodies", "severity_texts" or "attributes" field must be specified"`)
+)
+
+// Matcher matches log records by log record properties against prespecified values for each property.
+type Matcher struct {
+	bodyFilters     filterset.FilterSet
+	severityFilters filterset.FilterSet
+	attributes      []attributeMatcher
+}
+
+// attributeMatcher is a attribute key/value pair to match to.
+type attributeMatcher struct {
+	key string
+	// If nil only check for key existence.
+	value *pdata.AttributeValue
+}
+
+// NewMatcher constructs a log record Matcher that can be used to match log records by log record properties.
+// For each supported log record property, the Matcher accepts a set of prespecified values. An incoming log record
+// matches on a property if the property matches at least one of the prespecified values.
+// A log record only matches if every log record property configured on the Matcher is a match.
+//
+// The log record Matcher supports matching by the following log record properties:
+// - Body
+// - Severity text
+// - Attributes
+func NewMatcher(mp *MatchProperties) (*Matcher, error) {
+	if mp == nil {
+		return nil, nil
+	}
+
+	if len(mp.Bodies) == 0 && len(mp.SeverityTexts) == 0 && len(mp.Attributes) == 0 {
+		return nil, errAtLeastOneMatchFieldNeeded
+	}
+
+	m := &Matcher{}
+	var err error
+	if len(mp.Bodies) > 0 {
+		m.bodyFilters, err = filterset.CreateFilterSet(mp.Bodies, &mp.Config)
+		if err != nil {
+			return nil, fmt.Errorf("error creating log body filters: %v", err)
+		}
+	}
+	if len(mp.SeverityTexts) > 0 {
+		m.severityFilters, err = filterset.CreateFilterSet(mp.SeverityTexts, &mp.Config)
+		if err != nil {
+			return nil, fmt.Errorf("error creating log severity text filters: %v", err)
+		}
+	}
+	if len(mp.Attributes) > 0 {
+		m.attributes, err = newAttributesMatcher(mp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func newAttributesMatcher(mp *MatchProperties) ([]attributeMatcher, error) {
+	// attribute matching is only supported with strict matching
+	if mp.Config.MatchType != filterset.Strict {
+		return nil, fmt.Errorf(
+			"%s=%s is not supported for %q",
+			filterset.MatchTypeFieldName, filterset.Regexp, filterspan.AttributesFieldName,
+		)
+	}
+
+	attributes := make([]attributeMatcher, 0, len(mp.Attributes))
+	for _, attribute := range mp.Attributes {
+		if attribute.Key == "" {
+			return nil, errors.New("error creating processor. Can't have empty key in the list of attributes")
+		}
+
+		entry := attributeMatcher{
+			key: attribute.Key,
+		}
+		if attribute.Value != nil {
+			val, err := filterhelper.NewAttributeValueRaw(attribute.Value)
+			if err != nil {
+				return nil, err
+			}
+			entry.value = &val
+		}
+		attributes = append(attributes, entry)
+	}
+	return attributes, nil
+}
+
+// MatchLogRecord matches a log record using the log record properties configured on the Matcher.
+// A log record only matches if every log record property configured on the Matcher is a match.
+func (m *Matcher) MatchLogRecord(lr pdata.LogRecord) bool {
+	if m.bodyFilters != nil && !m.bodyFilters.Matches(tracetranslator.AttributeValueToString(lr.Body(), false)) {
+		return false
+	}
+
+	if m.severityFilters != nil && !m.severityFilters.Matches(lr.SeverityText()) {
+		return false
+	}
+
+	return m.matchAttributes(lr.Attributes())
+}
+
+// matchAttributes returns whether all the attributes of the Matcher are found in attrs with the expected values.
+func (m *Matcher) matchAttributes(attrs pdata.AttributeMap) bool {
+	for _, property := range m.attributes {
+		attr, exist := attrs.Get(property.key)
+		if !exist {
+			return false
+		}
+
+		// This is for the case of checking that the key existed.
+		if property.value == nil {
+			continue
+		}
+
+		if !attr.Equal(*property.value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,183 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filterlog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/internal/processor/filterset"
+	"go.opentelemetry.io/collector/internal/processor/filterspan"
+)
+
+func createLogRecord(body, severityText string, attributes map[string]pdata.AttributeValue) pdata.LogRecord {
+	lr := pdata.NewLogRecord()
+	lr.InitEmpty()
+	lr.Body().SetStringVal(body)
+	lr.SetSeverityText(severityText)
+	lr.Attributes().InitFromMap(attributes)
+	return lr
+}
+
+func TestNewMatcher_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         *MatchProperties
+		errorString string
+	}{
+		{
+			name:        "empty_property",
+			cfg:         &MatchProperties{Config: filterset.Config{MatchType: filterset.Strict}},
+			errorString: errAtLeastOneMatchFieldNeeded.Error(),
+		},
+		{
+			name: "invalid_match_type",
+			cfg: &MatchProperties{
+				Config: filterset.Config{MatchType: "wrong_match_type"},
+				Bodies: []string{"abc"},
+			},
+			errorString: "error creating log body filters: unrecognized match_type: 'wrong_match_type', valid types are: [regexp strict]",
+		},
+		{
+			name: "regexp_match_type_for_attributes",
+			cfg: &MatchProperties{
+				Config:     filterset.Config{MatchType: filterset.Regexp},
+				Attributes: []filterspan.Attribute{{Key: "key", Value: "value"}},
+			},
+			errorString: `match_type=regexp is not supported for "attributes"`,
+		},
+		{
+			name: "empty_key_name_in_attributes_list",
+			cfg: &MatchProperties{
+				Config:     filterset.Config{MatchType: filterset.Strict},
+				Attributes: []filterspan.Attribute{{Key: ""}},
+			},
+			errorString: "error creating processor. Can't have empty key in the list of attributes",
+		},
+		{
+			name: "invalid_attribute_value",
+			cfg: &MatchProperties{
+				Config:     filterset.Config{MatchType: filterset.Strict},
+				Attributes: []filterspan.Attribute{{Key: "key", Value: []string{"value"}}},
+			},
+			errorString: `error unsupported value type "[]string"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMatcher(tt.cfg)
+			assert.Nil(t, m)
+			assert.EqualError(t, err, tt.errorString)
+		})
+	}
+}
+
+func TestNewMatcher_NilConfig(t *testing.T) {
+	m, err := NewMatcher(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, m)
+}
+
+func TestMatcherMatches(t *testing.T) {
+	lr := createLogRecord("connection refused by host", "ERROR", map[string]pdata.AttributeValue{
+		"app":          pdata.NewAttributeValueString("server"),
+		"instance_num": pdata.NewAttributeValueInt(1),
+	})
+
+	tests := []struct {
+		name        string
+		cfg         *MatchProperties
+		shouldMatch bool
+	}{
+		{
+			name: "regexp_body_match",
+			cfg: &MatchProperties{
+				Config: filterset.Config{MatchType: filterset.Regexp},
+				Bodies: []string{".*refused.*"},
+			},
+			shouldMatch: true,
+		},
+		{
+			name: "regexp_body_mismatch",
+			cfg: &MatchProperties{
+				Config: filterset.Config{MatchType: filterset.Regexp},
+				Bodies: []string{".*timeout.*"},
+			},
+			shouldMatch: false,
+		},
+		{
+			name: "strict_severity_text_match",
+			cfg: &MatchProperties{
+				Config:        filterset.Config{MatchType: filterset.Strict},
+				SeverityTexts: []string{"WARN", "ERROR"},
+			},
+			shouldMatch: true,
+		},
+		{
+			name: "strict_severity_text_mismatch",
+			cfg: &MatchProperties{
+				Config:        filterset.Config{MatchType: filterset.Strict},
+				SeverityTexts: []string{"DEBUG"},
+			},
+			shouldMatch: false,
+		},
+		{
+			name: "attributes_match",
+			cfg: &MatchProperties{
+				Config: filterset.Config{MatchType: filterset.Strict},
+				Attributes: []filterspan.Attribute{
+					{Key: "app", Value: "server"},
+					{Key: "instance_num"},
+				},
+			},
+			shouldMatch: true,
+		},
+		{
+			name: "attribute_value_mismatch",
+			cfg: &MatchProperties{
+				Config:     filterset.Config{MatchType: filterset.Strict},
+				Attributes: []filterspan.Attribute{{Key: "instance_num", Value: 2}},
+			},
+			shouldMatch: false,
+		},
+		{
+			name: "attribute_missing",
+			cfg: &MatchProperties{
+				Config:     filterset.Config{MatchType: filterset.Strict},
+				Attributes: []filterspan.Attribute{{Key: "missing"}},
+			},
+			shouldMatch: false,
+		},
+		{
+			name: "all_properties_must_match",
+			cfg: &MatchProperties{
+				Config:        filterset.Config{MatchType: filterset.Strict},
+				Bodies:        []string{"connection refused by host"},
+				SeverityTexts: []string{"INFO"},
+			},
+			shouldMatch: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMatcher(tt.cfg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.shouldMatch, m.MatchLogRecord(lr))
+		})
+	}
+}
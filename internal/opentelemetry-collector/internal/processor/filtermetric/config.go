@@ -27,4 +27,8 @@ type MatchProperties struct {
 	// MetricNames specifies the list of string patterns to match metric names against.
 	// A match occurs if the metric name matches at least one string pattern in this list.
 	MetricNames []string `mapstructure:"metric_names"`
+
+	// Expressions specifies the list of expressions to match metric data points against, with the expr match type.
+	// A match occurs if the data point matches at least one expression in this list.
+	Expressions []string `mapstructure:"expressions"`
 }
@@ -15,23 +15,99 @@
 package filtermetric
 
 import (
+	"errors"
+
 	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
 
+	"go.opentelemetry.io/collector/internal/processor/filterexpr"
 	"go.opentelemetry.io/collector/internal/processor/filterset"
 )
 
+var (
+	errMetricNamesWithExpr    = errors.New(`"metric_names" can't be used with the "expr" match_type`)
+	errExpressionsWithoutExpr = errors.New(`"expressions" require the "expr" match_type`)
+)
+
 // Matcher matches metrics by metric properties against prespecified values for each property.
 type Matcher struct {
 	nameFilters filterset.FilterSet
+	exprMatcher *filterexpr.MetricMatcher
 }
 
 // MatchMetric matches a metric using the metric properties configured on the Matcher.
 // A metric only matches if every metric property configured on the Matcher is a match.
 func (m *Matcher) MatchMetric(metric *metricspb.Metric) bool {
-	name := metric.GetMetricDescriptor().GetName()
+	return m.MatchMetricName(metric.GetMetricDescriptor().GetName())
+}
+
+// MatchMetricName matches a metric by its name, for metrics that are not represented by OpenCensus metrics.
+// Matchers of the expr match type match data points instead, and match no metric by name.
+func (m *Matcher) MatchMetricName(name string) bool {
+	if m.nameFilters == nil {
+		return false
+	}
 	return m.nameFilters.Matches(name)
 }
 
+// MatchDataPoint matches a data point of metric, with the labels of its time series and the attributes of its
+// resource. Matchers of the expr match type evaluate their expressions, and return the error of an expression that
+// fails to be evaluated. Other matchers match the data points of the metrics they match.
+func (m *Matcher) MatchDataPoint(
+	resource *resourcepb.Resource,
+	metric *metricspb.Metric,
+	ts *metricspb.TimeSeries,
+	point *metricspb.Point,
+) (bool, error) {
+	if m.exprMatcher == nil {
+		return m.MatchMetric(metric), nil
+	}
+	return m.exprMatcher.MatchDataPoint(filterexpr.MetricDataPoint{
+		MetricName: metric.GetMetricDescriptor().GetName(),
+		Value:      pointValue(point),
+		Labels: timeSeriesLabels{
+			keys:   metric.GetMetricDescriptor().GetLabelKeys(),
+			values: ts.GetLabelValues(),
+		},
+		Resource: filterexpr.MapLabels(resource.GetLabels()),
+	})
+}
+
+// timeSeriesLabels are the labels of an OpenCensus time series.
+type timeSeriesLabels struct {
+	keys   []*metricspb.LabelKey
+	values []*metricspb.LabelValue
+}
+
+// Get returns the value of the label name.
+func (l timeSeriesLabels) Get(name string) (string, bool) {
+	for i, key := range l.keys {
+		if key.GetKey() != name {
+			continue
+		}
+		if i >= len(l.values) || !l.values[i].GetHasValue() {
+			return "", false
+		}
+		return l.values[i].GetValue(), true
+	}
+	return "", false
+}
+
+// pointValue returns the value of a scalar point, or the sum of the values of a distribution or a summary.
+func pointValue(point *metricspb.Point) float64 {
+	switch v := point.GetValue().(type) {
+	case *metricspb.Point_Int64Value:
+		return float64(v.Int64Value)
+	case *metricspb.Point_DoubleValue:
+		return v.DoubleValue
+	case *metricspb.Point_DistributionValue:
+		return v.DistributionValue.GetSum()
+	case *metricspb.Point_SummaryValue:
+		return v.SummaryValue.GetSum().GetValue()
+	}
+	return 0
+}
+
 // NewMatcher constructs a metric Matcher that can be used to match metrics by metric properties.
 // For each supported metric property, the Matcher accepts a set of prespecified values. An incoming metric
 // matches on a property if the property matches at least one of the prespecified values.
@@ -39,7 +115,25 @@ func (m *Matcher) MatchMetric(metric *metricspb.Metric) bool {
 //
 // The metric Matcher supports matching by the following metric properties:
 // - Metric name
+//
+// With the expr match type, the Matcher matches the data points of metrics with expressions instead.
 func NewMatcher(config *MatchProperties) (Matcher, error) {
+	if config.MatchType == filterset.Expr {
+		if len(config.MetricNames) > 0 {
+			return Matcher{}, errMetricNamesWithExpr
+		}
+		exprMatcher, err := filterexpr.NewMetricMatcher(config.Expressions)
+		if err != nil {
+			return Matcher{}, err
+		}
+		return Matcher{
+			exprMatcher: exprMatcher,
+		}, nil
+	}
+	if len(config.Expressions) > 0 {
+		return Matcher{}, errExpressionsWithoutExpr
+	}
+
 	nameFS, err := filterset.CreateFilterSet(config.MetricNames, &config.Config)
 	if err != nil {
 		return Matcher{}, err
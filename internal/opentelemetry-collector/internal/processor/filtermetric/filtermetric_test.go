@@ -18,7 +18,10 @@ import (
 	"testing"
 
 	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
+	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"go.opentelemetry.io/collector/internal/processor/filterset"
 )
@@ -92,6 +95,176 @@ func TestMatcherMatches(t *testing.T) {
 			assert.NoError(t, err)
 
 			assert.Equal(t, test.shouldMatch, matcher.MatchMetric(test.metric))
+			assert.Equal(t, test.shouldMatch, matcher.MatchMetricName(test.metric.MetricDescriptor.Name))
+		})
+	}
+}
+
+func createExprConfig(expressions ...string) *MatchProperties {
+	return &MatchProperties{
+		Config: filterset.Config{
+			MatchType: filterset.Expr,
+		},
+		Expressions: expressions,
+	}
+}
+
+func TestMatcherMatchesDataPoints(t *testing.T) {
+	resource := &resourcepb.Resource{Labels: map[string]string{"host": "a"}}
+	metric := &metricspb.Metric{
+		MetricDescriptor: &metricspb.MetricDescriptor{
+			Name:      "test/metric",
+			LabelKeys: []*metricspb.LabelKey{{Key: "env"}, {Key: "unset"}},
+		},
+	}
+	ts := &metricspb.TimeSeries{
+		LabelValues: []*metricspb.LabelValue{{Value: "prod", HasValue: true}, {}},
+	}
+	intPoint := &metricspb.Point{Value: &metricspb.Point_Int64Value{Int64Value: 10}}
+	doublePoint := &metricspb.Point{Value: &metricspb.Point_DoubleValue{DoubleValue: 2.5}}
+	distributionPoint := &metricspb.Point{Value: &metricspb.Point_DistributionValue{
+		DistributionValue: &metricspb.DistributionValue{Sum: 100},
+	}}
+	summaryPoint := &metricspb.Point{Value: &metricspb.Point_SummaryValue{
+		SummaryValue: &metricspb.SummaryValue{Sum: &wrappers.DoubleValue{Value: 50}},
+	}}
+
+	tests := []struct {
+		name        string
+		cfg         *MatchProperties
+		point       *metricspb.Point
+		shouldMatch bool
+		returnError bool
+	}{
+		{
+			name:        "exprMetricName",
+			cfg:         createExprConfig(`MetricName == "test/metric"`),
+			point:       intPoint,
+			shouldMatch: true,
+		}, {
+			name:        "exprIntValue",
+			cfg:         createExprConfig(`Value == 10`),
+			point:       intPoint,
+			shouldMatch: true,
+		}, {
+			name:        "exprDoubleValue",
+			cfg:         createExprConfig(`Value > 2 && Value < 3`),
+			point:       doublePoint,
+			shouldMatch: true,
+		}, {
+			name:        "exprDistributionSum",
+			cfg:         createExprConfig(`Value == 100`),
+			point:       distributionPoint,
+			shouldMatch: true,
+		}, {
+			name:        "exprSummarySum",
+			cfg:         createExprConfig(`Value == 50`),
+			point:       summaryPoint,
+			shouldMatch: true,
+		}, {
+			name:        "exprLabel",
+			cfg:         createExprConfig(`Label("env") == "prod" && HasLabel("env")`),
+			point:       intPoint,
+			shouldMatch: true,
+		}, {
+			name:        "exprUnsetLabel",
+			cfg:         createExprConfig(`HasLabel("unset")`),
+			point:       intPoint,
+			shouldMatch: false,
+		}, {
+			name:        "exprResource",
+			cfg:         createExprConfig(`Resource("host") == "a"`),
+			point:       intPoint,
+			shouldMatch: true,
+		}, {
+			name:        "exprSecondExpression",
+			cfg:         createExprConfig(`Value > 10`, `Label("env") == "prod"`),
+			point:       intPoint,
+			shouldMatch: true,
+		}, {
+			name:        "exprMismatch",
+			cfg:         createExprConfig(`Value > 10`, `Label("env") == "dev"`),
+			point:       intPoint,
+			shouldMatch: false,
+		}, {
+			name:        "exprEvaluationError",
+			cfg:         createExprConfig(`MetricName matches Label("env") + "("`),
+			point:       intPoint,
+			returnError: true,
+		}, {
+			name:        "nameMatch",
+			cfg:         createConfig([]string{"test/.*"}, filterset.Regexp),
+			point:       intPoint,
+			shouldMatch: true,
+		}, {
+			name:        "nameMismatch",
+			cfg:         createConfig([]string{"other"}, filterset.Strict),
+			point:       intPoint,
+			shouldMatch: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matcher, err := NewMatcher(test.cfg)
+			require.NoError(t, err)
+
+			match, err := matcher.MatchDataPoint(resource, metric, ts, test.point)
+			if test.returnError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.shouldMatch, match)
+		})
+	}
+}
+
+func TestMatcherExprMatchesNoMetricName(t *testing.T) {
+	matcher, err := NewMatcher(createExprConfig(`MetricName == "metric"`))
+	require.NoError(t, err)
+
+	assert.False(t, matcher.MatchMetric(createMetric("metric")))
+	assert.False(t, matcher.MatchMetricName("metric"))
+}
+
+func TestNewMatcherInvalidExpr(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *MatchProperties
+		err  string
+	}{
+		{
+			name: "metricNamesWithExpr",
+			cfg: &MatchProperties{
+				Config:      filterset.Config{MatchType: filterset.Expr},
+				MetricNames: []string{"metric"},
+				Expressions: []string{`Value > 0`},
+			},
+			err: errMetricNamesWithExpr.Error(),
+		}, {
+			name: "expressionsWithoutExpr",
+			cfg: &MatchProperties{
+				Config:      filterset.Config{MatchType: filterset.Strict},
+				Expressions: []string{`Value > 0`},
+			},
+			err: errExpressionsWithoutExpr.Error(),
+		}, {
+			name: "noExpressions",
+			cfg:  createExprConfig(),
+			err:  "at least one expression must be specified",
+		}, {
+			name: "invalidExpression",
+			cfg:  createExprConfig(`Value >`),
+			err:  `error compiling expression "Value >"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewMatcher(test.cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
 		})
 	}
 }
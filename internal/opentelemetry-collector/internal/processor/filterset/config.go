@@ -29,6 +29,9 @@ const (
 	Regexp MatchType = "regexp"
 	// Strict is the FilterType for filtering by exact string matches.
 	Strict MatchType = "strict"
+	// Expr is the MatchType for matching metric data points and spans with expressions. It is not a FilterSet
+	// type, and is handled by the filtermetric and filterspan packages instead.
+	Expr MatchType = "expr"
 	// MatchTypeFieldName is the mapstructure field name for MatchType field.
 	MatchTypeFieldName = "match_type"
 )
@@ -69,7 +69,7 @@ type MatchProperties struct {
 	filterset.Config `mapstructure:",squash"`
 
 	// Note: one of Services, SpanNames or Attributes must be specified with a
-	// non-empty value for a valid configuration.
+	// non-empty value for a valid configuration, unless the match type is expr.
 
 	// Services specify the list of of items to match service name against.
 	// A match occurs if the span's service name matches at least one item in this list.
@@ -86,6 +86,12 @@ type MatchProperties struct {
 	// Only match_type=strict is allowed if "attributes" are specified.
 	// This is an optional field.
 	Attributes []Attribute `mapstructure:"attributes"`
+
+	// Expressions specifies the list of expressions to match spans against.
+	// A match occurs if the span matches at least one expression in this list.
+	// Only allowed, and required, with match_type=expr. None of Services,
+	// SpanNames or Attributes can be specified along with it.
+	Expressions []string `mapstructure:"expressions"`
 }
 
 // MatchTypeFieldName is the mapstructure field name for MatchProperties.Attributes field.
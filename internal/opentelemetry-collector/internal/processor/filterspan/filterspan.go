@@ -19,6 +19,7 @@ import (
 	"fmt"
 
 	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/internal/processor/filterexpr"
 	"go.opentelemetry.io/collector/internal/processor/filterhelper"
 	"go.opentelemetry.io/collector/internal/processor/filterset"
 )
@@ -28,14 +29,22 @@ var (
 	errAtLeastOneMatchFieldNeeded = errors.New(
 		`error creating processor. At least one ` +
 			`of "services", "span_names" or "attributes" field must be specified"`)
+
+	errPropertiesWithExpr = errors.New(
+		`error creating processor. "services", "span_names" and "attributes" ` +
+			`can't be used with the "expr" match_type`)
+
+	errExpressionsWithoutExpr = errors.New(
+		`error creating processor. "expressions" require the "expr" match_type`)
 )
 
 // TODO: Modify Matcher to invoke both the include and exclude properties so
 // calling processors will always have the same logic.
 // Matcher is an interface that allows matching a span against a configuration
-// of a match.
+// of a match. An error is returned if the span could not be matched, such as
+// when an expression fails to be evaluated.
 type Matcher interface {
-	MatchSpan(span pdata.Span, serviceName string) bool
+	MatchSpan(span pdata.Span, serviceName string) (bool, error)
 }
 
 // propertiesMatcher allows matching a span against various span properties.
@@ -65,6 +74,13 @@ func NewMatcher(mp *MatchProperties) (Matcher, error) {
 		return nil, nil
 	}
 
+	if mp.MatchType == filterset.Expr {
+		return newExprMatcher(mp)
+	}
+	if len(mp.Expressions) > 0 {
+		return nil, errExpressionsWithoutExpr
+	}
+
 	if len(mp.Services) == 0 && len(mp.SpanNames) == 0 && len(mp.Attributes) == 0 {
 		return nil, errAtLeastOneMatchFieldNeeded
 	}
@@ -102,6 +118,28 @@ func NewMatcher(mp *MatchProperties) (Matcher, error) {
 	}, nil
 }
 
+// exprMatcher allows matching a span against expressions.
+type exprMatcher struct {
+	matcher *filterexpr.SpanMatcher
+}
+
+func newExprMatcher(mp *MatchProperties) (Matcher, error) {
+	if len(mp.Services) > 0 || len(mp.SpanNames) > 0 || len(mp.Attributes) > 0 {
+		return nil, errPropertiesWithExpr
+	}
+	matcher, err := filterexpr.NewSpanMatcher(mp.Expressions)
+	if err != nil {
+		return nil, fmt.Errorf("error creating span expressions: %v", err)
+	}
+	return &exprMatcher{matcher: matcher}, nil
+}
+
+// MatchSpan matches a span and service if at least one of the expressions evaluates to true.
+// The error of an expression that fails to be evaluated is returned.
+func (em *exprMatcher) MatchSpan(span pdata.Span, serviceName string) (bool, error) {
+	return em.matcher.MatchSpan(span, serviceName)
+}
+
 func newAttributesMatcher(mp *MatchProperties) (attributesMatcher, error) {
 	// attribute matching is only supported with strict matching
 	if mp.Config.MatchType != filterset.Strict {
@@ -142,18 +180,18 @@ func newAttributesMatcher(mp *MatchProperties) (attributesMatcher, error) {
 // At least one of services, span names or attributes must be specified. It is supported
 // to have more than one of these specified, and all specified must evaluate
 // to true for a match to occur.
-func (mp *propertiesMatcher) MatchSpan(span pdata.Span, serviceName string) bool {
+func (mp *propertiesMatcher) MatchSpan(span pdata.Span, serviceName string) (bool, error) {
 	// If a set of properties was not in the mp, all spans are considered to match on that property
 	if mp.serviceFilters != nil && !mp.serviceFilters.Matches(serviceName) {
-		return false
+		return false, nil
 	}
 
 	if mp.nameFilters != nil && !mp.nameFilters.Matches(span.Name()) {
-		return false
+		return false, nil
 	}
 
 	// Service name and span name matched. Now match attributes.
-	return mp.Attributes.match(span)
+	return mp.Attributes.match(span), nil
 }
 
 // match attributes specification against a span.
@@ -102,6 +102,30 @@ func TestSpan_validateMatchesConfiguration_InvalidConfig(t *testing.T) {
 			},
 			errorString: "error creating processor. Can't have empty key in the list of attributes",
 		},
+		{
+			name: "properties_with_expr_match_type",
+			property: MatchProperties{
+				Config:      *createConfig(filterset.Expr),
+				Services:    []string{"a"},
+				Expressions: []string{`SpanName == "a"`},
+			},
+			errorString: errPropertiesWithExpr.Error(),
+		},
+		{
+			name: "expressions_without_expr_match_type",
+			property: MatchProperties{
+				Config:      *createConfig(filterset.Strict),
+				Expressions: []string{`SpanName == "a"`},
+			},
+			errorString: errExpressionsWithoutExpr.Error(),
+		},
+		{
+			name: "missing_expressions",
+			property: MatchProperties{
+				Config: *createConfig(filterset.Expr),
+			},
+			errorString: "error creating span expressions: at least one expression must be specified",
+		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -209,7 +233,9 @@ func TestSpan_Matching_False(t *testing.T) {
 			assert.Nil(t, err)
 			assert.NotNil(t, matcher)
 
-			assert.False(t, matcher.MatchSpan(span, "wrongSvc"))
+			match, err := matcher.MatchSpan(span, "wrongSvc")
+			require.NoError(t, err)
+			assert.False(t, match)
 		})
 	}
 }
@@ -232,7 +258,9 @@ func TestSpan_MatchingCornerCases(t *testing.T) {
 
 	emptySpan := pdata.NewSpan()
 	emptySpan.InitEmpty()
-	assert.False(t, mp.MatchSpan(emptySpan, "svcA"))
+	match, err := mp.MatchSpan(emptySpan, "svcA")
+	assert.NoError(t, err)
+	assert.False(t, match)
 }
 
 func TestSpan_MissingServiceName(t *testing.T) {
@@ -247,7 +275,9 @@ func TestSpan_MissingServiceName(t *testing.T) {
 
 	emptySpan := pdata.NewSpan()
 	emptySpan.InitEmpty()
-	assert.False(t, mp.MatchSpan(emptySpan, ""))
+	match, err := mp.MatchSpan(emptySpan, "")
+	assert.NoError(t, err)
+	assert.False(t, match)
 }
 
 func TestSpan_Matching_True(t *testing.T) {
@@ -373,6 +403,80 @@ func TestSpan_Matching_True(t *testing.T) {
 	}
 }
 
+func TestSpan_MatchingExpressionError(t *testing.T) {
+	matcher, err := NewMatcher(&MatchProperties{
+		Config:      *createConfig(filterset.Expr),
+		Expressions: []string{`SpanName matches Attribute("pattern")`},
+	})
+	require.NoError(t, err)
+
+	span := pdata.NewSpan()
+	span.InitEmpty()
+	span.SetName("spanName")
+	span.Attributes().InitFromMap(map[string]pdata.AttributeValue{"pattern": pdata.NewAttributeValueString("(")})
+
+	_, err = matcher.MatchSpan(span, "svcA")
+	assert.Error(t, err)
+}
+
+func TestSpan_MatchingExpressions(t *testing.T) {
+	testcases := []struct {
+		name        string
+		expressions []string
+		match       bool
+	}{
+		{
+			name:        "span_name_match",
+			expressions: []string{`SpanName matches "^span"`},
+			match:       true,
+		},
+		{
+			name:        "service_match",
+			expressions: []string{`Service == "svcA" && SpanName == "spanName"`},
+			match:       true,
+		},
+		{
+			name:        "attribute_match",
+			expressions: []string{`Attribute("keyInt") == "123" && HasAttribute("keyString")`},
+			match:       true,
+		},
+		{
+			name:        "second_expression_match",
+			expressions: []string{`Service == "svcB"`, `Attribute("keyString") == "arithmetic"`},
+			match:       true,
+		},
+		{
+			name:        "service_mismatch",
+			expressions: []string{`Service == "svcB"`},
+		},
+		{
+			name:        "attribute_does_not_exist",
+			expressions: []string{`HasAttribute("doesnotexist")`},
+		},
+	}
+
+	span := pdata.NewSpan()
+	span.InitEmpty()
+	span.SetName("spanName")
+	span.Attributes().InitFromMap(map[string]pdata.AttributeValue{
+		"keyString": pdata.NewAttributeValueString("arithmetic"),
+		"keyInt":    pdata.NewAttributeValueInt(123),
+	})
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			matcher, err := NewMatcher(&MatchProperties{
+				Config:      *createConfig(filterset.Expr),
+				Expressions: tc.expressions,
+			})
+			require.NoError(t, err)
+			match, err := matcher.MatchSpan(span, "svcA")
+			require.NoError(t, err)
+			assert.Equal(t, tc.match, match)
+		})
+	}
+}
+
 func TestSpan_validateMatchesConfigurationForAttributes(t *testing.T) {
 	testcase := []struct {
 		name   string
@@ -83,6 +83,13 @@ var (
 		stats.UnitDimensionless)
 )
 
+// BuildExporterCustomMetricName is used to be build a metric name following
+// the standards used in the Collector. The configType should be the same
+// value used to identify the type on the config.
+func BuildExporterCustomMetricName(configType, metric string) string {
+	return buildComponentPrefix(exporterPrefix, configType) + metric
+}
+
 // StartTraceDataExportOp is called at the start of an Export operation.
 // The returned context should be used in other calls to the obsreport functions
 // dealing with the same export operation.
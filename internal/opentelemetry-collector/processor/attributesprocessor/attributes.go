@@ -17,7 +17,10 @@ package attributesprocessor
 import (
 	"context"
 
+	"go.uber.org/zap"
+
 	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/internal/processor/filterexpr"
 	"go.opentelemetry.io/collector/internal/processor/filterspan"
 	"go.opentelemetry.io/collector/processor"
 	"go.opentelemetry.io/collector/processor/processorhelper"
@@ -27,21 +30,23 @@ type attributesProcessor struct {
 	attrProc *processorhelper.AttrProc
 	include  filterspan.Matcher
 	exclude  filterspan.Matcher
+	logger   *zap.Logger
 }
 
 // newTraceProcessor returns a processor that modifies attributes of a span.
 // To construct the attributes processors, the use of the factory methods are required
 // in order to validate the inputs.
-func newAttributesProcessor(attrProc *processorhelper.AttrProc, include, exclude filterspan.Matcher) *attributesProcessor {
+func newAttributesProcessor(attrProc *processorhelper.AttrProc, include, exclude filterspan.Matcher, logger *zap.Logger) *attributesProcessor {
 	return &attributesProcessor{
 		attrProc: attrProc,
 		include:  include,
 		exclude:  exclude,
+		logger:   logger,
 	}
 }
 
 // ProcessTraces implements the TProcessor
-func (a *attributesProcessor) ProcessTraces(_ context.Context, td pdata.Traces) (pdata.Traces, error) {
+func (a *attributesProcessor) ProcessTraces(ctx context.Context, td pdata.Traces) (pdata.Traces, error) {
 	rss := td.ResourceSpans()
 	for i := 0; i < rss.Len(); i++ {
 		rs := rss.At(i)
@@ -63,7 +68,7 @@ func (a *attributesProcessor) ProcessTraces(_ context.Context, td pdata.Traces)
 					continue
 				}
 
-				if a.skipSpan(span, serviceName) {
+				if a.skipSpan(ctx, span, serviceName) {
 					continue
 				}
 
@@ -80,20 +85,39 @@ func (a *attributesProcessor) ProcessTraces(_ context.Context, td pdata.Traces)
 // The logic determining if a span should be processed is set
 // in the attribute configuration with the include and exclude settings.
 // Include properties are checked before exclude settings are checked.
-func (a *attributesProcessor) skipSpan(span pdata.Span, serviceName string) bool {
+// Spans that fail to be matched, such as when an expression fails to be
+// evaluated, are skipped and the failure is logged and recorded.
+func (a *attributesProcessor) skipSpan(ctx context.Context, span pdata.Span, serviceName string) bool {
 	if a.include != nil {
+		include, err := a.include.MatchSpan(span, serviceName)
+		if err != nil {
+			a.matchFailed(ctx, span, err)
+			return true
+		}
 		// A false returned in this case means the span should not be processed.
-		if include := a.include.MatchSpan(span, serviceName); !include {
+		if !include {
 			return true
 		}
 	}
 
 	if a.exclude != nil {
+		exclude, err := a.exclude.MatchSpan(span, serviceName)
+		if err != nil {
+			a.matchFailed(ctx, span, err)
+			return true
+		}
 		// A true returned in this case means the span should not be processed.
-		if exclude := a.exclude.MatchSpan(span, serviceName); exclude {
+		if exclude {
 			return true
 		}
 	}
 
 	return false
 }
+
+// matchFailed logs and records that span failed to be matched by the include or exclude settings.
+func (a *attributesProcessor) matchFailed(ctx context.Context, span pdata.Span, err error) {
+	a.logger.Warn("failed to match span, its attributes are left unmodified",
+		zap.String("span", span.Name()), zap.Error(err))
+	filterexpr.RecordEvaluationError(ctx)
+}
@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributesprocessor
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/internal/processor/filterlog"
+	"go.opentelemetry.io/collector/internal/processor/filterset"
+	"go.opentelemetry.io/collector/internal/processor/filterspan"
+	"go.opentelemetry.io/collector/obsreport"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+var errSpanPropertiesForLogs = errors.New(
+	`error creating "attributes" processor. "services", "span_names", "expressions" and the "expr" match_type ` +
+		`can't be used to include/exclude log records`)
+
+type logAttributesProcessor struct {
+	attrProc *processorhelper.AttrProc
+	include  *filterlog.Matcher
+	exclude  *filterlog.Matcher
+}
+
+// newLogAttributesProcessor returns a processor that modifies attributes of a log record.
+// To construct the attributes processors, the use of the factory methods are required
+// in order to validate the inputs.
+func newLogAttributesProcessor(attrProc *processorhelper.AttrProc, include, exclude *filterlog.Matcher) *logAttributesProcessor {
+	return &logAttributesProcessor{
+		attrProc: attrProc,
+		include:  include,
+		exclude:  exclude,
+	}
+}
+
+// newLogMatcher creates a log record Matcher from the include/exclude properties of the processor. Log records are
+// only matched by attributes, as they have neither a service nor a span name.
+func newLogMatcher(mp *filterspan.MatchProperties) (*filterlog.Matcher, error) {
+	if mp == nil {
+		return nil, nil
+	}
+	if len(mp.Services) > 0 || len(mp.SpanNames) > 0 || len(mp.Expressions) > 0 || mp.MatchType == filterset.Expr {
+		return nil, errSpanPropertiesForLogs
+	}
+	return filterlog.NewMatcher(&filterlog.MatchProperties{
+		Config:     mp.Config,
+		Attributes: mp.Attributes,
+	})
+}
+
+// ProcessLogs implements the LProcessor
+func (a *logAttributesProcessor) ProcessLogs(ctx context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		if rl.IsNil() {
+			continue
+		}
+		ills := rl.InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			ill := ills.At(j)
+			if ill.IsNil() {
+				continue
+			}
+			logs := ill.Logs()
+			for k := 0; k < logs.Len(); k++ {
+				lr := logs.At(k)
+				if lr.IsNil() {
+					// Do not create empty log records just to add attributes
+					continue
+				}
+
+				if a.skipLogRecord(lr) {
+					continue
+				}
+
+				a.attrProc.Process(lr.Attributes())
+			}
+		}
+	}
+	obsreport.ProcessorLogRecordsAccepted(ctx, ld.LogRecordCount())
+	return ld, nil
+}
+
+// skipLogRecord determines if a log record should be processed.
+// True is returned when a log record should be skipped.
+// Include properties are checked before exclude settings are checked.
+func (a *logAttributesProcessor) skipLogRecord(lr pdata.LogRecord) bool {
+	if a.include != nil && !a.include.MatchLogRecord(lr) {
+		return true
+	}
+
+	if a.exclude != nil && a.exclude.MatchLogRecord(lr) {
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,203 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributesprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/internal/data/testdata"
+	"go.opentelemetry.io/collector/internal/processor/filterset"
+	"go.opentelemetry.io/collector/internal/processor/filterspan"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+// runIndividualLogTestCase is the common logic of passing log data through a configured attributes processor.
+func runIndividualLogTestCase(t *testing.T, tt testCase, lp component.LogsProcessor) {
+	t.Run(tt.name, func(t *testing.T) {
+		ld := generateLogData(tt.name, tt.inputAttributes)
+		assert.NoError(t, lp.ConsumeLogs(context.Background(), ld))
+		// Ensure that the modified `ld` has the attributes sorted:
+		sortLogAttributes(ld)
+		require.Equal(t, generateLogData(tt.name, tt.expectedAttributes), ld)
+	})
+}
+
+func generateLogData(logName string, attrs map[string]pdata.AttributeValue) pdata.Logs {
+	ld := pdata.NewLogs()
+	ld.ResourceLogs().Resize(1)
+	rl := ld.ResourceLogs().At(0)
+	rl.InstrumentationLibraryLogs().Resize(1)
+	ill := rl.InstrumentationLibraryLogs().At(0)
+	logs := ill.Logs()
+	logs.Resize(1)
+	logs.At(0).SetName(logName)
+	logs.At(0).Attributes().InitFromMap(attrs).Sort()
+	return ld
+}
+
+func sortLogAttributes(ld pdata.Logs) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		ills := rls.At(i).InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				logs.At(k).Attributes().Sort()
+			}
+		}
+	}
+}
+
+func TestLogProcessor_NilEmptyData(t *testing.T) {
+	type nilEmptyTestCase struct {
+		name   string
+		input  pdata.Logs
+		output pdata.Logs
+	}
+	testCases := []nilEmptyTestCase{
+		{
+			name:   "empty",
+			input:  testdata.GenerateLogDataEmpty(),
+			output: testdata.GenerateLogDataEmpty(),
+		},
+		{
+			name:   "one-empty-resource-logs",
+			input:  testdata.GenerateLogDataOneEmptyResourceLogs(),
+			output: testdata.GenerateLogDataOneEmptyResourceLogs(),
+		},
+		{
+			name:   "one-empty-one-nil-resource-logs",
+			input:  testdata.GenerateLogDataOneEmptyOneNilResourceLogs(),
+			output: testdata.GenerateLogDataOneEmptyOneNilResourceLogs(),
+		},
+		{
+			name:   "no-log-records",
+			input:  testdata.GenerateLogDataNoLogRecords(),
+			output: testdata.GenerateLogDataNoLogRecords(),
+		},
+	}
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	oCfg := cfg.(*Config)
+	oCfg.Settings.Actions = []processorhelper.ActionKeyValue{
+		{Key: "attribute1", Action: processorhelper.INSERT, Value: 123},
+		{Key: "attribute1", Action: processorhelper.DELETE},
+	}
+
+	lp, err := factory.CreateLogsProcessor(
+		context.Background(), component.ProcessorCreateParams{}, oCfg, exportertest.NewNopLogsExporter())
+	require.Nil(t, err)
+	require.NotNil(t, lp)
+	for i := range testCases {
+		tt := testCases[i]
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, lp.ConsumeLogs(context.Background(), tt.input))
+			assert.EqualValues(t, tt.output, tt.input)
+		})
+	}
+}
+
+func TestAttributes_FilterLogRecords(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "apply processor",
+			inputAttributes: map[string]pdata.AttributeValue{
+				"app": pdata.NewAttributeValueString("server"),
+			},
+			expectedAttributes: map[string]pdata.AttributeValue{
+				"app":        pdata.NewAttributeValueString("server"),
+				"attribute1": pdata.NewAttributeValueInt(123),
+			},
+		},
+		{
+			name:               "missing attribute for include property",
+			inputAttributes:    map[string]pdata.AttributeValue{},
+			expectedAttributes: map[string]pdata.AttributeValue{},
+		},
+		{
+			name: "attribute match for exclude property",
+			inputAttributes: map[string]pdata.AttributeValue{
+				"app":            pdata.NewAttributeValueString("server"),
+				"NoModification": pdata.NewAttributeValueBool(true),
+			},
+			expectedAttributes: map[string]pdata.AttributeValue{
+				"app":            pdata.NewAttributeValueString("server"),
+				"NoModification": pdata.NewAttributeValueBool(true),
+			},
+		},
+	}
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	oCfg := cfg.(*Config)
+	oCfg.Actions = []processorhelper.ActionKeyValue{
+		{Key: "attribute1", Action: processorhelper.INSERT, Value: 123},
+	}
+	oCfg.Include = &filterspan.MatchProperties{
+		Attributes: []filterspan.Attribute{
+			{Key: "app", Value: "server"},
+		},
+		Config: *createConfig(filterset.Strict),
+	}
+	oCfg.Exclude = &filterspan.MatchProperties{
+		Attributes: []filterspan.Attribute{
+			{Key: "NoModification", Value: true},
+		},
+		Config: *createConfig(filterset.Strict),
+	}
+	lp, err := factory.CreateLogsProcessor(context.Background(), component.ProcessorCreateParams{}, cfg, exportertest.NewNopLogsExporter())
+	require.Nil(t, err)
+	require.NotNil(t, lp)
+
+	for _, tt := range testCases {
+		runIndividualLogTestCase(t, tt, lp)
+	}
+}
+
+func TestAttributes_HashLogRecords(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "String",
+			inputAttributes: map[string]pdata.AttributeValue{
+				"user.email": pdata.NewAttributeValueString("john.doe@example.com"),
+			},
+			expectedAttributes: map[string]pdata.AttributeValue{
+				"user.email": pdata.NewAttributeValueString("73ec53c4ba1747d485ae2a0d7bfafa6cda80a5a9"),
+			},
+		},
+	}
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	oCfg := cfg.(*Config)
+	oCfg.Actions = []processorhelper.ActionKeyValue{
+		{Key: "user.email", Action: processorhelper.HASH},
+	}
+
+	lp, err := factory.CreateLogsProcessor(context.Background(), component.ProcessorCreateParams{}, cfg, exportertest.NewNopLogsExporter())
+	require.Nil(t, err)
+	require.NotNil(t, lp)
+
+	for _, tt := range testCases {
+		runIndividualLogTestCase(t, tt, lp)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributesprocessor
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/consumer/pdatautil"
+	"go.opentelemetry.io/collector/internal/dataold"
+	"go.opentelemetry.io/collector/internal/processor/filtermetric"
+	"go.opentelemetry.io/collector/internal/processor/filterset"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+var errSpanPropertiesForMetrics = errors.New(
+	`error creating "attributes" processor. "include" and "exclude" can't be used to include/exclude metrics, "metrics" must be used instead`)
+
+var errExprForMetrics = errors.New(
+	`error creating "attributes" processor. The "expr" match_type can't be used to include/exclude metrics`)
+
+type metricAttributesProcessor struct {
+	attrProc *processorhelper.AttrProc
+	include  *filtermetric.Matcher
+	exclude  *filtermetric.Matcher
+}
+
+// newMetricAttributesProcessor returns a processor that modifies the labels of the data points of a metric.
+// To construct the attributes processors, the use of the factory methods are required
+// in order to validate the inputs.
+func newMetricAttributesProcessor(attrProc *processorhelper.AttrProc, include, exclude *filtermetric.Matcher) *metricAttributesProcessor {
+	return &metricAttributesProcessor{
+		attrProc: attrProc,
+		include:  include,
+		exclude:  exclude,
+	}
+}
+
+// newMetricMatcher creates a metric Matcher from the include/exclude properties of the processor.
+func newMetricMatcher(mp *filtermetric.MatchProperties) (*filtermetric.Matcher, error) {
+	if mp == nil {
+		return nil, nil
+	}
+	// labels are processed metric by metric, while expressions match data points
+	if mp.MatchType == filterset.Expr {
+		return nil, errExprForMetrics
+	}
+	matcher, err := filtermetric.NewMatcher(mp)
+	if err != nil {
+		return nil, err
+	}
+	return &matcher, nil
+}
+
+// ProcessMetrics implements the MProcessor interface
+func (a *metricAttributesProcessor) ProcessMetrics(_ context.Context, md pdata.Metrics) (pdata.Metrics, error) {
+	imd := pdatautil.MetricsToOldInternalMetrics(md)
+	rms := imd.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		if rm.IsNil() {
+			continue
+		}
+		ilms := rm.InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			ilm := ilms.At(j)
+			if ilm.IsNil() {
+				continue
+			}
+			metrics := ilm.Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				if metric.IsNil() {
+					continue
+				}
+
+				if a.skipMetric(metric) {
+					continue
+				}
+
+				a.processDataPoints(metric)
+			}
+		}
+	}
+	return pdatautil.MetricsFromOldInternalMetrics(imd), nil
+}
+
+// processDataPoints applies the actions to the labels of every data point of metric.
+func (a *metricAttributesProcessor) processDataPoints(metric dataold.Metric) {
+	int64DataPoints := metric.Int64DataPoints()
+	for i := 0; i < int64DataPoints.Len(); i++ {
+		if dp := int64DataPoints.At(i); !dp.IsNil() {
+			a.attrProc.ProcessLabels(dp.LabelsMap())
+		}
+	}
+	doubleDataPoints := metric.DoubleDataPoints()
+	for i := 0; i < doubleDataPoints.Len(); i++ {
+		if dp := doubleDataPoints.At(i); !dp.IsNil() {
+			a.attrProc.ProcessLabels(dp.LabelsMap())
+		}
+	}
+	histogramDataPoints := metric.HistogramDataPoints()
+	for i := 0; i < histogramDataPoints.Len(); i++ {
+		if dp := histogramDataPoints.At(i); !dp.IsNil() {
+			a.attrProc.ProcessLabels(dp.LabelsMap())
+		}
+	}
+	summaryDataPoints := metric.SummaryDataPoints()
+	for i := 0; i < summaryDataPoints.Len(); i++ {
+		if dp := summaryDataPoints.At(i); !dp.IsNil() {
+			a.attrProc.ProcessLabels(dp.LabelsMap())
+		}
+	}
+}
+
+// skipMetric determines if a metric should be processed.
+// True is returned when a metric should be skipped.
+// Include properties are checked before exclude settings are checked.
+func (a *metricAttributesProcessor) skipMetric(metric dataold.Metric) bool {
+	var name string
+	if desc := metric.MetricDescriptor(); !desc.IsNil() {
+		name = desc.Name()
+	}
+
+	if a.include != nil && !a.include.MatchMetricName(name) {
+		return true
+	}
+
+	if a.exclude != nil && a.exclude.MatchMetricName(name) {
+		return true
+	}
+
+	return false
+}
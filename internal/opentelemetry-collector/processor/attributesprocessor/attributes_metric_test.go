@@ -0,0 +1,216 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributesprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/consumer/pdatautil"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/internal/dataold"
+	"go.opentelemetry.io/collector/internal/processor/filtermetric"
+	"go.opentelemetry.io/collector/internal/processor/filterset"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+type metricTestCase struct {
+	name           string
+	metricName     string
+	inputLabels    map[string]string
+	expectedLabels map[string]string
+}
+
+// runIndividualMetricTestCase is the common logic of passing metric data through a configured attributes processor.
+func runIndividualMetricTestCase(t *testing.T, tt metricTestCase, mp component.MetricsProcessor) {
+	t.Run(tt.name, func(t *testing.T) {
+		md := generateMetricData(tt.metricName, tt.inputLabels)
+		assert.NoError(t, mp.ConsumeMetrics(context.Background(), md))
+		// Ensure that the modified `md` has the labels sorted:
+		sortMetricLabels(md)
+		require.Equal(t, generateMetricData(tt.metricName, tt.expectedLabels), md)
+	})
+}
+
+// generateMetricData returns a metric with an int64 and a summary data point, both with labels.
+func generateMetricData(metricName string, labels map[string]string) pdata.Metrics {
+	md := dataold.NewMetricData()
+	md.ResourceMetrics().Resize(1)
+	rm := md.ResourceMetrics().At(0)
+	rm.InstrumentationLibraryMetrics().Resize(1)
+	ilm := rm.InstrumentationLibraryMetrics().At(0)
+	ilm.Metrics().Resize(1)
+	metric := ilm.Metrics().At(0)
+	metric.MetricDescriptor().InitEmpty()
+	metric.MetricDescriptor().SetName(metricName)
+	metric.Int64DataPoints().Resize(1)
+	metric.Int64DataPoints().At(0).LabelsMap().InitFromMap(labels).Sort()
+	metric.SummaryDataPoints().Resize(1)
+	metric.SummaryDataPoints().At(0).LabelsMap().InitFromMap(labels).Sort()
+	return pdatautil.MetricsFromOldInternalMetrics(md)
+}
+
+func sortMetricLabels(md pdata.Metrics) {
+	rms := pdatautil.MetricsToOldInternalMetrics(md).ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		ilms := rms.At(i).InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			metrics := ilms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metrics.At(k).Int64DataPoints().At(0).LabelsMap().Sort()
+				metrics.At(k).SummaryDataPoints().At(0).LabelsMap().Sort()
+			}
+		}
+	}
+}
+
+func TestMetricProcessor_NilEmptyData(t *testing.T) {
+	md := dataold.NewMetricData()
+	md.ResourceMetrics().Resize(2)
+	md.ResourceMetrics().At(1).InstrumentationLibraryMetrics().Resize(2)
+	md.ResourceMetrics().At(1).InstrumentationLibraryMetrics().At(1).Metrics().Resize(2)
+	md.ResourceMetrics().At(1).InstrumentationLibraryMetrics().At(1).Metrics().At(1).Int64DataPoints().Resize(1)
+	md.ResourceMetrics().At(1).InstrumentationLibraryMetrics().At(1).Metrics().At(1).Int64DataPoints().At(0).
+		LabelsMap().InitFromMap(map[string]string{"label2": "value"})
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	oCfg := cfg.(*Config)
+	oCfg.Settings.Actions = []processorhelper.ActionKeyValue{
+		{Key: "label1", Action: processorhelper.INSERT, Value: 123},
+		{Key: "label1", Action: processorhelper.DELETE},
+	}
+
+	mp, err := factory.CreateMetricsProcessor(
+		context.Background(), component.ProcessorCreateParams{}, exportertest.NewNopMetricsExporter(), oCfg)
+	require.Nil(t, err)
+	require.NotNil(t, mp)
+
+	input := pdatautil.MetricsFromOldInternalMetrics(md)
+	expected := pdatautil.MetricsFromOldInternalMetrics(md.Clone())
+	assert.NoError(t, mp.ConsumeMetrics(context.Background(), input))
+	assert.EqualValues(t, expected, input)
+}
+
+func TestAttributes_FilterMetrics(t *testing.T) {
+	testCases := []metricTestCase{
+		{
+			name:       "apply processor",
+			metricName: "requests",
+			inputLabels: map[string]string{
+				"user_id": "1234",
+				"method":  "GET",
+			},
+			expectedLabels: map[string]string{
+				"method": "GET",
+			},
+		},
+		{
+			name:       "metric name mismatch for include property",
+			metricName: "latency",
+			inputLabels: map[string]string{
+				"user_id": "1234",
+			},
+			expectedLabels: map[string]string{
+				"user_id": "1234",
+			},
+		},
+		{
+			name:       "metric name match for exclude property",
+			metricName: "requests_internal",
+			inputLabels: map[string]string{
+				"user_id": "1234",
+			},
+			expectedLabels: map[string]string{
+				"user_id": "1234",
+			},
+		},
+	}
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	oCfg := cfg.(*Config)
+	oCfg.Actions = []processorhelper.ActionKeyValue{
+		{Key: "user_id", Action: processorhelper.DELETE},
+	}
+	oCfg.Metrics.Include = &filtermetric.MatchProperties{
+		Config:      filterset.Config{MatchType: filterset.Regexp},
+		MetricNames: []string{"^requests.*"},
+	}
+	oCfg.Metrics.Exclude = &filtermetric.MatchProperties{
+		Config:      filterset.Config{MatchType: filterset.Strict},
+		MetricNames: []string{"requests_internal"},
+	}
+	mp, err := factory.CreateMetricsProcessor(
+		context.Background(), component.ProcessorCreateParams{}, exportertest.NewNopMetricsExporter(), cfg)
+	require.Nil(t, err)
+	require.NotNil(t, mp)
+
+	for _, tt := range testCases {
+		runIndividualMetricTestCase(t, tt, mp)
+	}
+}
+
+func TestAttributes_MetricLabelActions(t *testing.T) {
+	testCases := []metricTestCase{
+		{
+			name:       "labels exist",
+			metricName: "requests",
+			inputLabels: map[string]string{
+				"user_id": "1234",
+				"path":    "/api/v1/user/1234",
+			},
+			expectedLabels: map[string]string{
+				"user_id": "7110eda4d09e062aa5e4a390b0a572ac0d2c0220",
+				"path":    "/api/v1/user/1234",
+				"version": "v1",
+				"env":     "prod",
+			},
+		},
+		{
+			name:       "labels missing",
+			metricName: "requests",
+			inputLabels: map[string]string{
+				"method": "GET",
+			},
+			expectedLabels: map[string]string{
+				"method": "GET",
+				"env":    "prod",
+			},
+		},
+	}
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	oCfg := cfg.(*Config)
+	oCfg.Actions = []processorhelper.ActionKeyValue{
+		{Key: "user_id", Action: processorhelper.HASH},
+		{Key: "path", Action: processorhelper.EXTRACT, RegexPattern: "^/api/(?P<version>v[0-9]+)/"},
+		{Key: "env", Action: processorhelper.INSERT, Value: "prod"},
+	}
+	mp, err := factory.CreateMetricsProcessor(
+		context.Background(), component.ProcessorCreateParams{}, exportertest.NewNopMetricsExporter(), cfg)
+	require.Nil(t, err)
+	require.NotNil(t, mp)
+
+	for _, tt := range testCases {
+		runIndividualMetricTestCase(t, tt, mp)
+	}
+}
@@ -16,6 +16,7 @@ package attributesprocessor
 
 import (
 	"go.opentelemetry.io/collector/config/configmodels"
+	"go.opentelemetry.io/collector/internal/processor/filtermetric"
 	"go.opentelemetry.io/collector/internal/processor/filterspan"
 	"go.opentelemetry.io/collector/processor/processorhelper"
 )
@@ -32,8 +33,38 @@ type Config struct {
 
 	filterspan.MatchConfig `mapstructure:",squash"`
 
+	// Metrics specifies the properties to include/exclude a metric from being
+	// processed. Metrics are matched by name, as they have no span properties.
+	Metrics MetricMatchConfig `mapstructure:"metrics"`
+
 	// Specifies the list of attributes to act on.
 	// The set of actions are {INSERT, UPDATE, UPSERT, DELETE, HASH, EXTRACT}.
 	// This is a required field.
 	processorhelper.Settings `mapstructure:",squash"`
 }
+
+// MetricMatchConfig specifies the properties to include/exclude a metric from
+// being processed. The actions are applied to the labels of the data points of
+// the metrics that are processed.
+type MetricMatchConfig struct {
+	// Include specifies the set of metric properties that must be present in
+	// order for this processor to apply to it.
+	// Note: If `exclude` is specified, the metric is compared against those
+	// properties after the `include` properties.
+	// This is an optional field. If neither `include` and `exclude` are set,
+	// all metrics are processed. If `include` is set and `exclude` isn't set,
+	// then all metrics matching the properties in this structure are processed.
+	Include *filtermetric.MatchProperties `mapstructure:"include"`
+
+	// Exclude specifies when this processor will not be applied to the metrics
+	// which match the specified properties.
+	// Note: The `exclude` properties are checked after the `include` properties,
+	// if they exist, are checked.
+	// If `include` isn't specified, the `exclude` properties are checked against
+	// all metrics.
+	// This is an optional field. If neither `include` and `exclude` are set,
+	// all metrics are processed. If `exclude` is set and `include` isn't set,
+	// then all metrics that do no match the properties in this structure are
+	// processed.
+	Exclude *filtermetric.MatchProperties `mapstructure:"exclude"`
+}
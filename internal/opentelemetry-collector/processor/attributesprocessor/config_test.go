@@ -24,6 +24,7 @@ import (
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config/configmodels"
 	"go.opentelemetry.io/collector/config/configtest"
+	"go.opentelemetry.io/collector/internal/processor/filtermetric"
 	"go.opentelemetry.io/collector/internal/processor/filterset"
 	"go.opentelemetry.io/collector/internal/processor/filterspan"
 	"go.opentelemetry.io/collector/processor/processorhelper"
@@ -234,4 +235,28 @@ func TestLoadingConifg(t *testing.T) {
 		},
 	})
 
+	p11 := cfg.Processors["attributes/metrics"]
+	assert.Equal(t, p11, &Config{
+		ProcessorSettings: configmodels.ProcessorSettings{
+			NameVal: "attributes/metrics",
+			TypeVal: typeStr,
+		},
+		Metrics: MetricMatchConfig{
+			Include: &filtermetric.MatchProperties{
+				Config:      *createConfig(filterset.Regexp),
+				MetricNames: []string{"http_.*"},
+			},
+			Exclude: &filtermetric.MatchProperties{
+				Config:      *createConfig(filterset.Strict),
+				MetricNames: []string{"http_requests_internal"},
+			},
+		},
+		Settings: processorhelper.Settings{
+			Actions: []processorhelper.ActionKeyValue{
+				{Key: "user_id", Action: processorhelper.DELETE},
+				{Key: "host", Action: processorhelper.HASH},
+			},
+		},
+	})
+
 }
@@ -37,7 +37,9 @@ func NewFactory() component.ProcessorFactory {
 	return processorhelper.NewFactory(
 		typeStr,
 		createDefaultConfig,
-		processorhelper.WithTraces(createTraceProcessor))
+		processorhelper.WithTraces(createTraceProcessor),
+		processorhelper.WithMetrics(createMetricsProcessor),
+		processorhelper.WithLogs(createLogsProcessor))
 }
 
 // Note: This isn't a valid configuration because the processor would do no work.
@@ -52,7 +54,7 @@ func createDefaultConfig() configmodels.Processor {
 
 func createTraceProcessor(
 	_ context.Context,
-	_ component.ProcessorCreateParams,
+	params component.ProcessorCreateParams,
 	cfg configmodels.Processor,
 	nextConsumer consumer.TraceConsumer,
 ) (component.TraceProcessor, error) {
@@ -76,6 +78,69 @@ func createTraceProcessor(
 	return processorhelper.NewTraceProcessor(
 		cfg,
 		nextConsumer,
-		newAttributesProcessor(attrProc, include, exclude),
+		newAttributesProcessor(attrProc, include, exclude, params.Logger),
+		processorhelper.WithCapabilities(processorCapabilities))
+}
+
+func createMetricsProcessor(
+	_ context.Context,
+	_ component.ProcessorCreateParams,
+	cfg configmodels.Processor,
+	nextConsumer consumer.MetricsConsumer,
+) (component.MetricsProcessor, error) {
+	oCfg := cfg.(*Config)
+	if len(oCfg.Actions) == 0 {
+		return nil, fmt.Errorf("error creating \"attributes\" processor due to missing required field \"actions\" of processor %q", cfg.Name())
+	}
+	if oCfg.Include != nil || oCfg.Exclude != nil {
+		return nil, errSpanPropertiesForMetrics
+	}
+	attrProc, err := processorhelper.NewAttrProc(&oCfg.Settings)
+	if err != nil {
+		return nil, fmt.Errorf("error creating \"attributes\" processor: %w of processor %q", err, cfg.Name())
+	}
+	include, err := newMetricMatcher(oCfg.Metrics.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := newMetricMatcher(oCfg.Metrics.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	return processorhelper.NewMetricsProcessor(
+		cfg,
+		nextConsumer,
+		newMetricAttributesProcessor(attrProc, include, exclude),
+		processorhelper.WithCapabilities(processorCapabilities))
+}
+
+func createLogsProcessor(
+	_ context.Context,
+	_ component.ProcessorCreateParams,
+	cfg configmodels.Processor,
+	nextConsumer consumer.LogsConsumer,
+) (component.LogsProcessor, error) {
+	oCfg := cfg.(*Config)
+	if len(oCfg.Actions) == 0 {
+		return nil, fmt.Errorf("error creating \"attributes\" processor due to missing required field \"actions\" of processor %q", cfg.Name())
+	}
+	attrProc, err := processorhelper.NewAttrProc(&oCfg.Settings)
+	if err != nil {
+		return nil, fmt.Errorf("error creating \"attributes\" processor: %w of processor %q", err, cfg.Name())
+	}
+	include, err := newLogMatcher(oCfg.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := newLogMatcher(oCfg.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	return processorhelper.NewLogsProcessor(
+		cfg,
+		nextConsumer,
+		newLogAttributesProcessor(attrProc, include, exclude),
 		processorhelper.WithCapabilities(processorCapabilities))
 }
@@ -19,13 +19,14 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/require"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configcheck"
-	"go.opentelemetry.io/collector/config/configerror"
 	"go.opentelemetry.io/collector/config/configmodels"
 	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/internal/processor/filtermetric"
+	"go.opentelemetry.io/collector/internal/processor/filterset"
+	"go.opentelemetry.io/collector/internal/processor/filterspan"
 	"go.opentelemetry.io/collector/processor/processorhelper"
 )
 
@@ -94,12 +95,100 @@ func TestFactoryCreateTraceProcessor(t *testing.T) {
 	assert.Error(t, err)
 }
 
-func TestFactory_CreateMetricsProcessor(t *testing.T) {
+func TestFactoryCreateMetricsProcessor(t *testing.T) {
 	factory := NewFactory()
 	cfg := factory.CreateDefaultConfig()
+	oCfg := cfg.(*Config)
 
 	mp, err := factory.CreateMetricsProcessor(
+		context.Background(), component.ProcessorCreateParams{}, exportertest.NewNopMetricsExporter(), cfg)
+	assert.Nil(t, mp)
+	assert.Error(t, err)
+
+	oCfg.Actions = []processorhelper.ActionKeyValue{
+		{Key: "user_id", Action: processorhelper.DELETE},
+	}
+	oCfg.Metrics.Include = &filtermetric.MatchProperties{
+		Config:      filterset.Config{MatchType: filterset.Strict},
+		MetricNames: []string{"requests"},
+	}
+	mp, err = factory.CreateMetricsProcessor(
+		context.Background(), component.ProcessorCreateParams{}, exportertest.NewNopMetricsExporter(), cfg)
+	assert.NotNil(t, mp)
+	assert.NoError(t, err)
+
+	mp, err = factory.CreateMetricsProcessor(
 		context.Background(), component.ProcessorCreateParams{}, nil, cfg)
-	require.Nil(t, mp)
-	assert.Equal(t, err, configerror.ErrDataTypeIsNotSupported)
+	assert.Nil(t, mp)
+	assert.Error(t, err)
+
+	oCfg.Metrics.Exclude = &filtermetric.MatchProperties{
+		Config:      filterset.Config{MatchType: filterset.Regexp},
+		MetricNames: []string{"["},
+	}
+	mp, err = factory.CreateMetricsProcessor(
+		context.Background(), component.ProcessorCreateParams{}, exportertest.NewNopMetricsExporter(), cfg)
+	assert.Nil(t, mp)
+	assert.Error(t, err)
+
+	oCfg.Metrics.Exclude = &filtermetric.MatchProperties{
+		Config:      filterset.Config{MatchType: filterset.Expr},
+		Expressions: []string{`Value > 0`},
+	}
+	mp, err = factory.CreateMetricsProcessor(
+		context.Background(), component.ProcessorCreateParams{}, exportertest.NewNopMetricsExporter(), cfg)
+	assert.Nil(t, mp)
+	assert.Equal(t, errExprForMetrics, err)
+
+	oCfg.Metrics.Exclude = nil
+	oCfg.Include = &filterspan.MatchProperties{
+		Config:   filterset.Config{MatchType: filterset.Strict},
+		Services: []string{"svcA"},
+	}
+	mp, err = factory.CreateMetricsProcessor(
+		context.Background(), component.ProcessorCreateParams{}, exportertest.NewNopMetricsExporter(), cfg)
+	assert.Nil(t, mp)
+	assert.Equal(t, errSpanPropertiesForMetrics, err)
+}
+
+func TestFactoryCreateLogsProcessor(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	oCfg := cfg.(*Config)
+
+	lp, err := factory.CreateLogsProcessor(
+		context.Background(), component.ProcessorCreateParams{}, cfg, exportertest.NewNopLogsExporter())
+	assert.Nil(t, lp)
+	assert.Error(t, err)
+
+	oCfg.Actions = []processorhelper.ActionKeyValue{
+		{Key: "a key", Action: processorhelper.DELETE},
+	}
+	lp, err = factory.CreateLogsProcessor(
+		context.Background(), component.ProcessorCreateParams{}, cfg, exportertest.NewNopLogsExporter())
+	assert.NotNil(t, lp)
+	assert.NoError(t, err)
+
+	lp, err = factory.CreateLogsProcessor(
+		context.Background(), component.ProcessorCreateParams{}, cfg, nil)
+	assert.Nil(t, lp)
+	assert.Error(t, err)
+
+	oCfg.Include = &filterspan.MatchProperties{
+		Config:   filterset.Config{MatchType: filterset.Strict},
+		Services: []string{"svcA"},
+	}
+	lp, err = factory.CreateLogsProcessor(
+		context.Background(), component.ProcessorCreateParams{}, cfg, exportertest.NewNopLogsExporter())
+	assert.Nil(t, lp)
+	assert.Equal(t, errSpanPropertiesForLogs, err)
+
+	oCfg.Include = &filterspan.MatchProperties{
+		Config:      filterset.Config{MatchType: filterset.Expr},
+		Expressions: []string{`SpanName == "span"`},
+	}
+	lp, err = factory.CreateLogsProcessor(
+		context.Background(), component.ProcessorCreateParams{}, cfg, exportertest.NewNopLogsExporter())
+	assert.Nil(t, lp)
+	assert.Equal(t, errSpanPropertiesForLogs, err)
 }
@@ -29,6 +29,7 @@ import (
 	"go.opentelemetry.io/collector/consumer/pdatautil"
 	"go.opentelemetry.io/collector/internal/collector/telemetry"
 	"go.opentelemetry.io/collector/internal/dataold"
+	"go.opentelemetry.io/collector/obsreport"
 	"go.opentelemetry.io/collector/processor"
 )
 
@@ -74,6 +75,7 @@ type batch interface {
 
 var _ consumer.TraceConsumer = (*batchProcessor)(nil)
 var _ consumer.MetricsConsumer = (*batchProcessor)(nil)
+var _ consumer.LogsConsumer = (*batchProcessor)(nil)
 
 func newBatchProcessor(params component.ProcessorCreateParams, cfg *Config, batch batch, telemetryLevel telemetry.Level) *batchProcessor {
 	return &batchProcessor{
@@ -123,13 +125,20 @@ func (bp *batchProcessor) startProcessingCycle() {
 				return
 			}
 			if bp.sendBatchMaxSize > 0 {
-				if td, ok := item.(pdata.Traces); ok {
-					itemCount := bp.batch.itemCount()
-					if itemCount+uint32(td.SpanCount()) > bp.sendBatchMaxSize {
-						tdRemainSize := splitTrace(int(bp.sendBatchSize-itemCount), td)
-						item = tdRemainSize
+				itemCount := bp.batch.itemCount()
+				switch data := item.(type) {
+				case pdata.Traces:
+					if itemCount+uint32(data.SpanCount()) > bp.sendBatchMaxSize {
+						item = splitTrace(int(bp.sendBatchSize-itemCount), data)
 						go func() {
-							bp.newItem <- td
+							bp.newItem <- data
+						}()
+					}
+				case pdata.Logs:
+					if itemCount+uint32(data.LogRecordCount()) > bp.sendBatchMaxSize {
+						item = splitLogs(int(bp.sendBatchSize-itemCount), data)
+						go func() {
+							bp.newItem <- data
 						}()
 					}
 				}
@@ -182,6 +191,13 @@ func (bp *batchProcessor) ConsumeMetrics(_ context.Context, md pdata.Metrics) er
 	return nil
 }
 
+// ConsumeLogs implements LogsProcessor
+func (bp *batchProcessor) ConsumeLogs(ctx context.Context, ld pdata.Logs) error {
+	obsreport.ProcessorLogRecordsAccepted(obsreport.ProcessorContext(ctx, bp.name), ld.LogRecordCount())
+	bp.newItem <- ld
+	return nil
+}
+
 // newBatchTracesProcessor creates a new batch processor that batches traces by size or with timeout
 func newBatchTracesProcessor(params component.ProcessorCreateParams, trace consumer.TraceConsumer, cfg *Config, telemetryLevel telemetry.Level) *batchProcessor {
 	return newBatchProcessor(params, cfg, newBatchTraces(trace), telemetryLevel)
@@ -192,6 +208,11 @@ func newBatchMetricsProcessor(params component.ProcessorCreateParams, metrics co
 	return newBatchProcessor(params, cfg, newBatchMetrics(metrics), telemetryLevel)
 }
 
+// newBatchLogsProcessor creates a new batch processor that batches logs by size or with timeout
+func newBatchLogsProcessor(params component.ProcessorCreateParams, logs consumer.LogsConsumer, cfg *Config, telemetryLevel telemetry.Level) *batchProcessor {
+	return newBatchProcessor(params, cfg, newBatchLogs(logs), telemetryLevel)
+}
+
 type batchTraces struct {
 	nextConsumer consumer.TraceConsumer
 	traceData    pdata.Traces
@@ -274,3 +295,43 @@ func (bm *batchMetrics) add(item interface{}) {
 	bm.metricCount += uint32(newMetricsCount)
 	md.ResourceMetrics().MoveAndAppendTo(bm.metricData.ResourceMetrics())
 }
+
+type batchLogs struct {
+	nextConsumer consumer.LogsConsumer
+	logData      pdata.Logs
+	logCount     uint32
+}
+
+func newBatchLogs(nextConsumer consumer.LogsConsumer) *batchLogs {
+	b := &batchLogs{nextConsumer: nextConsumer}
+	b.reset()
+	return b
+}
+
+func (bl *batchLogs) export(ctx context.Context) error {
+	return bl.nextConsumer.ConsumeLogs(ctx, bl.logData)
+}
+
+func (bl *batchLogs) itemCount() uint32 {
+	return bl.logCount
+}
+
+func (bl *batchLogs) size() int {
+	return bl.logData.Size()
+}
+
+func (bl *batchLogs) reset() {
+	bl.logData = pdata.NewLogs()
+	bl.logCount = 0
+}
+
+func (bl *batchLogs) add(item interface{}) {
+	ld := item.(pdata.Logs)
+
+	newLogsCount := ld.LogRecordCount()
+	if newLogsCount == 0 {
+		return
+	}
+	bl.logCount += uint32(newLogsCount)
+	ld.ResourceLogs().MoveAndAppendTo(bl.logData.ResourceLogs())
+}
@@ -16,6 +16,7 @@ package filterprocessor
 
 import (
 	"go.opentelemetry.io/collector/config/configmodels"
+	"go.opentelemetry.io/collector/internal/processor/filterlog"
 	"go.opentelemetry.io/collector/internal/processor/filtermetric"
 )
 
@@ -23,6 +24,7 @@ import (
 type Config struct {
 	configmodels.ProcessorSettings `mapstructure:",squash"`
 	Metrics                        MetricFilters `mapstructure:"metrics"`
+	Logs                           LogFilters    `mapstructure:"logs"`
 }
 
 // MetricFilter filters by Metric properties.
@@ -37,3 +39,16 @@ type MetricFilters struct {
 	// If both Include and Exclude are specified, Include filtering occurs first.
 	Exclude *filtermetric.MatchProperties `mapstructure:"exclude"`
 }
+
+// LogFilters filters by LogRecord properties.
+type LogFilters struct {
+	// Include match properties describe log records that should be included in the Collector Service pipeline,
+	// all other log records should be dropped from further processing.
+	// If both Include and Exclude are specified, Include filtering occurs first.
+	Include *filterlog.MatchProperties `mapstructure:"include"`
+
+	// Exclude match properties describe log records that should be excluded from the Collector Service pipeline,
+	// all other log records should be included.
+	// If both Include and Exclude are specified, Include filtering occurs first.
+	Exclude *filterlog.MatchProperties `mapstructure:"exclude"`
+}
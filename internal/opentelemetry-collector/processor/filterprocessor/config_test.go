@@ -24,9 +24,11 @@ import (
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config/configmodels"
 	"go.opentelemetry.io/collector/config/configtest"
+	"go.opentelemetry.io/collector/internal/processor/filterlog"
 	"go.opentelemetry.io/collector/internal/processor/filtermetric"
 	"go.opentelemetry.io/collector/internal/processor/filterset"
 	fsregexp "go.opentelemetry.io/collector/internal/processor/filterset/regexp"
+	"go.opentelemetry.io/collector/internal/processor/filterspan"
 )
 
 // TestLoadingConfigRegexp tests loading testdata/config_strict.yaml
@@ -229,3 +231,168 @@ func TestLoadingConfigRegexp(t *testing.T) {
 		})
 	}
 }
+
+// TestLoadingConfigLogs tests loading testdata/config_logs.yaml
+func TestLoadingConfigLogs(t *testing.T) {
+	factories, err := componenttest.ExampleComponents()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	config, err := configtest.LoadConfigFile(t, path.Join(".", "testdata", "config_logs.yaml"), factories)
+
+	assert.Nil(t, err)
+	require.NotNil(t, config)
+
+	tests := []struct {
+		filterName string
+		expCfg     *Config
+	}{
+		{
+			filterName: "filter/include",
+			expCfg: &Config{
+				ProcessorSettings: configmodels.ProcessorSettings{
+					NameVal: "filter/include",
+					TypeVal: typeStr,
+				},
+				Logs: LogFilters{
+					Include: &filterlog.MatchProperties{
+						Config: filterset.Config{
+							MatchType: filterset.Strict,
+						},
+						SeverityTexts: []string{"WARN", "ERROR"},
+					},
+				},
+			},
+		}, {
+			filterName: "filter/exclude",
+			expCfg: &Config{
+				ProcessorSettings: configmodels.ProcessorSettings{
+					NameVal: "filter/exclude",
+					TypeVal: typeStr,
+				},
+				Logs: LogFilters{
+					Exclude: &filterlog.MatchProperties{
+						Config: filterset.Config{
+							MatchType: filterset.Regexp,
+						},
+						Bodies: []string{"^GET /healthz.*"},
+					},
+				},
+			},
+		}, {
+			filterName: "filter/includeexclude",
+			expCfg: &Config{
+				ProcessorSettings: configmodels.ProcessorSettings{
+					NameVal: "filter/includeexclude",
+					TypeVal: typeStr,
+				},
+				Logs: LogFilters{
+					Include: &filterlog.MatchProperties{
+						Config: filterset.Config{
+							MatchType: filterset.Strict,
+						},
+						Attributes: []filterspan.Attribute{{Key: "app", Value: "server"}},
+					},
+					Exclude: &filterlog.MatchProperties{
+						Config: filterset.Config{
+							MatchType: filterset.Strict,
+						},
+						SeverityTexts: []string{"DEBUG"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filterName, func(t *testing.T) {
+			cfg := config.Processors[test.filterName]
+			assert.Equal(t, test.expCfg, cfg)
+		})
+	}
+}
+
+// TestLoadingConfigExpr tests loading testdata/config_expr.yaml
+func TestLoadingConfigExpr(t *testing.T) {
+	factories, err := componenttest.ExampleComponents()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	config, err := configtest.LoadConfigFile(t, path.Join(".", "testdata", "config_expr.yaml"), factories)
+
+	assert.Nil(t, err)
+	require.NotNil(t, config)
+
+	tests := []struct {
+		filterName string
+		expCfg     *Config
+	}{
+		{
+			filterName: "filter/include",
+			expCfg: &Config{
+				ProcessorSettings: configmodels.ProcessorSettings{
+					NameVal: "filter/include",
+					TypeVal: typeStr,
+				},
+				Metrics: MetricFilters{
+					Include: &filtermetric.MatchProperties{
+						Config: filterset.Config{
+							MatchType: filterset.Expr,
+						},
+						Expressions: []string{`Label("env") == "prod"`},
+					},
+				},
+			},
+		}, {
+			filterName: "filter/exclude",
+			expCfg: &Config{
+				ProcessorSettings: configmodels.ProcessorSettings{
+					NameVal: "filter/exclude",
+					TypeVal: typeStr,
+				},
+				Metrics: MetricFilters{
+					Exclude: &filtermetric.MatchProperties{
+						Config: filterset.Config{
+							MatchType: filterset.Expr,
+						},
+						Expressions: []string{
+							`MetricName == "http.server.duration" && Value > 10`,
+							`HasResource("debug")`,
+						},
+					},
+				},
+			},
+		}, {
+			filterName: "filter/includeexclude",
+			expCfg: &Config{
+				ProcessorSettings: configmodels.ProcessorSettings{
+					NameVal: "filter/includeexclude",
+					TypeVal: typeStr,
+				},
+				Metrics: MetricFilters{
+					Include: &filtermetric.MatchProperties{
+						Config: filterset.Config{
+							MatchType: filterset.Regexp,
+						},
+						MetricNames: []string{`^http\..*`},
+					},
+					Exclude: &filtermetric.MatchProperties{
+						Config: filterset.Config{
+							MatchType: filterset.Expr,
+						},
+						Expressions: []string{`Label("status") matches "^2"`},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filterName, func(t *testing.T) {
+			cfg := config.Processors[test.filterName]
+			assert.Equal(t, test.expCfg, cfg)
+		})
+	}
+}
@@ -35,7 +35,8 @@ func NewFactory() component.ProcessorFactory {
 	return processorhelper.NewFactory(
 		typeStr,
 		createDefaultConfig,
-		processorhelper.WithMetrics(createMetricsProcessor))
+		processorhelper.WithMetrics(createMetricsProcessor),
+		processorhelper.WithLogs(createLogsProcessor))
 }
 
 func createDefaultConfig() configmodels.Processor {
@@ -49,11 +50,11 @@ func createDefaultConfig() configmodels.Processor {
 
 func createMetricsProcessor(
 	_ context.Context,
-	_ component.ProcessorCreateParams,
+	params component.ProcessorCreateParams,
 	cfg configmodels.Processor,
 	nextConsumer consumer.MetricsConsumer,
 ) (component.MetricsProcessor, error) {
-	fp, err := newFilterMetricProcessor(cfg.(*Config))
+	fp, err := newFilterMetricProcessor(cfg.(*Config), params.Logger)
 	if err != nil {
 		return nil, err
 	}
@@ -63,3 +64,20 @@ func createMetricsProcessor(
 		fp,
 		processorhelper.WithCapabilities(processorCapabilities))
 }
+
+func createLogsProcessor(
+	_ context.Context,
+	_ component.ProcessorCreateParams,
+	cfg configmodels.Processor,
+	nextConsumer consumer.LogsConsumer,
+) (component.LogsProcessor, error) {
+	fp, err := newFilterLogProcessor(cfg.(*Config))
+	if err != nil {
+		return nil, err
+	}
+	return processorhelper.NewLogsProcessor(
+		cfg,
+		nextConsumer,
+		fp,
+		processorhelper.WithCapabilities(processorCapabilities))
+}
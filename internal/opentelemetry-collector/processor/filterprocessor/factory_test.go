@@ -61,6 +61,9 @@ func TestCreateProcessors(t *testing.T) {
 		}, {
 			configName: "config_strict.yaml",
 			succeed:    true,
+		}, {
+			configName: "config_logs.yaml",
+			succeed:    true,
 		}, {
 			configName: "config_invalid.yaml",
 			succeed:    false,
@@ -96,6 +99,14 @@ func TestCreateProcessors(t *testing.T) {
 					cfg)
 				assert.Equal(t, test.succeed, mp != nil)
 				assert.Equal(t, test.succeed, mErr == nil)
+
+				lp, lErr := factory.CreateLogsProcessor(
+					context.Background(),
+					component.ProcessorCreateParams{Logger: zap.NewNop()},
+					cfg,
+					exportertest.NewNopLogsExporter())
+				assert.Equal(t, test.succeed, lp != nil)
+				assert.Equal(t, test.succeed, lErr == nil)
 			})
 		}
 	}
@@ -0,0 +1,119 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filterprocessor
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/internal/processor/filterlog"
+	"go.opentelemetry.io/collector/obsreport"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+type filterLogProcessor struct {
+	cfg     *Config
+	include *filterlog.Matcher
+	exclude *filterlog.Matcher
+}
+
+func newFilterLogProcessor(cfg *Config) (*filterLogProcessor, error) {
+	inc, err := filterlog.NewMatcher(cfg.Logs.Include)
+	if err != nil {
+		return nil, err
+	}
+
+	exc, err := filterlog.NewMatcher(cfg.Logs.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	return &filterLogProcessor{
+		cfg:     cfg,
+		include: inc,
+		exclude: exc,
+	}, nil
+}
+
+// ProcessLogs filters the given logs based off the filterLogProcessor's filters. The consumed logs are not modified,
+// the returned logs share the kept log records with them.
+func (flp *filterLogProcessor) ProcessLogs(ctx context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	filtered := pdata.NewLogs()
+	dropped := 0
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		if rl.IsNil() {
+			continue
+		}
+		keptRl := pdata.NewResourceLogs()
+		keptRl.InitEmpty()
+		rl.Resource().CopyTo(keptRl.Resource())
+		ills := rl.InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			ill := ills.At(j)
+			if ill.IsNil() {
+				continue
+			}
+			keptIll := pdata.NewInstrumentationLibraryLogs()
+			keptIll.InitEmpty()
+			ill.InstrumentationLibrary().CopyTo(keptIll.InstrumentationLibrary())
+			logs := ill.Logs()
+			for k := 0; k < logs.Len(); k++ {
+				lr := logs.At(k)
+				if lr.IsNil() {
+					continue
+				}
+				if !flp.shouldKeepLogRecord(lr) {
+					dropped++
+					continue
+				}
+				keptIll.Logs().Append(&lr)
+			}
+			if keptIll.Logs().Len() > 0 {
+				keptRl.InstrumentationLibraryLogs().Append(&keptIll)
+			}
+		}
+		if keptRl.InstrumentationLibraryLogs().Len() > 0 {
+			filtered.ResourceLogs().Append(&keptRl)
+		}
+	}
+
+	if dropped > 0 {
+		obsreport.ProcessorLogRecordsDropped(ctx, dropped)
+	}
+	if filtered.ResourceLogs().Len() == 0 {
+		return ld, processorhelper.ErrSkipProcessingData
+	}
+	obsreport.ProcessorLogRecordsAccepted(ctx, filtered.LogRecordCount())
+	return filtered, nil
+}
+
+// shouldKeepLogRecord determines whether a log record should be kept based off the filterLogProcessor's filters.
+func (flp *filterLogProcessor) shouldKeepLogRecord(lr pdata.LogRecord) bool {
+	if flp.include != nil {
+		if !flp.include.MatchLogRecord(lr) {
+			return false
+		}
+	}
+
+	if flp.exclude != nil {
+		if flp.exclude.MatchLogRecord(lr) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,172 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filterprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configmodels"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	etest "go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/internal/processor/filterlog"
+	"go.opentelemetry.io/collector/internal/processor/filterset"
+	"go.opentelemetry.io/collector/internal/processor/filterspan"
+	"go.opentelemetry.io/collector/obsreport/obsreporttest"
+)
+
+type logRecord struct {
+	name     string
+	body     string
+	severity string
+	app      string
+}
+
+var (
+	inLogRecords = []logRecord{
+		{name: "request", body: "GET /api/users 200", severity: "INFO", app: "server"},
+		{name: "healthcheck", body: "GET /healthz 200", severity: "INFO", app: "server"},
+		{name: "timeout", body: "upstream timed out", severity: "ERROR", app: "server"},
+		{name: "debug", body: "cache miss", severity: "DEBUG", app: "cache"},
+	}
+
+	logTests = []struct {
+		name     string
+		inc      *filterlog.MatchProperties
+		exc      *filterlog.MatchProperties
+		outNames []string
+	}{
+		{
+			name: "includeSeverity",
+			inc: &filterlog.MatchProperties{
+				Config:        filterset.Config{MatchType: filterset.Strict},
+				SeverityTexts: []string{"WARN", "ERROR"},
+			},
+			outNames: []string{"timeout"},
+		},
+		{
+			name: "excludeBody",
+			exc: &filterlog.MatchProperties{
+				Config: filterset.Config{MatchType: filterset.Regexp},
+				Bodies: []string{"^GET /healthz.*"},
+			},
+			outNames: []string{"request", "timeout", "debug"},
+		},
+		{
+			name: "includeAttributesExcludeSeverity",
+			inc: &filterlog.MatchProperties{
+				Config:     filterset.Config{MatchType: filterset.Strict},
+				Attributes: []filterspan.Attribute{{Key: "app", Value: "server"}},
+			},
+			exc: &filterlog.MatchProperties{
+				Config:        filterset.Config{MatchType: filterset.Strict},
+				SeverityTexts: []string{"INFO"},
+			},
+			outNames: []string{"timeout"},
+		},
+		{
+			name: "allLogRecordsFiltered",
+			inc: &filterlog.MatchProperties{
+				Config:        filterset.Config{MatchType: filterset.Strict},
+				SeverityTexts: []string{"FATAL"},
+			},
+		},
+		{
+			name:     "noFilters",
+			outNames: []string{"request", "healthcheck", "timeout", "debug"},
+		},
+	}
+)
+
+func TestFilterLogProcessor(t *testing.T) {
+	for _, test := range logTests {
+		t.Run(test.name, func(t *testing.T) {
+			doneFn, err := obsreporttest.SetupRecordedMetricsTest()
+			require.NoError(t, err)
+			defer doneFn()
+
+			// next stores the results of the filter log processor
+			next := &etest.SinkLogsExporter{}
+			cfg := &Config{
+				ProcessorSettings: configmodels.ProcessorSettings{
+					TypeVal: typeStr,
+					NameVal: typeStr,
+				},
+				Logs: LogFilters{
+					Include: test.inc,
+					Exclude: test.exc,
+				},
+			}
+			factory := NewFactory()
+			flp, err := factory.CreateLogsProcessor(context.Background(), component.ProcessorCreateParams{}, cfg, next)
+			require.NoError(t, err)
+			require.NotNil(t, flp)
+			assert.Equal(t, false, flp.GetCapabilities().MutatesConsumedData)
+
+			ctx := context.Background()
+			assert.NoError(t, flp.Start(ctx, nil))
+
+			ld := logsWithRecords(inLogRecords)
+			assert.NoError(t, flp.ConsumeLogs(ctx, ld))
+			// the consumed logs are left untouched
+			assert.Equal(t, len(inLogRecords), ld.LogRecordCount())
+
+			got := next.AllLogs()
+			if len(test.outNames) == 0 {
+				assert.Len(t, got, 0)
+			} else {
+				require.Len(t, got, 1)
+				assert.Equal(t, test.outNames, logRecordNames(got[0]))
+			}
+			obsreporttest.CheckProcessorLogsViews(t, typeStr, int64(len(test.outNames)), 0, int64(len(inLogRecords)-len(test.outNames)))
+			assert.NoError(t, flp.Shutdown(ctx))
+		})
+	}
+}
+
+func logsWithRecords(records []logRecord) pdata.Logs {
+	ld := pdata.NewLogs()
+	ld.ResourceLogs().Resize(1)
+	ld.ResourceLogs().At(0).InstrumentationLibraryLogs().Resize(1)
+	logs := ld.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs()
+	logs.Resize(len(records))
+	for i, record := range records {
+		lr := logs.At(i)
+		lr.SetName(record.name)
+		lr.Body().SetStringVal(record.body)
+		lr.SetSeverityText(record.severity)
+		lr.Attributes().InsertString("app", record.app)
+	}
+	return ld
+}
+
+func logRecordNames(ld pdata.Logs) []string {
+	var names []string
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		ills := rls.At(i).InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				names = append(names, logs.At(k).Name())
+			}
+		}
+	}
+	return names
+}
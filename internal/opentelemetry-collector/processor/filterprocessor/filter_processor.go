@@ -18,10 +18,14 @@ import (
 	"context"
 
 	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
+	"go.uber.org/zap"
 
 	"go.opentelemetry.io/collector/consumer/pdata"
 	"go.opentelemetry.io/collector/consumer/pdatautil"
+	"go.opentelemetry.io/collector/internal/processor/filterexpr"
 	"go.opentelemetry.io/collector/internal/processor/filtermetric"
+	"go.opentelemetry.io/collector/internal/processor/filterset"
 	"go.opentelemetry.io/collector/processor/processorhelper"
 )
 
@@ -29,9 +33,12 @@ type filterMetricProcessor struct {
 	cfg     *Config
 	include *filtermetric.Matcher
 	exclude *filtermetric.Matcher
+	// matchDataPoints is set if data points are filtered one by one, with the expr match type
+	matchDataPoints bool
+	logger          *zap.Logger
 }
 
-func newFilterMetricProcessor(cfg *Config) (*filterMetricProcessor, error) {
+func newFilterMetricProcessor(cfg *Config, logger *zap.Logger) (*filterMetricProcessor, error) {
 	inc, err := createMatcher(cfg.Metrics.Include)
 	if err != nil {
 		return nil, err
@@ -43,12 +50,18 @@ func newFilterMetricProcessor(cfg *Config) (*filterMetricProcessor, error) {
 	}
 
 	return &filterMetricProcessor{
-		cfg:     cfg,
-		include: inc,
-		exclude: exc,
+		cfg:             cfg,
+		include:         inc,
+		exclude:         exc,
+		matchDataPoints: isExpr(cfg.Metrics.Include) || isExpr(cfg.Metrics.Exclude),
+		logger:          logger,
 	}, nil
 }
 
+func isExpr(mp *filtermetric.MatchProperties) bool {
+	return mp != nil && mp.MatchType == filterset.Expr
+}
+
 func createMatcher(mp *filtermetric.MatchProperties) (*filtermetric.Matcher, error) {
 	// Nothing specified in configuration
 	if mp == nil {
@@ -64,7 +77,7 @@ func createMatcher(mp *filtermetric.MatchProperties) (*filtermetric.Matcher, err
 }
 
 // ProcessMetrics filters the given metrics based off the filterMetricProcessor's filters.
-func (fmp *filterMetricProcessor) ProcessMetrics(_ context.Context, md pdata.Metrics) (pdata.Metrics, error) {
+func (fmp *filterMetricProcessor) ProcessMetrics(ctx context.Context, md pdata.Metrics) (pdata.Metrics, error) {
 	mds := pdatautil.MetricsToMetricsData(md)
 	foundMetricToKeep := false
 	for i := range mds {
@@ -73,7 +86,18 @@ func (fmp *filterMetricProcessor) ProcessMetrics(_ context.Context, md pdata.Met
 		}
 		keep := make([]*metricspb.Metric, 0, len(mds[i].Metrics))
 		for _, m := range mds[i].Metrics {
-			if fmp.shouldKeepMetric(m) {
+			if fmp.matchDataPoints {
+				resource := m.GetResource()
+				if resource == nil {
+					resource = mds[i].Resource
+				}
+				m = fmp.filterDataPoints(ctx, resource, m)
+				if m == nil {
+					continue
+				}
+				foundMetricToKeep = true
+				keep = append(keep, m)
+			} else if fmp.shouldKeepMetric(m) {
 				foundMetricToKeep = true
 				keep = append(keep, m)
 			}
@@ -103,3 +127,78 @@ func (fmp *filterMetricProcessor) shouldKeepMetric(metric *metricspb.Metric) boo
 
 	return true
 }
+
+// filterDataPoints returns a copy of metric holding the data points that should be kept based off the
+// filterMetricProcessor's filters, or nil if no data point should be kept. The time series of metric are not modified.
+func (fmp *filterMetricProcessor) filterDataPoints(
+	ctx context.Context,
+	resource *resourcepb.Resource,
+	metric *metricspb.Metric,
+) *metricspb.Metric {
+	timeseries := make([]*metricspb.TimeSeries, 0, len(metric.GetTimeseries()))
+	for _, ts := range metric.GetTimeseries() {
+		points := make([]*metricspb.Point, 0, len(ts.GetPoints()))
+		for _, point := range ts.GetPoints() {
+			if fmp.shouldKeepDataPoint(ctx, resource, metric, ts, point) {
+				points = append(points, point)
+			}
+		}
+		if len(points) == 0 {
+			continue
+		}
+		timeseries = append(timeseries, &metricspb.TimeSeries{
+			StartTimestamp: ts.StartTimestamp,
+			LabelValues:    ts.LabelValues,
+			Points:         points,
+		})
+	}
+	if len(timeseries) == 0 {
+		return nil
+	}
+	return &metricspb.Metric{
+		MetricDescriptor: metric.MetricDescriptor,
+		Timeseries:       timeseries,
+		Resource:         metric.Resource,
+	}
+}
+
+// shouldKeepDataPoint determines whether a data point should be kept based off the filterMetricProcessor's filters.
+// Data points whose expressions fail to be evaluated are kept, and the failure is logged and recorded.
+func (fmp *filterMetricProcessor) shouldKeepDataPoint(
+	ctx context.Context,
+	resource *resourcepb.Resource,
+	metric *metricspb.Metric,
+	ts *metricspb.TimeSeries,
+	point *metricspb.Point,
+) bool {
+	if fmp.include != nil {
+		include, err := fmp.include.MatchDataPoint(resource, metric, ts, point)
+		if err != nil {
+			fmp.matchFailed(ctx, metric, err)
+			return true
+		}
+		if !include {
+			return false
+		}
+	}
+
+	if fmp.exclude != nil {
+		exclude, err := fmp.exclude.MatchDataPoint(resource, metric, ts, point)
+		if err != nil {
+			fmp.matchFailed(ctx, metric, err)
+			return true
+		}
+		if exclude {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchFailed logs and records that a data point of metric failed to be matched by the expressions of the filters.
+func (fmp *filterMetricProcessor) matchFailed(ctx context.Context, metric *metricspb.Metric, err error) {
+	fmp.logger.Warn("failed to evaluate the expressions of a data point, it is kept",
+		zap.String("metric", metric.GetMetricDescriptor().GetName()), zap.Error(err))
+	filterexpr.RecordEvaluationError(ctx)
+}
@@ -19,14 +19,19 @@ import (
 	"testing"
 
 	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configmodels"
 	"go.opentelemetry.io/collector/consumer/consumerdata"
 	"go.opentelemetry.io/collector/consumer/pdatautil"
 	etest "go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/internal/processor/filterexpr"
 	"go.opentelemetry.io/collector/internal/processor/filtermetric"
 	"go.opentelemetry.io/collector/internal/processor/filterset"
 )
@@ -314,6 +319,227 @@ func BenchmarkFilter_MetricNames(b *testing.B) {
 	}
 }
 
+func TestFilterMetricProcessorDataPoints(t *testing.T) {
+	exprProperties := func(expressions ...string) *filtermetric.MatchProperties {
+		return &filtermetric.MatchProperties{
+			Config: filterset.Config{
+				MatchType: filterset.Expr,
+			},
+			Expressions: expressions,
+		}
+	}
+
+	tests := []struct {
+		name string
+		inc  *filtermetric.MatchProperties
+		exc  *filtermetric.MatchProperties
+		// output values of the data points of the time series of each metric, without empty time series
+		out                [][][]int64
+		allMetricsFiltered bool
+	}{
+		{
+			name: "includeLabel",
+			inc:  exprProperties(`Label("env") == "prod"`),
+			out:  [][][]int64{{{1, 2}}, {{5}}},
+		},
+		{
+			name: "excludeValue",
+			exc:  exprProperties(`Value > 2`),
+			out:  [][][]int64{{{1, 2}}},
+		},
+		{
+			name: "excludeMetricNameAndResource",
+			exc:  exprProperties(`MetricName == "first" && Value == 1`, `HasResource("debug")`),
+			out:  [][][]int64{{{2}, {3, 4}}},
+		},
+		{
+			name: "includeNameExcludeExpr",
+			inc: &filtermetric.MatchProperties{
+				Config: filterset.Config{
+					MatchType: filterset.Strict,
+				},
+				MetricNames: []string{"second"},
+			},
+			exc: exprProperties(`Label("env") == "dev"`),
+			out: [][][]int64{{{5}}},
+		},
+		{
+			name:               "excludeAll",
+			exc:                exprProperties(`Value >= 0`),
+			allMetricsFiltered: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			next := &etest.SinkMetricsExporter{}
+			cfg := &Config{
+				ProcessorSettings: configmodels.ProcessorSettings{
+					TypeVal: typeStr,
+					NameVal: typeStr,
+				},
+				Metrics: MetricFilters{
+					Include: test.inc,
+					Exclude: test.exc,
+				},
+			}
+			factory := NewFactory()
+			fmp, err := factory.CreateMetricsProcessor(context.Background(), component.ProcessorCreateParams{}, next, cfg)
+			require.NoError(t, err)
+
+			in := metricsWithDataPoints()
+			inMetrics := in[0].Metrics
+			assert.NoError(t, fmp.ConsumeMetrics(context.Background(), pdatautil.MetricsFromMetricsData(in)))
+			// the consumed metrics are not modified
+			assert.Equal(t, metricsWithDataPoints()[0].Metrics, inMetrics)
+
+			got := next.AllMetrics()
+			if test.allMetricsFiltered {
+				require.Equal(t, 0, len(got))
+				return
+			}
+			require.Equal(t, 1, len(got))
+			gotMD := pdatautil.MetricsToMetricsData(got[0])
+			require.Equal(t, 1, len(gotMD))
+			require.Equal(t, len(test.out), len(gotMD[0].Metrics))
+			for i, metric := range gotMD[0].Metrics {
+				var values [][]int64
+				for _, ts := range metric.Timeseries {
+					tsValues := []int64{}
+					for _, point := range ts.Points {
+						tsValues = append(tsValues, point.GetInt64Value())
+					}
+					values = append(values, tsValues)
+				}
+				assert.Equal(t, test.out[i], values)
+			}
+		})
+	}
+}
+
+// TestFilterMetricProcessorEvaluationErrors checks that data points whose expressions fail to be evaluated are kept,
+// and that the failures are logged and recorded.
+func TestFilterMetricProcessorEvaluationErrors(t *testing.T) {
+	views := filterexpr.MetricViews()
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	// the label values are not valid regular expressions
+	failing := &filtermetric.MatchProperties{
+		Config: filterset.Config{
+			MatchType: filterset.Expr,
+		},
+		Expressions: []string{`MetricName matches Label("env") + "("`},
+	}
+	for _, test := range []struct {
+		name string
+		inc  *filtermetric.MatchProperties
+		exc  *filtermetric.MatchProperties
+	}{
+		{name: "include", inc: failing},
+		{name: "exclude", exc: failing},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			next := &etest.SinkMetricsExporter{}
+			cfg := &Config{
+				ProcessorSettings: configmodels.ProcessorSettings{
+					TypeVal: typeStr,
+					NameVal: typeStr + "/" + test.name,
+				},
+				Metrics: MetricFilters{
+					Include: test.inc,
+					Exclude: test.exc,
+				},
+			}
+			core, logs := observer.New(zap.WarnLevel)
+			fmp, err := NewFactory().CreateMetricsProcessor(
+				context.Background(), component.ProcessorCreateParams{Logger: zap.New(core)}, next, cfg)
+			require.NoError(t, err)
+
+			assert.NoError(t, fmp.ConsumeMetrics(context.Background(), pdatautil.MetricsFromMetricsData(metricsWithDataPoints())))
+			require.Equal(t, 1, len(next.AllMetrics()))
+			assert.Equal(t, 5, pdatautil.MetricPointCount(next.AllMetrics()[0]))
+			assert.Equal(t, 5, logs.Len())
+
+			rows, err := view.RetrieveData(views[0].Name)
+			require.NoError(t, err)
+			var recorded float64
+			for _, row := range rows {
+				if row.Tags[0].Value == cfg.Name() {
+					recorded = row.Data.(*view.SumData).Value
+				}
+			}
+			assert.Equal(t, float64(5), recorded)
+		})
+	}
+}
+
+func BenchmarkFilter_DataPoints(b *testing.B) {
+	cfg := &Config{
+		ProcessorSettings: configmodels.ProcessorSettings{
+			TypeVal: typeStr,
+			NameVal: typeStr,
+		},
+		Metrics: MetricFilters{
+			Exclude: &filtermetric.MatchProperties{
+				Config: filterset.Config{
+					MatchType: filterset.Expr,
+				},
+				Expressions: []string{`Label("env") == "dev" && Value > 3`},
+			},
+		},
+	}
+	factory := NewFactory()
+	fmp, err := factory.CreateMetricsProcessor(
+		context.Background(), component.ProcessorCreateParams{}, &etest.SinkMetricsExporter{}, cfg)
+	require.NoError(b, err)
+
+	md := metricsWithDataPoints()
+	for len(md[0].Metrics) < 1000 {
+		md[0].Metrics = append(md[0].Metrics, metricsWithDataPoints()[0].Metrics...)
+	}
+	pdm := pdatautil.MetricsFromMetricsData(md)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		assert.NoError(b, fmp.ConsumeMetrics(context.Background(), pdm))
+	}
+}
+
+// metricsWithDataPoints returns two metrics with two time series each, labeled with env, whose data points have the
+// values 1 to 5. The resource of the second metric has the debug label.
+func metricsWithDataPoints() []consumerdata.MetricsData {
+	timeSeries := func(env string, values ...int64) *metricspb.TimeSeries {
+		ts := &metricspb.TimeSeries{
+			LabelValues: []*metricspb.LabelValue{{Value: env, HasValue: true}},
+		}
+		for _, value := range values {
+			ts.Points = append(ts.Points, &metricspb.Point{Value: &metricspb.Point_Int64Value{Int64Value: value}})
+		}
+		return ts
+	}
+	return []consumerdata.MetricsData{{
+		Resource: &resourcepb.Resource{Type: "test"},
+		Metrics: []*metricspb.Metric{
+			{
+				MetricDescriptor: &metricspb.MetricDescriptor{
+					Name:      "first",
+					LabelKeys: []*metricspb.LabelKey{{Key: "env"}},
+				},
+				Timeseries: []*metricspb.TimeSeries{timeSeries("prod", 1, 2), timeSeries("dev", 3, 4)},
+			},
+			{
+				MetricDescriptor: &metricspb.MetricDescriptor{
+					Name:      "second",
+					LabelKeys: []*metricspb.LabelKey{{Key: "env"}},
+				},
+				Timeseries: []*metricspb.TimeSeries{timeSeries("prod", 5), timeSeries("dev")},
+				Resource:   &resourcepb.Resource{Labels: map[string]string{"debug": "true"}},
+			},
+		},
+	}}
+}
+
 func metricsWithName(names []string) []*metricspb.Metric {
 	ret := make([]*metricspb.Metric, len(names))
 	for i, name := range names {
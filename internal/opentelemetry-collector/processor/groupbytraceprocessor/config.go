@@ -35,12 +35,15 @@ type Config struct {
 	// DiscardOrphans instructs the processor to discard traces without the root span.
 	// This typically indicates that the trace is incomplete.
 	// Default: false.
-	// Not yet implemented, and an error will be returned when this option is used.
 	DiscardOrphans bool `mapstructure:"discard_orphans"`
 
 	// StoreOnDisk tells the processor to keep only the trace ID in memory, serializing the trace spans to disk.
-	// Useful when the duration to wait for traces to complete is high.
+	// Useful when the duration to wait for traces to complete is high. Traces left on disk by a previous run,
+	// such as after a crash or a restart, are released once the wait duration elapses again.
 	// Default: false.
-	// Not yet implemented, and an error will be returned when this option is used.
 	StoreOnDisk bool `mapstructure:"store_on_disk"`
+
+	// Directory is the path of the directory holding the traces stored on disk, one file per trace.
+	// Required when StoreOnDisk is set.
+	Directory string `mapstructure:"directory"`
 }
@@ -16,7 +16,6 @@ package groupbytraceprocessor
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
@@ -35,9 +34,6 @@ var (
 	defaultNumTraces      = 1_000_000
 	defaultDiscardOrphans = false
 	defaultStoreOnDisk    = false
-
-	errDiskStorageNotSupported    = fmt.Errorf("option 'disk storage' not supported in this release")
-	errDiscardOrphansNotSupported = fmt.Errorf("option 'discard orphans' not supported in this release")
 )
 
 // NewFactory returns a new factory for the Filter processor.
@@ -55,10 +51,8 @@ func createDefaultConfig() configmodels.Processor {
 			TypeVal: typeStr,
 			NameVal: string(typeStr),
 		},
-		NumTraces:    defaultNumTraces,
-		WaitDuration: defaultWaitDuration,
-
-		// not supported for now
+		NumTraces:      defaultNumTraces,
+		WaitDuration:   defaultWaitDuration,
 		DiscardOrphans: defaultDiscardOrphans,
 		StoreOnDisk:    defaultStoreOnDisk,
 	}
@@ -75,14 +69,14 @@ func createTraceProcessor(
 
 	var st storage
 	if oCfg.StoreOnDisk {
-		return nil, errDiskStorageNotSupported
+		diskStorage, err := newDiskStorage(oCfg.Directory, params.Logger)
+		if err != nil {
+			return nil, err
+		}
+		st = diskStorage
+	} else {
+		st = newMemoryStorage()
 	}
-	if oCfg.DiscardOrphans {
-		return nil, errDiscardOrphansNotSupported
-	}
-
-	// the only supported storage for now
-	st = newMemoryStorage()
 
 	return newGroupByTraceProcessor(params.Logger, st, nextConsumer, *oCfg)
 }
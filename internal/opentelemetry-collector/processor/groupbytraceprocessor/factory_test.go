@@ -16,6 +16,8 @@ package groupbytraceprocessor
 
 import (
 	"context"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -55,10 +57,14 @@ func TestCreateTestProcessor(t *testing.T) {
 	assert.NotNil(t, p)
 }
 
-func TestCreateTestProcessorWithNotImplementedOptions(t *testing.T) {
+func TestCreateTestProcessorWithOptions(t *testing.T) {
 	// prepare
+	dir, err := ioutil.TempDir("", "groupbytrace")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
 	f := NewFactory()
-	params := component.ProcessorCreateParams{}
+	params := component.ProcessorCreateParams{Logger: zap.NewNop()}
 	next := &mockProcessor{}
 
 	// test
@@ -70,19 +76,31 @@ func TestCreateTestProcessorWithNotImplementedOptions(t *testing.T) {
 			&Config{
 				DiscardOrphans: true,
 			},
-			errDiscardOrphansNotSupported,
+			nil,
+		},
+		{
+			&Config{
+				StoreOnDisk: true,
+			},
+			errDiskStorageNoDirectory,
 		},
 		{
 			&Config{
 				StoreOnDisk: true,
+				Directory:   dir,
 			},
-			errDiskStorageNotSupported,
+			nil,
 		},
 	} {
 		p, err := f.CreateTraceProcessor(context.Background(), params, next, tt.config)
 
 		// verify
-		assert.Error(t, tt.expectedErr, err)
-		assert.Nil(t, p)
+		if tt.expectedErr != nil {
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Nil(t, p)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
 	}
 }
@@ -94,8 +94,11 @@ func (sp *groupByTraceProcessor) GetCapabilities() component.ProcessorCapabiliti
 	return component.ProcessorCapabilities{MutatesConsumedData: true}
 }
 
-// Start is invoked during service startup.
+// Start is invoked during service startup. Traces left in the storage by a previous run are scheduled to be released.
 func (sp *groupByTraceProcessor) Start(context.Context, component.Host) error {
+	if err := sp.recoverTraces(); err != nil {
+		return err
+	}
 	sp.eventMachine.startInBackground()
 	return nil
 }
@@ -164,6 +167,13 @@ func (sp *groupByTraceProcessor) processBatch(batch *singleTraceBatch) error {
 		return fmt.Errorf("couldn't add spans to new trace: %w", err)
 	}
 
+	sp.scheduleRelease(traceID)
+
+	return nil
+}
+
+// scheduleRelease fires the expiration of the trace once the wait duration elapses.
+func (sp *groupByTraceProcessor) scheduleRelease(traceID pdata.TraceID) {
 	sp.logger.Debug("scheduled to release trace", zap.Duration("duration", sp.config.WaitDuration))
 
 	time.AfterFunc(sp.config.WaitDuration, func() {
@@ -173,7 +183,32 @@ func (sp *groupByTraceProcessor) processBatch(batch *singleTraceBatch) error {
 			payload: traceID,
 		})
 	})
+}
+
+// recoverTraces places the traces left by a previous run in a storage that keeps them across restarts into the ring
+// buffer, and schedules them to be released once the wait duration elapses. It must be called before the event
+// machine is started, as it accesses the ring buffer directly.
+func (sp *groupByTraceProcessor) recoverTraces() error {
+	st, ok := sp.st.(recoverableStorage)
+	if !ok {
+		return nil
+	}
+	traceIDs, err := st.pending()
+	if err != nil {
+		return fmt.Errorf("couldn't recover the traces from the storage: %w", err)
+	}
 
+	for _, traceID := range traceIDs {
+		if evicted := sp.ringBuffer.put(traceID); evicted != nil {
+			if _, err := sp.removeTrace(evicted); err != nil {
+				sp.logger.Info("failed to delete evicted trace", zap.Error(err), zap.Stringer("traceID", evicted))
+			}
+		}
+		sp.scheduleRelease(traceID)
+	}
+	if len(traceIDs) > 0 {
+		sp.logger.Info("recovered traces from the storage", zap.Int("traces", len(traceIDs)))
+	}
 	return nil
 }
 
@@ -208,6 +243,15 @@ func (sp *groupByTraceProcessor) markAsReleased(traceID pdata.TraceID) error {
 		return fmt.Errorf("the trace %q couldn't be found at the storage", traceID)
 	}
 
+	if sp.config.DiscardOrphans && !hasRootSpan(trace) {
+		sp.logger.Debug("discarding orphan trace", zap.Stringer("traceID", traceID))
+		sp.eventMachine.fire(event{
+			typ:     traceRemoved,
+			payload: traceID,
+		})
+		return nil
+	}
+
 	// signal that the trace is ready to be released
 	sp.logger.Debug("trace marked as released", zap.Stringer("traceID", traceID))
 
@@ -233,23 +277,48 @@ func (sp *groupByTraceProcessor) onTraceReleased(rss []pdata.ResourceSpans) erro
 }
 
 func (sp *groupByTraceProcessor) onTraceRemoved(traceID pdata.TraceID) error {
-	trace, err := sp.st.delete(traceID)
+	found, err := sp.removeTrace(traceID)
 	if err != nil {
 		return fmt.Errorf("couldn't delete trace %q from the storage: %w", traceID.String(), err)
 	}
 
-	if trace == nil {
+	if !found {
 		return fmt.Errorf("trace %q not found at the storage", traceID.String())
 	}
 
 	return nil
 }
 
+// removeTrace removes the trace from the storage, without retrieving it if the storage can do so, and returns whether
+// it was found.
+func (sp *groupByTraceProcessor) removeTrace(traceID pdata.TraceID) (bool, error) {
+	if st, ok := sp.st.(removableStorage); ok {
+		return st.remove(traceID)
+	}
+	trace, err := sp.st.delete(traceID)
+	return trace != nil, err
+}
+
 func (sp *groupByTraceProcessor) addSpans(traceID pdata.TraceID, trace pdata.ResourceSpans) error {
 	sp.logger.Debug("creating trace at the storage", zap.Stringer("traceID", traceID))
 	return sp.st.createOrAppend(traceID, trace)
 }
 
+// hasRootSpan returns whether any of the spans of the trace has no parent.
+func hasRootSpan(rss []pdata.ResourceSpans) bool {
+	for _, rs := range rss {
+		for i := 0; i < rs.InstrumentationLibrarySpans().Len(); i++ {
+			spans := rs.InstrumentationLibrarySpans().At(i).Spans()
+			for j := 0; j < spans.Len(); j++ {
+				if len(spans.At(j).ParentSpanID()) == 0 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 type singleTraceBatch struct {
 	traceID pdata.TraceID
 	rs      pdata.ResourceSpans
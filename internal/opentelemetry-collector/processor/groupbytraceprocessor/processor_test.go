@@ -18,6 +18,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"sync"
 	"testing"
 	"time"
@@ -761,6 +763,101 @@ func TestErrorOnProcessResourceSpansContinuesProcessing(t *testing.T) {
 	assert.True(t, returnedError)
 }
 
+func TestTracesAreRecoveredFromDisk(t *testing.T) {
+	// prepare
+	dir, err := ioutil.TempDir("", "groupbytrace")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// a trace left on disk by a previous run
+	traceID := pdata.NewTraceID([]byte{1, 2, 3, 4})
+	previous, err := newDiskStorage(dir, logger)
+	require.NoError(t, err)
+	require.NoError(t, previous.createOrAppend(traceID, newTestResourceSpans(traceID, []byte{1, 2, 3, 4}, "recovered")))
+
+	wg := &sync.WaitGroup{}
+	config := Config{
+		WaitDuration: time.Nanosecond,
+		NumTraces:    5,
+	}
+	st, err := newDiskStorage(dir, logger)
+	require.NoError(t, err)
+	next := &mockProcessor{
+		onTraces: func(_ context.Context, traces pdata.Traces) error {
+			assert.Equal(t, "recovered", traces.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).Name())
+			wg.Done()
+			return nil
+		},
+	}
+
+	p, err := newGroupByTraceProcessor(logger, st, next, config)
+	require.NoError(t, err)
+
+	// test
+	wg.Add(1)
+	ctx := context.Background()
+	require.NoError(t, p.Start(ctx, nil))
+	defer p.Shutdown(ctx)
+
+	// verify
+	wg.Wait()
+}
+
+func TestOrphanTracesAreDiscarded(t *testing.T) {
+	// prepare
+	wgDeleted := &sync.WaitGroup{}
+	config := Config{
+		WaitDuration:   time.Nanosecond,
+		NumTraces:      5,
+		DiscardOrphans: true,
+	}
+	backing := newMemoryStorage()
+	st := &mockStorage{
+		onCreateOrAppend: backing.createOrAppend,
+		onGet:            backing.get,
+		onDelete: func(traceID pdata.TraceID) ([]pdata.ResourceSpans, error) {
+			wgDeleted.Done()
+			return backing.delete(traceID)
+		},
+	}
+	next := &mockProcessor{
+		onTraces: func(_ context.Context, traces pdata.Traces) error {
+			// only the trace with a root span should be released
+			assert.Equal(t, "root-span", traces.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).Name())
+			return nil
+		},
+	}
+
+	p, err := newGroupByTraceProcessor(logger, st, next, config)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	p.Start(ctx, nil)
+	defer p.Shutdown(ctx)
+
+	traces := []*v1.ResourceSpans{{
+		InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{{
+			Spans: []*v1.Span{{
+				Name:    "root-span",
+				TraceId: []byte{1, 2, 3, 4},
+				SpanId:  []byte{1, 2, 3, 4},
+			}, {
+				Name:         "orphan-span",
+				TraceId:      []byte{2, 3, 4, 5},
+				SpanId:       []byte{2, 3, 4, 5},
+				ParentSpanId: []byte{3, 4, 5, 6},
+			}},
+		}},
+	}}
+
+	// test
+	wgDeleted.Add(2) // both traces are removed from the storage
+	p.ConsumeTraces(ctx, pdata.TracesFromOtlp(traces))
+
+	// verify
+	wgDeleted.Wait()
+}
+
 func BenchmarkConsumeTracesCompleteOnFirstBatch(b *testing.B) {
 	// prepare
 	config := Config{
@@ -34,3 +34,20 @@ type storage interface {
 	// or nil in case a trace cannot be found
 	delete(pdata.TraceID) ([]pdata.ResourceSpans, error)
 }
+
+// recoverableStorage is a storage keeping traces across restarts, such as the disk storage.
+type recoverableStorage interface {
+	storage
+
+	// pending returns the IDs of the traces left in the storage by a previous run, from the oldest to the newest
+	pending() ([]pdata.TraceID, error)
+}
+
+// removableStorage is a storage able to remove a trace without retrieving it, which is cheaper for storages that would
+// otherwise read the trace back, such as the disk storage.
+type removableStorage interface {
+	storage
+
+	// remove removes the trace based on the given trace ID, returning whether it was found
+	remove(pdata.TraceID) (bool, error)
+}
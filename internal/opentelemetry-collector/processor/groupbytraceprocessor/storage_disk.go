@@ -0,0 +1,194 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package groupbytraceprocessor
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+	otlptrace "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/trace/v1"
+)
+
+// diskTraceFileExt is the extension of the files holding the spans of a trace, named after the trace ID.
+const diskTraceFileExt = ".trace"
+
+var errDiskStorageNoDirectory = errors.New("the directory of the disk storage must be set")
+
+// diskStorage is a storage keeping the spans of each trace in a file of its own, so that only the trace IDs are kept
+// in memory. Each file holds the resource spans appended to the trace, marshaled as OTLP and prefixed with their
+// length. The files are kept on shutdown, and the traces they hold are recovered by the next run. Appends are not
+// synced to disk, so the traces survive a crash of the process but not one of the host.
+type diskStorage struct {
+	sync.Mutex
+	directory string
+	logger    *zap.Logger
+}
+
+var _ recoverableStorage = (*diskStorage)(nil)
+var _ removableStorage = (*diskStorage)(nil)
+
+func newDiskStorage(directory string, logger *zap.Logger) (*diskStorage, error) {
+	if directory == "" {
+		return nil, errDiskStorageNoDirectory
+	}
+	if err := os.MkdirAll(directory, 0700); err != nil {
+		return nil, fmt.Errorf("couldn't create the directory of the disk storage: %w", err)
+	}
+	return &diskStorage{
+		directory: directory,
+		logger:    logger,
+	}, nil
+}
+
+func (st *diskStorage) createOrAppend(traceID pdata.TraceID, rs pdata.ResourceSpans) error {
+	if rs.IsNil() {
+		return errStorageNilResourceSpans
+	}
+
+	td := pdata.NewTraces()
+	td.ResourceSpans().Append(&rs)
+	data, err := pdata.TracesToOtlp(td)[0].Marshal()
+	if err != nil {
+		return err
+	}
+	record := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(data))
+	record = append(record[:binary.PutUvarint(record, uint64(len(data)))], data...)
+
+	st.Lock()
+	defer st.Unlock()
+
+	f, err := os.OpenFile(st.path(traceID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(record); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (st *diskStorage) get(traceID pdata.TraceID) ([]pdata.ResourceSpans, error) {
+	st.Lock()
+	defer st.Unlock()
+	return st.read(traceID)
+}
+
+// delete will return the trace read from its file before removing the file.
+func (st *diskStorage) delete(traceID pdata.TraceID) ([]pdata.ResourceSpans, error) {
+	st.Lock()
+	defer st.Unlock()
+
+	result, err := st.read(traceID)
+	if result == nil && err == nil {
+		return nil, nil
+	}
+	if rmErr := os.Remove(st.path(traceID)); rmErr != nil && !os.IsNotExist(rmErr) {
+		return nil, rmErr
+	}
+	return result, err
+}
+
+// remove removes the file of the trace without reading it.
+func (st *diskStorage) remove(traceID pdata.TraceID) (bool, error) {
+	st.Lock()
+	defer st.Unlock()
+
+	err := os.Remove(st.path(traceID))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// pending returns the IDs of the traces with a file in the directory, which were left by a previous run, from the
+// least to the most recently written.
+func (st *diskStorage) pending() ([]pdata.TraceID, error) {
+	st.Lock()
+	defer st.Unlock()
+
+	files, err := ioutil.ReadDir(st.directory)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].ModTime().Before(files[j].ModTime()) })
+
+	var traceIDs []pdata.TraceID
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, diskTraceFileExt) {
+			continue
+		}
+		id, err := hex.DecodeString(strings.TrimSuffix(name, diskTraceFileExt))
+		if err != nil || len(id) == 0 {
+			st.logger.Warn("skipping file with an invalid trace ID in the storage directory", zap.String("file", name))
+			continue
+		}
+		traceIDs = append(traceIDs, pdata.NewTraceID(id))
+	}
+	return traceIDs, nil
+}
+
+// read returns the resource spans of the file of the trace, or nil if the trace has no file. A record cut short, such
+// as by a crash while it was written, ends the trace.
+func (st *diskStorage) read(traceID pdata.TraceID) ([]pdata.ResourceSpans, error) {
+	data, err := ioutil.ReadFile(st.path(traceID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var orig []*otlptrace.ResourceSpans
+	for len(data) > 0 {
+		size, n := binary.Uvarint(data)
+		if n <= 0 || uint64(len(data)-n) < size {
+			st.logger.Warn("ignoring the truncated end of a trace in the storage", zap.Stringer("traceID", traceID))
+			break
+		}
+		rs := &otlptrace.ResourceSpans{}
+		if err := rs.Unmarshal(data[n : n+int(size)]); err != nil {
+			return nil, fmt.Errorf("couldn't read trace %q from the storage: %w", traceID, err)
+		}
+		orig = append(orig, rs)
+		data = data[n+int(size):]
+	}
+
+	rss := pdata.TracesFromOtlp(orig).ResourceSpans()
+	result := []pdata.ResourceSpans{}
+	for i := 0; i < rss.Len(); i++ {
+		result = append(result, rss.At(i))
+	}
+	return result, nil
+}
+
+func (st *diskStorage) path(traceID pdata.TraceID) string {
+	return filepath.Join(st.directory, traceID.String()+diskTraceFileExt)
+}
@@ -0,0 +1,206 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package groupbytraceprocessor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+)
+
+func newTestDiskStorage(t *testing.T) (*diskStorage, func()) {
+	dir, err := ioutil.TempDir("", "groupbytrace")
+	require.NoError(t, err)
+	st, err := newDiskStorage(dir, zap.NewNop())
+	require.NoError(t, err)
+	return st, func() { os.RemoveAll(dir) }
+}
+
+// newTestResourceSpans returns resource spans holding a single span of the trace with the given span ID and name.
+func newTestResourceSpans(traceID pdata.TraceID, spanID []byte, name string) pdata.ResourceSpans {
+	span := pdata.NewSpan()
+	span.InitEmpty()
+	span.SetTraceID(traceID)
+	span.SetSpanID(pdata.NewSpanID(spanID))
+	span.SetName(name)
+
+	ils := pdata.NewInstrumentationLibrarySpans()
+	ils.InitEmpty()
+	ils.Spans().Append(&span)
+
+	rs := pdata.NewResourceSpans()
+	rs.InitEmpty()
+	rs.InstrumentationLibrarySpans().Append(&ils)
+	return rs
+}
+
+func TestDiskCreateAppendAndGetTrace(t *testing.T) {
+	// prepare
+	st, cleanup := newTestDiskStorage(t)
+	defer cleanup()
+
+	traceID := pdata.NewTraceID([]byte{1, 2, 3, 4})
+	first := newTestResourceSpans(traceID, []byte{1, 2, 3, 4}, "first")
+	second := newTestResourceSpans(traceID, []byte{5, 6, 7, 8}, "second")
+
+	// test
+	require.NoError(t, st.createOrAppend(traceID, first))
+	require.NoError(t, st.createOrAppend(traceID, second))
+
+	// verify
+	retrieved, err := st.get(traceID)
+	require.NoError(t, err)
+	require.Len(t, retrieved, 2)
+	assert.Equal(t, "first", retrieved[0].InstrumentationLibrarySpans().At(0).Spans().At(0).Name())
+	assert.Equal(t, "second", retrieved[1].InstrumentationLibrarySpans().At(0).Spans().At(0).Name())
+	assert.Equal(t, traceID, retrieved[1].InstrumentationLibrarySpans().At(0).Spans().At(0).TraceID())
+
+	unknown, err := st.get(pdata.NewTraceID([]byte{2, 3, 4, 5}))
+	require.NoError(t, err)
+	assert.Nil(t, unknown)
+}
+
+func TestDiskDeleteTrace(t *testing.T) {
+	// prepare
+	st, cleanup := newTestDiskStorage(t)
+	defer cleanup()
+
+	traceID := pdata.NewTraceID([]byte{1, 2, 3, 4})
+	require.NoError(t, st.createOrAppend(traceID, newTestResourceSpans(traceID, []byte{1, 2, 3, 4}, "span")))
+
+	// test
+	deleted, err := st.delete(traceID)
+
+	// verify
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	assert.Equal(t, "span", deleted[0].InstrumentationLibrarySpans().At(0).Spans().At(0).Name())
+
+	retrieved, err := st.get(traceID)
+	require.NoError(t, err)
+	assert.Nil(t, retrieved)
+
+	deleted, err = st.delete(traceID)
+	require.NoError(t, err)
+	assert.Nil(t, deleted)
+}
+
+func TestDiskRemoveTrace(t *testing.T) {
+	// prepare
+	st, cleanup := newTestDiskStorage(t)
+	defer cleanup()
+
+	traceID := pdata.NewTraceID([]byte{1, 2, 3, 4})
+	require.NoError(t, st.createOrAppend(traceID, newTestResourceSpans(traceID, []byte{1, 2, 3, 4}, "span")))
+	// the file is not read, so a trace that cannot be read is removed as well
+	corrupted := pdata.NewTraceID([]byte{2, 3, 4, 5})
+	require.NoError(t, ioutil.WriteFile(st.path(corrupted), []byte{2, 0xff, 0xff}, 0600))
+
+	// test
+	found, err := st.remove(traceID)
+	require.NoError(t, err)
+	assert.True(t, found)
+	found, err = st.remove(corrupted)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	// verify
+	retrieved, err := st.get(traceID)
+	require.NoError(t, err)
+	assert.Nil(t, retrieved)
+	_, err = os.Stat(st.path(corrupted))
+	assert.True(t, os.IsNotExist(err))
+
+	found, err = st.remove(traceID)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestDiskPendingTraces(t *testing.T) {
+	// prepare
+	st, cleanup := newTestDiskStorage(t)
+	defer cleanup()
+
+	traceIDs := []pdata.TraceID{
+		pdata.NewTraceID([]byte{1, 2, 3, 4}),
+		pdata.NewTraceID([]byte{2, 3, 4, 5}),
+	}
+	for _, traceID := range traceIDs {
+		require.NoError(t, st.createOrAppend(traceID, newTestResourceSpans(traceID, []byte{1, 2, 3, 4}, "span")))
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(st.directory, "unrelated"), []byte("data"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(st.directory, "invalid"+diskTraceFileExt), nil, 0600))
+
+	// test
+	restarted, err := newDiskStorage(st.directory, zap.NewNop())
+	require.NoError(t, err)
+	pending, err := restarted.pending()
+
+	// verify
+	require.NoError(t, err)
+	assert.ElementsMatch(t, traceIDs, pending)
+}
+
+func TestDiskTruncatedTrace(t *testing.T) {
+	// prepare
+	st, cleanup := newTestDiskStorage(t)
+	defer cleanup()
+
+	traceID := pdata.NewTraceID([]byte{1, 2, 3, 4})
+	require.NoError(t, st.createOrAppend(traceID, newTestResourceSpans(traceID, []byte{1, 2, 3, 4}, "span")))
+
+	// a record cut short by a crash
+	f, err := os.OpenFile(st.path(traceID), os.O_WRONLY|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{100, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// test
+	retrieved, err := st.get(traceID)
+
+	// verify
+	require.NoError(t, err)
+	require.Len(t, retrieved, 1)
+	assert.Equal(t, "span", retrieved[0].InstrumentationLibrarySpans().At(0).Spans().At(0).Name())
+}
+
+func TestDiskCreateWithNilParameter(t *testing.T) {
+	// prepare
+	st, cleanup := newTestDiskStorage(t)
+	defer cleanup()
+
+	// test
+	err := st.createOrAppend(pdata.NewTraceID([]byte{1, 2, 3, 4}), pdata.NewResourceSpans())
+
+	// verify
+	require.Equal(t, errStorageNilResourceSpans, err)
+}
+
+func TestDiskStorageWithoutDirectory(t *testing.T) {
+	// test
+	st, err := newDiskStorage("", zap.NewNop())
+
+	// verify
+	assert.Equal(t, errDiskStorageNoDirectory, err)
+	assert.Nil(t, st)
+}
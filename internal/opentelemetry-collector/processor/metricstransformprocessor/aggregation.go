@@ -0,0 +1,238 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricstransformprocessor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+)
+
+// checkAggregation returns an error if the data points of metric cannot be aggregated with aggregationType, as
+// histograms can only be summed.
+func checkAggregation(metric pdata.Metric, aggregationType AggregationType) error {
+	switch metric.DataType() {
+	case pdata.MetricDataIntHistogram, pdata.MetricDataDoubleHistogram:
+		if aggregationType != Sum {
+			return fmt.Errorf("histograms cannot be aggregated with aggregation_type %q", aggregationType)
+		}
+	}
+	return nil
+}
+
+// aggregateDataPoints aggregates the data points of metric that have the same labels and timestamps, whatever their
+// start times. The aggregated data points start at the earliest start time of the data points they aggregate.
+func aggregateDataPoints(metric pdata.Metric, aggregationType AggregationType) {
+	aggregateIntDataPoints(intDataPoints(metric), aggregationType)
+	aggregateDoubleDataPoints(doubleDataPoints(metric), aggregationType)
+	aggregateIntHistogramDataPoints(intHistogramDataPoints(metric))
+	aggregateDoubleHistogramDataPoints(doubleHistogramDataPoints(metric))
+}
+
+func aggregateIntDataPoints(dps pdata.IntDataPointSlice, aggregationType AggregationType) {
+	aggregated := pdata.NewIntDataPointSlice()
+	indexes := map[string]int{}
+	var counts []int64
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		if dp.IsNil() {
+			continue
+		}
+		key := dataPointKey(dp.LabelsMap(), dp.Timestamp())
+		if index, ok := indexes[key]; ok {
+			acc := aggregated.At(index)
+			acc.SetStartTime(earliestStartTime(acc.StartTime(), dp.StartTime()))
+			acc.SetValue(aggregateInt64(acc.Value(), dp.Value(), aggregationType))
+			counts[index]++
+			continue
+		}
+		indexes[key] = aggregated.Len()
+		counts = append(counts, 1)
+		aggregated.Append(&dp)
+	}
+	// the mean of int data points is truncated toward zero, as they cannot hold a fraction
+	if aggregationType == Mean {
+		for i := 0; i < aggregated.Len(); i++ {
+			aggregated.At(i).SetValue(aggregated.At(i).Value() / counts[i])
+		}
+	}
+	dps.Resize(0)
+	aggregated.MoveAndAppendTo(dps)
+}
+
+func aggregateDoubleDataPoints(dps pdata.DoubleDataPointSlice, aggregationType AggregationType) {
+	aggregated := pdata.NewDoubleDataPointSlice()
+	indexes := map[string]int{}
+	var counts []float64
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		if dp.IsNil() {
+			continue
+		}
+		key := dataPointKey(dp.LabelsMap(), dp.Timestamp())
+		if index, ok := indexes[key]; ok {
+			acc := aggregated.At(index)
+			acc.SetStartTime(earliestStartTime(acc.StartTime(), dp.StartTime()))
+			acc.SetValue(aggregateDouble(acc.Value(), dp.Value(), aggregationType))
+			counts[index]++
+			continue
+		}
+		indexes[key] = aggregated.Len()
+		counts = append(counts, 1)
+		aggregated.Append(&dp)
+	}
+	if aggregationType == Mean {
+		for i := 0; i < aggregated.Len(); i++ {
+			aggregated.At(i).SetValue(aggregated.At(i).Value() / counts[i])
+		}
+	}
+	dps.Resize(0)
+	aggregated.MoveAndAppendTo(dps)
+}
+
+// aggregateIntHistogramDataPoints sums the histogram data points that have the same labels, timestamps and buckets.
+func aggregateIntHistogramDataPoints(dps pdata.IntHistogramDataPointSlice) {
+	aggregated := pdata.NewIntHistogramDataPointSlice()
+	indexes := map[string]int{}
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		if dp.IsNil() {
+			continue
+		}
+		key := dataPointKey(dp.LabelsMap(), dp.Timestamp()) + bucketsKey(dp.BucketCounts(), dp.ExplicitBounds())
+		if index, ok := indexes[key]; ok {
+			acc := aggregated.At(index)
+			acc.SetStartTime(earliestStartTime(acc.StartTime(), dp.StartTime()))
+			acc.SetCount(acc.Count() + dp.Count())
+			acc.SetSum(acc.Sum() + dp.Sum())
+			acc.SetBucketCounts(sumBucketCounts(acc.BucketCounts(), dp.BucketCounts()))
+			continue
+		}
+		indexes[key] = aggregated.Len()
+		aggregated.Append(&dp)
+	}
+	dps.Resize(0)
+	aggregated.MoveAndAppendTo(dps)
+}
+
+// aggregateDoubleHistogramDataPoints sums the histogram data points that have the same labels, timestamps and
+// buckets.
+func aggregateDoubleHistogramDataPoints(dps pdata.DoubleHistogramDataPointSlice) {
+	aggregated := pdata.NewDoubleHistogramDataPointSlice()
+	indexes := map[string]int{}
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		if dp.IsNil() {
+			continue
+		}
+		key := dataPointKey(dp.LabelsMap(), dp.Timestamp()) + bucketsKey(dp.BucketCounts(), dp.ExplicitBounds())
+		if index, ok := indexes[key]; ok {
+			acc := aggregated.At(index)
+			acc.SetStartTime(earliestStartTime(acc.StartTime(), dp.StartTime()))
+			acc.SetCount(acc.Count() + dp.Count())
+			acc.SetSum(acc.Sum() + dp.Sum())
+			acc.SetBucketCounts(sumBucketCounts(acc.BucketCounts(), dp.BucketCounts()))
+			continue
+		}
+		indexes[key] = aggregated.Len()
+		aggregated.Append(&dp)
+	}
+	dps.Resize(0)
+	aggregated.MoveAndAppendTo(dps)
+}
+
+// sumBucketCounts returns the sums of the bucket counts a and b, which have the same length, in a new slice, as
+// bucket counts may be shared with copies of the data point.
+func sumBucketCounts(a, b []uint64) []uint64 {
+	sums := make([]uint64, len(a))
+	for i := range a {
+		sums[i] = a[i] + b[i]
+	}
+	return sums
+}
+
+func aggregateInt64(acc, value int64, aggregationType AggregationType) int64 {
+	switch aggregationType {
+	case Min:
+		if value < acc {
+			return value
+		}
+		return acc
+	case Max:
+		if value > acc {
+			return value
+		}
+		return acc
+	}
+	// the sum is divided by the number of values for the mean
+	return acc + value
+}
+
+func aggregateDouble(acc, value float64, aggregationType AggregationType) float64 {
+	switch aggregationType {
+	case Min:
+		return math.Min(acc, value)
+	case Max:
+		return math.Max(acc, value)
+	}
+	// the sum is divided by the number of values for the mean
+	return acc + value
+}
+
+// earliestStartTime returns the earliest of the start times a and b, ignoring unset ones.
+func earliestStartTime(a, b pdata.TimestampUnixNano) pdata.TimestampUnixNano {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// dataPointKey returns the key of the data points with labels and timestamp, that are aggregated together.
+func dataPointKey(labels pdata.StringMap, timestamp pdata.TimestampUnixNano) string {
+	keys := make([]string, 0, labels.Len())
+	values := make(map[string]string, labels.Len())
+	labels.ForEach(func(k string, v pdata.StringValue) {
+		keys = append(keys, k)
+		values[k] = v.Value()
+	})
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(strconv.FormatUint(uint64(timestamp), 10))
+	for _, k := range keys {
+		b.WriteByte(0)
+		b.WriteString(k)
+		b.WriteByte(0)
+		b.WriteString(values[k])
+	}
+	return b.String()
+}
+
+// bucketsKey returns the key of the buckets of a histogram data point, as only the histograms with the same buckets
+// are aggregated together.
+func bucketsKey(bucketCounts []uint64, explicitBounds []float64) string {
+	var b strings.Builder
+	b.WriteByte(0)
+	b.WriteString(strconv.Itoa(len(bucketCounts)))
+	for _, bound := range explicitBounds {
+		b.WriteByte(0)
+		b.WriteString(strconv.FormatFloat(bound, 'g', -1, 64))
+	}
+	return b.String()
+}
@@ -0,0 +1,147 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricstransformprocessor
+
+import (
+	"go.opentelemetry.io/collector/config/configmodels"
+	"go.opentelemetry.io/collector/internal/processor/filterset"
+)
+
+// Config defines configuration for the Metrics Transform processor.
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+
+	// Transforms specifies the list of transforms on metrics, applied in the order specified in the configuration.
+	Transforms []Transform `mapstructure:"transforms"`
+}
+
+// Transform defines the transformation applied to the metrics matching a name.
+type Transform struct {
+	// Include specifies the name, or the regexp of the name, of the metrics to transform.
+	// This is a required field.
+	Include string `mapstructure:"include"`
+
+	// MatchType determines how Include is matched against metric names: {strict, regexp}.
+	// The default is strict.
+	MatchType filterset.MatchType `mapstructure:"match_type"`
+
+	// Action specifies whether the matching metrics are updated in place or copied as new metrics: {update, insert}.
+	// This is a required field.
+	Action ConfigAction `mapstructure:"action"`
+
+	// NewName specifies the name of the updated or inserted metric. With the regexp match type, it can reference
+	// the submatches of Include, e.g. "$1".
+	// This is a required field for the insert action.
+	NewName string `mapstructure:"new_name"`
+
+	// Operations contains the list of operations applied to the data points of the metric, in the order specified
+	// in the configuration.
+	Operations []Operation `mapstructure:"operations"`
+}
+
+// Operation defines an operation applied to the data points of a metric.
+type Operation struct {
+	// Action specifies the operation: {add_label, update_label, delete_label_value, toggle_scalar_data_type,
+	// aggregate_labels, aggregate_label_values}.
+	// This is a required field.
+	Action OperationAction `mapstructure:"action"`
+
+	// Label specifies the label to act upon, for update_label, delete_label_value and aggregate_label_values.
+	Label string `mapstructure:"label"`
+
+	// NewLabel specifies the label to add with add_label, or the new name of Label with update_label.
+	NewLabel string `mapstructure:"new_label"`
+
+	// LabelSet specifies the labels kept by aggregate_labels. Data points are aggregated across the other labels.
+	LabelSet []string `mapstructure:"label_set"`
+
+	// AggregationType specifies how the values of aggregated data points are combined, for aggregate_labels and
+	// aggregate_label_values: {sum, mean, min, max}.
+	AggregationType AggregationType `mapstructure:"aggregation_type"`
+
+	// AggregatedValues specifies the values of Label that are combined into NewValue by aggregate_label_values.
+	AggregatedValues []string `mapstructure:"aggregated_values"`
+
+	// NewValue specifies the value of NewLabel with add_label, or the value combining AggregatedValues with
+	// aggregate_label_values.
+	NewValue string `mapstructure:"new_value"`
+
+	// LabelValue specifies the value of Label of the data points removed by delete_label_value.
+	LabelValue string `mapstructure:"label_value"`
+
+	// ValueActions specifies the values of Label renamed by update_label.
+	ValueActions []ValueAction `mapstructure:"value_actions"`
+}
+
+// ValueAction renames a label value.
+type ValueAction struct {
+	// Value specifies the current value of the label.
+	Value string `mapstructure:"value"`
+
+	// NewValue specifies the new value of the label.
+	NewValue string `mapstructure:"new_value"`
+}
+
+// ConfigAction is the enum of the actions of a Transform.
+type ConfigAction string
+
+const (
+	// Update updates the matching metrics in place.
+	Update ConfigAction = "update"
+
+	// Insert copies the matching metrics, and applies the transform to the copies.
+	Insert ConfigAction = "insert"
+)
+
+// OperationAction is the enum of the actions of an Operation.
+type OperationAction string
+
+const (
+	// AddLabel adds a label with a fixed value to every data point.
+	AddLabel OperationAction = "add_label"
+
+	// UpdateLabel renames a label and/or its values.
+	UpdateLabel OperationAction = "update_label"
+
+	// DeleteLabelValue removes the data points with a label value.
+	DeleteLabelValue OperationAction = "delete_label_value"
+
+	// ToggleScalarDataType converts int64 data points to double data points and vice versa.
+	// This is an experimental operation.
+	ToggleScalarDataType OperationAction = "toggle_scalar_data_type"
+
+	// AggregateLabels aggregates the data points across the labels that are not in the label set.
+	AggregateLabels OperationAction = "aggregate_labels"
+
+	// AggregateLabelValues combines label values into a new value, and aggregates the data points sharing it.
+	AggregateLabelValues OperationAction = "aggregate_label_values"
+)
+
+// AggregationType is the enum of the ways to combine the values of aggregated data points.
+type AggregationType string
+
+const (
+	// Sum sums the values.
+	Sum AggregationType = "sum"
+
+	// Mean averages the values.
+	Mean AggregationType = "mean"
+
+	// Min keeps the minimum value.
+	Min AggregationType = "min"
+
+	// Max keeps the maximum value.
+	Max AggregationType = "max"
+)
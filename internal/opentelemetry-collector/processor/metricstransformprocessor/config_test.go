@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricstransformprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configmodels"
+	"go.opentelemetry.io/collector/config/configtest"
+	"go.opentelemetry.io/collector/internal/processor/filterset"
+)
+
+func TestLoadingConfig(t *testing.T) {
+	factories, err := componenttest.ExampleComponents()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	cfg, err := configtest.LoadConfigFile(t, path.Join(".", "testdata", "config.yaml"), factories)
+	assert.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	p0 := cfg.Processors["metricstransform"]
+	assert.Equal(t, factory.CreateDefaultConfig(), p0)
+
+	p1 := cfg.Processors["metricstransform/multiple"]
+	assert.Equal(t, &Config{
+		ProcessorSettings: configmodels.ProcessorSettings{
+			NameVal: "metricstransform/multiple",
+			TypeVal: typeStr,
+		},
+		Transforms: []Transform{
+			{
+				Include: "old_name",
+				Action:  Update,
+				NewName: "new_name",
+			},
+			{
+				Include:   `^system\.cpu\.(.*)$`,
+				MatchType: filterset.Regexp,
+				Action:    Update,
+				NewName:   "host.cpu.${1}",
+				Operations: []Operation{
+					{
+						Action:          AggregateLabels,
+						LabelSet:        []string{"state"},
+						AggregationType: Sum,
+					},
+				},
+			},
+			{
+				Include: "requests",
+				Action:  Insert,
+				NewName: "requests_by_status_class",
+				Operations: []Operation{
+					{
+						Action:   AddLabel,
+						NewLabel: "version",
+						NewValue: "v1",
+					},
+					{
+						Action:   UpdateLabel,
+						Label:    "status",
+						NewLabel: "status_class",
+					},
+					{
+						Action:           AggregateLabelValues,
+						Label:            "status_class",
+						AggregatedValues: []string{"200", "201", "204"},
+						NewValue:         "2xx",
+						AggregationType:  Sum,
+					},
+					{
+						Action:     DeleteLabelValue,
+						Label:      "status_class",
+						LabelValue: "404",
+					},
+					{
+						Action: ToggleScalarDataType,
+					},
+				},
+			},
+		},
+	}, p1)
+}
@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricstransformprocessor
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configcheck"
+	"go.opentelemetry.io/collector/config/configerror"
+	"go.opentelemetry.io/collector/config/configmodels"
+	"go.opentelemetry.io/collector/config/configtest"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/internal/processor/filterset"
+)
+
+func TestType(t *testing.T) {
+	factory := NewFactory()
+	assert.Equal(t, configmodels.Type(typeStr), factory.Type())
+}
+
+func TestCreateDefaultConfig(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	assert.Equal(t, &Config{
+		ProcessorSettings: configmodels.ProcessorSettings{
+			NameVal: typeStr,
+			TypeVal: typeStr,
+		},
+	}, cfg)
+	assert.NoError(t, configcheck.ValidateConfig(cfg))
+}
+
+func TestCreateProcessors(t *testing.T) {
+	factories, err := componenttest.ExampleComponents()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	cfg, err := configtest.LoadConfigFile(t, path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+
+	for name, pCfg := range cfg.Processors {
+		t.Run(name, func(t *testing.T) {
+			tp, err := factory.CreateTraceProcessor(
+				context.Background(), component.ProcessorCreateParams{}, exportertest.NewNopTraceExporter(), pCfg)
+			assert.Nil(t, tp)
+			assert.Equal(t, configerror.ErrDataTypeIsNotSupported, err)
+
+			mp, err := factory.CreateMetricsProcessor(
+				context.Background(), component.ProcessorCreateParams{}, exportertest.NewNopMetricsExporter(), pCfg)
+			assert.NotNil(t, mp)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCreateMetricsProcessorInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform Transform
+	}{
+		{
+			name:      "missing include",
+			transform: Transform{Action: Update, NewName: "new_name"},
+		},
+		{
+			name:      "invalid match type",
+			transform: Transform{Include: "name", MatchType: "wildcard", Action: Update},
+		},
+		{
+			name:      "invalid regexp",
+			transform: Transform{Include: "(", MatchType: filterset.Regexp, Action: Update},
+		},
+		{
+			name:      "invalid action",
+			transform: Transform{Include: "name", Action: "delete"},
+		},
+		{
+			name:      "insert without new name",
+			transform: Transform{Include: "name", Action: Insert},
+		},
+		{
+			name: "invalid operation",
+			transform: Transform{Include: "name", Action: Update, Operations: []Operation{
+				{Action: "scale_value"},
+			}},
+		},
+		{
+			name: "add label without value",
+			transform: Transform{Include: "name", Action: Update, Operations: []Operation{
+				{Action: AddLabel, NewLabel: "label"},
+			}},
+		},
+		{
+			name: "update label without label",
+			transform: Transform{Include: "name", Action: Update, Operations: []Operation{
+				{Action: UpdateLabel, NewLabel: "label"},
+			}},
+		},
+		{
+			name: "update label without update",
+			transform: Transform{Include: "name", Action: Update, Operations: []Operation{
+				{Action: UpdateLabel, Label: "label"},
+			}},
+		},
+		{
+			name: "delete label value without label",
+			transform: Transform{Include: "name", Action: Update, Operations: []Operation{
+				{Action: DeleteLabelValue, LabelValue: "value"},
+			}},
+		},
+		{
+			name: "aggregate labels without aggregation type",
+			transform: Transform{Include: "name", Action: Update, Operations: []Operation{
+				{Action: AggregateLabels, LabelSet: []string{"label"}},
+			}},
+		},
+		{
+			name: "aggregate label values with invalid aggregation type",
+			transform: Transform{Include: "name", Action: Update, Operations: []Operation{
+				{Action: AggregateLabelValues, Label: "label", AggregatedValues: []string{"a"}, NewValue: "b", AggregationType: "median"},
+			}},
+		},
+		{
+			name: "aggregate label values without new value",
+			transform: Transform{Include: "name", Action: Update, Operations: []Operation{
+				{Action: AggregateLabelValues, Label: "label", AggregatedValues: []string{"a"}, AggregationType: Sum},
+			}},
+		},
+	}
+
+	factory := NewFactory()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := factory.CreateDefaultConfig().(*Config)
+			cfg.Transforms = []Transform{test.transform}
+			mp, err := factory.CreateMetricsProcessor(
+				context.Background(), component.ProcessorCreateParams{}, exportertest.NewNopMetricsExporter(), cfg)
+			assert.Nil(t, mp)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricstransformprocessor
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/consumer/pdatautil"
+	"go.opentelemetry.io/collector/internal/processor/filterset"
+)
+
+type metricsTransformProcessor struct {
+	logger     *zap.Logger
+	transforms []internalTransform
+}
+
+// internalTransform is a validated Transform, with its regexp compiled.
+type internalTransform struct {
+	include    string
+	regexp     *regexp.Regexp
+	action     ConfigAction
+	newName    string
+	operations []internalOperation
+}
+
+// internalOperation is a validated Operation, with its lists of values indexed.
+type internalOperation struct {
+	Operation
+	valueActions     map[string]string
+	labelSet         map[string]bool
+	aggregatedValues map[string]bool
+}
+
+// newMetricsTransformProcessor validates the transforms of cfg, and returns a processor applying them.
+func newMetricsTransformProcessor(logger *zap.Logger, cfg *Config) (*metricsTransformProcessor, error) {
+	transforms := make([]internalTransform, 0, len(cfg.Transforms))
+	for i, t := range cfg.Transforms {
+		transform, err := newInternalTransform(t)
+		if err != nil {
+			return nil, fmt.Errorf("error creating %q processor: %w at the %d-th transform of processor %q", typeStr, err, i, cfg.Name())
+		}
+		transforms = append(transforms, transform)
+	}
+	return &metricsTransformProcessor{logger: logger, transforms: transforms}, nil
+}
+
+func newInternalTransform(t Transform) (internalTransform, error) {
+	if t.Include == "" {
+		return internalTransform{}, fmt.Errorf("missing required field %q", "include")
+	}
+	transform := internalTransform{
+		include: t.Include,
+		action:  t.Action,
+		newName: t.NewName,
+	}
+
+	switch t.MatchType {
+	case "", filterset.Strict:
+	case filterset.Regexp:
+		re, err := regexp.Compile(t.Include)
+		if err != nil {
+			return internalTransform{}, fmt.Errorf("invalid regexp %q in field %q: %v", t.Include, "include", err)
+		}
+		transform.regexp = re
+	default:
+		return internalTransform{}, fmt.Errorf("unsupported match_type %q", t.MatchType)
+	}
+
+	switch t.Action {
+	case Update:
+	case Insert:
+		if t.NewName == "" {
+			return internalTransform{}, fmt.Errorf("missing required field %q for action %q", "new_name", t.Action)
+		}
+	default:
+		return internalTransform{}, fmt.Errorf("unsupported action %q", t.Action)
+	}
+
+	for j, op := range t.Operations {
+		operation, err := newInternalOperation(op)
+		if err != nil {
+			return internalTransform{}, fmt.Errorf("%w at the %d-th operation", err, j)
+		}
+		transform.operations = append(transform.operations, operation)
+	}
+	return transform, nil
+}
+
+func newInternalOperation(op Operation) (internalOperation, error) {
+	operation := internalOperation{Operation: op}
+	switch op.Action {
+	case AddLabel:
+		if op.NewLabel == "" || op.NewValue == "" {
+			return internalOperation{}, fmt.Errorf("fields %q and %q are required for operation %q", "new_label", "new_value", op.Action)
+		}
+	case UpdateLabel:
+		if op.Label == "" {
+			return internalOperation{}, fmt.Errorf("missing required field %q for operation %q", "label", op.Action)
+		}
+		if op.NewLabel == "" && len(op.ValueActions) == 0 {
+			return internalOperation{}, fmt.Errorf("either field %q or %q is required for operation %q", "new_label", "value_actions", op.Action)
+		}
+		operation.valueActions = make(map[string]string, len(op.ValueActions))
+		for _, va := range op.ValueActions {
+			operation.valueActions[va.Value] = va.NewValue
+		}
+	case DeleteLabelValue:
+		if op.Label == "" {
+			return internalOperation{}, fmt.Errorf("missing required field %q for operation %q", "label", op.Action)
+		}
+	case ToggleScalarDataType:
+	case AggregateLabels:
+		if err := validateAggregationType(op); err != nil {
+			return internalOperation{}, err
+		}
+		operation.labelSet = toSet(op.LabelSet)
+	case AggregateLabelValues:
+		if op.Label == "" || op.NewValue == "" || len(op.AggregatedValues) == 0 {
+			return internalOperation{}, fmt.Errorf("fields %q, %q and %q are required for operation %q",
+				"label", "new_value", "aggregated_values", op.Action)
+		}
+		if err := validateAggregationType(op); err != nil {
+			return internalOperation{}, err
+		}
+		operation.aggregatedValues = toSet(op.AggregatedValues)
+	default:
+		return internalOperation{}, fmt.Errorf("unsupported operation %q", op.Action)
+	}
+	return operation, nil
+}
+
+func validateAggregationType(op Operation) error {
+	switch op.AggregationType {
+	case Sum, Mean, Min, Max:
+		return nil
+	}
+	return fmt.Errorf("unsupported aggregation_type %q for operation %q", op.AggregationType, op.Action)
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// ProcessMetrics implements the MProcessor interface
+func (mtp *metricsTransformProcessor) ProcessMetrics(_ context.Context, md pdata.Metrics) (pdata.Metrics, error) {
+	imd := pdatautil.MetricsToInternalMetrics(md)
+	rms := imd.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		if rm.IsNil() {
+			continue
+		}
+		ilms := rm.InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			ilm := ilms.At(j)
+			if ilm.IsNil() {
+				continue
+			}
+			for _, transform := range mtp.transforms {
+				transform.apply(mtp.logger, ilm.Metrics())
+			}
+		}
+	}
+	return pdatautil.MetricsFromInternalMetrics(imd), nil
+}
+
+// apply applies the transform to the matching metrics. Inserted metrics are appended to metrics, and only later
+// transforms are applied to them. The operations that cannot be applied to a metric are skipped and logged.
+func (t *internalTransform) apply(logger *zap.Logger, metrics pdata.MetricSlice) {
+	count := metrics.Len()
+	for i := 0; i < count; i++ {
+		metric := metrics.At(i)
+		if metric.IsNil() {
+			continue
+		}
+		newName, ok := t.match(metric.Name())
+		if !ok {
+			continue
+		}
+
+		if t.action == Insert {
+			inserted := pdata.NewMetric()
+			copyMetric(metric, inserted)
+			metric = inserted
+		}
+		if newName != "" {
+			metric.SetName(newName)
+		}
+		for _, op := range t.operations {
+			if err := op.apply(metric); err != nil {
+				logger.Warn("failed to apply an operation to a metric, it is skipped",
+					zap.String("metric", metric.Name()), zap.String("operation", string(op.Action)), zap.Error(err))
+			}
+		}
+		if t.action == Insert {
+			metrics.Append(&metric)
+		}
+	}
+}
+
+// match returns whether the transform applies to the metric named name, and the new name of the metric, expanded
+// with the submatches of name for regexps.
+func (t *internalTransform) match(name string) (string, bool) {
+	if t.regexp == nil {
+		return t.newName, name == t.include
+	}
+	submatches := t.regexp.FindStringSubmatchIndex(name)
+	if submatches == nil {
+		return "", false
+	}
+	if t.newName == "" {
+		return "", true
+	}
+	return string(t.regexp.ExpandString(nil, t.newName, name, submatches)), true
+}
@@ -0,0 +1,553 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricstransformprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/consumer/pdatautil"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/internal/data"
+	otlpmetrics "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1"
+	"go.opentelemetry.io/collector/internal/dataold"
+	"go.opentelemetry.io/collector/internal/processor/filterset"
+)
+
+// metricBuilder builds a metric of the new internal model for the tests.
+type metricBuilder struct {
+	metric pdata.Metric
+}
+
+// newMetric returns a builder of a metric of dataType. Sums are monotonic and histograms and sums are cumulative.
+func newMetric(name string, dataType pdata.MetricDataType) metricBuilder {
+	metric := pdata.NewMetric()
+	metric.InitEmpty()
+	metric.SetName(name)
+	switch dataType {
+	case pdata.MetricDataIntGauge:
+		data := pdata.NewIntGauge()
+		data.InitEmpty()
+		metric.SetIntGaugeData(data)
+	case pdata.MetricDataDoubleGauge:
+		data := pdata.NewDoubleGauge()
+		data.InitEmpty()
+		metric.SetDoubleGaugeData(data)
+	case pdata.MetricDataIntSum:
+		data := pdata.NewIntSum()
+		data.InitEmpty()
+		data.SetIsMonotonic(true)
+		data.SetAggregationTemporality(pdata.AggregationTemporalityCumulative)
+		metric.SetIntSumData(data)
+	case pdata.MetricDataDoubleSum:
+		data := pdata.NewDoubleSum()
+		data.InitEmpty()
+		data.SetIsMonotonic(true)
+		data.SetAggregationTemporality(pdata.AggregationTemporalityCumulative)
+		metric.SetDoubleSumData(data)
+	case pdata.MetricDataIntHistogram:
+		data := pdata.NewIntHistogram()
+		data.InitEmpty()
+		data.SetAggregationTemporality(pdata.AggregationTemporalityCumulative)
+		metric.SetIntHistogramData(data)
+	case pdata.MetricDataDoubleHistogram:
+		data := pdata.NewDoubleHistogram()
+		data.InitEmpty()
+		data.SetAggregationTemporality(pdata.AggregationTemporalityCumulative)
+		metric.SetDoubleHistogramData(data)
+	}
+	return metricBuilder{metric: metric}
+}
+
+func (b metricBuilder) intPoint(labels map[string]string, timestamp pdata.TimestampUnixNano, value int64) metricBuilder {
+	dp := pdata.NewIntDataPoint()
+	dp.InitEmpty()
+	dp.LabelsMap().InitFromMap(labels)
+	dp.SetTimestamp(timestamp)
+	dp.SetValue(value)
+	intDataPoints(b.metric).Append(&dp)
+	return b
+}
+
+func (b metricBuilder) doublePoint(labels map[string]string, timestamp pdata.TimestampUnixNano, value float64) metricBuilder {
+	dp := pdata.NewDoubleDataPoint()
+	dp.InitEmpty()
+	dp.LabelsMap().InitFromMap(labels)
+	dp.SetTimestamp(timestamp)
+	dp.SetValue(value)
+	doubleDataPoints(b.metric).Append(&dp)
+	return b
+}
+
+func (b metricBuilder) intHistogramPoint(labels map[string]string, sum int64, bucketCounts ...uint64) metricBuilder {
+	dp := pdata.NewIntHistogramDataPoint()
+	dp.InitEmpty()
+	dp.LabelsMap().InitFromMap(labels)
+	dp.SetSum(sum)
+	dp.SetBucketCounts(bucketCounts)
+	dp.SetCount(totalCount(bucketCounts))
+	intHistogramDataPoints(b.metric).Append(&dp)
+	return b
+}
+
+func (b metricBuilder) histogramPoint(labels map[string]string, sum float64, bucketCounts ...uint64) metricBuilder {
+	dp := pdata.NewDoubleHistogramDataPoint()
+	dp.InitEmpty()
+	dp.LabelsMap().InitFromMap(labels)
+	dp.SetSum(sum)
+	dp.SetBucketCounts(bucketCounts)
+	dp.SetCount(totalCount(bucketCounts))
+	doubleHistogramDataPoints(b.metric).Append(&dp)
+	return b
+}
+
+func totalCount(bucketCounts []uint64) uint64 {
+	var count uint64
+	for _, bucketCount := range bucketCounts {
+		count += bucketCount
+	}
+	return count
+}
+
+// startTimes sets the start times of the data points of the metric, in order.
+func (b metricBuilder) startTimes(startTimes ...pdata.TimestampUnixNano) metricBuilder {
+	for i, startTime := range startTimes {
+		switch b.metric.DataType() {
+		case pdata.MetricDataIntGauge, pdata.MetricDataIntSum:
+			intDataPoints(b.metric).At(i).SetStartTime(startTime)
+		case pdata.MetricDataDoubleGauge, pdata.MetricDataDoubleSum:
+			doubleDataPoints(b.metric).At(i).SetStartTime(startTime)
+		case pdata.MetricDataIntHistogram:
+			intHistogramDataPoints(b.metric).At(i).SetStartTime(startTime)
+		case pdata.MetricDataDoubleHistogram:
+			doubleHistogramDataPoints(b.metric).At(i).SetStartTime(startTime)
+		}
+	}
+	return b
+}
+
+func newMetrics(builders ...metricBuilder) pdata.Metrics {
+	md := data.NewMetricData()
+	md.ResourceMetrics().Resize(1)
+	md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().Resize(1)
+	metrics := md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics()
+	for _, b := range builders {
+		metrics.Append(&b.metric)
+	}
+	return pdatautil.MetricsFromInternalMetrics(md)
+}
+
+// normalizeLabels sorts the labels of every data point, as operations do not keep their order, and replaces nil labels
+// with empty ones, as aggregated labels are emptied.
+func normalizeLabels(md pdata.Metrics) {
+	metrics := pdatautil.MetricsToInternalMetrics(md).ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics()
+	for i := 0; i < metrics.Len(); i++ {
+		forEachLabels(metrics.At(i), func(labels pdata.StringMap) {
+			if labels.Len() == 0 {
+				labels.InitEmptyWithCapacity(0)
+			}
+			labels.Sort()
+		})
+	}
+}
+
+func TestMetricsTransformProcessor(t *testing.T) {
+	tests := []struct {
+		name       string
+		transforms []Transform
+		in         pdata.Metrics
+		out        pdata.Metrics
+		warnings   int
+	}{
+		{
+			name:       "rename",
+			transforms: []Transform{{Include: "old_name", Action: Update, NewName: "new_name"}},
+			in: newMetrics(
+				newMetric("old_name", pdata.MetricDataIntGauge).intPoint(nil, 1, 1),
+				newMetric("other_name", pdata.MetricDataIntGauge).intPoint(nil, 1, 2),
+			),
+			out: newMetrics(
+				newMetric("new_name", pdata.MetricDataIntGauge).intPoint(nil, 1, 1),
+				newMetric("other_name", pdata.MetricDataIntGauge).intPoint(nil, 1, 2),
+			),
+		},
+		{
+			name: "rename regexp",
+			transforms: []Transform{
+				{Include: `^system\.cpu\.(.*)$`, MatchType: filterset.Regexp, Action: Update, NewName: "host.cpu.${1}"},
+			},
+			in: newMetrics(
+				newMetric("system.cpu.time", pdata.MetricDataDoubleGauge).doublePoint(nil, 1, 1),
+				newMetric("system.memory.usage", pdata.MetricDataIntGauge).intPoint(nil, 1, 2),
+			),
+			out: newMetrics(
+				newMetric("host.cpu.time", pdata.MetricDataDoubleGauge).doublePoint(nil, 1, 1),
+				newMetric("system.memory.usage", pdata.MetricDataIntGauge).intPoint(nil, 1, 2),
+			),
+		},
+		{
+			name: "insert",
+			transforms: []Transform{
+				{Include: "requests", Action: Insert, NewName: "requests_v1", Operations: []Operation{
+					{Action: AddLabel, NewLabel: "version", NewValue: "v1"},
+				}},
+				{Include: "requests_v1", Action: Update, NewName: "requests_by_version"},
+			},
+			in: newMetrics(
+				newMetric("requests", pdata.MetricDataIntGauge).intPoint(nil, 1, 1),
+			),
+			out: newMetrics(
+				newMetric("requests", pdata.MetricDataIntGauge).intPoint(nil, 1, 1),
+				newMetric("requests_by_version", pdata.MetricDataIntGauge).
+					intPoint(map[string]string{"version": "v1"}, 1, 1),
+			),
+		},
+		{
+			name: "add label",
+			transforms: []Transform{{Include: "requests", Action: Update, Operations: []Operation{
+				{Action: AddLabel, NewLabel: "version", NewValue: "v1"},
+			}}},
+			in: newMetrics(
+				newMetric("requests", pdata.MetricDataIntGauge).
+					intPoint(map[string]string{"method": "GET"}, 1, 1).
+					intPoint(map[string]string{"version": "v2"}, 1, 2),
+			),
+			out: newMetrics(
+				newMetric("requests", pdata.MetricDataIntGauge).
+					intPoint(map[string]string{"method": "GET", "version": "v1"}, 1, 1).
+					intPoint(map[string]string{"version": "v2"}, 1, 2),
+			),
+		},
+		{
+			name: "update label",
+			transforms: []Transform{{Include: "requests", Action: Update, Operations: []Operation{
+				{Action: UpdateLabel, Label: "method", NewLabel: "http_method", ValueActions: []ValueAction{
+					{Value: "get", NewValue: "GET"},
+				}},
+			}}},
+			in: newMetrics(
+				newMetric("requests", pdata.MetricDataIntGauge).
+					intPoint(map[string]string{"method": "get"}, 1, 1).
+					intPoint(map[string]string{"method": "POST"}, 1, 2).
+					intPoint(map[string]string{"path": "/"}, 1, 3),
+			),
+			out: newMetrics(
+				newMetric("requests", pdata.MetricDataIntGauge).
+					intPoint(map[string]string{"http_method": "GET"}, 1, 1).
+					intPoint(map[string]string{"http_method": "POST"}, 1, 2).
+					intPoint(map[string]string{"path": "/"}, 1, 3),
+			),
+		},
+		{
+			name: "delete label value",
+			transforms: []Transform{{Include: "requests", Action: Update, Operations: []Operation{
+				{Action: DeleteLabelValue, Label: "path", LabelValue: "/health"},
+			}}},
+			in: newMetrics(
+				newMetric("requests", pdata.MetricDataIntGauge).
+					intPoint(map[string]string{"path": "/health"}, 1, 1).
+					intPoint(map[string]string{"path": "/"}, 1, 2),
+				newMetric("requests", pdata.MetricDataDoubleHistogram).
+					histogramPoint(map[string]string{"path": "/health"}, 1, 1),
+			),
+			out: newMetrics(
+				newMetric("requests", pdata.MetricDataIntGauge).
+					intPoint(map[string]string{"path": "/"}, 1, 2),
+				newMetric("requests", pdata.MetricDataDoubleHistogram),
+			),
+		},
+		{
+			name: "toggle scalar data type",
+			transforms: []Transform{
+				{Include: "int", Action: Update, Operations: []Operation{{Action: ToggleScalarDataType}}},
+				{Include: "double", Action: Update, Operations: []Operation{{Action: ToggleScalarDataType}}},
+			},
+			in: newMetrics(
+				newMetric("int", pdata.MetricDataIntSum).intPoint(map[string]string{"a": "b"}, 1, 3),
+				newMetric("double", pdata.MetricDataDoubleGauge).doublePoint(map[string]string{"a": "b"}, 1, 3.5),
+			),
+			out: newMetrics(
+				newMetric("int", pdata.MetricDataDoubleSum).doublePoint(map[string]string{"a": "b"}, 1, 3),
+				newMetric("double", pdata.MetricDataIntGauge).intPoint(map[string]string{"a": "b"}, 1, 3),
+			),
+		},
+		{
+			name: "aggregate labels",
+			transforms: []Transform{
+				{Include: "sum", Action: Update, Operations: []Operation{
+					{Action: AggregateLabels, LabelSet: []string{"state"}, AggregationType: Sum},
+				}},
+				{Include: "mean", Action: Update, Operations: []Operation{
+					{Action: AggregateLabels, LabelSet: []string{"state"}, AggregationType: Mean},
+				}},
+				{Include: "min", Action: Update, Operations: []Operation{
+					{Action: AggregateLabels, AggregationType: Min},
+				}},
+				{Include: "max", Action: Update, Operations: []Operation{
+					{Action: AggregateLabels, AggregationType: Max},
+				}},
+			},
+			in: newMetrics(
+				newMetric("sum", pdata.MetricDataIntGauge).
+					intPoint(map[string]string{"cpu": "0", "state": "idle"}, 1, 1).
+					intPoint(map[string]string{"cpu": "1", "state": "idle"}, 1, 2).
+					intPoint(map[string]string{"cpu": "0", "state": "user"}, 1, 3).
+					intPoint(map[string]string{"cpu": "0", "state": "idle"}, 2, 4),
+				newMetric("mean", pdata.MetricDataDoubleGauge).
+					doublePoint(map[string]string{"cpu": "0", "state": "idle"}, 1, 1).
+					doublePoint(map[string]string{"cpu": "1", "state": "idle"}, 1, 2),
+				newMetric("min", pdata.MetricDataIntGauge).
+					intPoint(map[string]string{"cpu": "0"}, 1, 2).
+					intPoint(map[string]string{"cpu": "1"}, 1, 1),
+				newMetric("max", pdata.MetricDataDoubleGauge).
+					doublePoint(map[string]string{"cpu": "0"}, 1, 2).
+					doublePoint(map[string]string{"cpu": "1"}, 1, 1),
+			),
+			out: newMetrics(
+				newMetric("sum", pdata.MetricDataIntGauge).
+					intPoint(map[string]string{"state": "idle"}, 1, 3).
+					intPoint(map[string]string{"state": "user"}, 1, 3).
+					intPoint(map[string]string{"state": "idle"}, 2, 4),
+				newMetric("mean", pdata.MetricDataDoubleGauge).
+					doublePoint(map[string]string{"state": "idle"}, 1, 1.5),
+				newMetric("min", pdata.MetricDataIntGauge).
+					intPoint(map[string]string{}, 1, 1),
+				newMetric("max", pdata.MetricDataDoubleGauge).
+					doublePoint(map[string]string{}, 1, 2),
+			),
+		},
+		{
+			name: "aggregate start times",
+			transforms: []Transform{{Include: ".*", MatchType: filterset.Regexp, Action: Update, Operations: []Operation{
+				{Action: AggregateLabels, AggregationType: Sum},
+			}}},
+			in: newMetrics(
+				newMetric("int", pdata.MetricDataIntSum).
+					intPoint(map[string]string{"cpu": "0"}, 10, 1).
+					intPoint(map[string]string{"cpu": "1"}, 10, 2).
+					intPoint(map[string]string{"cpu": "2"}, 10, 3).
+					startTimes(5, 2, 0),
+				newMetric("double", pdata.MetricDataDoubleSum).
+					doublePoint(map[string]string{"cpu": "0"}, 10, 1).
+					doublePoint(map[string]string{"cpu": "1"}, 10, 2).
+					startTimes(3, 4),
+				newMetric("histogram", pdata.MetricDataDoubleHistogram).
+					histogramPoint(map[string]string{"cpu": "0"}, 1, 1, 2).
+					histogramPoint(map[string]string{"cpu": "1"}, 2, 3, 4).
+					startTimes(7, 6),
+			),
+			out: newMetrics(
+				newMetric("int", pdata.MetricDataIntSum).
+					intPoint(map[string]string{}, 10, 6).
+					startTimes(2),
+				newMetric("double", pdata.MetricDataDoubleSum).
+					doublePoint(map[string]string{}, 10, 3).
+					startTimes(3),
+				newMetric("histogram", pdata.MetricDataDoubleHistogram).
+					histogramPoint(map[string]string{}, 3, 4, 6).
+					startTimes(6),
+			),
+		},
+		{
+			name: "aggregate int mean",
+			transforms: []Transform{{Include: "int", Action: Update, Operations: []Operation{
+				{Action: AggregateLabels, AggregationType: Mean},
+			}}},
+			in: newMetrics(
+				newMetric("int", pdata.MetricDataIntGauge).
+					intPoint(map[string]string{"cpu": "0"}, 1, 1).
+					intPoint(map[string]string{"cpu": "1"}, 1, 2),
+			),
+			out: newMetrics(
+				newMetric("int", pdata.MetricDataIntGauge).
+					intPoint(map[string]string{}, 1, 1),
+			),
+		},
+		{
+			name: "aggregate label values",
+			transforms: []Transform{{Include: "requests", Action: Update, Operations: []Operation{
+				{Action: AggregateLabelValues, Label: "status", AggregatedValues: []string{"200", "204"}, NewValue: "2xx", AggregationType: Sum},
+			}}},
+			in: newMetrics(
+				newMetric("requests", pdata.MetricDataIntGauge).
+					intPoint(map[string]string{"status": "200"}, 1, 1).
+					intPoint(map[string]string{"status": "204"}, 1, 2).
+					intPoint(map[string]string{"status": "500"}, 1, 3),
+			),
+			out: newMetrics(
+				newMetric("requests", pdata.MetricDataIntGauge).
+					intPoint(map[string]string{"status": "2xx"}, 1, 3).
+					intPoint(map[string]string{"status": "500"}, 1, 3),
+			),
+		},
+		{
+			name: "aggregate histograms",
+			transforms: []Transform{
+				{Include: "sum", Action: Update, Operations: []Operation{
+					{Action: AggregateLabels, AggregationType: Sum},
+				}},
+				{Include: "max", Action: Update, Operations: []Operation{
+					{Action: AggregateLabels, AggregationType: Max},
+				}},
+			},
+			in: newMetrics(
+				newMetric("sum", pdata.MetricDataDoubleHistogram).
+					histogramPoint(map[string]string{"cpu": "0"}, 1, 1, 2).
+					histogramPoint(map[string]string{"cpu": "1"}, 2, 3, 4).
+					histogramPoint(map[string]string{"cpu": "2"}, 3, 5, 6, 7),
+				newMetric("max", pdata.MetricDataDoubleHistogram).
+					histogramPoint(map[string]string{"cpu": "0"}, 1, 1, 2).
+					histogramPoint(map[string]string{"cpu": "1"}, 2, 3, 4),
+			),
+			out: newMetrics(
+				newMetric("sum", pdata.MetricDataDoubleHistogram).
+					histogramPoint(map[string]string{}, 3, 4, 6).
+					histogramPoint(map[string]string{}, 3, 5, 6, 7),
+				newMetric("max", pdata.MetricDataDoubleHistogram).
+					histogramPoint(map[string]string{"cpu": "0"}, 1, 1, 2).
+					histogramPoint(map[string]string{"cpu": "1"}, 2, 3, 4),
+			),
+			warnings: 1,
+		},
+		{
+			name: "aggregate int histograms",
+			transforms: []Transform{{Include: "sum", Action: Update, Operations: []Operation{
+				{Action: AggregateLabelValues, Label: "cpu", AggregatedValues: []string{"0", "1"}, NewValue: "0-1", AggregationType: Sum},
+			}}},
+			in: newMetrics(
+				newMetric("sum", pdata.MetricDataIntHistogram).
+					intHistogramPoint(map[string]string{"cpu": "0"}, 1, 1, 2).
+					intHistogramPoint(map[string]string{"cpu": "1"}, 2, 3, 4).
+					intHistogramPoint(map[string]string{"cpu": "2"}, 3, 5, 6),
+			),
+			out: newMetrics(
+				newMetric("sum", pdata.MetricDataIntHistogram).
+					intHistogramPoint(map[string]string{"cpu": "0-1"}, 3, 4, 6).
+					intHistogramPoint(map[string]string{"cpu": "2"}, 3, 5, 6),
+			),
+		},
+		{
+			name: "insert aggregated histograms",
+			transforms: []Transform{{Include: "latency", Action: Insert, NewName: "latency_sum", Operations: []Operation{
+				{Action: AggregateLabels, AggregationType: Sum},
+			}}},
+			in: newMetrics(
+				newMetric("latency", pdata.MetricDataDoubleHistogram).
+					histogramPoint(map[string]string{"cpu": "0"}, 1, 1, 2).
+					histogramPoint(map[string]string{"cpu": "1"}, 2, 3, 4),
+			),
+			out: newMetrics(
+				newMetric("latency", pdata.MetricDataDoubleHistogram).
+					histogramPoint(map[string]string{"cpu": "0"}, 1, 1, 2).
+					histogramPoint(map[string]string{"cpu": "1"}, 2, 3, 4),
+				newMetric("latency_sum", pdata.MetricDataDoubleHistogram).
+					histogramPoint(map[string]string{}, 3, 4, 6),
+			),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig().(*Config)
+			cfg.Transforms = test.transforms
+			core, logs := observer.New(zap.WarnLevel)
+			next := &exportertest.SinkMetricsExporter{}
+			mp, err := factory.CreateMetricsProcessor(
+				context.Background(), component.ProcessorCreateParams{Logger: zap.New(core)}, next, cfg)
+			require.NoError(t, err)
+
+			assert.NoError(t, mp.ConsumeMetrics(context.Background(), test.in))
+			require.Len(t, next.AllMetrics(), 1)
+			normalizeLabels(next.AllMetrics()[0])
+			normalizeLabels(test.out)
+			assert.Equal(t, test.out, next.AllMetrics()[0])
+			assert.Equal(t, test.warnings, logs.Len())
+		})
+	}
+}
+
+func TestMetricsTransformProcessorOldModel(t *testing.T) {
+	md := dataold.NewMetricData()
+	md.ResourceMetrics().Resize(1)
+	md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().Resize(1)
+	metrics := md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics()
+	metrics.Resize(2)
+	metrics.At(0).MetricDescriptor().InitEmpty()
+	metrics.At(0).MetricDescriptor().SetName("old_name")
+	metrics.At(0).MetricDescriptor().SetType(dataold.MetricTypeInt64)
+	metrics.At(0).Int64DataPoints().Resize(1)
+	metrics.At(0).Int64DataPoints().At(0).SetTimestamp(1)
+	metrics.At(0).Int64DataPoints().At(0).SetValue(2)
+	metrics.At(1).MetricDescriptor().InitEmpty()
+	metrics.At(1).MetricDescriptor().SetName("latency")
+	metrics.At(1).MetricDescriptor().SetType(dataold.MetricTypeSummary)
+	metrics.At(1).SummaryDataPoints().Resize(1)
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Transforms = []Transform{{Include: "old_name", Action: Update, NewName: "new_name"}}
+	next := &exportertest.SinkMetricsExporter{}
+	mp, err := factory.CreateMetricsProcessor(
+		context.Background(), component.ProcessorCreateParams{Logger: zap.NewNop()}, next, cfg)
+	require.NoError(t, err)
+
+	// the summaries of the old model are dropped, as the new model cannot represent them
+	assert.NoError(t, mp.ConsumeMetrics(context.Background(), pdatautil.MetricsFromOldInternalMetrics(md)))
+	require.Len(t, next.AllMetrics(), 1)
+	expected := newMetrics(newMetric("new_name", pdata.MetricDataIntGauge).intPoint(nil, 1, 2))
+	normalizeLabels(next.AllMetrics()[0])
+	normalizeLabels(expected)
+	assert.Equal(t, expected, next.AllMetrics()[0])
+}
+
+func TestMetricsTransformProcessorNilData(t *testing.T) {
+	md := data.MetricDataFromOtlp([]*otlpmetrics.ResourceMetrics{
+		nil,
+		{InstrumentationLibraryMetrics: []*otlpmetrics.InstrumentationLibraryMetrics{
+			nil,
+			{Metrics: []*otlpmetrics.Metric{
+				nil,
+				{Name: "no_data"},
+				{Name: "nil_sum", Data: &otlpmetrics.Metric_IntSum{}},
+				{Name: "nil_histogram", Data: &otlpmetrics.Metric_DoubleHistogram{}},
+			}},
+		}},
+	})
+	expected := md.Clone()
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Transforms = []Transform{
+		{Include: ".*", MatchType: filterset.Regexp, Action: Update, Operations: []Operation{
+			{Action: AddLabel, NewLabel: "label", NewValue: "value"},
+			{Action: DeleteLabelValue, Label: "label", LabelValue: "value"},
+			{Action: AggregateLabels, AggregationType: Sum},
+		}},
+	}
+	mp, err := factory.CreateMetricsProcessor(
+		context.Background(), component.ProcessorCreateParams{Logger: zap.NewNop()}, exportertest.NewNopMetricsExporter(), cfg)
+	require.NoError(t, err)
+
+	assert.NoError(t, mp.ConsumeMetrics(context.Background(), pdatautil.MetricsFromInternalMetrics(md)))
+	assert.Equal(t, expected, md)
+}
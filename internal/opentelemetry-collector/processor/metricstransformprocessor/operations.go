@@ -0,0 +1,310 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricstransformprocessor
+
+import (
+	"go.opentelemetry.io/collector/consumer/pdata"
+)
+
+// apply applies the operation to the data points of metric. It returns an error if the operation cannot be applied to
+// metric, which is then left unchanged.
+func (op *internalOperation) apply(metric pdata.Metric) error {
+	switch op.Action {
+	case AddLabel:
+		forEachLabels(metric, func(labels pdata.StringMap) {
+			labels.Insert(op.NewLabel, op.NewValue)
+		})
+	case UpdateLabel:
+		forEachLabels(metric, op.updateLabel)
+	case DeleteLabelValue:
+		deleteDataPoints(metric, func(labels pdata.StringMap) bool {
+			value, ok := labels.Get(op.Label)
+			return ok && value.Value() == op.LabelValue
+		})
+	case ToggleScalarDataType:
+		toggleScalarDataType(metric)
+	case AggregateLabels:
+		if err := checkAggregation(metric, op.AggregationType); err != nil {
+			return err
+		}
+		forEachLabels(metric, op.keepLabelSet)
+		aggregateDataPoints(metric, op.AggregationType)
+	case AggregateLabelValues:
+		if err := checkAggregation(metric, op.AggregationType); err != nil {
+			return err
+		}
+		forEachLabels(metric, op.combineLabelValues)
+		aggregateDataPoints(metric, op.AggregationType)
+	}
+	return nil
+}
+
+// updateLabel renames the label and its values.
+func (op *internalOperation) updateLabel(labels pdata.StringMap) {
+	value, ok := labels.Get(op.Label)
+	if !ok {
+		return
+	}
+	newValue := value.Value()
+	if v, ok := op.valueActions[newValue]; ok {
+		newValue = v
+	}
+	if op.NewLabel == "" || op.NewLabel == op.Label {
+		value.SetValue(newValue)
+		return
+	}
+	labels.Delete(op.Label)
+	labels.Upsert(op.NewLabel, newValue)
+}
+
+// keepLabelSet deletes the labels that are not in the label set.
+func (op *internalOperation) keepLabelSet(labels pdata.StringMap) {
+	var deleted []string
+	labels.ForEach(func(k string, _ pdata.StringValue) {
+		if !op.labelSet[k] {
+			deleted = append(deleted, k)
+		}
+	})
+	for _, k := range deleted {
+		labels.Delete(k)
+	}
+}
+
+// combineLabelValues replaces the aggregated values of the label with the new value.
+func (op *internalOperation) combineLabelValues(labels pdata.StringMap) {
+	if value, ok := labels.Get(op.Label); ok && op.aggregatedValues[value.Value()] {
+		value.SetValue(op.NewValue)
+	}
+}
+
+// copyMetric copies metric to dest, including its data, which pdata.Metric.CopyTo does not copy.
+func copyMetric(metric pdata.Metric, dest pdata.Metric) {
+	metric.CopyTo(dest)
+	switch metric.DataType() {
+	case pdata.MetricDataIntGauge:
+		data := pdata.NewIntGauge()
+		metric.IntGaugeData().CopyTo(data)
+		dest.SetIntGaugeData(data)
+	case pdata.MetricDataDoubleGauge:
+		data := pdata.NewDoubleGauge()
+		metric.DoubleGaugeData().CopyTo(data)
+		dest.SetDoubleGaugeData(data)
+	case pdata.MetricDataIntSum:
+		data := pdata.NewIntSum()
+		metric.IntSumData().CopyTo(data)
+		dest.SetIntSumData(data)
+	case pdata.MetricDataDoubleSum:
+		data := pdata.NewDoubleSum()
+		metric.DoubleSumData().CopyTo(data)
+		dest.SetDoubleSumData(data)
+	case pdata.MetricDataIntHistogram:
+		data := pdata.NewIntHistogram()
+		metric.IntHistogramData().CopyTo(data)
+		dest.SetIntHistogramData(data)
+	case pdata.MetricDataDoubleHistogram:
+		data := pdata.NewDoubleHistogram()
+		metric.DoubleHistogramData().CopyTo(data)
+		dest.SetDoubleHistogramData(data)
+	}
+}
+
+// intDataPoints returns the data points of metric if it is an int gauge or sum, and an empty slice otherwise.
+func intDataPoints(metric pdata.Metric) pdata.IntDataPointSlice {
+	switch metric.DataType() {
+	case pdata.MetricDataIntGauge:
+		if data := metric.IntGaugeData(); !data.IsNil() {
+			return data.DataPoints()
+		}
+	case pdata.MetricDataIntSum:
+		if data := metric.IntSumData(); !data.IsNil() {
+			return data.DataPoints()
+		}
+	}
+	return pdata.NewIntDataPointSlice()
+}
+
+// doubleDataPoints returns the data points of metric if it is a double gauge or sum, and an empty slice otherwise.
+func doubleDataPoints(metric pdata.Metric) pdata.DoubleDataPointSlice {
+	switch metric.DataType() {
+	case pdata.MetricDataDoubleGauge:
+		if data := metric.DoubleGaugeData(); !data.IsNil() {
+			return data.DataPoints()
+		}
+	case pdata.MetricDataDoubleSum:
+		if data := metric.DoubleSumData(); !data.IsNil() {
+			return data.DataPoints()
+		}
+	}
+	return pdata.NewDoubleDataPointSlice()
+}
+
+// intHistogramDataPoints returns the data points of metric if it is an int histogram, and an empty slice otherwise.
+func intHistogramDataPoints(metric pdata.Metric) pdata.IntHistogramDataPointSlice {
+	if metric.DataType() == pdata.MetricDataIntHistogram {
+		if data := metric.IntHistogramData(); !data.IsNil() {
+			return data.DataPoints()
+		}
+	}
+	return pdata.NewIntHistogramDataPointSlice()
+}
+
+// doubleHistogramDataPoints returns the data points of metric if it is a double histogram, and an empty slice
+// otherwise.
+func doubleHistogramDataPoints(metric pdata.Metric) pdata.DoubleHistogramDataPointSlice {
+	if metric.DataType() == pdata.MetricDataDoubleHistogram {
+		if data := metric.DoubleHistogramData(); !data.IsNil() {
+			return data.DataPoints()
+		}
+	}
+	return pdata.NewDoubleHistogramDataPointSlice()
+}
+
+// forEachLabels calls f with the labels of every data point of metric.
+func forEachLabels(metric pdata.Metric, f func(labels pdata.StringMap)) {
+	intDataPoints := intDataPoints(metric)
+	for i := 0; i < intDataPoints.Len(); i++ {
+		if dp := intDataPoints.At(i); !dp.IsNil() {
+			f(dp.LabelsMap())
+		}
+	}
+	doubleDataPoints := doubleDataPoints(metric)
+	for i := 0; i < doubleDataPoints.Len(); i++ {
+		if dp := doubleDataPoints.At(i); !dp.IsNil() {
+			f(dp.LabelsMap())
+		}
+	}
+	intHistogramDataPoints := intHistogramDataPoints(metric)
+	for i := 0; i < intHistogramDataPoints.Len(); i++ {
+		if dp := intHistogramDataPoints.At(i); !dp.IsNil() {
+			f(dp.LabelsMap())
+		}
+	}
+	doubleHistogramDataPoints := doubleHistogramDataPoints(metric)
+	for i := 0; i < doubleHistogramDataPoints.Len(); i++ {
+		if dp := doubleHistogramDataPoints.At(i); !dp.IsNil() {
+			f(dp.LabelsMap())
+		}
+	}
+}
+
+// deleteDataPoints removes the data points of metric whose labels match.
+func deleteDataPoints(metric pdata.Metric, match func(labels pdata.StringMap) bool) {
+	intDataPoints := intDataPoints(metric)
+	keptIntDataPoints := pdata.NewIntDataPointSlice()
+	for i := 0; i < intDataPoints.Len(); i++ {
+		if dp := intDataPoints.At(i); !dp.IsNil() && !match(dp.LabelsMap()) {
+			keptIntDataPoints.Append(&dp)
+		}
+	}
+	intDataPoints.Resize(0)
+	keptIntDataPoints.MoveAndAppendTo(intDataPoints)
+
+	doubleDataPoints := doubleDataPoints(metric)
+	keptDoubleDataPoints := pdata.NewDoubleDataPointSlice()
+	for i := 0; i < doubleDataPoints.Len(); i++ {
+		if dp := doubleDataPoints.At(i); !dp.IsNil() && !match(dp.LabelsMap()) {
+			keptDoubleDataPoints.Append(&dp)
+		}
+	}
+	doubleDataPoints.Resize(0)
+	keptDoubleDataPoints.MoveAndAppendTo(doubleDataPoints)
+
+	intHistogramDataPoints := intHistogramDataPoints(metric)
+	keptIntHistogramDataPoints := pdata.NewIntHistogramDataPointSlice()
+	for i := 0; i < intHistogramDataPoints.Len(); i++ {
+		if dp := intHistogramDataPoints.At(i); !dp.IsNil() && !match(dp.LabelsMap()) {
+			keptIntHistogramDataPoints.Append(&dp)
+		}
+	}
+	intHistogramDataPoints.Resize(0)
+	keptIntHistogramDataPoints.MoveAndAppendTo(intHistogramDataPoints)
+
+	doubleHistogramDataPoints := doubleHistogramDataPoints(metric)
+	keptDoubleHistogramDataPoints := pdata.NewDoubleHistogramDataPointSlice()
+	for i := 0; i < doubleHistogramDataPoints.Len(); i++ {
+		if dp := doubleHistogramDataPoints.At(i); !dp.IsNil() && !match(dp.LabelsMap()) {
+			keptDoubleHistogramDataPoints.Append(&dp)
+		}
+	}
+	doubleHistogramDataPoints.Resize(0)
+	keptDoubleHistogramDataPoints.MoveAndAppendTo(doubleHistogramDataPoints)
+}
+
+// toggleScalarDataType converts the int gauges and sums to double gauges and sums, and the double gauges and sums to
+// int gauges and sums. Double values are truncated, and exemplars are dropped.
+func toggleScalarDataType(metric pdata.Metric) {
+	switch metric.DataType() {
+	case pdata.MetricDataIntGauge:
+		gauge := pdata.NewDoubleGauge()
+		gauge.InitEmpty()
+		intToDoubleDataPoints(intDataPoints(metric), gauge.DataPoints())
+		metric.SetDoubleGaugeData(gauge)
+	case pdata.MetricDataIntSum:
+		sum := pdata.NewDoubleSum()
+		sum.InitEmpty()
+		if data := metric.IntSumData(); !data.IsNil() {
+			sum.SetAggregationTemporality(data.AggregationTemporality())
+			sum.SetIsMonotonic(data.IsMonotonic())
+			intToDoubleDataPoints(data.DataPoints(), sum.DataPoints())
+		}
+		metric.SetDoubleSumData(sum)
+	case pdata.MetricDataDoubleGauge:
+		gauge := pdata.NewIntGauge()
+		gauge.InitEmpty()
+		doubleToIntDataPoints(doubleDataPoints(metric), gauge.DataPoints())
+		metric.SetIntGaugeData(gauge)
+	case pdata.MetricDataDoubleSum:
+		sum := pdata.NewIntSum()
+		sum.InitEmpty()
+		if data := metric.DoubleSumData(); !data.IsNil() {
+			sum.SetAggregationTemporality(data.AggregationTemporality())
+			sum.SetIsMonotonic(data.IsMonotonic())
+			doubleToIntDataPoints(data.DataPoints(), sum.DataPoints())
+		}
+		metric.SetIntSumData(sum)
+	}
+}
+
+func intToDoubleDataPoints(dps pdata.IntDataPointSlice, dest pdata.DoubleDataPointSlice) {
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		if dp.IsNil() {
+			continue
+		}
+		converted := pdata.NewDoubleDataPoint()
+		converted.InitEmpty()
+		dp.LabelsMap().CopyTo(converted.LabelsMap())
+		converted.SetStartTime(dp.StartTime())
+		converted.SetTimestamp(dp.Timestamp())
+		converted.SetValue(float64(dp.Value()))
+		dest.Append(&converted)
+	}
+}
+
+func doubleToIntDataPoints(dps pdata.DoubleDataPointSlice, dest pdata.IntDataPointSlice) {
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		if dp.IsNil() {
+			continue
+		}
+		converted := pdata.NewIntDataPoint()
+		converted.InitEmpty()
+		dp.LabelsMap().CopyTo(converted.LabelsMap())
+		converted.SetStartTime(dp.StartTime())
+		converted.SetTimestamp(dp.Timestamp())
+		converted.SetValue(int64(dp.Value()))
+		dest.Append(&converted)
+	}
+}
@@ -21,6 +21,7 @@ import (
 
 	"go.opentelemetry.io/collector/consumer/pdata"
 	"go.opentelemetry.io/collector/internal/processor/filterhelper"
+	tracetranslator "go.opentelemetry.io/collector/translator/trace"
 )
 
 // Settings
@@ -126,6 +127,8 @@ type attributeAction struct {
 	// and could impact performance.
 	Action         Action
 	AttributeValue *pdata.AttributeValue
+	// String representation of AttributeValue, used as the value of labels.
+	LabelValue string
 }
 
 type AttrProc struct {
@@ -170,6 +173,7 @@ func NewAttrProc(settings *Settings) (*AttrProc, error) {
 					return nil, err
 				}
 				action.AttributeValue = &val
+				action.LabelValue = tracetranslator.AttributeValueToString(val, false)
 			} else {
 				action.FromAttribute = a.FromAttribute
 			}
@@ -281,3 +285,77 @@ func extractAttributes(action attributeAction, attrs pdata.AttributeMap) {
 		attrs.UpsertString(action.AttrNames[i], matches[i])
 	}
 }
+
+// ProcessLabels applies the actions to labels, such as the labels of metric data points. Labels only hold string
+// values, so configured values are converted to their string representation.
+func (ap *AttrProc) ProcessLabels(labels pdata.StringMap) {
+	for _, action := range ap.actions {
+		switch action.Action {
+		case DELETE:
+			labels.Delete(action.Key)
+		case INSERT:
+			value, found := getSourceLabelValue(action, labels)
+			if !found {
+				continue
+			}
+			labels.Insert(action.Key, value)
+		case UPDATE:
+			value, found := getSourceLabelValue(action, labels)
+			if !found {
+				continue
+			}
+			labels.Update(action.Key, value)
+		case UPSERT:
+			value, found := getSourceLabelValue(action, labels)
+			if !found {
+				continue
+			}
+			labels.Upsert(action.Key, value)
+		case HASH:
+			hashLabel(action, labels)
+		case EXTRACT:
+			extractLabels(action, labels)
+		}
+	}
+}
+
+func getSourceLabelValue(action attributeAction, labels pdata.StringMap) (string, bool) {
+	// Set the key with a value from the configuration.
+	if action.AttributeValue != nil {
+		return action.LabelValue, true
+	}
+
+	value, found := labels.Get(action.FromAttribute)
+	if !found {
+		return "", false
+	}
+	return value.Value(), true
+}
+
+func hashLabel(action attributeAction, labels pdata.StringMap) {
+	if value, exists := labels.Get(action.Key); exists {
+		hashed := pdata.NewAttributeValueString(value.Value())
+		sha1Hasher(hashed)
+		value.SetValue(hashed.StringVal())
+	}
+}
+
+func extractLabels(action attributeAction, labels pdata.StringMap) {
+	value, found := labels.Get(action.Key)
+	if !found {
+		return
+	}
+
+	// Note: The number of matches will always be equal to number of
+	// subexpressions.
+	matches := action.Regex.FindStringSubmatch(value.Value())
+	if matches == nil {
+		return
+	}
+
+	// Start from index 1, which is the first submatch (index 0 is the entire
+	// match).
+	for i := 1; i < len(matches); i++ {
+		labels.Upsert(action.AttrNames[i], matches[i])
+	}
+}
@@ -865,6 +865,7 @@ func TestValidConfiguration(t *testing.T) {
 		{Key: "one", Action: DELETE},
 		{Key: "two", Action: INSERT,
 			AttributeValue: &av,
+			LabelValue:     "123",
 		},
 		{Key: "three", FromAttribute: "two", Action: UPDATE},
 		{Key: "five", FromAttribute: "two", Action: UPSERT},
@@ -873,6 +874,111 @@ func TestValidConfiguration(t *testing.T) {
 
 }
 
+func TestLabels(t *testing.T) {
+	testCases := []struct {
+		name           string
+		action         ActionKeyValue
+		inputLabels    map[string]string
+		expectedLabels map[string]string
+	}{
+		{
+			name:           "InsertValue",
+			action:         ActionKeyValue{Key: "label1", Action: INSERT, Value: 123},
+			inputLabels:    map[string]string{"anotherkey": "bob"},
+			expectedLabels: map[string]string{"anotherkey": "bob", "label1": "123"},
+		},
+		{
+			name:           "InsertKeyExists",
+			action:         ActionKeyValue{Key: "label1", Action: INSERT, Value: 123},
+			inputLabels:    map[string]string{"label1": "bob"},
+			expectedLabels: map[string]string{"label1": "bob"},
+		},
+		{
+			name:           "InsertFromLabel",
+			action:         ActionKeyValue{Key: "label1", Action: INSERT, FromAttribute: "anotherkey"},
+			inputLabels:    map[string]string{"anotherkey": "bob"},
+			expectedLabels: map[string]string{"anotherkey": "bob", "label1": "bob"},
+		},
+		{
+			name:           "InsertFromLabelNoExists",
+			action:         ActionKeyValue{Key: "label1", Action: INSERT, FromAttribute: "anotherkey"},
+			inputLabels:    map[string]string{},
+			expectedLabels: map[string]string{},
+		},
+		{
+			name:           "UpdateValue",
+			action:         ActionKeyValue{Key: "label1", Action: UPDATE, Value: true},
+			inputLabels:    map[string]string{"label1": "bob"},
+			expectedLabels: map[string]string{"label1": "true"},
+		},
+		{
+			name:           "UpdateKeyNoExists",
+			action:         ActionKeyValue{Key: "label1", Action: UPDATE, Value: true},
+			inputLabels:    map[string]string{"anotherkey": "bob"},
+			expectedLabels: map[string]string{"anotherkey": "bob"},
+		},
+		{
+			name:           "UpsertValue",
+			action:         ActionKeyValue{Key: "label1", Action: UPSERT, Value: "fry"},
+			inputLabels:    map[string]string{"label1": "bob"},
+			expectedLabels: map[string]string{"label1": "fry"},
+		},
+		{
+			name:           "UpsertFromLabel",
+			action:         ActionKeyValue{Key: "label1", Action: UPSERT, FromAttribute: "anotherkey"},
+			inputLabels:    map[string]string{"anotherkey": "bob"},
+			expectedLabels: map[string]string{"anotherkey": "bob", "label1": "bob"},
+		},
+		{
+			name:           "Delete",
+			action:         ActionKeyValue{Key: "user_id", Action: DELETE},
+			inputLabels:    map[string]string{"user_id": "1234", "anotherkey": "bob"},
+			expectedLabels: map[string]string{"anotherkey": "bob"},
+		},
+		{
+			name:           "Hash",
+			action:         ActionKeyValue{Key: "user_id", Action: HASH},
+			inputLabels:    map[string]string{"user_id": "1234"},
+			expectedLabels: map[string]string{"user_id": sha1Hash([]byte("1234"))},
+		},
+		{
+			name:           "HashKeyNoExists",
+			action:         ActionKeyValue{Key: "user_id", Action: HASH},
+			inputLabels:    map[string]string{"anotherkey": "bob"},
+			expectedLabels: map[string]string{"anotherkey": "bob"},
+		},
+		{
+			name:   "Extract",
+			action: ActionKeyValue{Key: "path", Action: EXTRACT, RegexPattern: "^/api/v1/user/(?P<user_id>.*)$"},
+			inputLabels: map[string]string{
+				"path":    "/api/v1/user/1234",
+				"user_id": "unknown",
+			},
+			expectedLabels: map[string]string{
+				"path":    "/api/v1/user/1234",
+				"user_id": "1234",
+			},
+		},
+		{
+			name:           "ExtractNoMatch",
+			action:         ActionKeyValue{Key: "path", Action: EXTRACT, RegexPattern: "^/api/v1/user/(?P<user_id>.*)$"},
+			inputLabels:    map[string]string{"path": "/health"},
+			expectedLabels: map[string]string{"path": "/health"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ap, err := NewAttrProc(&Settings{Actions: []ActionKeyValue{tt.action}})
+			require.NoError(t, err)
+
+			labels := pdata.NewStringMap().InitFromMap(tt.inputLabels)
+			ap.ProcessLabels(labels)
+			assert.Equal(t, pdata.NewStringMap().InitFromMap(tt.expectedLabels).Sort(), labels.Sort())
+		})
+	}
+}
+
 func sha1Hash(b []byte) string {
 	h := sha1.New()
 	h.Write(b)
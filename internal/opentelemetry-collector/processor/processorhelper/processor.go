@@ -26,7 +26,7 @@ import (
 	"go.opentelemetry.io/collector/obsreport"
 )
 
-// ErrSkipProcessingData is a sentinel value to indicate when traces or metrics should intentionally be dropped
+// ErrSkipProcessingData is a sentinel value to indicate when metrics or logs should intentionally be dropped
 // from further processing in the pipeline because the data is determined to be irrelevant. A processor can return this error
 // to stop further processing without propagating an error back up the pipeline to logs.
 var ErrSkipProcessingData = errors.New("sentinel error to skip processing data from the remainder of the pipeline")
@@ -219,6 +219,9 @@ func (lp *logProcessor) ConsumeLogs(ctx context.Context, ld pdata.Logs) error {
 	var err error
 	ld, err = lp.processor.ProcessLogs(processorCtx, ld)
 	if err != nil {
+		if err == ErrSkipProcessingData {
+			return nil
+		}
 		return err
 	}
 	return lp.nextConsumer.ConsumeLogs(ctx, ld)
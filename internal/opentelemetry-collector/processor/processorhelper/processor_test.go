@@ -160,6 +160,12 @@ func TestNewLogsExporter_ProcessLogError(t *testing.T) {
 	assert.Equal(t, want, me.ConsumeLogs(context.Background(), testdata.GenerateLogDataEmpty()))
 }
 
+func TestNewLogsExporter_ProcessLogsErrSkipProcessingData(t *testing.T) {
+	me, err := NewLogsProcessor(testCfg, exportertest.NewNopLogsExporter(), newTestLProcessor(ErrSkipProcessingData))
+	require.NoError(t, err)
+	assert.Equal(t, nil, me.ConsumeLogs(context.Background(), testdata.GenerateLogDataEmpty()))
+}
+
 type testTProcessor struct {
 	retError error
 }
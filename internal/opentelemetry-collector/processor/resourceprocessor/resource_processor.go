@@ -19,6 +19,7 @@ import (
 
 	"go.opentelemetry.io/collector/consumer/pdata"
 	"go.opentelemetry.io/collector/consumer/pdatautil"
+	"go.opentelemetry.io/collector/obsreport"
 	"go.opentelemetry.io/collector/processor/processorhelper"
 )
 
@@ -56,3 +57,21 @@ func (rp *resourceProcessor) ProcessMetrics(_ context.Context, md pdata.Metrics)
 	}
 	return pdatautil.MetricsFromOldInternalMetrics(imd), nil
 }
+
+// ProcessLogs implements the LProcessor interface
+func (rp *resourceProcessor) ProcessLogs(ctx context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		if rl.IsNil() {
+			continue
+		}
+		resource := rl.Resource()
+		if resource.IsNil() {
+			resource.InitEmpty()
+		}
+		rp.attrProc.Process(resource.Attributes())
+	}
+	obsreport.ProcessorLogRecordsAccepted(ctx, ld.LogRecordCount())
+	return ld, nil
+}
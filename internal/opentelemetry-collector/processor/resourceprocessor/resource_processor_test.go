@@ -128,6 +128,18 @@ func TestResourceProcessorAttributesUpsert(t *testing.T) {
 			err = rmp.ConsumeMetrics(context.Background(), sourceMetricData)
 			require.NoError(t, err)
 			assert.EqualValues(t, wantMetricData, tmn.md)
+
+			// Test logs consumer
+			tln := &testLogsConsumer{}
+			rlp, err := factory.CreateLogsProcessor(context.Background(), component.ProcessorCreateParams{}, tt.config, tln)
+			require.NoError(t, err)
+			assert.Equal(t, true, rlp.GetCapabilities().MutatesConsumedData)
+
+			sourceLogData := generateLogData(tt.sourceAttributes)
+			wantLogData := generateLogData(tt.wantAttributes)
+			err = rlp.ConsumeLogs(context.Background(), sourceLogData)
+			require.NoError(t, err)
+			assert.EqualValues(t, wantLogData, tln.ld)
 		})
 	}
 }
@@ -160,6 +172,20 @@ func generateMetricData(attributes map[string]string) pdata.Metrics {
 	return pdatautil.MetricsFromOldInternalMetrics(md)
 }
 
+func generateLogData(attributes map[string]string) pdata.Logs {
+	ld := testdata.GenerateLogDataOneLogNoResource()
+	if attributes == nil {
+		return ld
+	}
+	resource := ld.ResourceLogs().At(0).Resource()
+	resource.InitEmpty()
+	for k, v := range attributes {
+		resource.Attributes().InsertString(k, v)
+	}
+	resource.Attributes().Sort()
+	return ld
+}
+
 type testTraceConsumer struct {
 	td pdata.Traces
 }
@@ -187,6 +213,19 @@ func (tmn *testMetricsConsumer) ConsumeMetrics(_ context.Context, md pdata.Metri
 	return nil
 }
 
+type testLogsConsumer struct {
+	ld pdata.Logs
+}
+
+func (tln *testLogsConsumer) ConsumeLogs(_ context.Context, ld pdata.Logs) error {
+	// sort attributes to be able to compare logs
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		sortResourceAttributes(ld.ResourceLogs().At(i).Resource())
+	}
+	tln.ld = ld
+	return nil
+}
+
 func sortResourceAttributes(resource pdata.Resource) {
 	if resource.IsNil() {
 		return
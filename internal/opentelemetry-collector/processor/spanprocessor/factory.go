@@ -56,7 +56,7 @@ func createDefaultConfig() configmodels.Processor {
 
 func createTraceProcessor(
 	_ context.Context,
-	_ component.ProcessorCreateParams,
+	params component.ProcessorCreateParams,
 	cfg configmodels.Processor,
 	nextConsumer consumer.TraceConsumer,
 ) (component.TraceProcessor, error) {
@@ -69,7 +69,7 @@ func createTraceProcessor(
 		return nil, errMissingRequiredField
 	}
 
-	sp, err := newSpanProcessor(*oCfg)
+	sp, err := newSpanProcessor(*oCfg, params.Logger)
 	if err != nil {
 		return nil, err
 	}
@@ -21,7 +21,10 @@ import (
 	"strconv"
 	"strings"
 
+	"go.uber.org/zap"
+
 	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/internal/processor/filterexpr"
 	"go.opentelemetry.io/collector/internal/processor/filterspan"
 	"go.opentelemetry.io/collector/processor"
 )
@@ -31,6 +34,7 @@ type spanProcessor struct {
 	toAttributeRules []toAttributeRule
 	include          filterspan.Matcher
 	exclude          filterspan.Matcher
+	logger           *zap.Logger
 }
 
 // toAttributeRule is the compiled equivalent of config.ToAttributes field.
@@ -43,7 +47,7 @@ type toAttributeRule struct {
 }
 
 // newSpanProcessor returns the span processor.
-func newSpanProcessor(config Config) (*spanProcessor, error) {
+func newSpanProcessor(config Config, logger *zap.Logger) (*spanProcessor, error) {
 	include, err := filterspan.NewMatcher(config.Include)
 	if err != nil {
 		return nil, err
@@ -57,6 +61,7 @@ func newSpanProcessor(config Config) (*spanProcessor, error) {
 		config:  config,
 		include: include,
 		exclude: exclude,
+		logger:  logger,
 	}
 
 	// Compile ToAttributes regexp and extract attributes names.
@@ -80,7 +85,7 @@ func newSpanProcessor(config Config) (*spanProcessor, error) {
 	return sp, nil
 }
 
-func (sp *spanProcessor) ProcessTraces(_ context.Context, td pdata.Traces) (pdata.Traces, error) {
+func (sp *spanProcessor) ProcessTraces(ctx context.Context, td pdata.Traces) (pdata.Traces, error) {
 	rss := td.ResourceSpans()
 	for i := 0; i < rss.Len(); i++ {
 		rs := rss.At(i)
@@ -101,7 +106,7 @@ func (sp *spanProcessor) ProcessTraces(_ context.Context, td pdata.Traces) (pdat
 					continue
 				}
 
-				if sp.skipSpan(s, serviceName) {
+				if sp.skipSpan(ctx, s, serviceName) {
 					continue
 				}
 				sp.processFromAttributes(s)
@@ -236,20 +241,39 @@ func (sp *spanProcessor) processToAttributes(span pdata.Span) {
 // The logic determining if a span should be processed is set
 // in the attribute configuration with the include and exclude settings.
 // Include properties are checked before exclude settings are checked.
-func (sp *spanProcessor) skipSpan(span pdata.Span, serviceName string) bool {
+// Spans that fail to be matched, such as when an expression fails to be
+// evaluated, are skipped and the failure is logged and recorded.
+func (sp *spanProcessor) skipSpan(ctx context.Context, span pdata.Span, serviceName string) bool {
 	if sp.include != nil {
+		include, err := sp.include.MatchSpan(span, serviceName)
+		if err != nil {
+			sp.matchFailed(ctx, span, err)
+			return true
+		}
 		// A false returned in this case means the span should not be processed.
-		if include := sp.include.MatchSpan(span, serviceName); !include {
+		if !include {
 			return true
 		}
 	}
 
 	if sp.exclude != nil {
+		exclude, err := sp.exclude.MatchSpan(span, serviceName)
+		if err != nil {
+			sp.matchFailed(ctx, span, err)
+			return true
+		}
 		// A true returned in this case means the span should not be processed.
-		if exclude := sp.exclude.MatchSpan(span, serviceName); exclude {
+		if exclude {
 			return true
 		}
 	}
 
 	return false
 }
+
+// matchFailed logs and records that span failed to be matched by the include or exclude settings.
+func (sp *spanProcessor) matchFailed(ctx context.Context, span pdata.Span, err error) {
+	sp.logger.Warn("failed to match span, it is left unprocessed",
+		zap.String("span", span.Name()), zap.Error(err))
+	filterexpr.RecordEvaluationError(ctx)
+}
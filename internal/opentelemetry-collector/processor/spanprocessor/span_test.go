@@ -21,6 +21,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenterror"
@@ -629,3 +630,30 @@ func TestSpanProcessor_skipSpan(t *testing.T) {
 		runIndividualTestCase(t, tc, tp)
 	}
 }
+
+// TestSpanProcessor_skipSpanEvaluationError checks that spans whose expressions fail to be evaluated are left
+// unprocessed, and that the failures are logged.
+func TestSpanProcessor_skipSpanEvaluationError(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	oCfg := cfg.(*Config)
+	// the name of the span is not a valid regular expression
+	oCfg.Include = &filterspan.MatchProperties{
+		Config:      *createMatchConfig(filterset.Expr),
+		Expressions: []string{`Service matches SpanName`},
+	}
+	oCfg.Rename.ToAttributes = &ToAttributes{
+		Rules: []string{`(?P<operation_website>.*?)$`},
+	}
+	core, logs := observer.New(zap.WarnLevel)
+	tp, err := factory.CreateTraceProcessor(
+		context.Background(), component.ProcessorCreateParams{Logger: zap.New(core)}, exportertest.NewNopTraceExporter(), oCfg)
+	require.NoError(t, err)
+
+	runIndividualTestCase(t, testCase{
+		serviceName: "banks",
+		inputName:   "www.test.com/(",
+		outputName:  "www.test.com/(",
+	}, tp)
+	assert.Equal(t, 1, logs.Len())
+}
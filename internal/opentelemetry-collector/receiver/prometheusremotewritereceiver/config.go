@@ -0,0 +1,35 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewritereceiver
+
+import (
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configmodels"
+)
+
+// Config defines configuration for the Prometheus remote write receiver.
+type Config struct {
+	configmodels.ReceiverSettings `mapstructure:",squash"`
+
+	// Configures the receiver server protocol.
+	confighttp.HTTPServerSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// MaxRequestBodySize is the maximum size in bytes of the compressed body of a request. Zero disables the limit.
+	MaxRequestBodySize int64 `mapstructure:"max_request_body_size"`
+
+	// MaxDecodedSize is the maximum size in bytes of the WriteRequest of a request once decompressed, which is checked
+	// before it is decompressed. Zero disables the limit.
+	MaxDecodedSize int64 `mapstructure:"max_decoded_size"`
+}
@@ -0,0 +1,212 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewritereceiver
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenterror"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/pdatautil"
+	"go.opentelemetry.io/collector/obsreport"
+)
+
+const (
+	receiverTransport = "http"
+	dataFormat        = "prometheus_remote_write"
+)
+
+var errNextConsumerRespBody = []byte(`"Internal Server Error"`)
+
+var (
+	errRequestBodyTooLarge = errors.New("request body exceeds the maximum size")
+	errDecodedTooLarge     = errors.New("decoded request exceeds the maximum size")
+)
+
+// Receiver receives Prometheus remote write requests over HTTP, and passes the metrics they hold to the next consumer.
+type Receiver struct {
+	// mu protects the fields of this struct
+	mu sync.Mutex
+
+	host         component.Host
+	nextConsumer consumer.MetricsConsumer
+	instanceName string
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	server    *http.Server
+	config    *Config
+}
+
+var _ http.Handler = (*Receiver)(nil)
+
+// New creates a new prometheusremotewritereceiver.Receiver reference.
+func New(config *Config, nextConsumer consumer.MetricsConsumer) (*Receiver, error) {
+	if nextConsumer == nil {
+		return nil, componenterror.ErrNilNextConsumer
+	}
+
+	r := &Receiver{
+		nextConsumer: nextConsumer,
+		instanceName: config.Name(),
+		config:       config,
+	}
+	return r, nil
+}
+
+// Start spins up the receiver's HTTP server and makes the receiver start its processing.
+func (r *Receiver) Start(_ context.Context, host component.Host) error {
+	if host == nil {
+		return errors.New("nil host")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err = componenterror.ErrAlreadyStarted
+
+	r.startOnce.Do(func() {
+		err = nil
+		r.host = host
+		r.server = r.config.HTTPServerSettings.ToServer(r)
+		var listener net.Listener
+		listener, err = r.config.HTTPServerSettings.ToListener()
+		if err != nil {
+			host.ReportFatalError(err)
+			return
+		}
+		go func() {
+			if err := r.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+				host.ReportFatalError(err)
+			}
+		}()
+	})
+
+	return err
+}
+
+// Shutdown tells the receiver that should stop reception, giving it a chance to perform any necessary clean-up and
+// shutting down its HTTP server.
+func (r *Receiver) Shutdown(context.Context) error {
+	var err = componenterror.ErrAlreadyStopped
+	r.stopOnce.Do(func() {
+		err = r.server.Close()
+	})
+	return err
+}
+
+// ServeHTTP decodes a Snappy-compressed remote write request, translates its TimeSeries to metrics and passes them to
+// the next consumer. As expected by remote write clients, malformed requests and metrics rejected permanently are
+// answered with 400 and requests over the maximum sizes with 413 so that they are not retried, and other failures of
+// the next consumer with 500.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := req.Context()
+	if c, ok := client.FromHTTP(req); ok {
+		ctx = client.NewContext(ctx, c)
+	}
+	ctx = obsreport.ReceiverContext(ctx, r.instanceName, receiverTransport, dataFormat)
+	ctx = obsreport.StartMetricsReceiveOp(ctx, r.instanceName, receiverTransport)
+
+	wr, err := decodeWriteRequest(w, req, r.config)
+	if err != nil {
+		obsreport.EndMetricsReceiveOp(ctx, dataFormat, 0, 0, err)
+		status := http.StatusBadRequest
+		if err == errRequestBodyTooLarge || err == errDecodedTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	md, err := writeRequestToMetrics(wr)
+	if err != nil {
+		obsreport.EndMetricsReceiveOp(ctx, dataFormat, 0, 0, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	metricCount, dataPointCount := md.MetricAndDataPointCount()
+	if metricCount == 0 {
+		obsreport.EndMetricsReceiveOp(ctx, dataFormat, 0, 0, nil)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	consumerErr := r.nextConsumer.ConsumeMetrics(ctx, pdatautil.MetricsFromOldInternalMetrics(md))
+	obsreport.EndMetricsReceiveOp(ctx, dataFormat, dataPointCount, metricCount, consumerErr)
+
+	if consumerErr != nil {
+		if consumererror.IsPermanent(consumerErr) {
+			http.Error(w, consumerErr.Error(), http.StatusBadRequest)
+			return
+		}
+		// Transient error, due to some internal condition.
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(errNextConsumerRespBody)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeWriteRequest reads the Snappy-compressed WriteRequest of the body of req. Bodies larger than the maximum
+// request body size are not read past it, and WriteRequests larger than the maximum decoded size are not decompressed.
+func decodeWriteRequest(w http.ResponseWriter, req *http.Request, config *Config) (*prompb.WriteRequest, error) {
+	body := req.Body
+	if config.MaxRequestBodySize > 0 {
+		body = http.MaxBytesReader(w, body, config.MaxRequestBodySize)
+	}
+	compressed, err := ioutil.ReadAll(body)
+	_ = body.Close()
+	if err != nil {
+		// the body is only cut short at the limit by the MaxBytesReader
+		if config.MaxRequestBodySize > 0 && int64(len(compressed)) >= config.MaxRequestBodySize {
+			return nil, errRequestBodyTooLarge
+		}
+		return nil, err
+	}
+	size, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		return nil, err
+	}
+	if config.MaxDecodedSize > 0 && int64(size) > config.MaxDecodedSize {
+		return nil, errDecodedTooLarge
+	}
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, err
+	}
+	wr := &prompb.WriteRequest{}
+	if err := proto.Unmarshal(data, wr); err != nil {
+		return nil, err
+	}
+	return wr, nil
+}
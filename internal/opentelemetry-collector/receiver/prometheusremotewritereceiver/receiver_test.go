@@ -0,0 +1,164 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewritereceiver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenterror"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configmodels"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/pdatautil"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/testutil"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(createDefaultConfig().(*Config), nil)
+	assert.Equal(t, componenterror.ErrNilNextConsumer, err)
+}
+
+func encodeWriteRequest(t *testing.T, wr *prompb.WriteRequest) []byte {
+	data, err := proto.Marshal(wr)
+	require.NoError(t, err)
+	return snappy.Encode(nil, data)
+}
+
+// TestReceiver checks that remote write requests are answered and their metrics passed to the next consumer.
+func TestReceiver(t *testing.T) {
+	addr := testutil.GetAvailableLocalAddress(t)
+	cfg := &Config{
+		ReceiverSettings: configmodels.ReceiverSettings{
+			TypeVal: typeStr,
+			NameVal: typeStr,
+		},
+		HTTPServerSettings: confighttp.HTTPServerSettings{
+			Endpoint: addr,
+		},
+	}
+	sink := new(exportertest.SinkMetricsExporter)
+	r, err := New(cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, r.Shutdown(context.Background()))
+	}()
+
+	url := "http://" + addr + "/api/v1/write"
+	valid := encodeWriteRequest(t, &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		getTimeSeries([]string{nameStr, "requests_total", "code", "200"}, getSample(10, msTime1)),
+		getTimeSeries([]string{nameStr, "temperature"}, getSample(21.5, msTime1), getSample(22, msTime2)),
+	}})
+
+	tests := []struct {
+		name       string
+		method     string
+		body       []byte
+		consumeErr error
+		status     int
+		// metrics is the number of metrics expected to be passed to the next consumer
+		metrics int
+	}{
+		{"valid", http.MethodPost, valid, nil, http.StatusNoContent, 2},
+		{"empty", http.MethodPost, encodeWriteRequest(t, &prompb.WriteRequest{}), nil, http.StatusNoContent, 0},
+		{"not_snappy", http.MethodPost, []byte("not snappy"), nil, http.StatusBadRequest, 0},
+		{"not_protobuf", http.MethodPost, snappy.Encode(nil, []byte{0xff}), nil, http.StatusBadRequest, 0},
+		{"invalid_series", http.MethodPost, encodeWriteRequest(t, &prompb.WriteRequest{
+			Timeseries: []prompb.TimeSeries{getTimeSeries([]string{"job", "a"}, getSample(1, msTime1))},
+		}), nil, http.StatusBadRequest, 0},
+		{"consumer_error", http.MethodPost, valid, errors.New("retry"), http.StatusInternalServerError, 0},
+		{"permanent_consumer_error", http.MethodPost, valid, consumererror.Permanent(errors.New("dropped")),
+			http.StatusBadRequest, 0},
+		{"wrong_method", http.MethodGet, nil, nil, http.StatusMethodNotAllowed, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink.Reset()
+			sink.SetConsumeMetricsError(tt.consumeErr)
+
+			req, err := http.NewRequest(tt.method, url, bytes.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Encoding", "snappy")
+			req.Header.Set("Content-Type", "application/x-protobuf")
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.metrics, sink.MetricsCount())
+			if tt.metrics > 0 {
+				md := pdatautil.MetricsToOldInternalMetrics(sink.AllMetrics()[0])
+				_, points := md.MetricAndDataPointCount()
+				assert.Equal(t, 3, points)
+			}
+		})
+	}
+}
+
+// TestReceiverMaxSizes checks that requests over the maximum body size or decoded size are rejected.
+func TestReceiverMaxSizes(t *testing.T) {
+	valid := encodeWriteRequest(t, &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		getTimeSeries([]string{nameStr, "temperature", "location", strings.Repeat("a", 100)}, getSample(21.5, msTime1)),
+	}})
+	decodedSize, err := snappy.DecodedLen(valid)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name               string
+		maxRequestBodySize int64
+		maxDecodedSize     int64
+		status             int
+	}{
+		{"unlimited", 0, 0, http.StatusNoContent},
+		{"within_limits", int64(len(valid)), int64(decodedSize), http.StatusNoContent},
+		{"body_too_large", int64(len(valid)) - 1, 0, http.StatusRequestEntityTooLarge},
+		{"decoded_too_large", 0, int64(decodedSize) - 1, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.MaxRequestBodySize = tt.maxRequestBodySize
+			cfg.MaxDecodedSize = tt.maxDecodedSize
+			sink := new(exportertest.SinkMetricsExporter)
+			r, err := New(cfg, sink)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/write", bytes.NewReader(valid))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusNoContent {
+				assert.Equal(t, 1, sink.MetricsCount())
+			} else {
+				assert.Equal(t, 0, sink.MetricsCount())
+			}
+		})
+	}
+}
@@ -0,0 +1,409 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewritereceiver
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/pkg/value"
+	"github.com/prometheus/prometheus/prompb"
+
+	otlpcommon "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/common/v1"
+	otlp "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1old"
+	otlpresource "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/resource/v1"
+	"go.opentelemetry.io/collector/internal/dataold"
+)
+
+const (
+	nameStr     = "__name__"
+	sumStr      = "_sum"
+	countStr    = "_count"
+	bucketStr   = "_bucket"
+	totalStr    = "_total"
+	leStr       = "le"
+	quantileStr = "quantile"
+)
+
+// metricKind is the kind of metric a TimeSeries is translated to.
+type metricKind int
+
+const (
+	gaugeKind metricKind = iota
+	counterKind
+	histogramKind
+	summaryKind
+)
+
+// seriesPart tells which part of a histogram or a summary a TimeSeries holds.
+type seriesPart int
+
+const (
+	valuePart seriesPart = iota
+	sumPart
+	countPart
+	bucketPart
+	quantilePart
+)
+
+// familyKey identifies the metric a TimeSeries belongs to.
+type familyKey struct {
+	name string
+	kind metricKind
+}
+
+// family accumulates the points of a metric, in the order their Samples are received.
+type family struct {
+	metric *otlp.Metric
+	// points are the points of a histogram or a summary, keyed by their labels and timestamp
+	points map[string]*point
+	order  []string
+}
+
+// point accumulates the Samples of the TimeSeries of a histogram or a summary which share labels and a timestamp.
+type point struct {
+	labels    []*otlpcommon.StringKeyValue
+	timestamp uint64
+	sum       float64
+	count     float64
+	hasCount  bool
+	// buckets are the cumulative counts of the buckets, keyed by their upper bound
+	buckets map[float64]float64
+	// quantiles are the values of the quantiles, keyed by quantile
+	quantiles map[float64]float64
+}
+
+// writeRequestToMetrics translates the TimeSeries of wr to metrics. TimeSeries are grouped back into metric families:
+//   - TimeSeries named with the _bucket suffix and the le label, and the _sum and _count TimeSeries of the same family,
+//     are translated to cumulative histograms
+//   - TimeSeries with the quantile label, and the _sum and _count TimeSeries of the same family, are translated to
+//     summaries
+//   - TimeSeries named with the _total suffix are translated to cumulative monotonic sums named without the suffix
+//   - any other TimeSeries is translated to a gauge
+//
+// Stale markers are dropped.
+func writeRequestToMetrics(wr *prompb.WriteRequest) (dataold.MetricData, error) {
+	histograms, summaries := findFamilies(wr.Timeseries)
+
+	families := map[familyKey]*family{}
+	var order []familyKey
+	for _, ts := range wr.Timeseries {
+		name, labels := splitName(ts.Labels)
+		if name == "" {
+			return dataold.NewMetricData(), fmt.Errorf("time series without the %s label", nameStr)
+		}
+		key, part := classify(name, labels, histograms, summaries)
+
+		var bound float64
+		switch part {
+		case bucketPart:
+			le, rest := splitLabel(labels, leStr)
+			b, err := strconv.ParseFloat(le, 64)
+			if err != nil {
+				return dataold.NewMetricData(), fmt.Errorf("invalid %s label of %s: %w", leStr, name, err)
+			}
+			bound, labels = b, rest
+		case quantilePart:
+			q, rest := splitLabel(labels, quantileStr)
+			b, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				return dataold.NewMetricData(), fmt.Errorf("invalid %s label of %s: %w", quantileStr, name, err)
+			}
+			bound, labels = b, rest
+		}
+
+		f, ok := families[key]
+		if !ok {
+			f = newFamily(key)
+			families[key] = f
+			order = append(order, key)
+		}
+		for _, sample := range ts.Samples {
+			if value.IsStaleNaN(sample.Value) {
+				continue
+			}
+			timestamp := uint64(sample.Timestamp) * uint64(time.Millisecond)
+			if key.kind == gaugeKind || key.kind == counterKind {
+				f.metric.DoubleDataPoints = append(f.metric.DoubleDataPoints, &otlp.DoubleDataPoint{
+					Labels:       labels,
+					TimeUnixNano: timestamp,
+					Value:        sample.Value,
+				})
+				continue
+			}
+			p := f.point(labels, timestamp)
+			switch part {
+			case sumPart:
+				p.sum = sample.Value
+			case countPart:
+				p.count, p.hasCount = sample.Value, true
+			case bucketPart:
+				p.buckets[bound] = sample.Value
+			case quantilePart:
+				p.quantiles[bound] = sample.Value
+			}
+		}
+	}
+
+	var metrics []*otlp.Metric
+	for _, key := range order {
+		if m := families[key].finish(); m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	if len(metrics) == 0 {
+		return dataold.NewMetricData(), nil
+	}
+	return dataold.MetricDataFromOtlp([]*otlp.ResourceMetrics{{
+		Resource: &otlpresource.Resource{},
+		InstrumentationLibraryMetrics: []*otlp.InstrumentationLibraryMetrics{{
+			Metrics: metrics,
+		}},
+	}}), nil
+}
+
+// findFamilies returns the names of the histogram families and of the summary families of series.
+func findFamilies(series []prompb.TimeSeries) (map[string]bool, map[string]bool) {
+	histograms, summaries := map[string]bool{}, map[string]bool{}
+	for _, ts := range series {
+		var name string
+		var hasLe, hasQuantile bool
+		for _, l := range ts.Labels {
+			switch l.Name {
+			case nameStr:
+				name = l.Value
+			case leStr:
+				hasLe = true
+			case quantileStr:
+				hasQuantile = true
+			}
+		}
+		if hasLe && strings.HasSuffix(name, bucketStr) {
+			histograms[strings.TrimSuffix(name, bucketStr)] = true
+		}
+		if hasQuantile {
+			summaries[name] = true
+		}
+	}
+	return histograms, summaries
+}
+
+// classify returns the metric a TimeSeries named name belongs to, and the part of it the TimeSeries holds.
+func classify(name string, labels []*otlpcommon.StringKeyValue, histograms, summaries map[string]bool) (familyKey,
+	seriesPart) {
+	if base := strings.TrimSuffix(name, bucketStr); base != name && histograms[base] && hasLabel(labels, leStr) {
+		return familyKey{base, histogramKind}, bucketPart
+	}
+	if summaries[name] && hasLabel(labels, quantileStr) {
+		return familyKey{name, summaryKind}, quantilePart
+	}
+	for _, sp := range []struct {
+		suffix string
+		part   seriesPart
+	}{{sumStr, sumPart}, {countStr, countPart}} {
+		base, part := strings.TrimSuffix(name, sp.suffix), sp.part
+		if base == name {
+			continue
+		}
+		if histograms[base] {
+			return familyKey{base, histogramKind}, part
+		}
+		if summaries[base] {
+			return familyKey{base, summaryKind}, part
+		}
+	}
+	if base := strings.TrimSuffix(name, totalStr); base != name && base != "" {
+		return familyKey{base, counterKind}, valuePart
+	}
+	return familyKey{name, gaugeKind}, valuePart
+}
+
+// splitName returns the value of the metric name label of labels, and the other labels.
+func splitName(labels []prompb.Label) (string, []*otlpcommon.StringKeyValue) {
+	var name string
+	kvs := make([]*otlpcommon.StringKeyValue, 0, len(labels))
+	for _, l := range labels {
+		if l.Name == nameStr {
+			name = l.Value
+			continue
+		}
+		kvs = append(kvs, &otlpcommon.StringKeyValue{Key: l.Name, Value: l.Value})
+	}
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+	return name, kvs
+}
+
+// splitLabel returns the value of the label named key of labels, and the other labels.
+func splitLabel(labels []*otlpcommon.StringKeyValue, key string) (string, []*otlpcommon.StringKeyValue) {
+	var value string
+	rest := make([]*otlpcommon.StringKeyValue, 0, len(labels))
+	for _, l := range labels {
+		if l.Key == key {
+			value = l.Value
+			continue
+		}
+		rest = append(rest, l)
+	}
+	return value, rest
+}
+
+func hasLabel(labels []*otlpcommon.StringKeyValue, key string) bool {
+	for _, l := range labels {
+		if l.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func newFamily(key familyKey) *family {
+	descriptor := &otlp.MetricDescriptor{Name: key.name}
+	switch key.kind {
+	case gaugeKind:
+		descriptor.Type = otlp.MetricDescriptor_DOUBLE
+		descriptor.Temporality = otlp.MetricDescriptor_INSTANTANEOUS
+	case counterKind:
+		descriptor.Type = otlp.MetricDescriptor_MONOTONIC_DOUBLE
+		descriptor.Temporality = otlp.MetricDescriptor_CUMULATIVE
+	case histogramKind:
+		descriptor.Type = otlp.MetricDescriptor_HISTOGRAM
+		descriptor.Temporality = otlp.MetricDescriptor_CUMULATIVE
+	case summaryKind:
+		descriptor.Type = otlp.MetricDescriptor_SUMMARY
+		descriptor.Temporality = otlp.MetricDescriptor_CUMULATIVE
+	}
+	return &family{
+		metric: &otlp.Metric{MetricDescriptor: descriptor},
+		points: map[string]*point{},
+	}
+}
+
+// point returns the point of f with labels and timestamp, and adds it if it does not exist yet.
+func (f *family) point(labels []*otlpcommon.StringKeyValue, timestamp uint64) *point {
+	var b strings.Builder
+	for _, l := range labels {
+		b.WriteString(l.Key)
+		b.WriteByte(0xff)
+		b.WriteString(l.Value)
+		b.WriteByte(0xff)
+	}
+	b.WriteString(strconv.FormatUint(timestamp, 10))
+	sig := b.String()
+
+	p, ok := f.points[sig]
+	if !ok {
+		p = &point{
+			labels:    labels,
+			timestamp: timestamp,
+			buckets:   map[float64]float64{},
+			quantiles: map[float64]float64{},
+		}
+		f.points[sig] = p
+		f.order = append(f.order, sig)
+	}
+	return p
+}
+
+// finish returns the metric of f with its points, or nil if it has none.
+func (f *family) finish() *otlp.Metric {
+	for _, sig := range f.order {
+		p := f.points[sig]
+		switch f.metric.MetricDescriptor.Type {
+		case otlp.MetricDescriptor_HISTOGRAM:
+			f.metric.HistogramDataPoints = append(f.metric.HistogramDataPoints, p.histogramPoint())
+		case otlp.MetricDescriptor_SUMMARY:
+			f.metric.SummaryDataPoints = append(f.metric.SummaryDataPoints, p.summaryPoint())
+		}
+	}
+	if len(f.metric.DoubleDataPoints) == 0 && len(f.metric.HistogramDataPoints) == 0 &&
+		len(f.metric.SummaryDataPoints) == 0 {
+		return nil
+	}
+	return f.metric
+}
+
+// histogramPoint returns the histogram data point of p. Prometheus buckets count the observations less than or equal
+// to their upper bound, so the count of each bucket is the difference with the previous one, and the last bucket
+// counts the observations above the last finite bound.
+func (p *point) histogramPoint() *otlp.HistogramDataPoint {
+	bounds := make([]float64, 0, len(p.buckets))
+	for bound := range p.buckets {
+		bounds = append(bounds, bound)
+	}
+	sort.Float64s(bounds)
+
+	total, hasTotal := p.count, p.hasCount
+	if inf, ok := p.buckets[math.Inf(1)]; ok && !hasTotal {
+		total, hasTotal = inf, true
+	}
+
+	hp := &otlp.HistogramDataPoint{
+		Labels:       p.labels,
+		TimeUnixNano: p.timestamp,
+		Sum:          p.sum,
+	}
+	previous := 0.0
+	for _, bound := range bounds {
+		if math.IsInf(bound, 1) {
+			continue
+		}
+		cumulative := p.buckets[bound]
+		hp.ExplicitBounds = append(hp.ExplicitBounds, bound)
+		hp.Buckets = append(hp.Buckets, &otlp.HistogramDataPoint_Bucket{Count: bucketCount(cumulative - previous)})
+		previous = cumulative
+	}
+	if !hasTotal {
+		total = previous
+	}
+	hp.Buckets = append(hp.Buckets, &otlp.HistogramDataPoint_Bucket{Count: bucketCount(total - previous)})
+	hp.Count = bucketCount(total)
+	return hp
+}
+
+// summaryPoint returns the summary data point of p. Quantiles are converted to percentiles.
+func (p *point) summaryPoint() *otlp.SummaryDataPoint {
+	quantiles := make([]float64, 0, len(p.quantiles))
+	for q := range p.quantiles {
+		quantiles = append(quantiles, q)
+	}
+	sort.Float64s(quantiles)
+
+	sp := &otlp.SummaryDataPoint{
+		Labels:       p.labels,
+		TimeUnixNano: p.timestamp,
+		Count:        bucketCount(p.count),
+		Sum:          p.sum,
+	}
+	for _, q := range quantiles {
+		sp.PercentileValues = append(sp.PercentileValues, &otlp.SummaryDataPoint_ValueAtPercentile{
+			Percentile: q * 100,
+			Value:      p.quantiles[q],
+		})
+	}
+	return sp
+}
+
+// bucketCount returns count as an integer count, which is 0 if count is negative because a counter was reset between
+// the TimeSeries of a point.
+func bucketCount(count float64) uint64 {
+	if count <= 0 || math.IsNaN(count) {
+		return 0
+	}
+	return uint64(math.Round(count))
+}
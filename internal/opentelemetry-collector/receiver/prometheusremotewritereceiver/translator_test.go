@@ -0,0 +1,215 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewritereceiver
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/prometheus/pkg/value"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	otlpcommon "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/common/v1"
+	otlp "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1old"
+	"go.opentelemetry.io/collector/internal/dataold"
+)
+
+const (
+	msTime1 = int64(1600000000000)
+	msTime2 = int64(1600000015000)
+	nsTime1 = uint64(msTime1) * 1e6
+	nsTime2 = uint64(msTime2) * 1e6
+)
+
+func getTimeSeries(labels []string, samples ...prompb.Sample) prompb.TimeSeries {
+	ts := prompb.TimeSeries{Samples: samples}
+	for i := 0; i+1 < len(labels); i += 2 {
+		ts.Labels = append(ts.Labels, prompb.Label{Name: labels[i], Value: labels[i+1]})
+	}
+	return ts
+}
+
+func getSample(v float64, ms int64) prompb.Sample {
+	return prompb.Sample{Value: v, Timestamp: ms}
+}
+
+func getLabels(pairs ...string) []*otlpcommon.StringKeyValue {
+	labels := make([]*otlpcommon.StringKeyValue, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		labels = append(labels, &otlpcommon.StringKeyValue{Key: pairs[i], Value: pairs[i+1]})
+	}
+	return labels
+}
+
+func getDescriptor(name string, ty otlp.MetricDescriptor_Type,
+	temporality otlp.MetricDescriptor_Temporality) *otlp.MetricDescriptor {
+	return &otlp.MetricDescriptor{Name: name, Type: ty, Temporality: temporality}
+}
+
+// getMetrics returns the metrics of the first resource of md.
+func getMetrics(t *testing.T, md dataold.MetricData) []*otlp.Metric {
+	rms := dataold.MetricDataToOtlp(md)
+	if len(rms) == 0 {
+		return nil
+	}
+	require.Len(t, rms, 1)
+	require.Len(t, rms[0].InstrumentationLibraryMetrics, 1)
+	return rms[0].InstrumentationLibraryMetrics[0].Metrics
+}
+
+// Test_writeRequestToMetrics checks that each kind of Prometheus metric family is translated back to metrics.
+func Test_writeRequestToMetrics(t *testing.T) {
+	tests := []struct {
+		name     string
+		series   []prompb.TimeSeries
+		expected []*otlp.Metric
+	}{
+		{
+			name: "gauge",
+			series: []prompb.TimeSeries{
+				getTimeSeries([]string{nameStr, "temperature", "room", "b", "floor", "1"},
+					getSample(21.5, msTime1), getSample(22, msTime2)),
+			},
+			expected: []*otlp.Metric{{
+				MetricDescriptor: getDescriptor("temperature", otlp.MetricDescriptor_DOUBLE,
+					otlp.MetricDescriptor_INSTANTANEOUS),
+				DoubleDataPoints: []*otlp.DoubleDataPoint{
+					{Labels: getLabels("floor", "1", "room", "b"), TimeUnixNano: nsTime1, Value: 21.5},
+					{Labels: getLabels("floor", "1", "room", "b"), TimeUnixNano: nsTime2, Value: 22},
+				},
+			}},
+		},
+		{
+			name: "counter",
+			series: []prompb.TimeSeries{
+				getTimeSeries([]string{nameStr, "requests_total", "code", "200"}, getSample(10, msTime1)),
+				getTimeSeries([]string{nameStr, "requests_total", "code", "500"}, getSample(2, msTime1)),
+			},
+			expected: []*otlp.Metric{{
+				MetricDescriptor: getDescriptor("requests", otlp.MetricDescriptor_MONOTONIC_DOUBLE,
+					otlp.MetricDescriptor_CUMULATIVE),
+				DoubleDataPoints: []*otlp.DoubleDataPoint{
+					{Labels: getLabels("code", "200"), TimeUnixNano: nsTime1, Value: 10},
+					{Labels: getLabels("code", "500"), TimeUnixNano: nsTime1, Value: 2},
+				},
+			}},
+		},
+		{
+			name: "histogram",
+			series: []prompb.TimeSeries{
+				getTimeSeries([]string{nameStr, "latency_bucket", "le", "0.1", "path", "/"}, getSample(3, msTime1)),
+				getTimeSeries([]string{nameStr, "latency_bucket", "le", "1", "path", "/"}, getSample(5, msTime1)),
+				getTimeSeries([]string{nameStr, "latency_bucket", "le", "+Inf", "path", "/"}, getSample(6, msTime1)),
+				getTimeSeries([]string{nameStr, "latency_sum", "path", "/"}, getSample(4.2, msTime1)),
+				getTimeSeries([]string{nameStr, "latency_count", "path", "/"}, getSample(6, msTime1)),
+			},
+			expected: []*otlp.Metric{{
+				MetricDescriptor: getDescriptor("latency", otlp.MetricDescriptor_HISTOGRAM,
+					otlp.MetricDescriptor_CUMULATIVE),
+				HistogramDataPoints: []*otlp.HistogramDataPoint{{
+					Labels:       getLabels("path", "/"),
+					TimeUnixNano: nsTime1,
+					Count:        6,
+					Sum:          4.2,
+					Buckets: []*otlp.HistogramDataPoint_Bucket{
+						{Count: 3}, {Count: 2}, {Count: 1},
+					},
+					ExplicitBounds: []float64{0.1, 1},
+				}},
+			}},
+		},
+		{
+			name: "summary",
+			series: []prompb.TimeSeries{
+				getTimeSeries([]string{nameStr, "rpc", "quantile", "0.99"}, getSample(9, msTime1)),
+				getTimeSeries([]string{nameStr, "rpc", "quantile", "0.5"}, getSample(4, msTime1)),
+				getTimeSeries([]string{nameStr, "rpc_sum"}, getSample(50, msTime1)),
+				getTimeSeries([]string{nameStr, "rpc_count"}, getSample(10, msTime1)),
+			},
+			expected: []*otlp.Metric{{
+				MetricDescriptor: getDescriptor("rpc", otlp.MetricDescriptor_SUMMARY, otlp.MetricDescriptor_CUMULATIVE),
+				SummaryDataPoints: []*otlp.SummaryDataPoint{{
+					Labels:       getLabels(),
+					TimeUnixNano: nsTime1,
+					Count:        10,
+					Sum:          50,
+					PercentileValues: []*otlp.SummaryDataPoint_ValueAtPercentile{
+						{Percentile: 50, Value: 4},
+						{Percentile: 99, Value: 9},
+					},
+				}},
+			}},
+		},
+		{
+			name: "sum_and_count_without_family",
+			series: []prompb.TimeSeries{
+				getTimeSeries([]string{nameStr, "bytes_sum"}, getSample(1, msTime1)),
+			},
+			expected: []*otlp.Metric{{
+				MetricDescriptor: getDescriptor("bytes_sum", otlp.MetricDescriptor_DOUBLE,
+					otlp.MetricDescriptor_INSTANTANEOUS),
+				DoubleDataPoints: []*otlp.DoubleDataPoint{
+					{Labels: getLabels(), TimeUnixNano: nsTime1, Value: 1},
+				},
+			}},
+		},
+		{
+			name: "stale_markers_dropped",
+			series: []prompb.TimeSeries{
+				getTimeSeries([]string{nameStr, "up"}, getSample(1, msTime1),
+					getSample(math.Float64frombits(value.StaleNaN), msTime2)),
+				getTimeSeries([]string{nameStr, "gone"}, getSample(math.Float64frombits(value.StaleNaN), msTime2)),
+			},
+			expected: []*otlp.Metric{{
+				MetricDescriptor: getDescriptor("up", otlp.MetricDescriptor_DOUBLE,
+					otlp.MetricDescriptor_INSTANTANEOUS),
+				DoubleDataPoints: []*otlp.DoubleDataPoint{
+					{Labels: getLabels(), TimeUnixNano: nsTime1, Value: 1},
+				},
+			}},
+		},
+		{
+			name:   "empty",
+			series: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md, err := writeRequestToMetrics(&prompb.WriteRequest{Timeseries: tt.series})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, getMetrics(t, md))
+		})
+	}
+}
+
+// Test_writeRequestToMetricsErrors checks that TimeSeries which cannot be translated are rejected.
+func Test_writeRequestToMetricsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		series prompb.TimeSeries
+	}{
+		{"no_name", getTimeSeries([]string{"job", "a"}, getSample(1, msTime1))},
+		{"invalid_le", getTimeSeries([]string{nameStr, "a_bucket", "le", "x"}, getSample(1, msTime1))},
+		{"invalid_quantile", getTimeSeries([]string{nameStr, "a", "quantile", "x"}, getSample(1, msTime1))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := writeRequestToMetrics(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{tt.series}})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -36,6 +36,7 @@ func TestDefaultComponents(t *testing.T) {
 		"jaeger",
 		"zipkin",
 		"prometheus",
+		"prometheusremotewrite",
 		"opencensus",
 		"otlp",
 		"hostmetrics",
@@ -53,6 +54,7 @@ func TestDefaultComponents(t *testing.T) {
 		"span",
 		"filter",
 		"groupbytrace",
+		"metricstransform",
 	}
 	expectedExporters := []configmodels.Type{
 		"opencensus",
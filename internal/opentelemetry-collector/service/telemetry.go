@@ -25,7 +25,11 @@ import (
 	"go.opencensus.io/stats/view"
 	"go.uber.org/zap"
 
+	"github.com/open-o11y/opentelemetry-collector-o11y/exporter/cortexexporter"
+
+	"go.opentelemetry.io/collector/exporter/prometheusremotewriteexporter"
 	"go.opentelemetry.io/collector/internal/collector/telemetry"
+	"go.opentelemetry.io/collector/internal/processor/filterexpr"
 	"go.opentelemetry.io/collector/obsreport"
 	"go.opentelemetry.io/collector/processor"
 	"go.opentelemetry.io/collector/processor/batchprocessor"
@@ -75,6 +79,9 @@ func (tel *appTelemetry) init(asyncErrorChannel chan<- error, ballastSizeBytes u
 	views = append(views, kafkareceiver.MetricViews()...)
 	views = append(views, processMetricsViews.Views()...)
 	views = append(views, fluentobserv.Views(level)...)
+	views = append(views, filterexpr.MetricViews()...)
+	views = append(views, prometheusremotewriteexporter.MetricViews()...)
+	views = append(views, cortexexporter.MetricViews()...)
 	tel.views = views
 	if err = view.Register(views...); err != nil {
 		return err
@@ -0,0 +1,197 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internaldata
+
+import (
+	"go.opentelemetry.io/collector/internal/data"
+	otlpmetrics "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1"
+	otlpmetricsold "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1old"
+	"go.opentelemetry.io/collector/internal/dataold"
+)
+
+// MetricDataToOldMetricData converts metrics of the new internal model to the old one, so that they can be consumed
+// by the components that only support the old model.
+//
+// Resources, instrumentation libraries and labels are shared with md instead of being copied. Exemplars are not
+// converted, and the sums of int histograms are converted to doubles. A metric without data is converted to a metric
+// of an invalid type.
+func MetricDataToOldMetricData(md data.MetricData) dataold.MetricData {
+	rms := data.MetricDataToOtlp(md)
+	oldRms := make([]*otlpmetricsold.ResourceMetrics, 0, len(rms))
+	for _, rm := range rms {
+		if rm == nil {
+			continue
+		}
+		oldRm := &otlpmetricsold.ResourceMetrics{
+			Resource:                      rm.Resource,
+			InstrumentationLibraryMetrics: make([]*otlpmetricsold.InstrumentationLibraryMetrics, 0, len(rm.InstrumentationLibraryMetrics)),
+		}
+		for _, ilm := range rm.InstrumentationLibraryMetrics {
+			if ilm == nil {
+				continue
+			}
+			oldIlm := &otlpmetricsold.InstrumentationLibraryMetrics{
+				InstrumentationLibrary: ilm.InstrumentationLibrary,
+				Metrics:                make([]*otlpmetricsold.Metric, 0, len(ilm.Metrics)),
+			}
+			for _, metric := range ilm.Metrics {
+				if metric == nil {
+					continue
+				}
+				oldIlm.Metrics = append(oldIlm.Metrics, metricToOldMetric(metric))
+			}
+			oldRm.InstrumentationLibraryMetrics = append(oldRm.InstrumentationLibraryMetrics, oldIlm)
+		}
+		oldRms = append(oldRms, oldRm)
+	}
+	return dataold.MetricDataFromOtlp(oldRms)
+}
+
+func metricToOldMetric(metric *otlpmetrics.Metric) *otlpmetricsold.Metric {
+	desc := &otlpmetricsold.MetricDescriptor{
+		Name:        metric.Name,
+		Description: metric.Description,
+		Unit:        metric.Unit,
+	}
+	oldMetric := &otlpmetricsold.Metric{MetricDescriptor: desc}
+
+	switch data := metric.Data.(type) {
+	case *otlpmetrics.Metric_IntGauge:
+		desc.Type = otlpmetricsold.MetricDescriptor_INT64
+		desc.Temporality = otlpmetricsold.MetricDescriptor_INSTANTANEOUS
+		oldMetric.Int64DataPoints = intDataPointsToOldIntDataPoints(data.IntGauge.GetDataPoints())
+	case *otlpmetrics.Metric_IntSum:
+		desc.Type = otlpmetricsold.MetricDescriptor_INT64
+		if data.IntSum.GetIsMonotonic() {
+			desc.Type = otlpmetricsold.MetricDescriptor_MONOTONIC_INT64
+		}
+		desc.Temporality = temporalityToOldTemporality(data.IntSum.GetAggregationTemporality())
+		oldMetric.Int64DataPoints = intDataPointsToOldIntDataPoints(data.IntSum.GetDataPoints())
+	case *otlpmetrics.Metric_DoubleGauge:
+		desc.Type = otlpmetricsold.MetricDescriptor_DOUBLE
+		desc.Temporality = otlpmetricsold.MetricDescriptor_INSTANTANEOUS
+		oldMetric.DoubleDataPoints = doubleDataPointsToOldDoubleDataPoints(data.DoubleGauge.GetDataPoints())
+	case *otlpmetrics.Metric_DoubleSum:
+		desc.Type = otlpmetricsold.MetricDescriptor_DOUBLE
+		if data.DoubleSum.GetIsMonotonic() {
+			desc.Type = otlpmetricsold.MetricDescriptor_MONOTONIC_DOUBLE
+		}
+		desc.Temporality = temporalityToOldTemporality(data.DoubleSum.GetAggregationTemporality())
+		oldMetric.DoubleDataPoints = doubleDataPointsToOldDoubleDataPoints(data.DoubleSum.GetDataPoints())
+	case *otlpmetrics.Metric_IntHistogram:
+		desc.Type = otlpmetricsold.MetricDescriptor_HISTOGRAM
+		desc.Temporality = temporalityToOldTemporality(data.IntHistogram.GetAggregationTemporality())
+		oldMetric.HistogramDataPoints = intHistogramDataPointsToOldHistogramDataPoints(data.IntHistogram.GetDataPoints())
+	case *otlpmetrics.Metric_DoubleHistogram:
+		desc.Type = otlpmetricsold.MetricDescriptor_HISTOGRAM
+		desc.Temporality = temporalityToOldTemporality(data.DoubleHistogram.GetAggregationTemporality())
+		oldMetric.HistogramDataPoints = doubleHistogramDataPointsToOldHistogramDataPoints(data.DoubleHistogram.GetDataPoints())
+	}
+	return oldMetric
+}
+
+// temporalityToOldTemporality maps an aggregation temporality to the temporality of the old model.
+func temporalityToOldTemporality(temporality otlpmetrics.AggregationTemporality) otlpmetricsold.MetricDescriptor_Temporality {
+	switch temporality {
+	case otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA:
+		return otlpmetricsold.MetricDescriptor_DELTA
+	case otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE:
+		return otlpmetricsold.MetricDescriptor_CUMULATIVE
+	}
+	return otlpmetricsold.MetricDescriptor_INVALID_TEMPORALITY
+}
+
+func intDataPointsToOldIntDataPoints(points []*otlpmetrics.IntDataPoint) []*otlpmetricsold.Int64DataPoint {
+	oldPoints := make([]*otlpmetricsold.Int64DataPoint, 0, len(points))
+	for _, point := range points {
+		if point == nil {
+			continue
+		}
+		oldPoints = append(oldPoints, &otlpmetricsold.Int64DataPoint{
+			Labels:            point.Labels,
+			StartTimeUnixNano: point.StartTimeUnixNano,
+			TimeUnixNano:      point.TimeUnixNano,
+			Value:             point.Value,
+		})
+	}
+	return oldPoints
+}
+
+func doubleDataPointsToOldDoubleDataPoints(points []*otlpmetrics.DoubleDataPoint) []*otlpmetricsold.DoubleDataPoint {
+	oldPoints := make([]*otlpmetricsold.DoubleDataPoint, 0, len(points))
+	for _, point := range points {
+		if point == nil {
+			continue
+		}
+		oldPoints = append(oldPoints, &otlpmetricsold.DoubleDataPoint{
+			Labels:            point.Labels,
+			StartTimeUnixNano: point.StartTimeUnixNano,
+			TimeUnixNano:      point.TimeUnixNano,
+			Value:             point.Value,
+		})
+	}
+	return oldPoints
+}
+
+func intHistogramDataPointsToOldHistogramDataPoints(
+	points []*otlpmetrics.IntHistogramDataPoint) []*otlpmetricsold.HistogramDataPoint {
+	oldPoints := make([]*otlpmetricsold.HistogramDataPoint, 0, len(points))
+	for _, point := range points {
+		if point == nil {
+			continue
+		}
+		oldPoints = append(oldPoints, &otlpmetricsold.HistogramDataPoint{
+			Labels:            point.Labels,
+			StartTimeUnixNano: point.StartTimeUnixNano,
+			TimeUnixNano:      point.TimeUnixNano,
+			Count:             point.Count,
+			Sum:               float64(point.Sum),
+			Buckets:           bucketCountsToOldBuckets(point.BucketCounts),
+			ExplicitBounds:    point.ExplicitBounds,
+		})
+	}
+	return oldPoints
+}
+
+func doubleHistogramDataPointsToOldHistogramDataPoints(
+	points []*otlpmetrics.DoubleHistogramDataPoint) []*otlpmetricsold.HistogramDataPoint {
+	oldPoints := make([]*otlpmetricsold.HistogramDataPoint, 0, len(points))
+	for _, point := range points {
+		if point == nil {
+			continue
+		}
+		oldPoints = append(oldPoints, &otlpmetricsold.HistogramDataPoint{
+			Labels:            point.Labels,
+			StartTimeUnixNano: point.StartTimeUnixNano,
+			TimeUnixNano:      point.TimeUnixNano,
+			Count:             point.Count,
+			Sum:               point.Sum,
+			Buckets:           bucketCountsToOldBuckets(point.BucketCounts),
+			ExplicitBounds:    point.ExplicitBounds,
+		})
+	}
+	return oldPoints
+}
+
+func bucketCountsToOldBuckets(bucketCounts []uint64) []*otlpmetricsold.HistogramDataPoint_Bucket {
+	if bucketCounts == nil {
+		return nil
+	}
+	buckets := make([]*otlpmetricsold.HistogramDataPoint_Bucket, 0, len(bucketCounts))
+	for _, count := range bucketCounts {
+		buckets = append(buckets, &otlpmetricsold.HistogramDataPoint_Bucket{Count: count})
+	}
+	return buckets
+}
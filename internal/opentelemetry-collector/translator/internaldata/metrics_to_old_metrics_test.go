@@ -0,0 +1,157 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internaldata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/internal/data"
+	otlpcommon "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/common/v1"
+	otlpmetrics "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1"
+	otlpmetricsold "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1old"
+	otlpresource "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/resource/v1"
+	"go.opentelemetry.io/collector/internal/dataold"
+)
+
+func TestMetricDataToOldMetricData(t *testing.T) {
+	resource := &otlpresource.Resource{
+		Attributes: []*otlpcommon.KeyValue{{Key: "host.name", Value: &otlpcommon.AnyValue{}}},
+	}
+	labels := []*otlpcommon.StringKeyValue{{Key: "k", Value: "v"}}
+	intPoints := []*otlpmetrics.IntDataPoint{{Labels: labels, StartTimeUnixNano: 1, TimeUnixNano: 2, Value: 3}, nil}
+	doublePoints := []*otlpmetrics.DoubleDataPoint{{Labels: labels, StartTimeUnixNano: 1, TimeUnixNano: 2, Value: 3.5}}
+	cumulative := otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+
+	md := data.MetricDataFromOtlp([]*otlpmetrics.ResourceMetrics{
+		nil,
+		{
+			Resource: resource,
+			InstrumentationLibraryMetrics: []*otlpmetrics.InstrumentationLibraryMetrics{
+				nil,
+				{
+					Metrics: []*otlpmetrics.Metric{
+						nil,
+						{
+							Name: "gauge", Description: "d", Unit: "1",
+							Data: &otlpmetrics.Metric_IntGauge{IntGauge: &otlpmetrics.IntGauge{DataPoints: intPoints}},
+						},
+						{
+							Name: "counter", Description: "d", Unit: "1",
+							Data: &otlpmetrics.Metric_IntSum{IntSum: &otlpmetrics.IntSum{
+								DataPoints:             intPoints,
+								AggregationTemporality: cumulative,
+								IsMonotonic:            true,
+							}},
+						},
+						{
+							Name: "double_gauge", Description: "d", Unit: "1",
+							Data: &otlpmetrics.Metric_DoubleGauge{DoubleGauge: &otlpmetrics.DoubleGauge{DataPoints: doublePoints}},
+						},
+						{
+							Name: "sum", Description: "d", Unit: "1",
+							Data: &otlpmetrics.Metric_DoubleSum{DoubleSum: &otlpmetrics.DoubleSum{
+								DataPoints:             doublePoints,
+								AggregationTemporality: otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+							}},
+						},
+						{
+							Name: "int_histogram", Description: "d", Unit: "1",
+							Data: &otlpmetrics.Metric_IntHistogram{IntHistogram: &otlpmetrics.IntHistogram{
+								DataPoints: []*otlpmetrics.IntHistogramDataPoint{{
+									Labels:         labels,
+									TimeUnixNano:   2,
+									Count:          3,
+									Sum:            4,
+									BucketCounts:   []uint64{1, 2},
+									ExplicitBounds: []float64{1},
+								}},
+								AggregationTemporality: cumulative,
+							}},
+						},
+						{
+							Name: "histogram", Description: "d", Unit: "1",
+							Data: &otlpmetrics.Metric_DoubleHistogram{DoubleHistogram: &otlpmetrics.DoubleHistogram{
+								DataPoints:             []*otlpmetrics.DoubleHistogramDataPoint{{Labels: labels, Sum: 4.5}},
+								AggregationTemporality: cumulative,
+							}},
+						},
+						{Name: "no_data"},
+					},
+				},
+			},
+		},
+	})
+
+	descriptor := func(name string, ty otlpmetricsold.MetricDescriptor_Type,
+		temporality otlpmetricsold.MetricDescriptor_Temporality) *otlpmetricsold.MetricDescriptor {
+		return &otlpmetricsold.MetricDescriptor{Name: name, Description: "d", Unit: "1", Type: ty, Temporality: temporality}
+	}
+	expectedIntPoints := []*otlpmetricsold.Int64DataPoint{{Labels: labels, StartTimeUnixNano: 1, TimeUnixNano: 2, Value: 3}}
+	expectedDoublePoints := []*otlpmetricsold.DoubleDataPoint{{Labels: labels, StartTimeUnixNano: 1, TimeUnixNano: 2, Value: 3.5}}
+	expected := dataold.MetricDataFromOtlp([]*otlpmetricsold.ResourceMetrics{
+		{
+			Resource: resource,
+			InstrumentationLibraryMetrics: []*otlpmetricsold.InstrumentationLibraryMetrics{
+				{
+					Metrics: []*otlpmetricsold.Metric{
+						{
+							MetricDescriptor: descriptor("gauge", otlpmetricsold.MetricDescriptor_INT64,
+								otlpmetricsold.MetricDescriptor_INSTANTANEOUS),
+							Int64DataPoints: expectedIntPoints,
+						},
+						{
+							MetricDescriptor: descriptor("counter", otlpmetricsold.MetricDescriptor_MONOTONIC_INT64,
+								otlpmetricsold.MetricDescriptor_CUMULATIVE),
+							Int64DataPoints: expectedIntPoints,
+						},
+						{
+							MetricDescriptor: descriptor("double_gauge", otlpmetricsold.MetricDescriptor_DOUBLE,
+								otlpmetricsold.MetricDescriptor_INSTANTANEOUS),
+							DoubleDataPoints: expectedDoublePoints,
+						},
+						{
+							MetricDescriptor: descriptor("sum", otlpmetricsold.MetricDescriptor_DOUBLE,
+								otlpmetricsold.MetricDescriptor_DELTA),
+							DoubleDataPoints: expectedDoublePoints,
+						},
+						{
+							MetricDescriptor: descriptor("int_histogram", otlpmetricsold.MetricDescriptor_HISTOGRAM,
+								otlpmetricsold.MetricDescriptor_CUMULATIVE),
+							HistogramDataPoints: []*otlpmetricsold.HistogramDataPoint{{
+								Labels:         labels,
+								TimeUnixNano:   2,
+								Count:          3,
+								Sum:            4,
+								Buckets:        []*otlpmetricsold.HistogramDataPoint_Bucket{{Count: 1}, {Count: 2}},
+								ExplicitBounds: []float64{1},
+							}},
+						},
+						{
+							MetricDescriptor: descriptor("histogram", otlpmetricsold.MetricDescriptor_HISTOGRAM,
+								otlpmetricsold.MetricDescriptor_CUMULATIVE),
+							HistogramDataPoints: []*otlpmetricsold.HistogramDataPoint{{Labels: labels, Sum: 4.5}},
+						},
+						{MetricDescriptor: &otlpmetricsold.MetricDescriptor{Name: "no_data"}},
+					},
+				},
+			},
+		},
+	})
+
+	assert.EqualValues(t, expected, MetricDataToOldMetricData(md))
+	assert.EqualValues(t, 0, MetricDataToOldMetricData(data.NewMetricData()).MetricCount())
+}
@@ -0,0 +1,340 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internaldata
+
+import (
+	"go.opentelemetry.io/collector/internal/data"
+	otlpmetrics "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1"
+	otlpmetricsold "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1old"
+	"go.opentelemetry.io/collector/internal/dataold"
+)
+
+// OldMetricDataToMetricData converts metrics of the old internal model to the new one.
+//
+// Resources, instrumentation libraries and labels are shared with md instead of being copied. The new model has no
+// summary type, so summaries are skipped, and histogram bucket exemplars are not converted. A metric without a
+// descriptor, of an invalid type, or without data points of its type is converted to a metric without data.
+func OldMetricDataToMetricData(md dataold.MetricData) data.MetricData {
+	return oldMetricDataToMetricData(md, nil)
+}
+
+// OldMetricDataToMetricDataAndSummaries converts metrics of the old internal model to the new one like
+// OldMetricDataToMetricData, and also returns the summaries of md, which the new model cannot represent, in the old
+// model. The summaries are split from md as it is converted, and they share their resources and data with md.
+func OldMetricDataToMetricDataAndSummaries(md dataold.MetricData) (data.MetricData, dataold.MetricData) {
+	summaries := []*otlpmetricsold.ResourceMetrics{}
+	return oldMetricDataToMetricData(md, &summaries), dataold.MetricDataFromOtlp(summaries)
+}
+
+// oldMetricDataToMetricData converts md to the new model, and appends the summaries of md to summaries unless it is
+// nil.
+func oldMetricDataToMetricData(md dataold.MetricData, summaries *[]*otlpmetricsold.ResourceMetrics) data.MetricData {
+	oldRms := dataold.MetricDataToOtlp(md)
+	rms := make([]*otlpmetrics.ResourceMetrics, 0, len(oldRms))
+	for _, oldRm := range oldRms {
+		if oldRm == nil {
+			continue
+		}
+		rm := &otlpmetrics.ResourceMetrics{
+			Resource:                      oldRm.Resource,
+			InstrumentationLibraryMetrics: make([]*otlpmetrics.InstrumentationLibraryMetrics, 0, len(oldRm.InstrumentationLibraryMetrics)),
+		}
+		// only resources and instrumentation libraries holding summaries are split
+		var summaryRm *otlpmetricsold.ResourceMetrics
+		for _, oldIlm := range oldRm.InstrumentationLibraryMetrics {
+			if oldIlm == nil {
+				continue
+			}
+			ilm := &otlpmetrics.InstrumentationLibraryMetrics{
+				InstrumentationLibrary: oldIlm.InstrumentationLibrary,
+				Metrics:                make([]*otlpmetrics.Metric, 0, len(oldIlm.Metrics)),
+			}
+			alloc := newMetricAllocator(oldIlm.Metrics)
+			var summaryIlm *otlpmetricsold.InstrumentationLibraryMetrics
+			for _, oldMetric := range oldIlm.Metrics {
+				if oldMetric == nil {
+					continue
+				}
+				if oldMetric.GetMetricDescriptor().GetType() != otlpmetricsold.MetricDescriptor_SUMMARY {
+					ilm.Metrics = append(ilm.Metrics, alloc.oldMetricToMetric(oldMetric))
+					continue
+				}
+				if summaries == nil {
+					continue
+				}
+				if summaryRm == nil {
+					summaryRm = &otlpmetricsold.ResourceMetrics{Resource: oldRm.Resource}
+					*summaries = append(*summaries, summaryRm)
+				}
+				if summaryIlm == nil {
+					summaryIlm = &otlpmetricsold.InstrumentationLibraryMetrics{InstrumentationLibrary: oldIlm.InstrumentationLibrary}
+					summaryRm.InstrumentationLibraryMetrics = append(summaryRm.InstrumentationLibraryMetrics, summaryIlm)
+				}
+				summaryIlm.Metrics = append(summaryIlm.Metrics, oldMetric)
+			}
+			rm.InstrumentationLibraryMetrics = append(rm.InstrumentationLibraryMetrics, ilm)
+		}
+		rms = append(rms, rm)
+	}
+	return data.MetricDataFromOtlp(rms)
+}
+
+// metricAllocator allocates the metrics of an instrumentation library converted to the new model, and their data, in
+// blocks sized for all the metrics rather than one by one.
+type metricAllocator struct {
+	metrics         []otlpmetrics.Metric
+	intGauges       []intGauge
+	intSums         []intSum
+	doubleGauges    []doubleGauge
+	doubleSums      []doubleSum
+	histograms      []doubleHistogram
+	intPoints       []otlpmetrics.IntDataPoint
+	intPtrs         []*otlpmetrics.IntDataPoint
+	doublePoints    []otlpmetrics.DoubleDataPoint
+	doublePtrs      []*otlpmetrics.DoubleDataPoint
+	histogramPoints []otlpmetrics.DoubleHistogramDataPoint
+	histogramPtrs   []*otlpmetrics.DoubleHistogramDataPoint
+	bucketCounts    []uint64
+}
+
+// The data of a metric is allocated with the field of the metric holding it.
+type intGauge struct {
+	field otlpmetrics.Metric_IntGauge
+	data  otlpmetrics.IntGauge
+}
+
+type intSum struct {
+	field otlpmetrics.Metric_IntSum
+	data  otlpmetrics.IntSum
+}
+
+type doubleGauge struct {
+	field otlpmetrics.Metric_DoubleGauge
+	data  otlpmetrics.DoubleGauge
+}
+
+type doubleSum struct {
+	field otlpmetrics.Metric_DoubleSum
+	data  otlpmetrics.DoubleSum
+}
+
+type doubleHistogram struct {
+	field otlpmetrics.Metric_DoubleHistogram
+	data  otlpmetrics.DoubleHistogram
+}
+
+// newMetricAllocator returns a metricAllocator holding the blocks needed to convert oldMetrics. Metrics of each type
+// are counted as both gauges and sums, as the metric type of the new model is only known on conversion.
+func newMetricAllocator(oldMetrics []*otlpmetricsold.Metric) *metricAllocator {
+	var metrics, ints, doubles, histograms, intPoints, doublePoints, histogramPoints, buckets int
+	for _, oldMetric := range oldMetrics {
+		if oldMetric == nil || oldMetric.GetMetricDescriptor().GetType() == otlpmetricsold.MetricDescriptor_SUMMARY {
+			continue
+		}
+		metrics++
+		switch oldMetric.GetMetricDescriptor().GetType() {
+		case otlpmetricsold.MetricDescriptor_INT64, otlpmetricsold.MetricDescriptor_MONOTONIC_INT64:
+			ints++
+			intPoints += len(oldMetric.Int64DataPoints)
+		case otlpmetricsold.MetricDescriptor_DOUBLE, otlpmetricsold.MetricDescriptor_MONOTONIC_DOUBLE:
+			doubles++
+			doublePoints += len(oldMetric.DoubleDataPoints)
+		case otlpmetricsold.MetricDescriptor_HISTOGRAM:
+			histograms++
+			histogramPoints += len(oldMetric.HistogramDataPoints)
+			for _, oldPoint := range oldMetric.HistogramDataPoints {
+				buckets += len(oldPoint.GetBuckets())
+			}
+		}
+	}
+
+	alloc := &metricAllocator{metrics: make([]otlpmetrics.Metric, metrics)}
+	if ints > 0 {
+		alloc.intGauges = make([]intGauge, ints)
+		alloc.intSums = make([]intSum, ints)
+		alloc.intPoints = make([]otlpmetrics.IntDataPoint, intPoints)
+		alloc.intPtrs = make([]*otlpmetrics.IntDataPoint, 0, intPoints)
+	}
+	if doubles > 0 {
+		alloc.doubleGauges = make([]doubleGauge, doubles)
+		alloc.doubleSums = make([]doubleSum, doubles)
+		alloc.doublePoints = make([]otlpmetrics.DoubleDataPoint, doublePoints)
+		alloc.doublePtrs = make([]*otlpmetrics.DoubleDataPoint, 0, doublePoints)
+	}
+	if histograms > 0 {
+		alloc.histograms = make([]doubleHistogram, histograms)
+		alloc.histogramPoints = make([]otlpmetrics.DoubleHistogramDataPoint, histogramPoints)
+		alloc.histogramPtrs = make([]*otlpmetrics.DoubleHistogramDataPoint, 0, histogramPoints)
+		alloc.bucketCounts = make([]uint64, buckets)
+	}
+	return alloc
+}
+
+func (a *metricAllocator) oldMetricToMetric(oldMetric *otlpmetricsold.Metric) *otlpmetrics.Metric {
+	desc := oldMetric.GetMetricDescriptor()
+	metric := &a.metrics[0]
+	a.metrics = a.metrics[1:]
+	metric.Name = desc.GetName()
+	metric.Description = desc.GetDescription()
+	metric.Unit = desc.GetUnit()
+	temporality := oldTemporalityToTemporality(desc.GetTemporality())
+
+	switch desc.GetType() {
+	case otlpmetricsold.MetricDescriptor_INT64, otlpmetricsold.MetricDescriptor_MONOTONIC_INT64:
+		if oldMetric.Int64DataPoints == nil {
+			break
+		}
+		points := a.oldIntDataPointsToIntDataPoints(oldMetric.Int64DataPoints)
+		monotonic := desc.GetType() == otlpmetricsold.MetricDescriptor_MONOTONIC_INT64
+		if !monotonic && temporality == otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED {
+			gauge := &a.intGauges[0]
+			a.intGauges = a.intGauges[1:]
+			gauge.data.DataPoints = points
+			gauge.field.IntGauge = &gauge.data
+			metric.Data = &gauge.field
+			break
+		}
+		sum := &a.intSums[0]
+		a.intSums = a.intSums[1:]
+		sum.data = otlpmetrics.IntSum{
+			DataPoints:             points,
+			AggregationTemporality: temporality,
+			IsMonotonic:            monotonic,
+		}
+		sum.field.IntSum = &sum.data
+		metric.Data = &sum.field
+	case otlpmetricsold.MetricDescriptor_DOUBLE, otlpmetricsold.MetricDescriptor_MONOTONIC_DOUBLE:
+		if oldMetric.DoubleDataPoints == nil {
+			break
+		}
+		points := a.oldDoubleDataPointsToDoubleDataPoints(oldMetric.DoubleDataPoints)
+		monotonic := desc.GetType() == otlpmetricsold.MetricDescriptor_MONOTONIC_DOUBLE
+		if !monotonic && temporality == otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED {
+			gauge := &a.doubleGauges[0]
+			a.doubleGauges = a.doubleGauges[1:]
+			gauge.data.DataPoints = points
+			gauge.field.DoubleGauge = &gauge.data
+			metric.Data = &gauge.field
+			break
+		}
+		sum := &a.doubleSums[0]
+		a.doubleSums = a.doubleSums[1:]
+		sum.data = otlpmetrics.DoubleSum{
+			DataPoints:             points,
+			AggregationTemporality: temporality,
+			IsMonotonic:            monotonic,
+		}
+		sum.field.DoubleSum = &sum.data
+		metric.Data = &sum.field
+	case otlpmetricsold.MetricDescriptor_HISTOGRAM:
+		if oldMetric.HistogramDataPoints == nil {
+			break
+		}
+		histogram := &a.histograms[0]
+		a.histograms = a.histograms[1:]
+		histogram.data = otlpmetrics.DoubleHistogram{
+			DataPoints:             a.oldHistogramDataPointsToDoubleHistogramDataPoints(oldMetric.HistogramDataPoints),
+			AggregationTemporality: temporality,
+		}
+		histogram.field.DoubleHistogram = &histogram.data
+		metric.Data = &histogram.field
+	}
+	return metric
+}
+
+// oldTemporalityToTemporality maps the temporality of the old model to an aggregation temporality. Instantaneous
+// metrics are not aggregated, so their aggregation temporality is unspecified.
+func oldTemporalityToTemporality(temporality otlpmetricsold.MetricDescriptor_Temporality) otlpmetrics.AggregationTemporality {
+	switch temporality {
+	case otlpmetricsold.MetricDescriptor_DELTA:
+		return otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA
+	case otlpmetricsold.MetricDescriptor_CUMULATIVE:
+		return otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+	}
+	return otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED
+}
+
+// oldIntDataPointsToIntDataPoints converts oldPoints, skipping nil ones. The converted points are a full slice of the
+// block of pointers to the points of all the metrics, so that appending to them cannot overwrite the points of other
+// metrics. The same holds for the other data point types.
+func (a *metricAllocator) oldIntDataPointsToIntDataPoints(oldPoints []*otlpmetricsold.Int64DataPoint) []*otlpmetrics.IntDataPoint {
+	start := len(a.intPtrs)
+	for _, oldPoint := range oldPoints {
+		if oldPoint == nil {
+			continue
+		}
+		point := &a.intPoints[0]
+		a.intPoints = a.intPoints[1:]
+		*point = otlpmetrics.IntDataPoint{
+			Labels:            oldPoint.Labels,
+			StartTimeUnixNano: oldPoint.StartTimeUnixNano,
+			TimeUnixNano:      oldPoint.TimeUnixNano,
+			Value:             oldPoint.Value,
+		}
+		a.intPtrs = append(a.intPtrs, point)
+	}
+	return a.intPtrs[start:len(a.intPtrs):len(a.intPtrs)]
+}
+
+func (a *metricAllocator) oldDoubleDataPointsToDoubleDataPoints(oldPoints []*otlpmetricsold.DoubleDataPoint) []*otlpmetrics.DoubleDataPoint {
+	start := len(a.doublePtrs)
+	for _, oldPoint := range oldPoints {
+		if oldPoint == nil {
+			continue
+		}
+		point := &a.doublePoints[0]
+		a.doublePoints = a.doublePoints[1:]
+		*point = otlpmetrics.DoubleDataPoint{
+			Labels:            oldPoint.Labels,
+			StartTimeUnixNano: oldPoint.StartTimeUnixNano,
+			TimeUnixNano:      oldPoint.TimeUnixNano,
+			Value:             oldPoint.Value,
+		}
+		a.doublePtrs = append(a.doublePtrs, point)
+	}
+	return a.doublePtrs[start:len(a.doublePtrs):len(a.doublePtrs)]
+}
+
+func (a *metricAllocator) oldHistogramDataPointsToDoubleHistogramDataPoints(
+	oldPoints []*otlpmetricsold.HistogramDataPoint) []*otlpmetrics.DoubleHistogramDataPoint {
+	start := len(a.histogramPtrs)
+	for _, oldPoint := range oldPoints {
+		if oldPoint == nil {
+			continue
+		}
+		var bucketCounts []uint64
+		if oldPoint.Buckets != nil {
+			n := len(oldPoint.Buckets)
+			bucketCounts = a.bucketCounts[:0:n]
+			a.bucketCounts = a.bucketCounts[n:]
+			for _, bucket := range oldPoint.Buckets {
+				bucketCounts = append(bucketCounts, bucket.GetCount())
+			}
+		}
+		point := &a.histogramPoints[0]
+		a.histogramPoints = a.histogramPoints[1:]
+		*point = otlpmetrics.DoubleHistogramDataPoint{
+			Labels:            oldPoint.Labels,
+			StartTimeUnixNano: oldPoint.StartTimeUnixNano,
+			TimeUnixNano:      oldPoint.TimeUnixNano,
+			Count:             oldPoint.Count,
+			Sum:               oldPoint.Sum,
+			BucketCounts:      bucketCounts,
+			ExplicitBounds:    oldPoint.ExplicitBounds,
+		}
+		a.histogramPtrs = append(a.histogramPtrs, point)
+	}
+	return a.histogramPtrs[start:len(a.histogramPtrs):len(a.histogramPtrs)]
+}
@@ -0,0 +1,165 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internaldata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/internal/data"
+	otlpcommon "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/common/v1"
+	otlpmetrics "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1"
+	otlpmetricsold "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/metrics/v1old"
+	otlpresource "go.opentelemetry.io/collector/internal/data/opentelemetry-proto-gen/resource/v1"
+	"go.opentelemetry.io/collector/internal/dataold"
+)
+
+func TestOldMetricDataToMetricData(t *testing.T) {
+	resource := &otlpresource.Resource{
+		Attributes: []*otlpcommon.KeyValue{{Key: "host.name", Value: &otlpcommon.AnyValue{}}},
+	}
+	labels := []*otlpcommon.StringKeyValue{{Key: "k", Value: "v"}}
+	descriptor := func(name string, ty otlpmetricsold.MetricDescriptor_Type,
+		temporality otlpmetricsold.MetricDescriptor_Temporality) *otlpmetricsold.MetricDescriptor {
+		return &otlpmetricsold.MetricDescriptor{Name: name, Description: "d", Unit: "1", Type: ty, Temporality: temporality}
+	}
+	intPoints := []*otlpmetricsold.Int64DataPoint{{Labels: labels, StartTimeUnixNano: 1, TimeUnixNano: 2, Value: 3}, nil}
+	doublePoints := []*otlpmetricsold.DoubleDataPoint{{Labels: labels, StartTimeUnixNano: 1, TimeUnixNano: 2, Value: 3.5}}
+	summary := &otlpmetricsold.Metric{
+		MetricDescriptor: descriptor("summary", otlpmetricsold.MetricDescriptor_SUMMARY,
+			otlpmetricsold.MetricDescriptor_CUMULATIVE),
+		SummaryDataPoints: []*otlpmetricsold.SummaryDataPoint{{Labels: labels}},
+	}
+
+	old := dataold.MetricDataFromOtlp([]*otlpmetricsold.ResourceMetrics{
+		nil,
+		{
+			Resource: resource,
+			InstrumentationLibraryMetrics: []*otlpmetricsold.InstrumentationLibraryMetrics{
+				nil,
+				{
+					Metrics: []*otlpmetricsold.Metric{
+						nil,
+						{
+							MetricDescriptor: descriptor("gauge", otlpmetricsold.MetricDescriptor_INT64,
+								otlpmetricsold.MetricDescriptor_INSTANTANEOUS),
+							Int64DataPoints: intPoints,
+						},
+						{
+							MetricDescriptor: descriptor("counter", otlpmetricsold.MetricDescriptor_MONOTONIC_INT64,
+								otlpmetricsold.MetricDescriptor_CUMULATIVE),
+							Int64DataPoints: intPoints,
+						},
+						{
+							MetricDescriptor: descriptor("sum", otlpmetricsold.MetricDescriptor_DOUBLE,
+								otlpmetricsold.MetricDescriptor_DELTA),
+							DoubleDataPoints: doublePoints,
+						},
+						{
+							MetricDescriptor: descriptor("histogram", otlpmetricsold.MetricDescriptor_HISTOGRAM,
+								otlpmetricsold.MetricDescriptor_CUMULATIVE),
+							HistogramDataPoints: []*otlpmetricsold.HistogramDataPoint{{
+								Labels:         labels,
+								TimeUnixNano:   2,
+								Count:          3,
+								Sum:            4.5,
+								Buckets:        []*otlpmetricsold.HistogramDataPoint_Bucket{{Count: 1}, {Count: 2}},
+								ExplicitBounds: []float64{1},
+							}},
+						},
+						summary,
+						{
+							MetricDescriptor: descriptor("no_points", otlpmetricsold.MetricDescriptor_MONOTONIC_DOUBLE,
+								otlpmetricsold.MetricDescriptor_CUMULATIVE),
+							Int64DataPoints: intPoints,
+						},
+						{},
+					},
+				},
+			},
+		},
+	})
+
+	expectedIntPoints := []*otlpmetrics.IntDataPoint{{Labels: labels, StartTimeUnixNano: 1, TimeUnixNano: 2, Value: 3}}
+	expected := data.MetricDataFromOtlp([]*otlpmetrics.ResourceMetrics{
+		{
+			Resource: resource,
+			InstrumentationLibraryMetrics: []*otlpmetrics.InstrumentationLibraryMetrics{
+				{
+					Metrics: []*otlpmetrics.Metric{
+						{
+							Name: "gauge", Description: "d", Unit: "1",
+							Data: &otlpmetrics.Metric_IntGauge{IntGauge: &otlpmetrics.IntGauge{DataPoints: expectedIntPoints}},
+						},
+						{
+							Name: "counter", Description: "d", Unit: "1",
+							Data: &otlpmetrics.Metric_IntSum{IntSum: &otlpmetrics.IntSum{
+								DataPoints:             expectedIntPoints,
+								AggregationTemporality: otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+								IsMonotonic:            true,
+							}},
+						},
+						{
+							Name: "sum", Description: "d", Unit: "1",
+							Data: &otlpmetrics.Metric_DoubleSum{DoubleSum: &otlpmetrics.DoubleSum{
+								DataPoints: []*otlpmetrics.DoubleDataPoint{
+									{Labels: labels, StartTimeUnixNano: 1, TimeUnixNano: 2, Value: 3.5},
+								},
+								AggregationTemporality: otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+							}},
+						},
+						{
+							Name: "histogram", Description: "d", Unit: "1",
+							Data: &otlpmetrics.Metric_DoubleHistogram{DoubleHistogram: &otlpmetrics.DoubleHistogram{
+								DataPoints: []*otlpmetrics.DoubleHistogramDataPoint{{
+									Labels:         labels,
+									TimeUnixNano:   2,
+									Count:          3,
+									Sum:            4.5,
+									BucketCounts:   []uint64{1, 2},
+									ExplicitBounds: []float64{1},
+								}},
+								AggregationTemporality: otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+							}},
+						},
+						{Name: "no_points", Description: "d", Unit: "1"},
+						{},
+					},
+				},
+			},
+		},
+	})
+
+	assert.EqualValues(t, expected, OldMetricDataToMetricData(old))
+	assert.EqualValues(t, data.NewMetricData().MetricCount(), OldMetricDataToMetricData(dataold.NewMetricData()).MetricCount())
+
+	metrics, summaries := OldMetricDataToMetricDataAndSummaries(old)
+	assert.EqualValues(t, expected, metrics)
+	assert.EqualValues(t, dataold.MetricDataFromOtlp([]*otlpmetricsold.ResourceMetrics{
+		{
+			Resource: resource,
+			InstrumentationLibraryMetrics: []*otlpmetricsold.InstrumentationLibraryMetrics{
+				{Metrics: []*otlpmetricsold.Metric{summary}},
+			},
+		},
+	}), summaries)
+	// the summaries are shared with the converted metrics rather than copied
+	assert.Same(t, summary, dataold.MetricDataToOtlp(summaries)[0].InstrumentationLibraryMetrics[0].Metrics[0])
+
+	metrics, summaries = OldMetricDataToMetricDataAndSummaries(dataold.NewMetricData())
+	assert.EqualValues(t, 0, metrics.MetricCount())
+	assert.EqualValues(t, 0, summaries.MetricCount())
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// fakeCortex is a local stand-in of Cortex. It stores the Samples of the remote write requests it receives, and answers
+// instant queries selecting a metric by name with the latest Sample of each of its series, in the format of the
+// Prometheus HTTP API.
+type fakeCortex struct {
+	mu sync.Mutex
+	// series are the series received, keyed by their sorted labels
+	series map[string]*fakeSeries
+}
+
+// fakeSeries is a series received by fakeCortex.
+type fakeSeries struct {
+	labels  map[string]string
+	samples []prompb.Sample
+}
+
+func newFakeCortex() *fakeCortex {
+	return &fakeCortex{series: map[string]*fakeSeries{}}
+}
+
+// ServeHTTP stores the remote write requests posted to /api/v1/push, and answers queries sent to /api/v1/query.
+func (c *fakeCortex) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/api/v1/push":
+		c.push(w, r)
+	case "/api/v1/query":
+		c.query(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (c *fakeCortex) push(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	data, err := snappy.Decode(nil, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := &prompb.WriteRequest{}
+	if err = proto.Unmarshal(data, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, ts := range req.Timeseries {
+		labels := map[string]string{}
+		for _, l := range ts.Labels {
+			labels[l.Name] = l.Value
+		}
+		key := labelsKey(labels)
+		s, ok := c.series[key]
+		if !ok {
+			s = &fakeSeries{labels: labels}
+			c.series[key] = s
+		}
+		s.samples = append(s.samples, ts.Samples...)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// queryResult is an element of the result of an instant query of the Prometheus HTTP API.
+type queryResult struct {
+	Metric map[string]string `json:"metric"`
+	Value  [2]interface{}    `json:"value"`
+}
+
+func (c *fakeCortex) query(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("query")
+
+	c.mu.Lock()
+	var keys []string
+	for key, s := range c.series {
+		if s.labels["__name__"] == name && len(s.samples) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	results := make([]queryResult, 0, len(keys))
+	for _, key := range keys {
+		s := c.series[key]
+		latest := s.samples[len(s.samples)-1]
+		results = append(results, queryResult{
+			Metric: s.labels,
+			Value:  [2]interface{}{float64(latest.Timestamp) / 1000, strconv.FormatFloat(latest.Value, 'f', -1, 64)},
+		})
+	}
+	c.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data": map[string]interface{}{
+			"resultType": "vector",
+			"result":     results,
+		},
+	})
+}
+
+// latest returns the latest value of each series received, keyed by their sorted labels.
+func (c *fakeCortex) latest() map[string]float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	values := make(map[string]float64, len(c.series))
+	for key, s := range c.series {
+		values[key] = s.samples[len(s.samples)-1].Value
+	}
+	return values
+}
+
+// labelsKey returns the labels of a series sorted by name, in the form of {name="value", ...}.
+func labelsKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + strconv.Quote(labels[name])
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
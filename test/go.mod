@@ -1,12 +1,22 @@
-module github.com/o11y/openetelemetry-collector-o11y/exporter/cortexexporter/test/
+module github.com/o11y/openetelemetry-collector-o11y/exporter/cortexexporter/test
 
 go 1.14
 
+replace (
+	github.com/open-o11y/opentelemetry-collector-o11y => ../
+	go.opentelemetry.io/collector => ../internal/opentelemetry-collector
+)
+
 require (
 	github.com/aws/aws-sdk-go v1.34.13
-	github.com/golang/protobuf v1.4.2 // indirect
-	github.com/grpc-ecosystem/grpc-gateway v1.14.7 // indirect
+	github.com/gogo/protobuf v1.3.1
+	github.com/golang/snappy v0.0.1
+	github.com/open-o11y/opentelemetry-collector-o11y v0.0.0-00010101000000-000000000000
 	github.com/open-telemetry/opentelemetry-proto v0.4.0
+	github.com/prometheus/prometheus v1.8.2-0.20200626085723-c448ada63d83
+	github.com/spf13/viper v1.7.1
+	github.com/stretchr/testify v1.6.1
 	github.com/tidwall/gjson v1.6.1
+	go.opentelemetry.io/collector v0.9.0
 	google.golang.org/grpc v1.31.0
 )
@@ -55,12 +55,15 @@ var (
 	awsRegion  = "us-west-2"
 )
 func init() {
-	log.Println("initializing test pipeline...")
-
 	rand.Seed(time.Now().UnixNano())
 	randomSuffix = strconv.Itoa(rand.Intn(5000))
+}
+
+// initQueryClient attaches the sig v4 signer to the client of the querier. It is not done in init, so that the
+// hermetic pipeline test querying a local stand-in of Cortex does not look up AWS credentials.
+func initQueryClient() {
+	log.Println("initializing test pipeline...")
 
-	// attach sig v4 signer for querier
 	interceptor, err := NewAuth(awsService, awsRegion, http.DefaultTransport)
 	if err != nil {
 		log.Println(err)
@@ -73,7 +76,10 @@ func init() {
 	}
 	log.Println("finished.")
 }
+
 func main() {
+	initQueryClient()
+
 	log.Println("waiting for the Collector to start...")
 
 	// wait for collector to start
@@ -92,7 +92,8 @@ func (s *sender) sendMetric(m *metrics.Metric) {
 		},
 	}
 	// specifc
-	ctx, _ := context.WithTimeout(context.Background(), requestTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
 	_, err := s.client.Export(ctx, &request)
 	time.Sleep(waitTime)
 	if err != nil {
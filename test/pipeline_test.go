@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-o11y/opentelemetry-collector-o11y/exporter/cortexexporter"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configmodels"
+	"go.opentelemetry.io/collector/receiver/otlpreceiver"
+	"go.opentelemetry.io/collector/service"
+	"go.opentelemetry.io/collector/testutil"
+)
+
+// pipelineConfig is the configuration of the Collector under test, receiving OTLP metrics on the address given first
+// and exporting them to the remote write endpoint given second.
+const pipelineConfig = `
+receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: "%s"
+exporters:
+  cortex:
+    endpoint: "%s"
+    namespace: ""
+    timeout: 10s
+service:
+  pipelines:
+    metrics:
+      receivers: [otlp]
+      exporters: [cortex]
+`
+
+// startCollector starts a Collector with the OTLP receiver and the Cortex exporter configured by cfg, and returns a
+// function shutting it down.
+func startCollector(t *testing.T, cfg string) func() {
+	receivers, err := component.MakeReceiverFactoryMap(otlpreceiver.NewFactory())
+	require.NoError(t, err)
+	exporters, err := component.MakeExporterFactoryMap(cortexexporter.NewFactory())
+	require.NoError(t, err)
+
+	app, err := service.New(service.Parameters{
+		ApplicationStartInfo: componenttest.TestApplicationStartInfo(),
+		ConfigFactory: func(v *viper.Viper, factories component.Factories) (*configmodels.Config, error) {
+			v.SetConfigType("yaml")
+			if err := v.ReadConfig(strings.NewReader(cfg)); err != nil {
+				return nil, err
+			}
+			return config.Load(v, factories)
+		},
+		Factories: component.Factories{Receivers: receivers, Exporters: exporters},
+	})
+	require.NoError(t, err)
+	app.Command().SetArgs([]string{"--metrics-level=NONE"})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Start()
+	}()
+	for state := range app.GetStateChannel() {
+		if state == service.Running {
+			break
+		}
+	}
+	return func() {
+		app.SignalTestComplete()
+		assert.NoError(t, <-done)
+	}
+}
+
+// expectedSeries returns the latest value of each series the metrics written to path by generateData are exported as,
+// keyed by their sorted labels.
+func expectedSeries(t *testing.T, path string) map[string]float64 {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	series := map[string]float64{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		params := strings.Split(strings.Trim(scanner.Text(), space), delimeter)
+		require.Len(t, params, 4)
+		name := strings.Trim(params[0], space)
+		pairs := strings.Split(strings.Trim(params[2], space), space)
+		add := func(value float64, extra ...string) {
+			labels := map[string]string{}
+			for i := 0; i+1 < len(pairs); i += 2 {
+				labels[pairs[i]] = pairs[i+1]
+			}
+			for i := 0; i+1 < len(extra); i += 2 {
+				labels[extra[i]] = extra[i+1]
+			}
+			series[labelsKey(labels)] = value
+		}
+
+		switch params[1] {
+		case gauge, counter:
+			add(parseNumber(params[3]), "__name__", name)
+		case histogram:
+			values := parseuUInt64Slice(params[3])
+			add(float64(values[0]), "__name__", name+"_sum")
+			add(float64(values[1]), "__name__", name+"_count")
+			total := uint64(0)
+			for i, bound := range bounds {
+				total += values[2+i]
+				add(float64(values[2+i]), "__name__", name+"_"+bucketStr, "le", strconv.FormatFloat(bound, 'f', -1, 64))
+			}
+			add(float64(total), "__name__", name+"_"+bucketStr, "le", "+Inf")
+		case summary:
+			values := parseFloat64Slice(params[3])
+			add(values[0], "__name__", name+"_sum")
+			add(values[1], "__name__", name+"_count")
+			for i, bound := range bounds {
+				add(values[2+i], "__name__", name, quantileStr, strconv.FormatFloat(bound, 'f', -1, 64))
+			}
+		default:
+			t.Fatalf("invalid metric type %s", params[1])
+		}
+	}
+	require.NoError(t, scanner.Err())
+	return series
+}
+
+// readLines returns the lines of the file at path without leading and trailing white space.
+func readLines(t *testing.T, path string) []string {
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return lines
+}
+
+// TestPipeline sends generated OTLP metrics to a Collector running in process, which exports them with the Cortex
+// exporter to a local stand-in of Cortex, and checks that every series is received with the generated labels and
+// values, and that querying them back gives the generated metrics.
+func TestPipeline(t *testing.T) {
+	cortex := newFakeCortex()
+	server := httptest.NewServer(cortex)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "pipeline")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	inputPath = filepath.Join(dir, "data.txt")
+	outputPath = filepath.Join(dir, "ans.txt")
+	endpoint = testutil.GetAvailableLocalAddress(t)
+	queryPath = server.URL + "/api/v1/query?query="
+	client = http.Client{Timeout: requestTimeout}
+	waitTime = 0
+
+	stop := startCollector(t, fmt.Sprintf(pipelineConfig, endpoint, server.URL+"/api/v1/push"))
+	defer stop()
+
+	generateData()
+	createAndSendLoad()
+
+	expected := expectedSeries(t, inputPath)
+	assert.Eventually(t, func() bool { return len(cortex.latest()) >= len(expected) }, 10*time.Second,
+		10*time.Millisecond)
+	assert.Equal(t, expected, cortex.latest())
+
+	getQueryAndStore(outputPath)
+	output := readLines(t, outputPath)
+	assert.Len(t, output, item)
+	assert.Equal(t, readLines(t, inputPath), output)
+}